@@ -0,0 +1,113 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	db "github.com/DMV-Nicolas/robotgram/backend/db/mongo"
+	"github.com/labstack/echo/v4"
+)
+
+func (server *Server) GetTimelineStats(c echo.Context) error {
+	stats, err := server.queries.GetTimelineStats(context.TODO())
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, stats)
+}
+
+type listTimelineJobsRequest struct {
+	Status string `query:"status" validate:"omitempty,oneof=pending processing done failed"`
+	Offset int64  `query:"offset" validate:"min=0"`
+	Limit  int64  `query:"limit" validate:"min=1"`
+}
+
+func (server *Server) ListTimelineJobs(c echo.Context) error {
+	req := new(listTimelineJobsRequest)
+	if err := bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	arg := db.ListTimelineJobsParams{
+		Status: req.Status,
+		Offset: req.Offset,
+		Limit:  req.Limit,
+	}
+
+	jobs, err := server.queries.ListTimelineJobs(context.TODO(), arg)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, jobs)
+}
+
+type listPurgeJobsRequest struct {
+	Status string `query:"status" validate:"omitempty,oneof=scheduled processing done failed canceled"`
+	Offset int64  `query:"offset" validate:"min=0"`
+	Limit  int64  `query:"limit" validate:"min=1"`
+}
+
+func (server *Server) ListPurgeJobs(c echo.Context) error {
+	req := new(listPurgeJobsRequest)
+	if err := bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	arg := db.ListPurgeJobsParams{
+		Status: req.Status,
+		Offset: req.Offset,
+		Limit:  req.Limit,
+	}
+
+	jobs, err := server.queries.ListPurgeJobs(context.TODO(), arg)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, jobs)
+}
+
+type updateUserRoleRequest struct {
+	ID   string `param:"id" validate:"required,len=24"`
+	Role string `json:"role" validate:"required,oneof=user moderator admin"`
+}
+
+// UpdateUserRole promotes or demotes a user. The privileged routes read the new role at once, the tokens
+// of the user carry it once they are refreshed
+func (server *Server) UpdateUserRole(c echo.Context) error {
+	req := new(updateUserRoleRequest)
+	if err := bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	user, err := server.validUser(c, req.ID)
+	if err != nil {
+		return err
+	}
+
+	payload, err := getAuthorizationPayload(c)
+	if err != nil {
+		return err
+	}
+
+	// an administrator demoting itself could leave nobody to manage the users
+	if user.ID == payload.UserID {
+		err = errors.New("an administrator can't change its own role")
+		return echo.NewHTTPError(http.StatusForbidden, err)
+	}
+
+	arg := db.UpdateUserParams{
+		ID:   user.ID,
+		Role: &req.Role,
+	}
+
+	result, err := server.queries.UpdateUser(context.TODO(), arg)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
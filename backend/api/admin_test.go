@@ -0,0 +1,592 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/DMV-Nicolas/robotgram/backend/db/mock"
+	db "github.com/DMV-Nicolas/robotgram/backend/db/mongo"
+	"github.com/DMV-Nicolas/robotgram/backend/token"
+	"github.com/DMV-Nicolas/robotgram/backend/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestGetTimelineStatsAPI(t *testing.T) {
+	admin, _ := randomUser(t)
+	user, _ := randomUser(t)
+	stats := db.TimelineStats{
+		Pending:         3,
+		Processing:      1,
+		Done:            100,
+		Failed:          2,
+		OldestPendingAt: time.Now().Add(-time.Minute).Truncate(time.Millisecond),
+	}
+
+	testCases := []struct {
+		name          string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockQuerier)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addRoleAuthorization(t, request, tokenMaker, authorizationTypeBearer, admin.ID, util.RoleAdmin, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				expectStoredRole(querier, admin.ID, util.RoleAdmin)
+				querier.EXPECT().
+					GetTimelineStats(gomock.Any()).
+					Times(1).
+					Return(stats, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchTimelineStats(t, recorder.Body, stats)
+			},
+		},
+		{
+			name: "InternalError",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addRoleAuthorization(t, request, tokenMaker, authorizationTypeBearer, admin.ID, util.RoleAdmin, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				expectStoredRole(querier, admin.ID, util.RoleAdmin)
+				querier.EXPECT().
+					GetTimelineStats(gomock.Any()).
+					Times(1).
+					Return(db.TimelineStats{}, mongo.ErrClientDisconnected)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name: "NotAdmin",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				expectStoredRole(querier, user.ID, util.RoleUser)
+				querier.EXPECT().
+					GetTimelineStats(gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "Promoted",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				expectStoredRole(querier, user.ID, util.RoleAdmin)
+				querier.EXPECT().
+					GetTimelineStats(gomock.Any()).
+					Times(1).
+					Return(stats, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Demoted",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addRoleAuthorization(t, request, tokenMaker, authorizationTypeBearer, admin.ID, util.RoleAdmin, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				expectStoredRole(querier, admin.ID, util.RoleModerator)
+				querier.EXPECT().
+					GetTimelineStats(gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "Suspended",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addRoleAuthorization(t, request, tokenMaker, authorizationTypeBearer, admin.ID, util.RoleAdmin, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq("_id"), gomock.Eq(admin.ID)).
+					Times(1).
+					Return(db.User{ID: admin.ID, Role: util.RoleAdmin, SuspendedAt: time.Now()}, nil)
+				querier.EXPECT().
+					GetTimelineStats(gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "UserNotFound",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addRoleAuthorization(t, request, tokenMaker, authorizationTypeBearer, admin.ID, util.RoleAdmin, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq("_id"), gomock.Eq(admin.ID)).
+					Times(1).
+					Return(db.User{}, mongo.ErrNoDocuments)
+				querier.EXPECT().
+					GetTimelineStats(gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "NoAuthorization",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetTimelineStats(gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			queries := mockdb.NewMockQuerier(ctrl)
+			tc.buildStubs(queries)
+
+			// start test server and send request
+			server := newTestServer(t, queries, util.RandomPassword(32))
+			recorder := httptest.NewRecorder()
+
+			url := "/v1/admin/timeline"
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListTimelineJobsAPI(t *testing.T) {
+	admin, _ := randomUser(t)
+	jobs := []db.TimelineJob{
+		{ID: util.RandomID(), Kind: db.TimelineJobFanout, Status: db.TimelineJobFailed, Error: "oops"},
+		{ID: util.RandomID(), Kind: db.TimelineJobBackfill, Status: db.TimelineJobFailed, Error: "oops"},
+	}
+
+	testCases := []struct {
+		name          string
+		query         map[string]any
+		buildStubs    func(store *mockdb.MockQuerier)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			query: map[string]any{
+				"status": db.TimelineJobFailed,
+				"offset": 0,
+				"limit":  10,
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				arg := db.ListTimelineJobsParams{
+					Status: db.TimelineJobFailed,
+					Offset: 0,
+					Limit:  10,
+				}
+
+				expectStoredRole(querier, admin.ID, util.RoleAdmin)
+				querier.EXPECT().
+					ListTimelineJobs(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(jobs, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var bodyResult []db.TimelineJob
+				err := json.NewDecoder(recorder.Body).Decode(&bodyResult)
+				require.NoError(t, err)
+				require.Len(t, bodyResult, len(jobs))
+			},
+		},
+		{
+			name: "InvalidStatus",
+			query: map[string]any{
+				"status": "sleeping",
+				"offset": 0,
+				"limit":  10,
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				expectStoredRole(querier, admin.ID, util.RoleAdmin)
+				querier.EXPECT().
+					ListTimelineJobs(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			query: map[string]any{
+				"status": "",
+				"offset": 0,
+				"limit":  10,
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				expectStoredRole(querier, admin.ID, util.RoleAdmin)
+				querier.EXPECT().
+					ListTimelineJobs(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, mongo.ErrClientDisconnected)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			queries := mockdb.NewMockQuerier(ctrl)
+			tc.buildStubs(queries)
+
+			// start test server and send request
+			server := newTestServer(t, queries, util.RandomPassword(32))
+			recorder := httptest.NewRecorder()
+
+			url := "/v1/admin/timeline/jobs"
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			q := request.URL.Query()
+			q.Add("status", fmt.Sprint(tc.query["status"]))
+			q.Add("offset", fmt.Sprint(tc.query["offset"]))
+			q.Add("limit", fmt.Sprint(tc.query["limit"]))
+			request.URL.RawQuery = q.Encode()
+
+			addRoleAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, admin.ID, util.RoleAdmin, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListPurgeJobsAPI(t *testing.T) {
+	admin, _ := randomUser(t)
+	jobs := []db.PurgeJob{
+		{ID: util.RandomID(), UserID: util.RandomID(), Step: db.PurgeStepLikes, Status: db.PurgeJobFailed, Error: "oops"},
+		{ID: util.RandomID(), UserID: util.RandomID(), Step: db.PurgeStepMedia, Status: db.PurgeJobFailed, Error: "oops"},
+	}
+
+	testCases := []struct {
+		name          string
+		query         map[string]any
+		buildStubs    func(store *mockdb.MockQuerier)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			query: map[string]any{
+				"status": db.PurgeJobFailed,
+				"offset": 0,
+				"limit":  10,
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				arg := db.ListPurgeJobsParams{
+					Status: db.PurgeJobFailed,
+					Offset: 0,
+					Limit:  10,
+				}
+
+				expectStoredRole(querier, admin.ID, util.RoleAdmin)
+				querier.EXPECT().
+					ListPurgeJobs(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(jobs, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var bodyResult []db.PurgeJob
+				err := json.NewDecoder(recorder.Body).Decode(&bodyResult)
+				require.NoError(t, err)
+				require.Len(t, bodyResult, len(jobs))
+			},
+		},
+		{
+			name: "InvalidStatus",
+			query: map[string]any{
+				"status": "sleeping",
+				"offset": 0,
+				"limit":  10,
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				expectStoredRole(querier, admin.ID, util.RoleAdmin)
+				querier.EXPECT().
+					ListPurgeJobs(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			query: map[string]any{
+				"status": "",
+				"offset": 0,
+				"limit":  10,
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				expectStoredRole(querier, admin.ID, util.RoleAdmin)
+				querier.EXPECT().
+					ListPurgeJobs(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, mongo.ErrClientDisconnected)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			queries := mockdb.NewMockQuerier(ctrl)
+			tc.buildStubs(queries)
+
+			// start test server and send request
+			server := newTestServer(t, queries, util.RandomPassword(32))
+			recorder := httptest.NewRecorder()
+
+			url := "/v1/admin/purges"
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			q := request.URL.Query()
+			q.Add("status", fmt.Sprint(tc.query["status"]))
+			q.Add("offset", fmt.Sprint(tc.query["offset"]))
+			q.Add("limit", fmt.Sprint(tc.query["limit"]))
+			request.URL.RawQuery = q.Encode()
+
+			addRoleAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, admin.ID, util.RoleAdmin, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestUpdateUserRoleAPI(t *testing.T) {
+	admin, _ := randomUser(t)
+	user, _ := randomUser(t)
+	result := &mongo.UpdateResult{
+		MatchedCount:  1,
+		ModifiedCount: 1,
+	}
+
+	testCases := []struct {
+		name          string
+		id            string
+		body          map[string]any
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockQuerier)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			id:   user.ID.Hex(),
+			body: map[string]any{
+				"role": util.RoleModerator,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addRoleAuthorization(t, request, tokenMaker, authorizationTypeBearer, admin.ID, util.RoleAdmin, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				expectStoredRole(querier, admin.ID, util.RoleAdmin)
+				querier.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq("_id"), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+
+				role := util.RoleModerator
+				arg := db.UpdateUserParams{
+					ID:   user.ID,
+					Role: &role,
+				}
+
+				querier.EXPECT().
+					UpdateUser(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(result, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchUpdateResult(t, recorder.Body, result)
+			},
+		},
+		{
+			name: "InternalError",
+			id:   user.ID.Hex(),
+			body: map[string]any{
+				"role": util.RoleModerator,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addRoleAuthorization(t, request, tokenMaker, authorizationTypeBearer, admin.ID, util.RoleAdmin, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				expectStoredRole(querier, admin.ID, util.RoleAdmin)
+				querier.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq("_id"), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				querier.EXPECT().
+					UpdateUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, mongo.ErrClientDisconnected)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name: "UserNotFound",
+			id:   user.ID.Hex(),
+			body: map[string]any{
+				"role": util.RoleModerator,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addRoleAuthorization(t, request, tokenMaker, authorizationTypeBearer, admin.ID, util.RoleAdmin, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				expectStoredRole(querier, admin.ID, util.RoleAdmin)
+				querier.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq("_id"), gomock.Eq(user.ID)).
+					Times(1).
+					Return(db.User{}, mongo.ErrNoDocuments)
+				querier.EXPECT().
+					UpdateUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "OwnRole",
+			id:   admin.ID.Hex(),
+			body: map[string]any{
+				"role": util.RoleUser,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addRoleAuthorization(t, request, tokenMaker, authorizationTypeBearer, admin.ID, util.RoleAdmin, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				expectStoredRole(querier, admin.ID, util.RoleAdmin)
+				querier.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq("_id"), gomock.Eq(admin.ID)).
+					Times(1).
+					Return(admin, nil)
+				querier.EXPECT().
+					UpdateUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidRole",
+			id:   user.ID.Hex(),
+			body: map[string]any{
+				"role": "root",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addRoleAuthorization(t, request, tokenMaker, authorizationTypeBearer, admin.ID, util.RoleAdmin, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				expectStoredRole(querier, admin.ID, util.RoleAdmin)
+				querier.EXPECT().
+					UpdateUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Moderator",
+			id:   user.ID.Hex(),
+			body: map[string]any{
+				"role": util.RoleAdmin,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addRoleAuthorization(t, request, tokenMaker, authorizationTypeBearer, admin.ID, util.RoleModerator, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				expectStoredRole(querier, admin.ID, util.RoleModerator)
+				querier.EXPECT().
+					UpdateUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			queries := mockdb.NewMockQuerier(ctrl)
+			tc.buildStubs(queries)
+
+			// marshal data body to json
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			// start test server and send request
+			server := newTestServer(t, queries, util.RandomPassword(32))
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/v1/admin/users/%s/role", tc.id)
+			request, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			request.Header.Add("Content-Type", "application/json")
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	db "github.com/DMV-Nicolas/robotgram/backend/db/mongo"
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type blockUserRequest struct {
+	ID string `param:"id" validate:"required,len=24"`
+}
+
+// BlockUser blocks the user for the authenticated one, removing the follows between them
+func (server *Server) BlockUser(c echo.Context) error {
+	req := new(blockUserRequest)
+	if err := bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	gotUser, err := server.validUser(c, req.ID)
+	if err != nil {
+		return err
+	}
+
+	payload, err := getAuthorizationPayload(c)
+	if err != nil {
+		return err
+	}
+
+	arg := db.BlockParams{
+		BlockerID: payload.UserID,
+		BlockedID: gotUser.ID,
+	}
+
+	block, err := server.queries.BlockUser(context.TODO(), arg)
+	if err != nil {
+		if err == db.ErrAlreadyBlocked || err == db.ErrSelfBlock {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusCreated, block)
+}
+
+func (server *Server) UnblockUser(c echo.Context) error {
+	req := new(blockUserRequest)
+	if err := bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	blockedID, err := primitive.ObjectIDFromHex(req.ID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	payload, err := getAuthorizationPayload(c)
+	if err != nil {
+		return err
+	}
+
+	arg := db.BlockParams{
+		BlockerID: payload.UserID,
+		BlockedID: blockedID,
+	}
+
+	result, err := server.queries.UnblockUser(context.TODO(), arg)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+type listBlocksRequest struct {
+	Offset int64 `query:"offset" validate:"min=0"`
+	Limit  int64 `query:"limit" validate:"min=1,max=50"`
+}
+
+// ListBlocks lists the users blocked by the authenticated one, the latest first
+func (server *Server) ListBlocks(c echo.Context) error {
+	req := new(listBlocksRequest)
+	if err := bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	payload, err := getAuthorizationPayload(c)
+	if err != nil {
+		return err
+	}
+
+	arg := db.ListBlocksParams{
+		UserID: payload.UserID,
+		Offset: req.Offset,
+		Limit:  req.Limit,
+	}
+
+	blocks, err := server.queries.ListBlocks(context.TODO(), arg)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, blocks)
+}
+
+type muteUserRequest struct {
+	ID string `param:"id" validate:"required,len=24"`
+}
+
+// MuteUser hides the user from the feed and the notifications of the authenticated one
+func (server *Server) MuteUser(c echo.Context) error {
+	req := new(muteUserRequest)
+	if err := bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	gotUser, err := server.validUser(c, req.ID)
+	if err != nil {
+		return err
+	}
+
+	payload, err := getAuthorizationPayload(c)
+	if err != nil {
+		return err
+	}
+
+	arg := db.MuteParams{
+		MuterID: payload.UserID,
+		MutedID: gotUser.ID,
+	}
+
+	mute, err := server.queries.MuteUser(context.TODO(), arg)
+	if err != nil {
+		if err == db.ErrAlreadyMuted || err == db.ErrSelfMute {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusCreated, mute)
+}
+
+func (server *Server) UnmuteUser(c echo.Context) error {
+	req := new(muteUserRequest)
+	if err := bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	mutedID, err := primitive.ObjectIDFromHex(req.ID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	payload, err := getAuthorizationPayload(c)
+	if err != nil {
+		return err
+	}
+
+	arg := db.MuteParams{
+		MuterID: payload.UserID,
+		MutedID: mutedID,
+	}
+
+	result, err := server.queries.UnmuteUser(context.TODO(), arg)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+type listMutesRequest struct {
+	Offset int64 `query:"offset" validate:"min=0"`
+	Limit  int64 `query:"limit" validate:"min=1,max=50"`
+}
+
+// ListMutes lists the users muted by the authenticated one, the latest first
+func (server *Server) ListMutes(c echo.Context) error {
+	req := new(listMutesRequest)
+	if err := bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	payload, err := getAuthorizationPayload(c)
+	if err != nil {
+		return err
+	}
+
+	arg := db.ListMutesParams{
+		UserID: payload.UserID,
+		Offset: req.Offset,
+		Limit:  req.Limit,
+	}
+
+	mutes, err := server.queries.ListMutes(context.TODO(), arg)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, mutes)
+}
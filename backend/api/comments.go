@@ -6,6 +6,7 @@ import (
 	"net/http"
 
 	db "github.com/DMV-Nicolas/robotgram/backend/db/mongo"
+	"github.com/DMV-Nicolas/robotgram/backend/realtime"
 	"github.com/labstack/echo/v4"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -16,6 +17,7 @@ type createCommentRequest struct {
 	Content  string `json:"content" validate:"required"`
 }
 
+// CreateComment comments the target, which is a post for a top-level comment or a comment for a reply
 func (server *Server) CreateComment(c echo.Context) error {
 	req := new(createCommentRequest)
 	if err := bindAndValidate(c, req); err != nil {
@@ -33,9 +35,48 @@ func (server *Server) CreateComment(c echo.Context) error {
 	}
 
 	arg := db.CreateCommentParams{
-		UserID:   payload.UserID,
-		TargetID: targetID,
-		Content:  req.Content,
+		UserID:  payload.UserID,
+		PostID:  targetID,
+		Content: req.Content,
+	}
+
+	parent, err := server.queries.GetComment(context.TODO(), targetID)
+	if err == nil {
+		if parent.Deleted {
+			err = errors.New("the comment was deleted")
+			return echo.NewHTTPError(http.StatusNotFound, err)
+		}
+
+		if parent.Removed {
+			return echo.NewHTTPError(http.StatusNotFound, errRemoved)
+		}
+
+		arg.PostID = parent.PostID()
+		arg.ParentID = parent.ID
+
+		if err = server.checkCommentAuthor(payload.UserID, parent.UserID); err != nil {
+			return err
+		}
+	} else if err != mongo.ErrNoDocuments {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	post, err := server.queries.GetPost(context.TODO(), "_id", arg.PostID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return echo.NewHTTPError(http.StatusNotFound, err)
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	// nobody comments on a removed post, not even its author
+	if post.Removed {
+		return echo.NewHTTPError(http.StatusNotFound, errRemoved)
+	}
+
+	// the users can only comment on the posts they can see
+	if err = server.checkCanView(payload.UserID, post.UserID); err != nil {
+		return err
 	}
 
 	result, err := server.queries.CreateComment(context.TODO(), arg)
@@ -43,15 +84,45 @@ func (server *Server) CreateComment(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, err)
 	}
 
+	server.publishComment(result, arg, targetID)
+
 	return c.JSON(http.StatusCreated, result)
 }
 
+type commentEvent struct {
+	ID         any                `json:"id"`
+	TargetID   primitive.ObjectID `json:"target_id"`
+	RootPostID primitive.ObjectID `json:"root_post_id"`
+	UserID     primitive.ObjectID `json:"user_id"`
+}
+
+// publishComment tells the clients viewing the post, and the parent comment for a reply, about the new comment.
+// The clients fetch the comment itself
+func (server *Server) publishComment(result *mongo.InsertOneResult, arg db.CreateCommentParams, targetID primitive.ObjectID) {
+	event := commentEvent{
+		ID:         result.InsertedID,
+		TargetID:   targetID,
+		RootPostID: arg.PostID,
+		UserID:     arg.UserID,
+	}
+
+	topics := []string{realtime.TargetTopic(arg.PostID)}
+	if targetID != arg.PostID {
+		topics = append(topics, realtime.TargetTopic(targetID))
+	}
+
+	for _, topic := range topics {
+		server.broker.Publish(realtime.Event{Topic: topic, Type: realtime.EventComment, Data: event})
+	}
+}
+
 type listCommentsRequest struct {
 	TargetID string `param:"target_id" validate:"required,len=24"`
 	Offset   int64  `query:"offset" validate:"min=0"`
 	Limit    int64  `query:"limit" validate:"min=1"`
 }
 
+// ListComments lists the top-level comments of the post
 func (server *Server) ListComments(c echo.Context) error {
 	req := new(listCommentsRequest)
 	if err := bindAndValidate(c, req); err != nil {
@@ -63,10 +134,19 @@ func (server *Server) ListComments(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, err)
 	}
 
+	viewerID, err := getViewerID(c)
+	if err != nil {
+		return err
+	}
+
+	if err = server.checkCanViewTarget(viewerID, targetID); err != nil {
+		return err
+	}
+
 	arg := db.ListCommentsParams{
-		TargetID: targetID,
-		Offset:   req.Offset,
-		Limit:    req.Limit,
+		PostID: targetID,
+		Offset: req.Offset,
+		Limit:  req.Limit,
 	}
 
 	comments, err := server.queries.ListComments(context.TODO(), arg)
@@ -77,6 +157,47 @@ func (server *Server) ListComments(c echo.Context) error {
 	return c.JSON(http.StatusOK, comments)
 }
 
+type listRepliesRequest struct {
+	ID     string `param:"id" validate:"required,len=24"`
+	Offset int64  `query:"offset" validate:"min=0"`
+	Limit  int64  `query:"limit" validate:"min=1,max=100"`
+}
+
+// ListReplies lists the replies to the comment
+func (server *Server) ListReplies(c echo.Context) error {
+	req := new(listRepliesRequest)
+	if err := bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	comment, err := server.validComment(c, req.ID)
+	if err != nil {
+		return err
+	}
+
+	viewerID, err := getViewerID(c)
+	if err != nil {
+		return err
+	}
+
+	if err = server.checkCanViewTarget(viewerID, comment.PostID()); err != nil {
+		return err
+	}
+
+	arg := db.ListRepliesParams{
+		CommentID: comment.ID,
+		Offset:    req.Offset,
+		Limit:     req.Limit,
+	}
+
+	replies, err := server.queries.ListReplies(context.TODO(), arg)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, replies)
+}
+
 type updateCommentRequest struct {
 	ID      string `param:"id" validate:"required,len=24"`
 	Content string `json:"content" validate:"required"`
@@ -98,9 +219,9 @@ func (server *Server) UpdateComment(c echo.Context) error {
 		return err
 	}
 
-	if gotComment.UserID != payload.UserID {
-		err = errors.New("account doesn't belong to the authenticated user")
-		return echo.NewHTTPError(http.StatusUnauthorized, err)
+	moderated, err := server.authorizeContent(payload, gotComment.UserID)
+	if err != nil {
+		return err
 	}
 
 	arg := db.UpdateCommentParams{
@@ -116,6 +237,13 @@ func (server *Server) UpdateComment(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, err)
 	}
 
+	if moderated {
+		err = server.attributeModeration(payload, db.ReportTargetComment, gotComment.ID, gotComment.UserID, db.ModerationEditContent)
+		if err != nil {
+			return err
+		}
+	}
+
 	return c.JSON(http.StatusOK, result)
 }
 
@@ -139,9 +267,9 @@ func (server *Server) DeleteComment(c echo.Context) error {
 		return err
 	}
 
-	if gotComment.UserID != payload.UserID {
-		err = errors.New("account doesn't belong to the authenticated user")
-		return echo.NewHTTPError(http.StatusUnauthorized, err)
+	moderated, err := server.authorizeContent(payload, gotComment.UserID)
+	if err != nil {
+		return err
 	}
 
 	result, err := server.queries.DeleteComment(context.TODO(), gotComment.ID)
@@ -152,6 +280,13 @@ func (server *Server) DeleteComment(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, err)
 	}
 
+	if moderated {
+		err = server.attributeModeration(payload, db.ReportTargetComment, gotComment.ID, gotComment.UserID, db.ModerationDeleteContent)
+		if err != nil {
+			return err
+		}
+	}
+
 	return c.JSON(http.StatusOK, result)
 }
 
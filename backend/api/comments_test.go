@@ -23,6 +23,7 @@ func TestCreateCommentAPI(t *testing.T) {
 	user, _ := randomUser(t)
 	post := randomPost(t, primitive.NewObjectID())
 	comment := randomComment(t, user.ID, post.ID)
+	reply := randomReply(t, user.ID, comment)
 	result := &mongo.InsertOneResult{InsertedID: comment.ID}
 
 	testCases := []struct {
@@ -38,15 +39,51 @@ func TestCreateCommentAPI(t *testing.T) {
 				"target_id": post.ID.Hex(),
 				"content":   comment.Content,
 			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			}, buildStubs: func(querier *mockdb.MockQuerier) {
+				arg := db.CreateCommentParams{
+					UserID:  user.ID,
+					PostID:  post.ID,
+					Content: comment.Content,
+				}
+
+				querier.EXPECT().
+					GetComment(gomock.Any(), gomock.Eq(post.ID)).
+					Times(1).
+					Return(db.Comment{}, mongo.ErrNoDocuments)
+				expectVisiblePost(querier, post, user.ID)
+				querier.EXPECT().
+					CreateComment(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(result, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+				requireBodyMatchInsertOneResult(t, recorder.Body, result)
+			},
+		},
+		{
+			name: "ReplyOK",
+			body: map[string]any{
+				"target_id": comment.ID.Hex(),
+				"content":   reply.Content,
+			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
 			}, buildStubs: func(querier *mockdb.MockQuerier) {
 				arg := db.CreateCommentParams{
 					UserID:   user.ID,
-					TargetID: post.ID,
-					Content:  comment.Content,
+					PostID:   post.ID,
+					ParentID: comment.ID,
+					Content:  reply.Content,
 				}
 
+				querier.EXPECT().
+					GetComment(gomock.Any(), gomock.Eq(comment.ID)).
+					Times(1).
+					Return(comment, nil)
+				expectVisiblePost(querier, post, user.ID)
 				querier.EXPECT().
 					CreateComment(gomock.Any(), gomock.Eq(arg)).
 					Times(1).
@@ -57,6 +94,195 @@ func TestCreateCommentAPI(t *testing.T) {
 				requireBodyMatchInsertOneResult(t, recorder.Body, result)
 			},
 		},
+		{
+			name: "ReplyLegacyParent",
+			body: map[string]any{
+				"target_id": comment.ID.Hex(),
+				"content":   reply.Content,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			}, buildStubs: func(querier *mockdb.MockQuerier) {
+				// the comments made before the threads existed have no root post
+				legacy := comment
+				legacy.RootPostID = primitive.NilObjectID
+
+				arg := db.CreateCommentParams{
+					UserID:   user.ID,
+					PostID:   post.ID,
+					ParentID: comment.ID,
+					Content:  reply.Content,
+				}
+
+				querier.EXPECT().
+					GetComment(gomock.Any(), gomock.Eq(comment.ID)).
+					Times(1).
+					Return(legacy, nil)
+				expectVisiblePost(querier, post, user.ID)
+				querier.EXPECT().
+					CreateComment(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(result, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		{
+			name: "Blocked",
+			body: map[string]any{
+				"target_id": post.ID.Hex(),
+				"content":   comment.Content,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			}, buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetComment(gomock.Any(), gomock.Eq(post.ID)).
+					Times(1).
+					Return(db.Comment{}, mongo.ErrNoDocuments)
+				querier.EXPECT().
+					GetPost(gomock.Any(), gomock.Eq("_id"), gomock.Eq(post.ID)).
+					Times(1).
+					Return(post, nil)
+				querier.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq("_id"), gomock.Eq(post.UserID)).
+					Times(1).
+					Return(db.User{ID: post.UserID}, nil)
+				querier.EXPECT().
+					IsBlockedBetween(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(true, nil)
+				querier.EXPECT().
+					CreateComment(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "DeletedParent",
+			body: map[string]any{
+				"target_id": comment.ID.Hex(),
+				"content":   reply.Content,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			}, buildStubs: func(querier *mockdb.MockQuerier) {
+				tombstone := comment
+				tombstone.Deleted = true
+
+				querier.EXPECT().
+					GetComment(gomock.Any(), gomock.Eq(comment.ID)).
+					Times(1).
+					Return(tombstone, nil)
+				querier.EXPECT().
+					CreateComment(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "RemovedParent",
+			body: map[string]any{
+				"target_id": comment.ID.Hex(),
+				"content":   reply.Content,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			}, buildStubs: func(querier *mockdb.MockQuerier) {
+				removed := comment
+				removed.Removed = true
+
+				querier.EXPECT().
+					GetComment(gomock.Any(), gomock.Eq(comment.ID)).
+					Times(1).
+					Return(removed, nil)
+				querier.EXPECT().
+					CreateComment(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "RemovedPost",
+			body: map[string]any{
+				"target_id": post.ID.Hex(),
+				"content":   comment.Content,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, post.UserID, time.Minute)
+			}, buildStubs: func(querier *mockdb.MockQuerier) {
+				removed := post
+				removed.Removed = true
+
+				querier.EXPECT().
+					GetComment(gomock.Any(), gomock.Eq(post.ID)).
+					Times(1).
+					Return(db.Comment{}, mongo.ErrNoDocuments)
+				querier.EXPECT().
+					GetPost(gomock.Any(), gomock.Eq("_id"), gomock.Eq(post.ID)).
+					Times(1).
+					Return(removed, nil)
+				querier.EXPECT().
+					CreateComment(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "TargetNotFound",
+			body: map[string]any{
+				"target_id": post.ID.Hex(),
+				"content":   comment.Content,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			}, buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetComment(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Comment{}, mongo.ErrNoDocuments)
+				querier.EXPECT().
+					GetPost(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Post{}, mongo.ErrNoDocuments)
+				querier.EXPECT().
+					CreateComment(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "GetCommentInternalError",
+			body: map[string]any{
+				"target_id": post.ID.Hex(),
+				"content":   comment.Content,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			}, buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetComment(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Comment{}, mongo.ErrClientDisconnected)
+				querier.EXPECT().
+					CreateComment(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
 		{
 			name: "InternalError",
 			body: map[string]any{
@@ -66,6 +292,11 @@ func TestCreateCommentAPI(t *testing.T) {
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
 			}, buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetComment(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Comment{}, mongo.ErrNoDocuments)
+				expectVisiblePost(querier, post, user.ID)
 				querier.EXPECT().
 					CreateComment(gomock.Any(), gomock.Any()).
 					Times(1).
@@ -162,11 +393,12 @@ func TestListCommentsAPI(t *testing.T) {
 			},
 			buildStubs: func(querier *mockdb.MockQuerier) {
 				arg := db.ListCommentsParams{
-					TargetID: post.ID,
-					Offset:   int64(offset),
-					Limit:    int64(limit),
+					PostID: post.ID,
+					Offset: int64(offset),
+					Limit:  int64(limit),
 				}
 
+				expectVisiblePost(querier, post, primitive.NilObjectID)
 				querier.EXPECT().
 					ListComments(gomock.Any(), gomock.Eq(arg)).
 					Times(1).
@@ -185,6 +417,7 @@ func TestListCommentsAPI(t *testing.T) {
 				"limit":     limit,
 			},
 			buildStubs: func(querier *mockdb.MockQuerier) {
+				expectVisiblePost(querier, post, primitive.NilObjectID)
 				querier.EXPECT().
 					ListComments(gomock.Any(), gomock.Any()).
 					Times(1).
@@ -194,6 +427,54 @@ func TestListCommentsAPI(t *testing.T) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
 			},
 		},
+		{
+			name: "PrivateAccount",
+			query: map[string]any{
+				"target_id": post.ID.Hex(),
+				"offset":    offset,
+				"limit":     limit,
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetPost(gomock.Any(), gomock.Eq("_id"), gomock.Eq(post.ID)).
+					Times(1).
+					Return(post, nil)
+				querier.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq("_id"), gomock.Eq(post.UserID)).
+					Times(1).
+					Return(db.User{ID: post.UserID, IsPrivate: true}, nil)
+				querier.EXPECT().
+					ListComments(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "PostNotFound",
+			query: map[string]any{
+				"target_id": post.ID.Hex(),
+				"offset":    offset,
+				"limit":     limit,
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetPost(gomock.Any(), gomock.Eq("_id"), gomock.Eq(post.ID)).
+					Times(1).
+					Return(db.Post{}, mongo.ErrNoDocuments)
+				querier.EXPECT().
+					GetComment(gomock.Any(), gomock.Eq(post.ID)).
+					Times(1).
+					Return(db.Comment{}, mongo.ErrNoDocuments)
+				querier.EXPECT().
+					ListComments(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
 		{
 			name: "InvalidTargetID",
 			query: map[string]any{
@@ -257,9 +538,144 @@ func TestListCommentsAPI(t *testing.T) {
 	}
 }
 
+func TestListRepliesAPI(t *testing.T) {
+	offset, limit := 0, 5
+	post := randomPost(t, primitive.NewObjectID())
+	comment := randomComment(t, primitive.NewObjectID(), post.ID)
+	replies := make([]db.Comment, limit)
+	for i := 0; i < limit; i++ {
+		replies[i] = randomReply(t, primitive.NewObjectID(), comment)
+	}
+
+	testCases := []struct {
+		name          string
+		commentID     string
+		limit         int
+		buildStubs    func(store *mockdb.MockQuerier)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "OK",
+			commentID: comment.ID.Hex(),
+			limit:     limit,
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				arg := db.ListRepliesParams{
+					CommentID: comment.ID,
+					Offset:    int64(offset),
+					Limit:     int64(limit),
+				}
+
+				querier.EXPECT().
+					GetComment(gomock.Any(), gomock.Eq(comment.ID)).
+					Times(1).
+					Return(comment, nil)
+				expectVisiblePost(querier, post, primitive.NilObjectID)
+				querier.EXPECT().
+					ListReplies(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(replies, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchComments(t, recorder.Body, replies)
+			},
+		},
+		{
+			name:      "CommentNotFound",
+			commentID: comment.ID.Hex(),
+			limit:     limit,
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetComment(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Comment{}, mongo.ErrNoDocuments)
+				querier.EXPECT().
+					ListReplies(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:      "InternalError",
+			commentID: comment.ID.Hex(),
+			limit:     limit,
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetComment(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(comment, nil)
+				expectVisiblePost(querier, post, primitive.NilObjectID)
+				querier.EXPECT().
+					ListReplies(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, mongo.ErrClientDisconnected)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name:      "LimitTooBig",
+			commentID: comment.ID.Hex(),
+			limit:     1000,
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					ListReplies(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidID",
+			commentID: "qwertyuiopasdfghjklñzxcv",
+			limit:     limit,
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					ListReplies(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			queries := mockdb.NewMockQuerier(ctrl)
+			tc.buildStubs(queries)
+
+			// start test server and send request
+			server := newTestServer(t, queries, util.RandomPassword(32))
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/v1/comments/%s/replies", tc.commentID)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			q := request.URL.Query()
+			q.Add("offset", fmt.Sprint(offset))
+			q.Add("limit", fmt.Sprint(tc.limit))
+			request.URL.RawQuery = q.Encode()
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
 func TestUpdateCommentAPI(t *testing.T) {
 	user, _ := randomUser(t)
 	comment := randomComment(t, user.ID, primitive.NewObjectID())
+	moderatorID := util.RandomID()
+	strangerID := util.RandomID()
 	result := &mongo.UpdateResult{
 		MatchedCount:  1,
 		ModifiedCount: 1,
@@ -347,6 +763,42 @@ func TestUpdateCommentAPI(t *testing.T) {
 				require.Equal(t, http.StatusNotFound, recorder.Code)
 			},
 		},
+		{
+			name: "Moderator",
+			body: map[string]any{
+				"id":      comment.ID,
+				"content": comment.Content,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addRoleAuthorization(t, request, tokenMaker, authorizationTypeBearer, moderatorID, util.RoleModerator, time.Minute)
+			}, buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetComment(gomock.Any(), gomock.Eq(comment.ID)).
+					Times(1).
+					Return(comment, nil)
+				expectStoredRole(querier, moderatorID, util.RoleModerator)
+				querier.EXPECT().
+					UpdateComment(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(result, nil)
+				querier.EXPECT().
+					CreateModerationAction(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CreateModerationActionParams) (db.ModerationAction, error) {
+						require.Equal(t, moderatorID, arg.ModeratorID)
+						require.Equal(t, db.ReportTargetComment, arg.TargetType)
+						require.Equal(t, comment.ID, arg.TargetID)
+						require.Equal(t, user.ID, arg.UserID)
+						require.Equal(t, db.ModerationEditContent, arg.Action)
+
+						return db.ModerationAction{ID: util.RandomID()}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchUpdateResult(t, recorder.Body, result)
+			},
+		},
 		{
 			name: "NonCommentOwner",
 			body: map[string]any{
@@ -354,18 +806,19 @@ func TestUpdateCommentAPI(t *testing.T) {
 				"content": comment.Content,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, primitive.NewObjectID(), time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, strangerID, time.Minute)
 			}, buildStubs: func(querier *mockdb.MockQuerier) {
 				querier.EXPECT().
 					GetComment(gomock.Any(), gomock.Eq(comment.ID)).
 					Times(1).
 					Return(comment, nil)
+				expectStoredRole(querier, strangerID, util.RoleUser)
 				querier.EXPECT().
 					UpdateComment(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Equal(t, http.StatusForbidden, recorder.Code)
 			},
 		},
 		{
@@ -442,6 +895,8 @@ func TestUpdateCommentAPI(t *testing.T) {
 func TestDeleteCommentAPI(t *testing.T) {
 	user, _ := randomUser(t)
 	comment := randomComment(t, user.ID, primitive.NewObjectID())
+	moderatorID := util.RandomID()
+	strangerID := util.RandomID()
 	result := &mongo.DeleteResult{
 		DeletedCount: 1,
 	}
@@ -514,23 +969,58 @@ func TestDeleteCommentAPI(t *testing.T) {
 				require.Equal(t, http.StatusNotFound, recorder.Code)
 			},
 		},
+		{
+			name: "Moderator",
+			id:   comment.ID.Hex(),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addRoleAuthorization(t, request, tokenMaker, authorizationTypeBearer, moderatorID, util.RoleModerator, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetComment(gomock.Any(), gomock.Eq(comment.ID)).
+					Times(1).
+					Return(comment, nil)
+				expectStoredRole(querier, moderatorID, util.RoleModerator)
+				querier.EXPECT().
+					DeleteComment(gomock.Any(), gomock.Eq(comment.ID)).
+					Times(1).
+					Return(result, nil)
+				querier.EXPECT().
+					CreateModerationAction(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CreateModerationActionParams) (db.ModerationAction, error) {
+						require.Equal(t, moderatorID, arg.ModeratorID)
+						require.Equal(t, db.ReportTargetComment, arg.TargetType)
+						require.Equal(t, comment.ID, arg.TargetID)
+						require.Equal(t, user.ID, arg.UserID)
+						require.Equal(t, db.ModerationDeleteContent, arg.Action)
+
+						return db.ModerationAction{ID: util.RandomID()}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchDeleteResult(t, recorder.Body, result)
+			},
+		},
 		{
 			name: "NonCommentOwner",
 			id:   comment.ID.Hex(),
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, primitive.NewObjectID(), time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, strangerID, time.Minute)
 			},
 			buildStubs: func(querier *mockdb.MockQuerier) {
 				querier.EXPECT().
 					GetComment(gomock.Any(), gomock.Eq(comment.ID)).
 					Times(1).
 					Return(comment, nil)
+				expectStoredRole(querier, strangerID, util.RoleUser)
 				querier.EXPECT().
 					DeleteComment(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Equal(t, http.StatusForbidden, recorder.Code)
 			},
 		},
 		{
@@ -634,12 +1124,25 @@ func TestDeleteCommentAPI(t *testing.T) {
 	}
 }
 
-func randomComment(t *testing.T, userID primitive.ObjectID, targetID primitive.ObjectID) db.Comment {
+func randomComment(t *testing.T, userID primitive.ObjectID, postID primitive.ObjectID) db.Comment {
+	return db.Comment{
+		ID:         primitive.NewObjectID(),
+		UserID:     userID,
+		TargetID:   postID,
+		RootPostID: postID,
+		Content:    util.RandomString(10),
+		CreatedAt:  time.Now(),
+	}
+}
+
+func randomReply(t *testing.T, userID primitive.ObjectID, parent db.Comment) db.Comment {
 	return db.Comment{
-		ID:        primitive.NewObjectID(),
-		UserID:    userID,
-		TargetID:  targetID,
-		Content:   util.RandomString(10),
-		CreatedAt: time.Now(),
+		ID:         primitive.NewObjectID(),
+		UserID:     userID,
+		TargetID:   parent.ID,
+		ParentID:   parent.ID,
+		RootPostID: parent.RootPostID,
+		Content:    util.RandomString(10),
+		CreatedAt:  time.Now(),
 	}
 }
@@ -0,0 +1,482 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	db "github.com/DMV-Nicolas/robotgram/backend/db/mongo"
+	"github.com/DMV-Nicolas/robotgram/backend/realtime"
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var errNotConversationMember = errors.New("the authenticated user isn't a member of the conversation")
+
+type createConversationRequest struct {
+	MemberIDs []string `json:"member_ids" validate:"required,min=1,max=9,dive,len=24"`
+}
+
+// CreateConversation starts a conversation between the authenticated user and up to nine other users.
+// Starting a conversation with a single user returns the existing one, if any
+func (server *Server) CreateConversation(c echo.Context) error {
+	req := new(createConversationRequest)
+	if err := bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	payload, err := getAuthorizationPayload(c)
+	if err != nil {
+		return err
+	}
+
+	arg := db.CreateConversationParams{
+		MemberIDs: []primitive.ObjectID{payload.UserID},
+	}
+
+	for _, idStr := range req.MemberIDs {
+		id, err := primitive.ObjectIDFromHex(idStr)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+
+		if id == payload.UserID {
+			continue
+		}
+
+		_, err = server.queries.GetUser(context.TODO(), "_id", id)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				return echo.NewHTTPError(http.StatusNotFound, err)
+			}
+			return echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+
+		if err = server.checkNotBlocked(payload.UserID, id); err != nil {
+			return err
+		}
+
+		arg.MemberIDs = append(arg.MemberIDs, id)
+	}
+
+	if len(arg.MemberIDs) < 2 {
+		err = errors.New("a conversation needs another member")
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	conversation, err := server.queries.CreateConversation(context.TODO(), arg)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusCreated, conversation)
+}
+
+type listConversationsRequest struct {
+	Offset int64 `query:"offset" validate:"min=0"`
+	Limit  int64 `query:"limit" validate:"min=1,max=20"`
+}
+
+type conversationResponse struct {
+	db.Conversation
+	UnreadCount int64 `json:"unread_count"`
+}
+
+// ListConversations lists the conversations of the authenticated user with how many unread messages each one has
+func (server *Server) ListConversations(c echo.Context) error {
+	req := new(listConversationsRequest)
+	if err := bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	payload, err := getAuthorizationPayload(c)
+	if err != nil {
+		return err
+	}
+
+	arg := db.ListConversationsParams{
+		UserID: payload.UserID,
+		Offset: req.Offset,
+		Limit:  req.Limit,
+	}
+
+	conversations, err := server.queries.ListConversations(context.TODO(), arg)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	res := make([]conversationResponse, len(conversations))
+	for i, conversation := range conversations {
+		nUnread, err := server.countUnreadMessages(conversation, payload.UserID)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+
+		res[i] = conversationResponse{Conversation: conversation, UnreadCount: nUnread}
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+type conversationRequest struct {
+	ID string `param:"id" validate:"required,len=24"`
+}
+
+func (server *Server) GetConversation(c echo.Context) error {
+	req := new(conversationRequest)
+	if err := bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	conversation, err := server.memberConversation(c, req.ID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, conversation)
+}
+
+// CountUnreadMessages counts the messages of the conversation the authenticated user hasn't read
+func (server *Server) CountUnreadMessages(c echo.Context) error {
+	req := new(conversationRequest)
+	if err := bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	conversation, err := server.memberConversation(c, req.ID)
+	if err != nil {
+		return err
+	}
+
+	payload, err := getAuthorizationPayload(c)
+	if err != nil {
+		return err
+	}
+
+	nUnread, err := server.countUnreadMessages(conversation, payload.UserID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, nUnread)
+}
+
+func (server *Server) countUnreadMessages(conversation db.Conversation, userID primitive.ObjectID) (int64, error) {
+	arg := db.CountUnreadMessagesParams{
+		ConversationID: conversation.ID,
+		UserID:         userID,
+	}
+
+	for _, member := range conversation.Members {
+		if member.UserID == userID {
+			arg.LastReadID = member.LastReadID
+		}
+	}
+
+	return server.queries.CountUnreadMessages(context.TODO(), arg)
+}
+
+type markConversationReadRequest struct {
+	ID        string `param:"id" validate:"required,len=24"`
+	MessageID string `json:"message_id" validate:"required,len=24"`
+}
+
+// MarkConversationRead moves the read cursor of the authenticated user up to the message
+func (server *Server) MarkConversationRead(c echo.Context) error {
+	req := new(markConversationReadRequest)
+	if err := bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	conversation, err := server.memberConversation(c, req.ID)
+	if err != nil {
+		return err
+	}
+
+	message, err := server.validMessage(c, req.MessageID)
+	if err != nil {
+		return err
+	}
+
+	if message.ConversationID != conversation.ID {
+		err = errors.New("the message doesn't belong to the conversation")
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	payload, err := getAuthorizationPayload(c)
+	if err != nil {
+		return err
+	}
+
+	arg := db.MarkConversationReadParams{
+		ConversationID: conversation.ID,
+		UserID:         payload.UserID,
+		MessageID:      message.ID,
+	}
+
+	result, err := server.queries.MarkConversationRead(context.TODO(), arg)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+type createMessageRequest struct {
+	ConversationID string `param:"id" validate:"required,len=24"`
+	Content        string `json:"content" validate:"required_without=PostID,max=2000"`
+	PostID         string `json:"post_id" validate:"omitempty,len=24"`
+}
+
+// CreateMessage sends a message to the conversation, which may share a post along with the content or
+// instead of it. The other members receive it through their stream
+func (server *Server) CreateMessage(c echo.Context) error {
+	req := new(createMessageRequest)
+	if err := bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	conversation, err := server.memberConversation(c, req.ConversationID)
+	if err != nil {
+		return err
+	}
+
+	payload, err := getAuthorizationPayload(c)
+	if err != nil {
+		return err
+	}
+
+	// a block closes the direct conversation, the groups stay open
+	if len(conversation.Members) == 2 {
+		for _, member := range conversation.Members {
+			if err = server.checkNotBlocked(payload.UserID, member.UserID); err != nil {
+				return err
+			}
+		}
+	}
+
+	arg := db.CreateMessageParams{
+		ConversationID: conversation.ID,
+		UserID:         payload.UserID,
+		Content:        req.Content,
+	}
+
+	if req.PostID != "" {
+		post, err := server.validPost(c, req.PostID)
+		if err != nil {
+			return err
+		}
+
+		// the other members would see it, a removed post can't be shared even by its author
+		if post.Removed {
+			return echo.NewHTTPError(http.StatusNotFound, errRemoved)
+		}
+
+		if err = server.checkCanView(payload.UserID, post.UserID); err != nil {
+			return err
+		}
+		arg.PostID = post.ID
+	}
+
+	message, err := server.queries.CreateMessage(context.TODO(), arg)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	for _, member := range conversation.Members {
+		if member.UserID == payload.UserID {
+			continue
+		}
+
+		server.broker.Publish(realtime.Event{
+			Topic: realtime.UserTopic(member.UserID),
+			Type:  realtime.EventMessage,
+			Data:  message,
+		})
+	}
+
+	return c.JSON(http.StatusCreated, message)
+}
+
+type listMessagesRequest struct {
+	ConversationID string `param:"id" validate:"required,len=24"`
+	Cursor         string `query:"cursor" validate:"omitempty,len=24"`
+	Limit          int64  `query:"limit" validate:"min=1,max=50"`
+}
+
+type listMessagesResponse struct {
+	Messages   []db.Message `json:"messages"`
+	NextCursor string       `json:"next_cursor"`
+}
+
+// ListMessages lists the messages of the conversation newest first. The next cursor
+// continues with the older messages, and it is empty once the start of the history is reached
+func (server *Server) ListMessages(c echo.Context) error {
+	req := new(listMessagesRequest)
+	if err := bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	conversation, err := server.memberConversation(c, req.ConversationID)
+	if err != nil {
+		return err
+	}
+
+	arg := db.ListMessagesParams{
+		ConversationID: conversation.ID,
+		Limit:          req.Limit,
+	}
+
+	if req.Cursor != "" {
+		arg.BeforeID, err = primitive.ObjectIDFromHex(req.Cursor)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, errInvalidCursor)
+		}
+	}
+
+	messages, err := server.queries.ListMessages(context.TODO(), arg)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	res := listMessagesResponse{
+		Messages: messages,
+	}
+
+	if int64(len(messages)) == req.Limit {
+		res.NextCursor = messages[len(messages)-1].ID.Hex()
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+type updateMessageRequest struct {
+	ID      string `param:"id" validate:"required,len=24"`
+	Content string `json:"content" validate:"required,max=2000"`
+}
+
+func (server *Server) UpdateMessage(c echo.Context) error {
+	req := new(updateMessageRequest)
+	if err := bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	message, err := server.ownMessage(c, req.ID)
+	if err != nil {
+		return err
+	}
+
+	arg := db.UpdateMessageParams{
+		ID:      message.ID,
+		Content: req.Content,
+	}
+
+	result, err := server.queries.UpdateMessage(context.TODO(), arg)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+type deleteMessageRequest struct {
+	ID string `param:"id" validate:"required,len=24"`
+}
+
+func (server *Server) DeleteMessage(c echo.Context) error {
+	req := new(deleteMessageRequest)
+	if err := bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	message, err := server.ownMessage(c, req.ID)
+	if err != nil {
+		return err
+	}
+
+	result, err := server.queries.DeleteMessage(context.TODO(), message.ID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// memberConversation returns the conversation if the authenticated user is one of its members
+func (server *Server) memberConversation(c echo.Context, idStr string) (db.Conversation, error) {
+	id, err := primitive.ObjectIDFromHex(idStr)
+	if err != nil {
+		err = echo.NewHTTPError(http.StatusBadRequest, err)
+		return db.Conversation{}, err
+	}
+
+	payload, err := getAuthorizationPayload(c)
+	if err != nil {
+		return db.Conversation{}, err
+	}
+
+	conversation, err := server.queries.GetConversation(context.TODO(), id)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			err = echo.NewHTTPError(http.StatusNotFound, err)
+			return db.Conversation{}, err
+		}
+		err = echo.NewHTTPError(http.StatusInternalServerError, err)
+		return db.Conversation{}, err
+	}
+
+	for _, member := range conversation.Members {
+		if member.UserID == payload.UserID {
+			return conversation, nil
+		}
+	}
+
+	err = echo.NewHTTPError(http.StatusForbidden, errNotConversationMember)
+	return db.Conversation{}, err
+}
+
+func (server *Server) validMessage(c echo.Context, idStr string) (db.Message, error) {
+	id, err := primitive.ObjectIDFromHex(idStr)
+	if err != nil {
+		err = echo.NewHTTPError(http.StatusBadRequest, err)
+		return db.Message{}, err
+	}
+
+	message, err := server.queries.GetMessage(context.TODO(), id)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			err = echo.NewHTTPError(http.StatusNotFound, err)
+			return db.Message{}, err
+		}
+		err = echo.NewHTTPError(http.StatusInternalServerError, err)
+		return db.Message{}, err
+	}
+
+	return message, nil
+}
+
+// ownMessage returns the message if the authenticated user sent it and it wasn't deleted
+func (server *Server) ownMessage(c echo.Context, idStr string) (db.Message, error) {
+	message, err := server.validMessage(c, idStr)
+	if err != nil {
+		return db.Message{}, err
+	}
+
+	payload, err := getAuthorizationPayload(c)
+	if err != nil {
+		return db.Message{}, err
+	}
+
+	if message.UserID != payload.UserID {
+		err = errors.New("message doesn't belong to the authenticated user")
+		return db.Message{}, echo.NewHTTPError(http.StatusUnauthorized, err)
+	}
+
+	if message.Deleted {
+		err = errors.New("the message was deleted")
+		return db.Message{}, echo.NewHTTPError(http.StatusNotFound, err)
+	}
+
+	return message, nil
+}
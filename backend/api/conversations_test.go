@@ -0,0 +1,1180 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/DMV-Nicolas/robotgram/backend/db/mock"
+	db "github.com/DMV-Nicolas/robotgram/backend/db/mongo"
+	"github.com/DMV-Nicolas/robotgram/backend/realtime"
+	"github.com/DMV-Nicolas/robotgram/backend/token"
+	"github.com/DMV-Nicolas/robotgram/backend/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestCreateConversationAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	other, _ := randomUser(t)
+	conversation := randomConversation(user.ID, other.ID)
+
+	testCases := []struct {
+		name          string
+		body          map[string]any
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(querier *mockdb.MockQuerier)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: map[string]any{
+				"member_ids": []string{other.ID.Hex(), user.ID.Hex()},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				arg := db.CreateConversationParams{
+					MemberIDs: []primitive.ObjectID{user.ID, other.ID},
+				}
+
+				querier.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq("_id"), gomock.Eq(other.ID)).
+					Times(1).
+					Return(other, nil)
+				expectNotBlocked(querier, user.ID, other.ID)
+				querier.EXPECT().
+					CreateConversation(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(conversation, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+				requireBodyMatchConversation(t, recorder.Body, conversation)
+			},
+		},
+		{
+			name: "OnlyItself",
+			body: map[string]any{
+				"member_ids": []string{user.ID.Hex()},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					CreateConversation(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "MemberNotFound",
+			body: map[string]any{
+				"member_ids": []string{other.ID.Hex()},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetUser(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, mongo.ErrNoDocuments)
+				querier.EXPECT().
+					CreateConversation(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "TooManyMembers",
+			body: map[string]any{
+				"member_ids": func() []string {
+					ids := make([]string, 10)
+					for i := range ids {
+						ids[i] = util.RandomID().Hex()
+					}
+					return ids
+				}(),
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					CreateConversation(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			body: map[string]any{
+				"member_ids": []string{other.ID.Hex()},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetUser(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(other, nil)
+				expectNotBlocked(querier, user.ID, other.ID)
+				querier.EXPECT().
+					CreateConversation(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Conversation{}, mongo.ErrClientDisconnected)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name: "NoAuthorization",
+			body: map[string]any{
+				"member_ids": []string{other.ID.Hex()},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					CreateConversation(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			queries := mockdb.NewMockQuerier(ctrl)
+			tc.buildStubs(queries)
+
+			// start test server and send request
+			server := newTestServer(t, queries, util.RandomPassword(32))
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/v1/conversations", bytes.NewReader(data))
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", "application/json")
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListConversationsAPI(t *testing.T) {
+	offset, limit := 0, 3
+	user, _ := randomUser(t)
+	conversations := make([]db.Conversation, limit)
+	for i := range conversations {
+		conversations[i] = randomConversation(user.ID, util.RandomID())
+		conversations[i].Members[0].LastReadID = util.RandomID()
+	}
+
+	testCases := []struct {
+		name          string
+		limit         int
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(querier *mockdb.MockQuerier)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			limit: limit,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				arg := db.ListConversationsParams{
+					UserID: user.ID,
+					Offset: int64(offset),
+					Limit:  int64(limit),
+				}
+
+				querier.EXPECT().
+					ListConversations(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(conversations, nil)
+
+				for i, conversation := range conversations {
+					arg := db.CountUnreadMessagesParams{
+						ConversationID: conversation.ID,
+						UserID:         user.ID,
+						LastReadID:     conversation.Members[0].LastReadID,
+					}
+
+					querier.EXPECT().
+						CountUnreadMessages(gomock.Any(), gomock.Eq(arg)).
+						Times(1).
+						Return(int64(i), nil)
+				}
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res []conversationResponse
+				err := json.NewDecoder(recorder.Body).Decode(&res)
+				require.NoError(t, err)
+				require.Len(t, res, len(conversations))
+
+				for i := range res {
+					require.Equal(t, conversations[i].ID, res[i].ID)
+					require.Equal(t, conversations[i].Members, res[i].Members)
+					require.EqualValues(t, i, res[i].UnreadCount)
+				}
+			},
+		},
+		{
+			name:  "InternalError",
+			limit: limit,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					ListConversations(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, mongo.ErrClientDisconnected)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name:  "LimitTooBig",
+			limit: 1000,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					ListConversations(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			queries := mockdb.NewMockQuerier(ctrl)
+			tc.buildStubs(queries)
+
+			// start test server and send request
+			server := newTestServer(t, queries, util.RandomPassword(32))
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/v1/conversations", nil)
+			require.NoError(t, err)
+
+			q := request.URL.Query()
+			q.Add("offset", fmt.Sprint(offset))
+			q.Add("limit", fmt.Sprint(tc.limit))
+			request.URL.RawQuery = q.Encode()
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestCreateMessageAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	other, _ := randomUser(t)
+	conversation := randomConversation(user.ID, other.ID)
+	post := randomPost(t, other.ID)
+	message := randomMessage(conversation.ID, user.ID)
+	message.PostID = post.ID
+	removedPost := randomPost(t, other.ID)
+	removedPost.Removed = true
+	strangerPost := randomPost(t, util.RandomID())
+
+	testCases := []struct {
+		name          string
+		conversation  string
+		body          map[string]any
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(querier *mockdb.MockQuerier)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:         "OK",
+			conversation: conversation.ID.Hex(),
+			body: map[string]any{
+				"content": message.Content,
+				"post_id": post.ID.Hex(),
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				arg := db.CreateMessageParams{
+					ConversationID: conversation.ID,
+					UserID:         user.ID,
+					Content:        message.Content,
+					PostID:         post.ID,
+				}
+
+				querier.EXPECT().
+					GetConversation(gomock.Any(), gomock.Eq(conversation.ID)).
+					Times(1).
+					Return(conversation, nil)
+				expectNotBlocked(querier, user.ID, other.ID)
+				expectVisiblePost(querier, post, user.ID)
+				querier.EXPECT().
+					CreateMessage(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(message, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+				requireBodyMatchMessage(t, recorder.Body, message)
+			},
+		},
+		{
+			name:         "NotMember",
+			conversation: conversation.ID.Hex(),
+			body: map[string]any{
+				"content": message.Content,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, util.RandomID(), time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetConversation(gomock.Any(), gomock.Eq(conversation.ID)).
+					Times(1).
+					Return(conversation, nil)
+				querier.EXPECT().
+					CreateMessage(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:         "Blocked",
+			conversation: conversation.ID.Hex(),
+			body: map[string]any{
+				"content": message.Content,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetConversation(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(conversation, nil)
+				querier.EXPECT().
+					IsBlockedBetween(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(true, nil)
+				querier.EXPECT().
+					CreateMessage(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:         "ConversationNotFound",
+			conversation: conversation.ID.Hex(),
+			body: map[string]any{
+				"content": message.Content,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetConversation(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Conversation{}, mongo.ErrNoDocuments)
+				querier.EXPECT().
+					CreateMessage(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:         "PostNotFound",
+			conversation: conversation.ID.Hex(),
+			body: map[string]any{
+				"post_id": post.ID.Hex(),
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetConversation(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(conversation, nil)
+				expectNotBlocked(querier, user.ID, other.ID)
+				querier.EXPECT().
+					GetPost(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Post{}, mongo.ErrNoDocuments)
+				querier.EXPECT().
+					CreateMessage(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:         "PostRemoved",
+			conversation: conversation.ID.Hex(),
+			body: map[string]any{
+				"post_id": removedPost.ID.Hex(),
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetConversation(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(conversation, nil)
+				expectNotBlocked(querier, user.ID, other.ID)
+				querier.EXPECT().
+					GetPost(gomock.Any(), gomock.Eq("_id"), gomock.Eq(removedPost.ID)).
+					Times(1).
+					Return(removedPost, nil)
+				querier.EXPECT().
+					CreateMessage(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:         "PostPrivate",
+			conversation: conversation.ID.Hex(),
+			body: map[string]any{
+				"post_id": strangerPost.ID.Hex(),
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetConversation(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(conversation, nil)
+				expectNotBlocked(querier, user.ID, other.ID)
+				querier.EXPECT().
+					GetPost(gomock.Any(), gomock.Eq("_id"), gomock.Eq(strangerPost.ID)).
+					Times(1).
+					Return(strangerPost, nil)
+				querier.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq("_id"), gomock.Eq(strangerPost.UserID)).
+					Times(1).
+					Return(db.User{ID: strangerPost.UserID, IsPrivate: true}, nil)
+				expectNotBlocked(querier, user.ID, strangerPost.UserID)
+				querier.EXPECT().
+					IsFollowing(gomock.Any(), gomock.Eq(db.FollowParams{FollowerID: user.ID, FollowingID: strangerPost.UserID})).
+					Times(1).
+					Return(false, nil)
+				querier.EXPECT().
+					CreateMessage(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:         "PostBlocked",
+			conversation: conversation.ID.Hex(),
+			body: map[string]any{
+				"post_id": strangerPost.ID.Hex(),
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetConversation(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(conversation, nil)
+				expectNotBlocked(querier, user.ID, other.ID)
+				querier.EXPECT().
+					GetPost(gomock.Any(), gomock.Eq("_id"), gomock.Eq(strangerPost.ID)).
+					Times(1).
+					Return(strangerPost, nil)
+				querier.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq("_id"), gomock.Eq(strangerPost.UserID)).
+					Times(1).
+					Return(db.User{ID: strangerPost.UserID}, nil)
+				querier.EXPECT().
+					IsBlockedBetween(gomock.Any(), gomock.Eq(db.BlockParams{BlockerID: strangerPost.UserID, BlockedID: user.ID})).
+					Times(1).
+					Return(true, nil)
+				querier.EXPECT().
+					CreateMessage(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:         "Empty",
+			conversation: conversation.ID.Hex(),
+			body:         map[string]any{},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetConversation(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:         "InvalidID",
+			conversation: "abc",
+			body: map[string]any{
+				"content": message.Content,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetConversation(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:         "InternalError",
+			conversation: conversation.ID.Hex(),
+			body: map[string]any{
+				"content": message.Content,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetConversation(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(conversation, nil)
+				expectNotBlocked(querier, user.ID, other.ID)
+				querier.EXPECT().
+					CreateMessage(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Message{}, mongo.ErrClientDisconnected)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			queries := mockdb.NewMockQuerier(ctrl)
+			tc.buildStubs(queries)
+
+			// start test server and send request
+			server := newTestServer(t, queries, util.RandomPassword(32))
+			recorder := httptest.NewRecorder()
+
+			// the other member is told about the new message
+			sub := server.broker.Subscribe()
+			defer sub.Close()
+			sub.Add(realtime.UserTopic(other.ID))
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/v1/conversations/%s/messages", tc.conversation)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", "application/json")
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+
+			if recorder.Code == http.StatusCreated {
+				require.Len(t, sub.Events(), 1)
+			} else {
+				require.Empty(t, sub.Events())
+			}
+		})
+	}
+}
+
+func TestListMessagesAPI(t *testing.T) {
+	limit := 3
+	user, _ := randomUser(t)
+	conversation := randomConversation(user.ID, util.RandomID())
+	messages := make([]db.Message, limit)
+	for i := range messages {
+		messages[i] = randomMessage(conversation.ID, user.ID)
+	}
+	cursor := util.RandomID()
+
+	testCases := []struct {
+		name          string
+		query         map[string]string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(querier *mockdb.MockQuerier)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			query: map[string]string{"limit": fmt.Sprint(limit), "cursor": cursor.Hex()},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				arg := db.ListMessagesParams{
+					ConversationID: conversation.ID,
+					BeforeID:       cursor,
+					Limit:          int64(limit),
+				}
+
+				querier.EXPECT().
+					GetConversation(gomock.Any(), gomock.Eq(conversation.ID)).
+					Times(1).
+					Return(conversation, nil)
+				querier.EXPECT().
+					ListMessages(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(messages, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res listMessagesResponse
+				err := json.NewDecoder(recorder.Body).Decode(&res)
+				require.NoError(t, err)
+				require.Len(t, res.Messages, limit)
+				for i := range res.Messages {
+					require.Equal(t, messages[i].ID, res.Messages[i].ID)
+				}
+				require.Equal(t, messages[limit-1].ID.Hex(), res.NextCursor)
+			},
+		},
+		{
+			name:  "LastPage",
+			query: map[string]string{"limit": fmt.Sprint(limit + 1)},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetConversation(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(conversation, nil)
+				querier.EXPECT().
+					ListMessages(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(messages, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res listMessagesResponse
+				err := json.NewDecoder(recorder.Body).Decode(&res)
+				require.NoError(t, err)
+				require.Empty(t, res.NextCursor)
+			},
+		},
+		{
+			name:  "InvalidCursor",
+			query: map[string]string{"limit": fmt.Sprint(limit), "cursor": "zzzzzzzzzzzzzzzzzzzzzzzz"},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetConversation(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(conversation, nil)
+				querier.EXPECT().
+					ListMessages(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "NotMember",
+			query: map[string]string{"limit": fmt.Sprint(limit)},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, util.RandomID(), time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetConversation(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(conversation, nil)
+				querier.EXPECT().
+					ListMessages(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:  "InternalError",
+			query: map[string]string{"limit": fmt.Sprint(limit)},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetConversation(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(conversation, nil)
+				querier.EXPECT().
+					ListMessages(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, mongo.ErrClientDisconnected)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			queries := mockdb.NewMockQuerier(ctrl)
+			tc.buildStubs(queries)
+
+			// start test server and send request
+			server := newTestServer(t, queries, util.RandomPassword(32))
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/v1/conversations/%s/messages", conversation.ID.Hex())
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			q := request.URL.Query()
+			for key, value := range tc.query {
+				q.Add(key, value)
+			}
+			request.URL.RawQuery = q.Encode()
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestMarkConversationReadAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	conversation := randomConversation(user.ID, util.RandomID())
+	message := randomMessage(conversation.ID, conversation.Members[1].UserID)
+	otherMessage := randomMessage(util.RandomID(), user.ID)
+
+	testCases := []struct {
+		name          string
+		messageID     string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(querier *mockdb.MockQuerier)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "OK",
+			messageID: message.ID.Hex(),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				arg := db.MarkConversationReadParams{
+					ConversationID: conversation.ID,
+					UserID:         user.ID,
+					MessageID:      message.ID,
+				}
+
+				querier.EXPECT().
+					GetConversation(gomock.Any(), gomock.Eq(conversation.ID)).
+					Times(1).
+					Return(conversation, nil)
+				querier.EXPECT().
+					GetMessage(gomock.Any(), gomock.Eq(message.ID)).
+					Times(1).
+					Return(message, nil)
+				querier.EXPECT().
+					MarkConversationRead(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(&mongo.UpdateResult{MatchedCount: 1, ModifiedCount: 1}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchUpdateResult(t, recorder.Body, &mongo.UpdateResult{MatchedCount: 1, ModifiedCount: 1})
+			},
+		},
+		{
+			name:      "MessageOfAnotherConversation",
+			messageID: otherMessage.ID.Hex(),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetConversation(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(conversation, nil)
+				querier.EXPECT().
+					GetMessage(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(otherMessage, nil)
+				querier.EXPECT().
+					MarkConversationRead(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "NotMember",
+			messageID: message.ID.Hex(),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, util.RandomID(), time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetConversation(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(conversation, nil)
+				querier.EXPECT().
+					MarkConversationRead(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			queries := mockdb.NewMockQuerier(ctrl)
+			tc.buildStubs(queries)
+
+			// start test server and send request
+			server := newTestServer(t, queries, util.RandomPassword(32))
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(map[string]any{"message_id": tc.messageID})
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/v1/conversations/%s/read", conversation.ID.Hex())
+			request, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(data))
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", "application/json")
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestUpdateMessageAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	message := randomMessage(util.RandomID(), user.ID)
+	deleted := randomMessage(util.RandomID(), user.ID)
+	deleted.Deleted = true
+	content := util.RandomDescription(50)
+
+	testCases := []struct {
+		name          string
+		message       db.Message
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(querier *mockdb.MockQuerier)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:    "OK",
+			message: message,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				arg := db.UpdateMessageParams{
+					ID:      message.ID,
+					Content: content,
+				}
+
+				querier.EXPECT().
+					GetMessage(gomock.Any(), gomock.Eq(message.ID)).
+					Times(1).
+					Return(message, nil)
+				querier.EXPECT().
+					UpdateMessage(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(&mongo.UpdateResult{MatchedCount: 1, ModifiedCount: 1}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchUpdateResult(t, recorder.Body, &mongo.UpdateResult{MatchedCount: 1, ModifiedCount: 1})
+			},
+		},
+		{
+			name:    "NotTheSender",
+			message: message,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, util.RandomID(), time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetMessage(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(message, nil)
+				querier.EXPECT().
+					UpdateMessage(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:    "Deleted",
+			message: deleted,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetMessage(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(deleted, nil)
+				querier.EXPECT().
+					UpdateMessage(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:    "NotFound",
+			message: message,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetMessage(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Message{}, mongo.ErrNoDocuments)
+				querier.EXPECT().
+					UpdateMessage(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			queries := mockdb.NewMockQuerier(ctrl)
+			tc.buildStubs(queries)
+
+			// start test server and send request
+			server := newTestServer(t, queries, util.RandomPassword(32))
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(map[string]any{"content": content})
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/v1/messages/%s", tc.message.ID.Hex())
+			request, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(data))
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", "application/json")
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestDeleteMessageAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	message := randomMessage(util.RandomID(), user.ID)
+
+	testCases := []struct {
+		name          string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(querier *mockdb.MockQuerier)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetMessage(gomock.Any(), gomock.Eq(message.ID)).
+					Times(1).
+					Return(message, nil)
+				querier.EXPECT().
+					DeleteMessage(gomock.Any(), gomock.Eq(message.ID)).
+					Times(1).
+					Return(&mongo.UpdateResult{MatchedCount: 1, ModifiedCount: 1}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchUpdateResult(t, recorder.Body, &mongo.UpdateResult{MatchedCount: 1, ModifiedCount: 1})
+			},
+		},
+		{
+			name: "NotTheSender",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, util.RandomID(), time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetMessage(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(message, nil)
+				querier.EXPECT().
+					DeleteMessage(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetMessage(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(message, nil)
+				querier.EXPECT().
+					DeleteMessage(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, mongo.ErrClientDisconnected)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			queries := mockdb.NewMockQuerier(ctrl)
+			tc.buildStubs(queries)
+
+			// start test server and send request
+			server := newTestServer(t, queries, util.RandomPassword(32))
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/v1/messages/%s", message.ID.Hex())
+			request, err := http.NewRequest(http.MethodDelete, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func randomConversation(memberIDs ...primitive.ObjectID) db.Conversation {
+	conversation := db.Conversation{
+		ID:            util.RandomID(),
+		LastMessageAt: time.Now(),
+		CreatedAt:     time.Now(),
+	}
+
+	for _, id := range memberIDs {
+		conversation.Members = append(conversation.Members, db.ConversationMember{UserID: id})
+	}
+
+	return conversation
+}
+
+func randomMessage(conversationID, userID primitive.ObjectID) db.Message {
+	return db.Message{
+		ID:             util.RandomID(),
+		ConversationID: conversationID,
+		UserID:         userID,
+		Content:        util.RandomDescription(50),
+		CreatedAt:      time.Now(),
+	}
+}
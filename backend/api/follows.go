@@ -0,0 +1,312 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	db "github.com/DMV-Nicolas/robotgram/backend/db/mongo"
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type followUserRequest struct {
+	ID string `param:"id" validate:"required,len=24"`
+}
+
+func (server *Server) FollowUser(c echo.Context) error {
+	req := new(followUserRequest)
+	if err := bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	gotUser, err := server.validUser(c, req.ID)
+	if err != nil {
+		return err
+	}
+
+	payload, err := getAuthorizationPayload(c)
+	if err != nil {
+		return err
+	}
+
+	if err = server.checkNotBlocked(payload.UserID, gotUser.ID); err != nil {
+		return err
+	}
+
+	arg := db.FollowParams{
+		FollowerID:  payload.UserID,
+		FollowingID: gotUser.ID,
+	}
+
+	// the private accounts approve their followers, so the follow waits as a request
+	if gotUser.IsPrivate {
+		request, err := server.queries.RequestFollow(context.TODO(), arg)
+		if err != nil {
+			if err == db.ErrAlreadyFollows || err == db.ErrSelfFollow || err == db.ErrFollowRequested {
+				return echo.NewHTTPError(http.StatusBadRequest, err)
+			}
+			return echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+
+		return c.JSON(http.StatusAccepted, request)
+	}
+
+	result, err := server.queries.Follow(context.TODO(), arg)
+	if err != nil {
+		if err == db.ErrAlreadyFollows || err == db.ErrSelfFollow {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusCreated, result)
+}
+
+type unfollowUserRequest struct {
+	ID string `param:"id" validate:"required,len=24"`
+}
+
+// UnfollowUser stops following the user, or cancels the follow request if it wasn't approved yet
+func (server *Server) UnfollowUser(c echo.Context) error {
+	req := new(unfollowUserRequest)
+	if err := bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	followingID, err := primitive.ObjectIDFromHex(req.ID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	payload, err := getAuthorizationPayload(c)
+	if err != nil {
+		return err
+	}
+
+	arg := db.FollowParams{
+		FollowerID:  payload.UserID,
+		FollowingID: followingID,
+	}
+
+	result, err := server.queries.Unfollow(context.TODO(), arg)
+	if err == nil && result.DeletedCount == 0 {
+		result, err = server.queries.DeleteFollowRequest(context.TODO(), arg)
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+type isFollowingRequest struct {
+	ID string `param:"id" validate:"required,len=24"`
+}
+
+func (server *Server) IsFollowing(c echo.Context) error {
+	req := new(isFollowingRequest)
+	if err := bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	followingID, err := primitive.ObjectIDFromHex(req.ID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	payload, err := getAuthorizationPayload(c)
+	if err != nil {
+		return err
+	}
+
+	arg := db.FollowParams{
+		FollowerID:  payload.UserID,
+		FollowingID: followingID,
+	}
+
+	following, err := server.queries.IsFollowing(context.TODO(), arg)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, following)
+}
+
+type listFollowsRequest struct {
+	ID     string `param:"id" validate:"required,len=24"`
+	Offset int64  `query:"offset" validate:"min=0"`
+	Limit  int64  `query:"limit" validate:"min=1"`
+}
+
+// ListFollowers lists the follows whose target is the user. Like its posts, they are hidden
+// from the viewers that can't see the user
+func (server *Server) ListFollowers(c echo.Context) error {
+	req := new(listFollowsRequest)
+	if err := bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	userID, err := primitive.ObjectIDFromHex(req.ID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	viewerID, err := getViewerID(c)
+	if err != nil {
+		return err
+	}
+
+	if err = server.checkCanView(viewerID, userID); err != nil {
+		return err
+	}
+
+	arg := db.ListFollowsParams{
+		UserID: userID,
+		Offset: req.Offset,
+		Limit:  req.Limit,
+	}
+
+	follows, err := server.queries.ListFollowers(context.TODO(), arg)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, follows)
+}
+
+// ListFollowing lists the follows made by the user, hidden like in ListFollowers
+func (server *Server) ListFollowing(c echo.Context) error {
+	req := new(listFollowsRequest)
+	if err := bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	userID, err := primitive.ObjectIDFromHex(req.ID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	viewerID, err := getViewerID(c)
+	if err != nil {
+		return err
+	}
+
+	if err = server.checkCanView(viewerID, userID); err != nil {
+		return err
+	}
+
+	arg := db.ListFollowsParams{
+		UserID: userID,
+		Offset: req.Offset,
+		Limit:  req.Limit,
+	}
+
+	follows, err := server.queries.ListFollowing(context.TODO(), arg)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, follows)
+}
+
+type listFollowRequestsRequest struct {
+	Offset int64 `query:"offset" validate:"min=0"`
+	Limit  int64 `query:"limit" validate:"min=1,max=50"`
+}
+
+// ListFollowRequests lists the pending follows of the authenticated user, the newest first
+func (server *Server) ListFollowRequests(c echo.Context) error {
+	req := new(listFollowRequestsRequest)
+	if err := bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	payload, err := getAuthorizationPayload(c)
+	if err != nil {
+		return err
+	}
+
+	arg := db.ListFollowsParams{
+		UserID: payload.UserID,
+		Offset: req.Offset,
+		Limit:  req.Limit,
+	}
+
+	requests, err := server.queries.ListFollowRequests(context.TODO(), arg)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, requests)
+}
+
+type followRequestRequest struct {
+	FollowerID string `param:"id" validate:"required,len=24"`
+}
+
+// ApproveFollowRequest lets the user that made the request follow the authenticated user
+func (server *Server) ApproveFollowRequest(c echo.Context) error {
+	req := new(followRequestRequest)
+	if err := bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	followerID, err := primitive.ObjectIDFromHex(req.FollowerID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	payload, err := getAuthorizationPayload(c)
+	if err != nil {
+		return err
+	}
+
+	arg := db.FollowParams{
+		FollowerID:  followerID,
+		FollowingID: payload.UserID,
+	}
+
+	result, err := server.queries.ApproveFollowRequest(context.TODO(), arg)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return echo.NewHTTPError(http.StatusNotFound, err)
+		}
+		if err == db.ErrAlreadyFollows {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusCreated, result)
+}
+
+// RejectFollowRequest removes the request that the user made to follow the authenticated user
+func (server *Server) RejectFollowRequest(c echo.Context) error {
+	req := new(followRequestRequest)
+	if err := bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	followerID, err := primitive.ObjectIDFromHex(req.FollowerID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	payload, err := getAuthorizationPayload(c)
+	if err != nil {
+		return err
+	}
+
+	arg := db.FollowParams{
+		FollowerID:  followerID,
+		FollowingID: payload.UserID,
+	}
+
+	result, err := server.queries.DeleteFollowRequest(context.TODO(), arg)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	db "github.com/DMV-Nicolas/robotgram/backend/db/mongo"
+	"github.com/DMV-Nicolas/robotgram/backend/util"
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var errInvalidHashtag = errors.New("invalid hashtag")
+
+type getHashtagRequest struct {
+	Tag string `param:"tag" validate:"required"`
+}
+
+// GetHashtag returns the hashtag along with how many posts use it
+func (server *Server) GetHashtag(c echo.Context) error {
+	req := new(getHashtagRequest)
+	if err := bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	name := util.NormalizeHashtag(req.Tag)
+	if name == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, errInvalidHashtag)
+	}
+
+	hashtag, err := server.queries.GetHashtag(context.TODO(), name)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return echo.NewHTTPError(http.StatusNotFound, err)
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, hashtag)
+}
+
+type searchHashtagsRequest struct {
+	Prefix string `query:"prefix" validate:"required"`
+	Limit  int64  `query:"limit" validate:"min=1,max=20"`
+}
+
+// SearchHashtags autocompletes the hashtags that start with the prefix, the most used first
+func (server *Server) SearchHashtags(c echo.Context) error {
+	req := new(searchHashtagsRequest)
+	if err := bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	prefix := util.NormalizeHashtag(req.Prefix)
+	if prefix == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, errInvalidHashtag)
+	}
+
+	arg := db.SearchHashtagsParams{
+		Prefix: prefix,
+		Limit:  req.Limit,
+	}
+
+	hashtags, err := server.queries.SearchHashtags(context.TODO(), arg)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, hashtags)
+}
+
+type listHashtagPostsRequest struct {
+	Tag    string `param:"tag" validate:"required"`
+	Offset int64  `query:"offset" validate:"min=0"`
+	Limit  int64  `query:"limit" validate:"min=1,max=50"`
+}
+
+// ListHashtagPosts lists the posts that use the hashtag, the newest first
+func (server *Server) ListHashtagPosts(c echo.Context) error {
+	req := new(listHashtagPostsRequest)
+	if err := bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	name := util.NormalizeHashtag(req.Tag)
+	if name == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, errInvalidHashtag)
+	}
+
+	viewerID, err := getViewerID(c)
+	if err != nil {
+		return err
+	}
+
+	arg := db.ListHashtagPostsParams{
+		Hashtag:  name,
+		Offset:   req.Offset,
+		Limit:    req.Limit,
+		ViewerID: viewerID,
+	}
+
+	posts, err := server.queries.ListHashtagPosts(context.TODO(), arg)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, posts)
+}
@@ -0,0 +1,319 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	mockdb "github.com/DMV-Nicolas/robotgram/backend/db/mock"
+	db "github.com/DMV-Nicolas/robotgram/backend/db/mongo"
+	"github.com/DMV-Nicolas/robotgram/backend/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestGetHashtagAPI(t *testing.T) {
+	hashtag := randomHashtag()
+
+	testCases := []struct {
+		name          string
+		tag           string
+		buildStubs    func(querier *mockdb.MockQuerier)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			tag:  "#" + hashtag.Name,
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetHashtag(gomock.Any(), gomock.Eq(hashtag.Name)).
+					Times(1).
+					Return(hashtag, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchHashtag(t, recorder.Body, hashtag)
+			},
+		},
+		{
+			name: "NotFound",
+			tag:  hashtag.Name,
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetHashtag(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Hashtag{}, mongo.ErrNoDocuments)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			tag:  hashtag.Name,
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetHashtag(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Hashtag{}, mongo.ErrClientDisconnected)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidHashtag",
+			tag:  "2024",
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetHashtag(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			queries := mockdb.NewMockQuerier(ctrl)
+			tc.buildStubs(queries)
+
+			// start test server and send request
+			server := newTestServer(t, queries, util.RandomPassword(32))
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/v1/hashtags/%s", url.PathEscape(tc.tag))
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestSearchHashtagsAPI(t *testing.T) {
+	limit := 5
+	hashtags := make([]db.Hashtag, limit)
+	for i := 0; i < limit; i++ {
+		hashtags[i] = randomHashtag()
+	}
+
+	testCases := []struct {
+		name          string
+		prefix        string
+		limit         int
+		buildStubs    func(querier *mockdb.MockQuerier)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "OK",
+			prefix: "#Robo",
+			limit:  limit,
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				arg := db.SearchHashtagsParams{
+					Prefix: "robo",
+					Limit:  int64(limit),
+				}
+
+				querier.EXPECT().
+					SearchHashtags(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(hashtags, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchHashtags(t, recorder.Body, hashtags)
+			},
+		},
+		{
+			name:   "InternalError",
+			prefix: "robo",
+			limit:  limit,
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					SearchHashtags(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, mongo.ErrClientDisconnected)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name:   "InvalidPrefix",
+			prefix: "ro bo",
+			limit:  limit,
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					SearchHashtags(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "LimitTooBig",
+			prefix: "robo",
+			limit:  100,
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					SearchHashtags(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			queries := mockdb.NewMockQuerier(ctrl)
+			tc.buildStubs(queries)
+
+			// start test server and send request
+			server := newTestServer(t, queries, util.RandomPassword(32))
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/v1/hashtags", nil)
+			require.NoError(t, err)
+
+			q := request.URL.Query()
+			q.Add("prefix", tc.prefix)
+			q.Add("limit", fmt.Sprint(tc.limit))
+			request.URL.RawQuery = q.Encode()
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListHashtagPostsAPI(t *testing.T) {
+	offset, limit := 0, 5
+	hashtag := randomHashtag()
+	posts := make([]db.Post, limit)
+	for i := 0; i < limit; i++ {
+		posts[i] = randomPost(t, primitive.NewObjectID())
+		posts[i].Hashtags = []string{hashtag.Name}
+	}
+
+	testCases := []struct {
+		name          string
+		tag           string
+		limit         int
+		buildStubs    func(querier *mockdb.MockQuerier)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			tag:   "#" + hashtag.Name,
+			limit: limit,
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				arg := db.ListHashtagPostsParams{
+					Hashtag: hashtag.Name,
+					Offset:  int64(offset),
+					Limit:   int64(limit),
+				}
+
+				querier.EXPECT().
+					ListHashtagPosts(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(posts, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchPosts(t, recorder.Body, posts)
+			},
+		},
+		{
+			name:  "InternalError",
+			tag:   hashtag.Name,
+			limit: limit,
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					ListHashtagPosts(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, mongo.ErrClientDisconnected)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name:  "InvalidHashtag",
+			tag:   "__",
+			limit: limit,
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					ListHashtagPosts(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "LimitTooBig",
+			tag:   hashtag.Name,
+			limit: 1000,
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					ListHashtagPosts(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			queries := mockdb.NewMockQuerier(ctrl)
+			tc.buildStubs(queries)
+
+			// start test server and send request
+			server := newTestServer(t, queries, util.RandomPassword(32))
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/v1/hashtags/%s/posts", url.PathEscape(tc.tag))
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			q := request.URL.Query()
+			q.Add("offset", fmt.Sprint(offset))
+			q.Add("limit", fmt.Sprint(tc.limit))
+			request.URL.RawQuery = q.Encode()
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func randomHashtag() db.Hashtag {
+	return db.Hashtag{
+		Name:      "robot" + util.RandomString(6),
+		PostCount: 10,
+		UpdatedAt: time.Now(),
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -79,6 +80,22 @@ func requireBodyMatchUser(t *testing.T, body *bytes.Buffer, user db.User) {
 	require.WithinDuration(t, bodyResult.CreatedAt, user.CreatedAt, time.Second)
 }
 
+func requireBodyMatchUserWithFollows(t *testing.T, body *bytes.Buffer, user db.User, nFollowers, nFollowing int64) {
+	bodyResult := new(getUserResponse)
+	err := json.NewDecoder(body).Decode(bodyResult)
+	require.NoError(t, err)
+	require.NotEmpty(t, bodyResult)
+
+	require.Equal(t, user.ID, bodyResult.ID)
+	require.Equal(t, user.Username, bodyResult.Username)
+	require.Equal(t, user.FullName, bodyResult.FullName)
+	require.Equal(t, user.Avatar, bodyResult.Avatar)
+	require.Equal(t, nFollowers, bodyResult.Followers)
+	require.Equal(t, nFollowing, bodyResult.Following)
+
+	require.WithinDuration(t, user.CreatedAt, bodyResult.CreatedAt, time.Second)
+}
+
 func requireBodyMatchUsers(t *testing.T, body *bytes.Buffer, users []db.User) {
 	bodyResult := make([]db.User, 0, len(users))
 	err := json.NewDecoder(body).Decode(&bodyResult)
@@ -124,25 +141,111 @@ func requireBodyMatchPosts(t *testing.T, body *bytes.Buffer, posts []db.Post) {
 		require.Equal(t, posts[i].UserID, bodyResult[i].UserID)
 		require.Equal(t, posts[i].Images, bodyResult[i].Images)
 		require.Equal(t, posts[i].Description, bodyResult[i].Description)
+		require.Equal(t, posts[i].Hashtags, bodyResult[i].Hashtags)
 		require.WithinDuration(t, posts[i].CreatedAt, bodyResult[i].CreatedAt, time.Second)
 	}
 }
 
-func requireBodyMatchToggleLikeResponse(t *testing.T, body *bytes.Buffer, res toggleLikeResponse) {
-	bodyResult := new(toggleLikeResponse)
+func requireBodyMatchHashtag(t *testing.T, body *bytes.Buffer, hashtag db.Hashtag) {
+	bodyResult := new(db.Hashtag)
+	err := json.NewDecoder(body).Decode(bodyResult)
+	require.NoError(t, err)
+	require.NotEmpty(t, bodyResult)
+
+	require.Equal(t, hashtag.Name, bodyResult.Name)
+	require.Equal(t, hashtag.PostCount, bodyResult.PostCount)
+	require.WithinDuration(t, hashtag.UpdatedAt, bodyResult.UpdatedAt, time.Second)
+}
+
+func requireBodyMatchHashtags(t *testing.T, body *bytes.Buffer, hashtags []db.Hashtag) {
+	bodyResult := make([]db.Hashtag, 0, len(hashtags))
+	err := json.NewDecoder(body).Decode(&bodyResult)
+	require.NoError(t, err)
+	require.NotEmpty(t, bodyResult)
+
+	require.Len(t, bodyResult, len(hashtags))
+
+	for i := range bodyResult {
+		require.Equal(t, hashtags[i].Name, bodyResult[i].Name)
+		require.Equal(t, hashtags[i].PostCount, bodyResult[i].PostCount)
+		require.WithinDuration(t, hashtags[i].UpdatedAt, bodyResult[i].UpdatedAt, time.Second)
+	}
+}
+
+func requireBodyMatchMentionings(t *testing.T, body *bytes.Buffer, mentionings []db.Mentioning) {
+	bodyResult := make([]db.Mentioning, 0, len(mentionings))
+	err := json.NewDecoder(body).Decode(&bodyResult)
+	require.NoError(t, err)
+	require.NotEmpty(t, bodyResult)
+
+	require.Len(t, bodyResult, len(mentionings))
+
+	for i := range bodyResult {
+		require.Equal(t, mentionings[i].Kind, bodyResult[i].Kind)
+		if mentionings[i].Post != nil {
+			require.NotNil(t, bodyResult[i].Post)
+			require.Equal(t, mentionings[i].Post.ID, bodyResult[i].Post.ID)
+		}
+		if mentionings[i].Comment != nil {
+			require.NotNil(t, bodyResult[i].Comment)
+			require.Equal(t, mentionings[i].Comment.ID, bodyResult[i].Comment.ID)
+		}
+		require.WithinDuration(t, mentionings[i].CreatedAt, bodyResult[i].CreatedAt, time.Second)
+	}
+}
+
+func requireBodyMatchNotifications(t *testing.T, body *bytes.Buffer, notifications []db.Notification) {
+	bodyResult := make([]db.Notification, 0, len(notifications))
+	err := json.NewDecoder(body).Decode(&bodyResult)
+	require.NoError(t, err)
+	require.NotEmpty(t, bodyResult)
+
+	require.Len(t, bodyResult, len(notifications))
+
+	for i := range bodyResult {
+		require.Equal(t, notifications[i].ID, bodyResult[i].ID)
+		require.Equal(t, notifications[i].UserID, bodyResult[i].UserID)
+		require.Equal(t, notifications[i].Type, bodyResult[i].Type)
+		require.Equal(t, notifications[i].TargetID, bodyResult[i].TargetID)
+		require.Equal(t, notifications[i].ActorIDs, bodyResult[i].ActorIDs)
+		require.Equal(t, notifications[i].ActorCount, bodyResult[i].ActorCount)
+		require.Equal(t, notifications[i].Read, bodyResult[i].Read)
+		require.WithinDuration(t, notifications[i].UpdatedAt, bodyResult[i].UpdatedAt, time.Second)
+	}
+}
+
+func requireBodyMatchNotificationPreferences(t *testing.T, body *bytes.Buffer, preferences map[string]bool) {
+	bodyResult := make(map[string]bool)
+	err := json.NewDecoder(body).Decode(&bodyResult)
+	require.NoError(t, err)
+
+	require.Equal(t, preferences, bodyResult)
+}
+
+func requireBodyMatchFeed(t *testing.T, body *bytes.Buffer, posts []db.Post, nextCursor string) {
+	bodyResult := new(listFeedResponse)
 	err := json.NewDecoder(body).Decode(bodyResult)
 	require.NoError(t, err)
 	require.NotEmpty(t, bodyResult)
 
-	if res.CreatedResult != nil {
-		require.Equal(t, res.CreatedResult.InsertedID.(primitive.ObjectID).Hex(), bodyResult.CreatedResult.InsertedID)
-		require.Equal(t, res.DeletedResult, bodyResult.DeletedResult)
-	} else {
-		require.Equal(t, res.CreatedResult, bodyResult.CreatedResult)
-		require.Equal(t, res.DeletedResult, bodyResult.DeletedResult)
+	require.Len(t, bodyResult.Posts, len(posts))
+	require.Equal(t, nextCursor, bodyResult.NextCursor)
+
+	for i := range bodyResult.Posts {
+		require.Equal(t, posts[i].ID, bodyResult.Posts[i].ID)
+		require.Equal(t, posts[i].UserID, bodyResult.Posts[i].UserID)
+		require.WithinDuration(t, posts[i].CreatedAt, bodyResult.Posts[i].CreatedAt, time.Second)
 	}
 }
 
+func requireBodyMatchLikeResponse(t *testing.T, body *bytes.Buffer, res likeResponse) {
+	bodyResult := new(likeResponse)
+	err := json.NewDecoder(body).Decode(bodyResult)
+	require.NoError(t, err)
+
+	require.Equal(t, res, *bodyResult)
+}
+
 func requireBodyMatchCountLikes(t *testing.T, body *bytes.Buffer, nLikes int64) {
 	bodyResult := int64(0)
 	err := json.NewDecoder(body).Decode(&bodyResult)
@@ -192,3 +295,218 @@ func requireBodyMatchComments(t *testing.T, body *bytes.Buffer, comments []db.Co
 		require.WithinDuration(t, comments[i].CreatedAt, bodyResult[i].CreatedAt, time.Second)
 	}
 }
+
+func requireBodyMatchFollows(t *testing.T, body *bytes.Buffer, follows []db.Follow) {
+	bodyResult := make([]db.Follow, 0, len(follows))
+	err := json.NewDecoder(body).Decode(&bodyResult)
+	require.NoError(t, err)
+	require.NotEmpty(t, bodyResult)
+
+	require.Len(t, bodyResult, len(follows))
+
+	for i := range bodyResult {
+		require.Equal(t, follows[i].ID, bodyResult[i].ID)
+		require.Equal(t, follows[i].FollowerID, bodyResult[i].FollowerID)
+		require.Equal(t, follows[i].FollowingID, bodyResult[i].FollowingID)
+		require.WithinDuration(t, follows[i].CreatedAt, bodyResult[i].CreatedAt, time.Second)
+	}
+}
+
+func requireBodyMatchFollowRequest(t *testing.T, body *bytes.Buffer, request db.FollowRequest) {
+	var bodyResult db.FollowRequest
+	err := json.NewDecoder(body).Decode(&bodyResult)
+	require.NoError(t, err)
+
+	require.Equal(t, request.ID, bodyResult.ID)
+	require.Equal(t, request.FollowerID, bodyResult.FollowerID)
+	require.Equal(t, request.FollowingID, bodyResult.FollowingID)
+	require.WithinDuration(t, request.CreatedAt, bodyResult.CreatedAt, time.Second)
+}
+
+func requireBodyMatchFollowRequests(t *testing.T, body *bytes.Buffer, requests []db.FollowRequest) {
+	bodyResult := make([]db.FollowRequest, 0, len(requests))
+	err := json.NewDecoder(body).Decode(&bodyResult)
+	require.NoError(t, err)
+
+	require.Len(t, bodyResult, len(requests))
+
+	for i := range bodyResult {
+		require.Equal(t, requests[i].ID, bodyResult[i].ID)
+		require.Equal(t, requests[i].FollowerID, bodyResult[i].FollowerID)
+		require.Equal(t, requests[i].FollowingID, bodyResult[i].FollowingID)
+		require.WithinDuration(t, requests[i].CreatedAt, bodyResult[i].CreatedAt, time.Second)
+	}
+}
+
+func requireBodyMatchBlock(t *testing.T, body *bytes.Buffer, block db.Block) {
+	var bodyResult db.Block
+	err := json.NewDecoder(body).Decode(&bodyResult)
+	require.NoError(t, err)
+
+	require.Equal(t, block.ID, bodyResult.ID)
+	require.Equal(t, block.BlockerID, bodyResult.BlockerID)
+	require.Equal(t, block.BlockedID, bodyResult.BlockedID)
+	require.WithinDuration(t, block.CreatedAt, bodyResult.CreatedAt, time.Second)
+}
+
+func requireBodyMatchBlocks(t *testing.T, body *bytes.Buffer, blocks []db.Block) {
+	bodyResult := make([]db.Block, 0, len(blocks))
+	err := json.NewDecoder(body).Decode(&bodyResult)
+	require.NoError(t, err)
+
+	require.Len(t, bodyResult, len(blocks))
+
+	for i := range bodyResult {
+		require.Equal(t, blocks[i].ID, bodyResult[i].ID)
+		require.Equal(t, blocks[i].BlockerID, bodyResult[i].BlockerID)
+		require.Equal(t, blocks[i].BlockedID, bodyResult[i].BlockedID)
+		require.WithinDuration(t, blocks[i].CreatedAt, bodyResult[i].CreatedAt, time.Second)
+	}
+}
+
+func requireBodyMatchMute(t *testing.T, body *bytes.Buffer, mute db.Mute) {
+	var bodyResult db.Mute
+	err := json.NewDecoder(body).Decode(&bodyResult)
+	require.NoError(t, err)
+
+	require.Equal(t, mute.ID, bodyResult.ID)
+	require.Equal(t, mute.MuterID, bodyResult.MuterID)
+	require.Equal(t, mute.MutedID, bodyResult.MutedID)
+	require.WithinDuration(t, mute.CreatedAt, bodyResult.CreatedAt, time.Second)
+}
+
+func requireBodyMatchReport(t *testing.T, body *bytes.Buffer, report db.Report) {
+	var bodyResult db.Report
+	err := json.NewDecoder(body).Decode(&bodyResult)
+	require.NoError(t, err)
+
+	require.Equal(t, report.ID, bodyResult.ID)
+	require.Equal(t, report.ReporterID, bodyResult.ReporterID)
+	require.Equal(t, report.TargetType, bodyResult.TargetType)
+	require.Equal(t, report.TargetID, bodyResult.TargetID)
+	require.Equal(t, report.Reason, bodyResult.Reason)
+	require.Equal(t, report.Details, bodyResult.Details)
+	require.Equal(t, report.Status, bodyResult.Status)
+	require.WithinDuration(t, report.CreatedAt, bodyResult.CreatedAt, time.Second)
+}
+
+func requireBodyMatchReportGroups(t *testing.T, body *bytes.Buffer, groups []db.ReportGroup) {
+	bodyResult := make([]db.ReportGroup, 0, len(groups))
+	err := json.NewDecoder(body).Decode(&bodyResult)
+	require.NoError(t, err)
+
+	require.Len(t, bodyResult, len(groups))
+
+	for i := range bodyResult {
+		require.Equal(t, groups[i].TargetType, bodyResult[i].TargetType)
+		require.Equal(t, groups[i].TargetID, bodyResult[i].TargetID)
+		require.Equal(t, groups[i].ReportCount, bodyResult[i].ReportCount)
+		require.Equal(t, groups[i].Reasons, bodyResult[i].Reasons)
+		require.WithinDuration(t, groups[i].FirstReportedAt, bodyResult[i].FirstReportedAt, time.Second)
+		require.WithinDuration(t, groups[i].LastReportedAt, bodyResult[i].LastReportedAt, time.Second)
+	}
+}
+
+func requireBodyMatchModerationAction(t *testing.T, body *bytes.Buffer, action db.ModerationAction) {
+	var bodyResult db.ModerationAction
+	err := json.NewDecoder(body).Decode(&bodyResult)
+	require.NoError(t, err)
+
+	require.Equal(t, action.ID, bodyResult.ID)
+	require.Equal(t, action.ModeratorID, bodyResult.ModeratorID)
+	require.Equal(t, action.TargetType, bodyResult.TargetType)
+	require.Equal(t, action.TargetID, bodyResult.TargetID)
+	require.Equal(t, action.UserID, bodyResult.UserID)
+	require.Equal(t, action.Action, bodyResult.Action)
+	require.Equal(t, action.Note, bodyResult.Note)
+	require.Equal(t, action.ReportCount, bodyResult.ReportCount)
+	require.WithinDuration(t, action.CreatedAt, bodyResult.CreatedAt, time.Second)
+}
+
+func requireBodyMatchModerationActions(t *testing.T, body *bytes.Buffer, actions []db.ModerationAction) {
+	bodyResult := make([]db.ModerationAction, 0, len(actions))
+	err := json.NewDecoder(body).Decode(&bodyResult)
+	require.NoError(t, err)
+
+	require.Len(t, bodyResult, len(actions))
+
+	for i := range bodyResult {
+		require.Equal(t, actions[i].ID, bodyResult[i].ID)
+		require.Equal(t, actions[i].TargetID, bodyResult[i].TargetID)
+		require.Equal(t, actions[i].Action, bodyResult[i].Action)
+	}
+}
+
+func requireBodyMatchFollowing(t *testing.T, body *bytes.Buffer, following bool) {
+	var bodyResult bool
+	err := json.NewDecoder(body).Decode(&bodyResult)
+	require.NoError(t, err)
+	require.Equal(t, following, bodyResult)
+}
+
+func requireBodyMatchTimelineStats(t *testing.T, body *bytes.Buffer, stats db.TimelineStats) {
+	bodyResult := new(db.TimelineStats)
+	err := json.NewDecoder(body).Decode(bodyResult)
+	require.NoError(t, err)
+
+	require.Equal(t, stats.Pending, bodyResult.Pending)
+	require.Equal(t, stats.Processing, bodyResult.Processing)
+	require.Equal(t, stats.Done, bodyResult.Done)
+	require.Equal(t, stats.Failed, bodyResult.Failed)
+	require.WithinDuration(t, stats.OldestPendingAt, bodyResult.OldestPendingAt, time.Second)
+}
+
+func requireBodyMatchPurgeJob(t *testing.T, body *bytes.Buffer, job db.PurgeJob) {
+	var gotJob db.PurgeJob
+	err := json.NewDecoder(body).Decode(&gotJob)
+	require.NoError(t, err)
+
+	require.Equal(t, job.ID, gotJob.ID)
+	require.Equal(t, job.UserID, gotJob.UserID)
+	require.Equal(t, job.Status, gotJob.Status)
+	require.Equal(t, job.Step, gotJob.Step)
+	require.Equal(t, job.Processed, gotJob.Processed)
+	require.WithinDuration(t, job.PurgeAfter, gotJob.PurgeAfter, time.Second)
+}
+
+func requireBodyMatchUploadMedia(t *testing.T, body *bytes.Buffer, width, height int) uploadMediaResponse {
+	bodyResult := uploadMediaResponse{}
+	err := json.NewDecoder(body).Decode(&bodyResult)
+	require.NoError(t, err)
+
+	require.False(t, bodyResult.ID.IsZero())
+	require.Equal(t, width, bodyResult.Width)
+	require.Equal(t, height, bodyResult.Height)
+	require.NotEmpty(t, bodyResult.Blurhash)
+	require.Len(t, bodyResult.Renditions, 3)
+
+	for _, rendition := range bodyResult.Renditions {
+		require.Empty(t, rendition.Key)
+		require.True(t, strings.HasSuffix(rendition.URL, "/v1/media/"+bodyResult.ID.Hex()+"/"+rendition.Name))
+	}
+
+	return bodyResult
+}
+
+func requireBodyMatchConversation(t *testing.T, body *bytes.Buffer, conversation db.Conversation) {
+	var bodyConversation db.Conversation
+	err := json.NewDecoder(body).Decode(&bodyConversation)
+	require.NoError(t, err)
+
+	require.Equal(t, conversation.ID, bodyConversation.ID)
+	require.Equal(t, conversation.Members, bodyConversation.Members)
+	require.WithinDuration(t, conversation.LastMessageAt, bodyConversation.LastMessageAt, time.Second)
+}
+
+func requireBodyMatchMessage(t *testing.T, body *bytes.Buffer, message db.Message) {
+	var bodyMessage db.Message
+	err := json.NewDecoder(body).Decode(&bodyMessage)
+	require.NoError(t, err)
+
+	require.Equal(t, message.ID, bodyMessage.ID)
+	require.Equal(t, message.ConversationID, bodyMessage.ConversationID)
+	require.Equal(t, message.UserID, bodyMessage.UserID)
+	require.Equal(t, message.Content, bodyMessage.Content)
+	require.Equal(t, message.PostID, bodyMessage.PostID)
+	require.Equal(t, message.Deleted, bodyMessage.Deleted)
+}
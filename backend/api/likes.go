@@ -5,18 +5,41 @@ import (
 	"net/http"
 
 	db "github.com/DMV-Nicolas/robotgram/backend/db/mongo"
+	"github.com/DMV-Nicolas/robotgram/backend/realtime"
 	"github.com/labstack/echo/v4"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
-type toggleLikeRequest struct {
-	TargetID string `json:"target_id" validate:"required,len=24"`
+type likeResponse struct {
+	Liked     bool  `json:"liked"`
+	LikeCount int64 `json:"like_count"`
+}
+
+type likeCountEvent struct {
+	TargetID  primitive.ObjectID `json:"target_id"`
+	LikeCount int64              `json:"like_count"`
+}
+
+// newLikeResponse returns the like state of the target after the request along with its stored like count,
+// publishing the count to the clients viewing the target
+func (server *Server) newLikeResponse(ctx context.Context, targetID primitive.ObjectID, liked bool) (likeResponse, error) {
+	nLikes, err := server.queries.GetLikeCount(ctx, targetID)
+	if err != nil {
+		return likeResponse{}, err
+	}
+
+	server.broker.Publish(realtime.Event{
+		Topic: realtime.TargetTopic(targetID),
+		Type:  realtime.EventLikeCount,
+		Data:  likeCountEvent{TargetID: targetID, LikeCount: nLikes},
+	})
+
+	return likeResponse{Liked: liked, LikeCount: nLikes}, nil
 }
 
-type toggleLikeResponse struct {
-	CreatedResult *mongo.InsertOneResult
-	DeletedResult *mongo.DeleteResult
+type toggleLikeRequest struct {
+	TargetID string `json:"target_id" validate:"required,len=24"`
 }
 
 func (server *Server) ToggleLike(c echo.Context) error {
@@ -35,19 +58,103 @@ func (server *Server) ToggleLike(c echo.Context) error {
 		return err
 	}
 
-	arg := db.ToggleLikeParams{
+	if err = server.checkCanLikeTarget(payload.UserID, targetID); err != nil {
+		return err
+	}
+
+	arg := db.LikeParams{
+		UserID:   payload.UserID,
+		TargetID: targetID,
+	}
+
+	liked, err := server.queries.ToggleLike(context.TODO(), arg)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	res, err := server.newLikeResponse(context.TODO(), targetID, liked)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+type likeRequest struct {
+	TargetID string `param:"target_id" validate:"required,len=24"`
+}
+
+// LikeTarget gives the like to the target. Liking an already liked target doesn't change anything,
+// so repeating the request is safe
+func (server *Server) LikeTarget(c echo.Context) error {
+	req := new(likeRequest)
+	if err := bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	targetID, err := primitive.ObjectIDFromHex(req.TargetID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	payload, err := getAuthorizationPayload(c)
+	if err != nil {
+		return err
+	}
+
+	if err = server.checkCanLikeTarget(payload.UserID, targetID); err != nil {
+		return err
+	}
+
+	arg := db.LikeParams{
 		UserID:   payload.UserID,
 		TargetID: targetID,
 	}
 
-	createdResult, deletedResult, err := server.queries.ToggleLike(context.TODO(), arg)
+	_, err = server.queries.CreateLike(context.TODO(), arg)
+	if err != nil && err != db.ErrDuplicatedLike {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	res, err := server.newLikeResponse(context.TODO(), targetID, true)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err)
 	}
 
-	res := toggleLikeResponse{
-		CreatedResult: createdResult,
-		DeletedResult: deletedResult,
+	return c.JSON(http.StatusOK, res)
+}
+
+// UnlikeTarget removes the like from the target. Unliking a target that isn't liked doesn't change anything,
+// so repeating the request is safe
+func (server *Server) UnlikeTarget(c echo.Context) error {
+	req := new(likeRequest)
+	if err := bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	targetID, err := primitive.ObjectIDFromHex(req.TargetID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	payload, err := getAuthorizationPayload(c)
+	if err != nil {
+		return err
+	}
+
+	arg := db.LikeParams{
+		UserID:   payload.UserID,
+		TargetID: targetID,
+	}
+
+	_, err = server.queries.DeleteLike(context.TODO(), arg)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	res, err := server.newLikeResponse(context.TODO(), targetID, false)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
 	}
 
 	return c.JSON(http.StatusOK, res)
@@ -70,6 +177,15 @@ func (server *Server) ListLikes(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, err)
 	}
 
+	viewerID, err := getViewerID(c)
+	if err != nil {
+		return err
+	}
+
+	if err = server.checkCanViewTarget(viewerID, targetID); err != nil {
+		return err
+	}
+
 	arg := db.ListLikesParams{
 		TargetID: targetID,
 		Offset:   req.Offset,
@@ -88,6 +204,7 @@ type countLikesRequest struct {
 	TargetID string `param:"target_id" validate:"required,len=24"`
 }
 
+// CountLikes returns the like count stored in the target, if the viewer can see the target
 func (server *Server) CountLikes(c echo.Context) error {
 	req := new(countLikesRequest)
 	if err := bindAndValidate(c, req); err != nil {
@@ -99,8 +216,20 @@ func (server *Server) CountLikes(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, err)
 	}
 
-	nLikes, err := server.queries.CountLikes(context.TODO(), targetID)
+	viewerID, err := getViewerID(c)
+	if err != nil {
+		return err
+	}
+
+	if err = server.checkCanViewTarget(viewerID, targetID); err != nil {
+		return err
+	}
+
+	nLikes, err := server.queries.GetLikeCount(context.TODO(), targetID)
 	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return echo.NewHTTPError(http.StatusNotFound, err)
+		}
 		return echo.NewHTTPError(http.StatusInternalServerError, err)
 	}
 
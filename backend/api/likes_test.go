@@ -22,19 +22,8 @@ import (
 func TestToggleLikeAPI(t *testing.T) {
 	user, _ := randomUser(t)
 	post := randomPost(t, primitive.NewObjectID())
-	like := randomLike(t, user.ID, post.ID)
-	res1 := toggleLikeResponse{
-		CreatedResult: &mongo.InsertOneResult{
-			InsertedID: like.ID,
-		},
-		DeletedResult: nil,
-	}
-	res2 := toggleLikeResponse{
-		CreatedResult: nil,
-		DeletedResult: &mongo.DeleteResult{
-			DeletedCount: 1,
-		},
-	}
+	comment := randomComment(t, user.ID, post.ID)
+	nLikes := int64(10)
 
 	testCases := []struct {
 		name          string
@@ -46,61 +35,183 @@ func TestToggleLikeAPI(t *testing.T) {
 		{
 			name: "CreateLikeOK",
 			body: map[string]any{
-				"target_id": like.TargetID.Hex(),
+				"target_id": post.ID.Hex(),
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
 			}, buildStubs: func(querier *mockdb.MockQuerier) {
-				arg := db.ToggleLikeParams{
+				arg := db.LikeParams{
 					UserID:   user.ID,
 					TargetID: post.ID,
 				}
 
+				expectVisiblePost(querier, post, user.ID)
 				querier.EXPECT().
 					ToggleLike(gomock.Any(), gomock.Eq(arg)).
 					Times(1).
-					Return(res1.CreatedResult, res1.DeletedResult, nil)
+					Return(true, nil)
+				querier.EXPECT().
+					GetLikeCount(gomock.Any(), gomock.Eq(post.ID)).
+					Times(1).
+					Return(nLikes, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
-				requireBodyMatchToggleLikeResponse(t, recorder.Body, res1)
+				requireBodyMatchLikeResponse(t, recorder.Body, likeResponse{Liked: true, LikeCount: nLikes})
+			},
+		},
+		{
+			name: "Blocked",
+			body: map[string]any{
+				"target_id": post.ID.Hex(),
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			}, buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetPost(gomock.Any(), gomock.Eq("_id"), gomock.Eq(post.ID)).
+					Times(1).
+					Return(post, nil)
+				querier.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq("_id"), gomock.Eq(post.UserID)).
+					Times(1).
+					Return(db.User{ID: post.UserID}, nil)
+				querier.EXPECT().
+					IsBlockedBetween(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(true, nil)
+				querier.EXPECT().
+					ToggleLike(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
 			},
 		},
 		{
 			name: "DeleteLikeOK",
 			body: map[string]any{
-				"target_id": like.TargetID.Hex(),
+				"target_id": post.ID.Hex(),
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
 			}, buildStubs: func(querier *mockdb.MockQuerier) {
-				arg := db.ToggleLikeParams{
+				arg := db.LikeParams{
 					UserID:   user.ID,
 					TargetID: post.ID,
 				}
 
+				expectVisiblePost(querier, post, user.ID)
 				querier.EXPECT().
 					ToggleLike(gomock.Any(), gomock.Eq(arg)).
 					Times(1).
-					Return(res2.CreatedResult, res2.DeletedResult, nil)
+					Return(false, nil)
+				querier.EXPECT().
+					GetLikeCount(gomock.Any(), gomock.Eq(post.ID)).
+					Times(1).
+					Return(nLikes, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
-				requireBodyMatchToggleLikeResponse(t, recorder.Body, res2)
+				requireBodyMatchLikeResponse(t, recorder.Body, likeResponse{Liked: false, LikeCount: nLikes})
+			},
+		},
+		{
+			name: "PendingPurgeCommentAuthor",
+			body: map[string]any{
+				"target_id": comment.ID.Hex(),
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, primitive.NewObjectID(), time.Minute)
+			}, buildStubs: func(querier *mockdb.MockQuerier) {
+				purging := user
+				purging.PurgeAt = time.Now().Add(time.Hour)
+
+				querier.EXPECT().
+					GetPost(gomock.Any(), gomock.Eq("_id"), gomock.Eq(comment.ID)).
+					Times(1).
+					Return(db.Post{}, mongo.ErrNoDocuments)
+				querier.EXPECT().
+					GetComment(gomock.Any(), gomock.Eq(comment.ID)).
+					Times(1).
+					Return(comment, nil)
+				querier.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq("_id"), gomock.Eq(comment.UserID)).
+					Times(1).
+					Return(purging, nil)
+				querier.EXPECT().
+					ToggleLike(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "RemovedComment",
+			body: map[string]any{
+				"target_id": comment.ID.Hex(),
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, comment.UserID, time.Minute)
+			}, buildStubs: func(querier *mockdb.MockQuerier) {
+				removed := comment
+				removed.Removed = true
+
+				querier.EXPECT().
+					GetPost(gomock.Any(), gomock.Eq("_id"), gomock.Eq(comment.ID)).
+					Times(1).
+					Return(db.Post{}, mongo.ErrNoDocuments)
+				querier.EXPECT().
+					GetComment(gomock.Any(), gomock.Eq(comment.ID)).
+					Times(1).
+					Return(removed, nil)
+				querier.EXPECT().
+					ToggleLike(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
 			},
 		},
 		{
 			name: "InternalError",
 			body: map[string]any{
-				"target_id": like.TargetID.Hex(),
+				"target_id": post.ID.Hex(),
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
 			}, buildStubs: func(querier *mockdb.MockQuerier) {
+				expectVisiblePost(querier, post, user.ID)
 				querier.EXPECT().
 					ToggleLike(gomock.Any(), gomock.Any()).
 					Times(1).
-					Return(nil, nil, mongo.ErrClientDisconnected)
+					Return(false, mongo.ErrClientDisconnected)
+				querier.EXPECT().
+					GetLikeCount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name: "CountInternalError",
+			body: map[string]any{
+				"target_id": post.ID.Hex(),
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			}, buildStubs: func(querier *mockdb.MockQuerier) {
+				expectVisiblePost(querier, post, user.ID)
+				querier.EXPECT().
+					ToggleLike(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(true, nil)
+				querier.EXPECT().
+					GetLikeCount(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), mongo.ErrClientDisconnected)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
@@ -168,6 +279,296 @@ func TestToggleLikeAPI(t *testing.T) {
 	}
 }
 
+func TestLikeTargetAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	post := randomPost(t, primitive.NewObjectID())
+	like := randomLike(t, user.ID, post.ID)
+	nLikes := int64(10)
+
+	testCases := []struct {
+		name          string
+		targetID      string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockQuerier)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			targetID: post.ID.Hex(),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				arg := db.LikeParams{
+					UserID:   user.ID,
+					TargetID: post.ID,
+				}
+
+				expectVisiblePost(querier, post, user.ID)
+				querier.EXPECT().
+					CreateLike(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(like, nil)
+				querier.EXPECT().
+					GetLikeCount(gomock.Any(), gomock.Eq(post.ID)).
+					Times(1).
+					Return(nLikes, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchLikeResponse(t, recorder.Body, likeResponse{Liked: true, LikeCount: nLikes})
+			},
+		},
+		{
+			name:     "AlreadyLiked",
+			targetID: post.ID.Hex(),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				expectVisiblePost(querier, post, user.ID)
+				querier.EXPECT().
+					CreateLike(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Like{}, db.ErrDuplicatedLike)
+				querier.EXPECT().
+					GetLikeCount(gomock.Any(), gomock.Eq(post.ID)).
+					Times(1).
+					Return(nLikes, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchLikeResponse(t, recorder.Body, likeResponse{Liked: true, LikeCount: nLikes})
+			},
+		},
+		{
+			name:     "RemovedOwnPost",
+			targetID: post.ID.Hex(),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, post.UserID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				removed := post
+				removed.Removed = true
+
+				querier.EXPECT().
+					GetPost(gomock.Any(), gomock.Eq("_id"), gomock.Eq(post.ID)).
+					Times(1).
+					Return(removed, nil)
+				querier.EXPECT().
+					CreateLike(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:     "InternalError",
+			targetID: post.ID.Hex(),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				expectVisiblePost(querier, post, user.ID)
+				querier.EXPECT().
+					CreateLike(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Like{}, mongo.ErrClientDisconnected)
+				querier.EXPECT().
+					GetLikeCount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name:     "NoAuthorization",
+			targetID: post.ID.Hex(),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					CreateLike(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "InvalidTargetID",
+			targetID: "qwertyuiopasdfghjklñzxcv",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					CreateLike(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			queries := mockdb.NewMockQuerier(ctrl)
+			tc.buildStubs(queries)
+
+			// start test server and send request
+			server := newTestServer(t, queries, util.RandomPassword(32))
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/v1/likes/%s", tc.targetID)
+			request, err := http.NewRequest(http.MethodPut, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestUnlikeTargetAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	post := randomPost(t, primitive.NewObjectID())
+	nLikes := int64(10)
+
+	testCases := []struct {
+		name          string
+		targetID      string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockQuerier)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			targetID: post.ID.Hex(),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				arg := db.LikeParams{
+					UserID:   user.ID,
+					TargetID: post.ID,
+				}
+
+				querier.EXPECT().
+					DeleteLike(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(&mongo.DeleteResult{DeletedCount: 1}, nil)
+				querier.EXPECT().
+					GetLikeCount(gomock.Any(), gomock.Eq(post.ID)).
+					Times(1).
+					Return(nLikes, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchLikeResponse(t, recorder.Body, likeResponse{Liked: false, LikeCount: nLikes})
+			},
+		},
+		{
+			name:     "NotLiked",
+			targetID: post.ID.Hex(),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					DeleteLike(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&mongo.DeleteResult{DeletedCount: 0}, nil)
+				querier.EXPECT().
+					GetLikeCount(gomock.Any(), gomock.Eq(post.ID)).
+					Times(1).
+					Return(nLikes, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchLikeResponse(t, recorder.Body, likeResponse{Liked: false, LikeCount: nLikes})
+			},
+		},
+		{
+			name:     "InternalError",
+			targetID: post.ID.Hex(),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					DeleteLike(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, mongo.ErrClientDisconnected)
+				querier.EXPECT().
+					GetLikeCount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name:     "NoAuthorization",
+			targetID: post.ID.Hex(),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					DeleteLike(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "TargetIDLenIsNot24",
+			targetID: ":c",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					DeleteLike(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			queries := mockdb.NewMockQuerier(ctrl)
+			tc.buildStubs(queries)
+
+			// start test server and send request
+			server := newTestServer(t, queries, util.RandomPassword(32))
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/v1/likes/%s", tc.targetID)
+			request, err := http.NewRequest(http.MethodDelete, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
 func TestListLikesAPI(t *testing.T) {
 	offset, limit := 5, 10
 	post := randomPost(t, primitive.NewObjectID())
@@ -176,9 +577,13 @@ func TestListLikesAPI(t *testing.T) {
 		likes[i] = randomLike(t, primitive.NewObjectID(), post.ID)
 	}
 
+	comment := randomComment(t, primitive.NewObjectID(), post.ID)
+	viewer, _ := randomUser(t)
+
 	testCases := []struct {
 		name          string
 		query         map[string]any
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
 		buildStubs    func(store *mockdb.MockQuerier)
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
 	}{
@@ -196,6 +601,7 @@ func TestListLikesAPI(t *testing.T) {
 					Limit:    int64(limit),
 				}
 
+				expectVisiblePost(querier, post, primitive.NilObjectID)
 				querier.EXPECT().
 					ListLikes(gomock.Any(), gomock.Eq(arg)).
 					Times(1).
@@ -214,6 +620,7 @@ func TestListLikesAPI(t *testing.T) {
 				"limit":     limit,
 			},
 			buildStubs: func(querier *mockdb.MockQuerier) {
+				expectVisiblePost(querier, post, primitive.NilObjectID)
 				querier.EXPECT().
 					ListLikes(gomock.Any(), gomock.Any()).
 					Times(1).
@@ -223,6 +630,76 @@ func TestListLikesAPI(t *testing.T) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
 			},
 		},
+		{
+			name: "CommentOK",
+			query: map[string]any{
+				"target_id": comment.ID.Hex(),
+				"offset":    offset,
+				"limit":     limit,
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetPost(gomock.Any(), gomock.Eq("_id"), gomock.Eq(comment.ID)).
+					Times(1).
+					Return(db.Post{}, mongo.ErrNoDocuments)
+				querier.EXPECT().
+					GetComment(gomock.Any(), gomock.Eq(comment.ID)).
+					Times(1).
+					Return(comment, nil)
+				querier.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq("_id"), gomock.Eq(comment.UserID)).
+					Times(1).
+					Return(db.User{ID: comment.UserID}, nil)
+				expectVisiblePost(querier, post, primitive.NilObjectID)
+				querier.EXPECT().
+					ListLikes(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(likes, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchLikes(t, recorder.Body, likes)
+			},
+		},
+		{
+			name: "PrivateFollower",
+			query: map[string]any{
+				"target_id": post.ID.Hex(),
+				"offset":    offset,
+				"limit":     limit,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, viewer.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				arg := db.FollowParams{
+					FollowerID:  viewer.ID,
+					FollowingID: post.UserID,
+				}
+
+				querier.EXPECT().
+					GetPost(gomock.Any(), gomock.Eq("_id"), gomock.Eq(post.ID)).
+					Times(1).
+					Return(post, nil)
+				querier.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq("_id"), gomock.Eq(post.UserID)).
+					Times(1).
+					Return(db.User{ID: post.UserID, IsPrivate: true}, nil)
+				expectNotBlocked(querier, viewer.ID, post.UserID)
+				querier.EXPECT().
+					IsFollowing(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(true, nil)
+				querier.EXPECT().
+					ListLikes(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(likes, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchLikes(t, recorder.Body, likes)
+			},
+		},
 		{
 			name: "InvalidTargetID",
 			query: map[string]any{
@@ -286,6 +763,9 @@ func TestListLikesAPI(t *testing.T) {
 			q.Add("limit", fmt.Sprint(tc.query["limit"]))
 			request.URL.RawQuery = q.Encode()
 
+			if tc.setupAuth != nil {
+				tc.setupAuth(t, request, server.tokenMaker)
+			}
 			server.router.ServeHTTP(recorder, request)
 			tc.checkResponse(t, recorder)
 		})
@@ -309,8 +789,9 @@ func TestCountLikesAPI(t *testing.T) {
 			name:     "OK",
 			targetID: post.ID.Hex(),
 			buildStubs: func(querier *mockdb.MockQuerier) {
+				expectVisiblePost(querier, post, primitive.NilObjectID)
 				querier.EXPECT().
-					CountLikes(gomock.Any(), gomock.Eq(post.ID)).
+					GetLikeCount(gomock.Any(), gomock.Eq(post.ID)).
 					Times(1).
 					Return(int64(n), nil)
 			},
@@ -323,8 +804,9 @@ func TestCountLikesAPI(t *testing.T) {
 			name:     "InternalError",
 			targetID: post.ID.Hex(),
 			buildStubs: func(querier *mockdb.MockQuerier) {
+				expectVisiblePost(querier, post, primitive.NilObjectID)
 				querier.EXPECT().
-					CountLikes(gomock.Any(), gomock.Any()).
+					GetLikeCount(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(int64(0), mongo.ErrClientDisconnected)
 			},
@@ -332,12 +814,52 @@ func TestCountLikesAPI(t *testing.T) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
 			},
 		},
+		{
+			name:     "NotFound",
+			targetID: post.ID.Hex(),
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetPost(gomock.Any(), gomock.Eq("_id"), gomock.Eq(post.ID)).
+					Times(1).
+					Return(db.Post{}, mongo.ErrNoDocuments)
+				querier.EXPECT().
+					GetComment(gomock.Any(), gomock.Eq(post.ID)).
+					Times(1).
+					Return(db.Comment{}, mongo.ErrNoDocuments)
+				querier.EXPECT().
+					GetLikeCount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:     "PrivateAccount",
+			targetID: post.ID.Hex(),
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetPost(gomock.Any(), gomock.Eq("_id"), gomock.Eq(post.ID)).
+					Times(1).
+					Return(post, nil)
+				querier.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq("_id"), gomock.Eq(post.UserID)).
+					Times(1).
+					Return(db.User{ID: post.UserID, IsPrivate: true}, nil)
+				querier.EXPECT().
+					GetLikeCount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
 		{
 			name:     "InvalidTargetID",
 			targetID: "qwertyuiopasdfghjklñzxcv",
 			buildStubs: func(querier *mockdb.MockQuerier) {
 				querier.EXPECT().
-					CountLikes(gomock.Any(), gomock.Any()).
+					GetLikeCount(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
@@ -349,7 +871,7 @@ func TestCountLikesAPI(t *testing.T) {
 			targetID: "<3",
 			buildStubs: func(querier *mockdb.MockQuerier) {
 				querier.EXPECT().
-					CountLikes(gomock.Any(), gomock.Any()).
+					GetLikeCount(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
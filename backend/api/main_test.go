@@ -5,6 +5,7 @@ import (
 	"time"
 
 	db "github.com/DMV-Nicolas/robotgram/backend/db/mongo"
+	"github.com/DMV-Nicolas/robotgram/backend/realtime"
 	"github.com/DMV-Nicolas/robotgram/backend/util"
 	"github.com/stretchr/testify/require"
 )
@@ -14,9 +15,12 @@ func newTestServer(t *testing.T, queries db.Querier, tokenSymmetricKey string) *
 		TokenSymmetricKey:    tokenSymmetricKey,
 		AccessTokenDuration:  time.Minute,
 		RefreshTokenDuration: time.Minute * 2,
+		MediaLocalDir:        t.TempDir(),
+		MediaMaxSize:         1 << 20,
+		MediaBaseURL:         "http://localhost:5000",
 	}
 
-	server, err := NewServer(config, queries)
+	server, err := NewServer(config, queries, realtime.NewHub(10))
 	require.NoError(t, err)
 
 	return server
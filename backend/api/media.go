@@ -0,0 +1,258 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	db "github.com/DMV-Nicolas/robotgram/backend/db/mongo"
+	"github.com/DMV-Nicolas/robotgram/backend/imaging"
+	"github.com/DMV-Nicolas/robotgram/backend/storage"
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// mediaContentTypes contains the accepted content types of the uploaded files
+var mediaContentTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+}
+
+// mediaExtensions contains the extension of the files of every rendition content type
+var mediaExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+}
+
+var (
+	errMediaTooLarge   = errors.New("media file is too large")
+	errMediaType       = errors.New("media content type is not supported")
+	errMediaNotOwned   = errors.New("media doesn't belong to the authenticated user")
+	errMediaFileNeeded = errors.New("multipart field 'file' is required")
+)
+
+type uploadMediaResponse struct {
+	ID         primitive.ObjectID `json:"id"`
+	Width      int                `json:"width"`
+	Height     int                `json:"height"`
+	Blurhash   string             `json:"blurhash"`
+	Renditions []db.Rendition     `json:"renditions"`
+}
+
+func (server *Server) UploadMedia(c echo.Context) error {
+	payload, err := getAuthorizationPayload(c)
+	if err != nil {
+		return err
+	}
+
+	// leave some room for the rest of the multipart body
+	c.Request().Body = http.MaxBytesReader(c.Response(), c.Request().Body, server.config.MediaMaxSize+1<<20)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return echo.NewHTTPError(http.StatusRequestEntityTooLarge, errMediaTooLarge)
+		}
+		return echo.NewHTTPError(http.StatusBadRequest, errMediaFileNeeded)
+	}
+
+	if fileHeader.Size > server.config.MediaMaxSize {
+		return echo.NewHTTPError(http.StatusRequestEntityTooLarge, errMediaTooLarge)
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	// the content type is sniffed from the content, the one sent by the client can't be trusted
+	if !mediaContentTypes[http.DetectContentType(data)] {
+		return echo.NewHTTPError(http.StatusUnsupportedMediaType, errMediaType)
+	}
+
+	processed, err := imaging.Process(data, imaging.DefaultSpecs)
+	if err != nil {
+		switch err {
+		case imaging.ErrUnsupportedFormat:
+			return echo.NewHTTPError(http.StatusUnsupportedMediaType, err)
+		case imaging.ErrTooManyPixels:
+			return echo.NewHTTPError(http.StatusRequestEntityTooLarge, err)
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	id := primitive.NewObjectID()
+	renditions := make([]db.Rendition, 0, len(processed.Renditions))
+	for _, rendition := range processed.Renditions {
+		key := fmt.Sprintf("%s/%s/%s%s", payload.UserID.Hex(), id.Hex(), rendition.Name, mediaExtensions[rendition.ContentType])
+
+		err = server.storage.Put(context.TODO(), key, bytes.NewReader(rendition.Data), int64(len(rendition.Data)), rendition.ContentType)
+		if err != nil {
+			server.deleteRenditions(renditions)
+			return echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+
+		renditions = append(renditions, db.Rendition{
+			Name:        rendition.Name,
+			Key:         key,
+			URL:         fmt.Sprintf("%s/v1/media/%s/%s", server.config.MediaBaseURL, id.Hex(), rendition.Name),
+			ContentType: rendition.ContentType,
+			Width:       rendition.Width,
+			Height:      rendition.Height,
+			Size:        int64(len(rendition.Data)),
+		})
+	}
+
+	arg := db.CreateMediaParams{
+		ID:         id,
+		UserID:     payload.UserID,
+		Width:      processed.Width,
+		Height:     processed.Height,
+		Blurhash:   processed.Blurhash,
+		Renditions: renditions,
+	}
+
+	_, err = server.queries.CreateMedia(context.TODO(), arg)
+	if err != nil {
+		server.deleteRenditions(renditions)
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	res := uploadMediaResponse{
+		ID:         arg.ID,
+		Width:      arg.Width,
+		Height:     arg.Height,
+		Blurhash:   arg.Blurhash,
+		Renditions: arg.Renditions,
+	}
+
+	return c.JSON(http.StatusCreated, res)
+}
+
+// deleteRenditions removes the stored files of an upload that couldn't be completed
+func (server *Server) deleteRenditions(renditions []db.Rendition) {
+	for _, rendition := range renditions {
+		server.storage.Delete(context.TODO(), rendition.Key)
+	}
+}
+
+type getMediaRequest struct {
+	ID        string `param:"id" validate:"required,len=24"`
+	Rendition string `param:"rendition" validate:"required,alpha"`
+}
+
+func (server *Server) GetMedia(c echo.Context) error {
+	req := new(getMediaRequest)
+	if err := bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	id, err := primitive.ObjectIDFromHex(req.ID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	media, err := server.queries.GetMedia(context.TODO(), id)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return echo.NewHTTPError(http.StatusNotFound, err)
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	rendition, ok := findRendition(media, req.Rendition)
+	if !ok {
+		err = fmt.Errorf("media doesn't have a %s rendition", req.Rendition)
+		return echo.NewHTTPError(http.StatusNotFound, err)
+	}
+
+	file, err := server.storage.Get(context.TODO(), rendition.Key)
+	if err != nil {
+		if err == storage.ErrNotFound {
+			return echo.NewHTTPError(http.StatusNotFound, err)
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+	defer file.Close()
+
+	// the content of a media never changes
+	c.Response().Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+
+	return c.Stream(http.StatusOK, rendition.ContentType, file)
+}
+
+// ownedMedia loads the media with the given ids checking that all of them were uploaded by the user
+func (server *Server) ownedMedia(userID primitive.ObjectID, idsStr []string) ([]db.Media, error) {
+	media := make([]db.Media, len(idsStr))
+	for i, idStr := range idsStr {
+		id, err := primitive.ObjectIDFromHex(idStr)
+		if err != nil {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+
+		media[i], err = server.queries.GetMedia(context.TODO(), id)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				return nil, echo.NewHTTPError(http.StatusNotFound, err)
+			}
+			return nil, echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+
+		if media[i].UserID != userID {
+			return nil, echo.NewHTTPError(http.StatusUnauthorized, errMediaNotOwned)
+		}
+	}
+
+	return media, nil
+}
+
+// mediaImage converts the media into the image attached to a post
+func mediaImage(media db.Media) db.Image {
+	return db.Image{
+		MediaID:      media.ID,
+		URL:          renditionURL(media, "feed"),
+		ThumbnailURL: renditionURL(media, "thumbnail"),
+		OriginalURL:  renditionURL(media, "original"),
+		Width:        media.Width,
+		Height:       media.Height,
+		Blurhash:     media.Blurhash,
+	}
+}
+
+func mediaImages(media []db.Media) []db.Image {
+	images := make([]db.Image, len(media))
+	for i := range media {
+		images[i] = mediaImage(media[i])
+	}
+	return images
+}
+
+func findRendition(media db.Media, name string) (db.Rendition, bool) {
+	for _, rendition := range media.Renditions {
+		if rendition.Name == name {
+			return rendition, true
+		}
+	}
+	return db.Rendition{}, false
+}
+
+// renditionURL returns the URL of the rendition falling back to the first one of the media
+func renditionURL(media db.Media, name string) string {
+	rendition, ok := findRendition(media, name)
+	if !ok && len(media.Renditions) > 0 {
+		rendition = media.Renditions[0]
+	}
+	return rendition.URL
+}
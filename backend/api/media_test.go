@@ -0,0 +1,390 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/DMV-Nicolas/robotgram/backend/db/mock"
+	db "github.com/DMV-Nicolas/robotgram/backend/db/mongo"
+	"github.com/DMV-Nicolas/robotgram/backend/storage"
+	"github.com/DMV-Nicolas/robotgram/backend/token"
+	"github.com/DMV-Nicolas/robotgram/backend/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestUploadMediaAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	jpegFile := randomJPEG(t, 600, 400)
+
+	// keys of the stored renditions
+	var storedKeys []string
+
+	testCases := []struct {
+		name          string
+		content       []byte
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(querier *mockdb.MockQuerier)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder, backend storage.Backend)
+	}{
+		{
+			name:    "OK",
+			content: jpegFile,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					CreateMedia(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateMediaParams) (*mongo.InsertOneResult, error) {
+						require.Equal(t, user.ID, arg.UserID)
+						require.Equal(t, 600, arg.Width)
+						require.Equal(t, 400, arg.Height)
+						require.NotEmpty(t, arg.Blurhash)
+
+						sizes := map[string][2]int{
+							"thumbnail": {320, 213},
+							"feed":      {600, 400},
+							"original":  {600, 400},
+						}
+
+						require.Len(t, arg.Renditions, len(sizes))
+						for _, rendition := range arg.Renditions {
+							require.Equal(t, sizes[rendition.Name], [2]int{rendition.Width, rendition.Height})
+							require.Equal(t, "image/jpeg", rendition.ContentType)
+							require.Equal(t, fmt.Sprintf("%s/%s/%s.jpg", user.ID.Hex(), arg.ID.Hex(), rendition.Name), rendition.Key)
+							require.Equal(t, fmt.Sprintf("http://localhost:5000/v1/media/%s/%s", arg.ID.Hex(), rendition.Name), rendition.URL)
+						}
+
+						storedKeys = storedKeys[:0]
+						for _, rendition := range arg.Renditions {
+							storedKeys = append(storedKeys, rendition.Key)
+						}
+
+						return &mongo.InsertOneResult{InsertedID: arg.ID}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, backend storage.Backend) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+				requireBodyMatchUploadMedia(t, recorder.Body, 600, 400)
+
+				// the renditions are stored re-encoded
+				for _, key := range storedKeys {
+					file, err := backend.Get(context.TODO(), key)
+					require.NoError(t, err)
+
+					content, err := io.ReadAll(file)
+					require.NoError(t, err)
+					require.NoError(t, file.Close())
+
+					_, err = jpeg.Decode(bytes.NewReader(content))
+					require.NoError(t, err)
+				}
+			},
+		},
+		{
+			name:    "InternalError",
+			content: jpegFile,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					CreateMedia(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateMediaParams) (*mongo.InsertOneResult, error) {
+						storedKeys = storedKeys[:0]
+						for _, rendition := range arg.Renditions {
+							storedKeys = append(storedKeys, rendition.Key)
+						}
+						return nil, mongo.ErrClientDisconnected
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, backend storage.Backend) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+
+				// the stored files are removed when the media can't be saved
+				require.NotEmpty(t, storedKeys)
+				for _, key := range storedKeys {
+					_, err := backend.Get(context.TODO(), key)
+					require.ErrorIs(t, err, storage.ErrNotFound)
+				}
+			},
+		},
+		{
+			name:    "UnsupportedContentType",
+			content: []byte("just some text pretending to be an image"),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().CreateMedia(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, backend storage.Backend) {
+				require.Equal(t, http.StatusUnsupportedMediaType, recorder.Code)
+			},
+		},
+		{
+			name:    "TooLarge",
+			content: append(jpegFile, make([]byte, 1<<20)...),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().CreateMedia(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, backend storage.Backend) {
+				require.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code)
+			},
+		},
+		{
+			name:    "NoFile",
+			content: nil,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().CreateMedia(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, backend storage.Backend) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:    "NoAuthorization",
+			content: jpegFile,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().CreateMedia(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, backend storage.Backend) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			queries := mockdb.NewMockQuerier(ctrl)
+			tc.buildStubs(queries)
+
+			body := new(bytes.Buffer)
+			writer := multipart.NewWriter(body)
+			if tc.content != nil {
+				part, err := writer.CreateFormFile("file", "image.jpg")
+				require.NoError(t, err)
+				_, err = part.Write(tc.content)
+				require.NoError(t, err)
+			}
+			require.NoError(t, writer.Close())
+
+			server := newTestServer(t, queries, util.RandomPassword(32))
+			recorder := httptest.NewRecorder()
+
+			url := "/v1/media"
+			request, err := http.NewRequest(http.MethodPost, url, body)
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", writer.FormDataContentType())
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder, server.storage)
+		})
+	}
+}
+
+func TestGetMediaAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	media := randomMedia(t, user.ID)
+	pngFile := randomPNG(t)
+
+	testCases := []struct {
+		name          string
+		id            string
+		rendition     string
+		storeFile     bool
+		buildStubs    func(querier *mockdb.MockQuerier)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "OK",
+			id:        media.ID.Hex(),
+			rendition: "feed",
+			storeFile: true,
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetMedia(gomock.Any(), gomock.Eq(media.ID)).
+					Times(1).
+					Return(media, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "image/png", recorder.Header().Get("Content-Type"))
+				require.Equal(t, pngFile, recorder.Body.Bytes())
+			},
+		},
+		{
+			name:      "RenditionNotFound",
+			id:        media.ID.Hex(),
+			rendition: "huge",
+			storeFile: true,
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetMedia(gomock.Any(), gomock.Eq(media.ID)).
+					Times(1).
+					Return(media, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:      "MediaNotFound",
+			id:        media.ID.Hex(),
+			rendition: "feed",
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetMedia(gomock.Any(), gomock.Eq(media.ID)).
+					Times(1).
+					Return(db.Media{}, mongo.ErrNoDocuments)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:      "FileNotFound",
+			id:        media.ID.Hex(),
+			rendition: "feed",
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetMedia(gomock.Any(), gomock.Eq(media.ID)).
+					Times(1).
+					Return(media, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:      "InternalError",
+			id:        media.ID.Hex(),
+			rendition: "feed",
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetMedia(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Media{}, mongo.ErrClientDisconnected)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidID",
+			id:        "qwertyuiopasdfghjklñzxcv",
+			rendition: "feed",
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().GetMedia(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			queries := mockdb.NewMockQuerier(ctrl)
+			tc.buildStubs(queries)
+
+			server := newTestServer(t, queries, util.RandomPassword(32))
+			if tc.storeFile {
+				rendition := media.Renditions[0]
+				err := server.storage.Put(context.TODO(), rendition.Key, bytes.NewReader(pngFile), int64(len(pngFile)), rendition.ContentType)
+				require.NoError(t, err)
+			}
+
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/v1/media/%s/%s", tc.id, tc.rendition)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func randomMedia(t *testing.T, userID primitive.ObjectID) db.Media {
+	id := util.RandomID()
+	media := db.Media{
+		ID:        id,
+		UserID:    userID,
+		Width:     1080,
+		Height:    720,
+		Blurhash:  util.RandomString(28),
+		CreatedAt: time.Now(),
+	}
+
+	for _, name := range []string{"feed", "thumbnail", "original"} {
+		media.Renditions = append(media.Renditions, db.Rendition{
+			Name:        name,
+			Key:         fmt.Sprintf("%s/%s/%s.png", userID.Hex(), id.Hex(), name),
+			URL:         fmt.Sprintf("http://localhost:5000/v1/media/%s/%s", id.Hex(), name),
+			ContentType: "image/png",
+			Width:       1080,
+			Height:      720,
+			Size:        int64(len(util.RandomString(64))),
+		})
+	}
+
+	return media
+}
+
+func randomJPEG(t *testing.T, width, height int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for i := range img.Pix {
+		img.Pix[i] = byte(util.RandomString(1)[0])
+	}
+
+	buf := new(bytes.Buffer)
+	err := jpeg.Encode(buf, img, nil)
+	require.NoError(t, err)
+
+	return buf.Bytes()
+}
+
+func randomPNG(t *testing.T) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for i := range img.Pix {
+		img.Pix[i] = byte(util.RandomString(1)[0])
+	}
+
+	buf := new(bytes.Buffer)
+	err := png.Encode(buf, img)
+	require.NoError(t, err)
+
+	return buf.Bytes()
+}
@@ -0,0 +1,40 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	db "github.com/DMV-Nicolas/robotgram/backend/db/mongo"
+	"github.com/labstack/echo/v4"
+)
+
+type listMentionsRequest struct {
+	Offset int64 `query:"offset" validate:"min=0"`
+	Limit  int64 `query:"limit" validate:"min=1,max=50"`
+}
+
+// ListMentions lists the posts and the comments that mention the authenticated user, the newest first
+func (server *Server) ListMentions(c echo.Context) error {
+	req := new(listMentionsRequest)
+	if err := bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	payload, err := getAuthorizationPayload(c)
+	if err != nil {
+		return err
+	}
+
+	arg := db.ListMentionsParams{
+		UserID: payload.UserID,
+		Offset: req.Offset,
+		Limit:  req.Limit,
+	}
+
+	mentionings, err := server.queries.ListMentions(context.TODO(), arg)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, mentionings)
+}
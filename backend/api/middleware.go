@@ -1,13 +1,18 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"strings"
 
 	"github.com/DMV-Nicolas/robotgram/backend/token"
+	"github.com/DMV-Nicolas/robotgram/backend/util"
 	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 const (
@@ -42,6 +47,12 @@ func authMiddleware(next echo.HandlerFunc, tokenMaker token.Maker) echo.HandlerF
 			return echo.NewHTTPError(http.StatusUnauthorized, err)
 		}
 
+		// a refresh token lives much longer and is only checked against its session when refreshing
+		if payload.Kind != token.KindAccess {
+			err := errors.New("the token is not an access token")
+			return echo.NewHTTPError(http.StatusUnauthorized, err)
+		}
+
 		payloadJSON, err := json.Marshal(payload)
 		if err != nil {
 			return echo.NewHTTPError(http.StatusInternalServerError, err)
@@ -53,6 +64,34 @@ func authMiddleware(next echo.HandlerFunc, tokenMaker token.Maker) echo.HandlerF
 	}
 }
 
+// optionalAuthMiddleware lets the anonymous requests through and authenticates the rest like authMiddleware,
+// so the public routes can know who is viewing them
+func optionalAuthMiddleware(next echo.HandlerFunc, tokenMaker token.Maker) echo.HandlerFunc {
+	withAuth := authMiddleware(next, tokenMaker)
+	return func(c echo.Context) error {
+		if c.Request().Header.Get(authorizationHeaderKey) == "" {
+			return next(c)
+		}
+
+		return withAuth(c)
+	}
+}
+
+// getViewerID returns the authenticated user of a route behind optionalAuthMiddleware,
+// or a zero ID for the anonymous requests
+func getViewerID(c echo.Context) (primitive.ObjectID, error) {
+	if c.Response().Header().Get(authorizationPayloadKey) == "" {
+		return primitive.NilObjectID, nil
+	}
+
+	payload, err := getAuthorizationPayload(c)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+
+	return payload.UserID, nil
+}
+
 func getAuthorizationPayload(c echo.Context) (*token.Payload, error) {
 	payloadJSON := c.Response().Header().Get(authorizationPayloadKey)
 	payload := new(token.Payload)
@@ -62,3 +101,44 @@ func getAuthorizationPayload(c echo.Context) (*token.Payload, error) {
 	}
 	return payload, nil
 }
+
+// permissionMiddleware only lets through the users whose role grants the permission. The role is read from
+// the stored user, not from the token, so a promotion, a demotion or a suspension applies at once.
+// It must be chained after authMiddleware
+func (server *Server) permissionMiddleware(next echo.HandlerFunc, permission string) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		payload, err := getAuthorizationPayload(c)
+		if err != nil {
+			return err
+		}
+
+		if err = server.checkPermission(payload, permission); err != nil {
+			return err
+		}
+
+		return next(c)
+	}
+}
+
+// checkPermission returns a forbidden error unless the stored user isn't suspended and its role grants the permission
+func (server *Server) checkPermission(payload *token.Payload, permission string) error {
+	user, err := server.queries.GetUser(context.TODO(), "_id", payload.UserID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return echo.NewHTTPError(http.StatusUnauthorized, err)
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	if !user.SuspendedAt.IsZero() {
+		err := errors.New("the account is suspended")
+		return echo.NewHTTPError(http.StatusForbidden, err)
+	}
+
+	if !util.HasPermission(user.Role, permission) {
+		err := fmt.Errorf("the role of the authenticated user lacks the %s permission", permission)
+		return echo.NewHTTPError(http.StatusForbidden, err)
+	}
+
+	return nil
+}
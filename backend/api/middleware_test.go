@@ -7,8 +7,11 @@ import (
 	"testing"
 	"time"
 
+	mockdb "github.com/DMV-Nicolas/robotgram/backend/db/mock"
+	db "github.com/DMV-Nicolas/robotgram/backend/db/mongo"
 	"github.com/DMV-Nicolas/robotgram/backend/token"
 	"github.com/DMV-Nicolas/robotgram/backend/util"
+	"github.com/golang/mock/gomock"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -22,7 +25,19 @@ func addAuthorization(
 	userID primitive.ObjectID,
 	duration time.Duration,
 ) {
-	token, payload, err := tokenMaker.CreateToken(userID, duration)
+	addRoleAuthorization(t, request, tokenMaker, authorizationType, userID, util.RoleUser, duration)
+}
+
+func addRoleAuthorization(
+	t *testing.T,
+	request *http.Request,
+	tokenMaker token.Maker,
+	authorizationType string,
+	userID primitive.ObjectID,
+	role string,
+	duration time.Duration,
+) {
+	token, payload, err := tokenMaker.CreateToken(token.KindAccess, userID, primitive.NewObjectID(), role, duration)
 	require.NoError(t, err)
 	require.NotEmpty(t, token)
 	require.NotEmpty(t, payload)
@@ -31,6 +46,14 @@ func addAuthorization(
 	request.Header.Set(authorizationHeaderKey, authorizationHeader)
 }
 
+// expectStoredRole stubs the lookup of the user that checkPermission makes on the privileged routes
+func expectStoredRole(querier *mockdb.MockQuerier, userID primitive.ObjectID, role string) {
+	querier.EXPECT().
+		GetUser(gomock.Any(), gomock.Eq("_id"), gomock.Eq(userID)).
+		Times(1).
+		Return(db.User{ID: userID, Role: role}, nil)
+}
+
 func TestAuthMiddleware(t *testing.T) {
 	user, _ := randomUser(t)
 	tests := []struct {
@@ -82,6 +105,18 @@ func TestAuthMiddleware(t *testing.T) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			},
 		},
+		{
+			name: "RefreshToken",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				refreshToken, _, err := tokenMaker.CreateToken(token.KindRefresh, user.ID, primitive.NewObjectID(), util.RoleUser, time.Minute)
+				require.NoError(t, err)
+
+				request.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeBearer, refreshToken))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
 	}
 
 	for _, tc := range tests {
@@ -108,3 +143,68 @@ func TestAuthMiddleware(t *testing.T) {
 		})
 	}
 }
+
+func TestOptionalAuthMiddleware(t *testing.T) {
+	user, _ := randomUser(t)
+	tests := []struct {
+		name          string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Contains(t, recorder.Body.String(), user.ID.Hex())
+			},
+		},
+		{
+			name: "Anonymous",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Contains(t, recorder.Body.String(), primitive.NilObjectID.Hex())
+			},
+		},
+		{
+			name: "ExpiredToken",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, -time.Minute)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// start test server and send request
+			server := newTestServer(t, nil, util.RandomPassword(32))
+			recorder := httptest.NewRecorder()
+
+			url := "/optional-auth"
+			server.router.GET(
+				url,
+				optionalAuthMiddleware(func(c echo.Context) error {
+					viewerID, err := getViewerID(c)
+					if err != nil {
+						return err
+					}
+					return c.JSON(http.StatusOK, viewerID)
+				}, server.tokenMaker),
+			)
+
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
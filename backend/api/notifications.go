@@ -0,0 +1,154 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	db "github.com/DMV-Nicolas/robotgram/backend/db/mongo"
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type listNotificationsRequest struct {
+	Offset int64 `query:"offset" validate:"min=0"`
+	Limit  int64 `query:"limit" validate:"min=1,max=50"`
+}
+
+// ListNotifications lists the notifications of the authenticated user, the most recently updated first
+func (server *Server) ListNotifications(c echo.Context) error {
+	req := new(listNotificationsRequest)
+	if err := bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	payload, err := getAuthorizationPayload(c)
+	if err != nil {
+		return err
+	}
+
+	arg := db.ListNotificationsParams{
+		UserID: payload.UserID,
+		Offset: req.Offset,
+		Limit:  req.Limit,
+	}
+
+	notifications, err := server.queries.ListNotifications(context.TODO(), arg)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, notifications)
+}
+
+func (server *Server) CountUnreadNotifications(c echo.Context) error {
+	payload, err := getAuthorizationPayload(c)
+	if err != nil {
+		return err
+	}
+
+	nUnread, err := server.queries.CountUnreadNotifications(context.TODO(), payload.UserID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, nUnread)
+}
+
+type markNotificationsReadRequest struct {
+	IDs []string `json:"ids" validate:"max=100,dive,len=24"`
+}
+
+// MarkNotificationsRead marks the given notifications of the authenticated user as read, or all of them
+// when no ids are sent
+func (server *Server) MarkNotificationsRead(c echo.Context) error {
+	req := new(markNotificationsReadRequest)
+	if err := bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	payload, err := getAuthorizationPayload(c)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]primitive.ObjectID, len(req.IDs))
+	for i, hex := range req.IDs {
+		ids[i], err = primitive.ObjectIDFromHex(hex)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+	}
+
+	arg := db.MarkNotificationsReadParams{
+		UserID: payload.UserID,
+		IDs:    ids,
+	}
+
+	result, err := server.queries.MarkNotificationsRead(context.TODO(), arg)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// GetNotificationPreferences returns whether each notification type is enabled for the authenticated user
+func (server *Server) GetNotificationPreferences(c echo.Context) error {
+	payload, err := getAuthorizationPayload(c)
+	if err != nil {
+		return err
+	}
+
+	user, err := server.queries.GetUser(context.TODO(), "_id", payload.UserID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, notificationPreferences(user))
+}
+
+type updateNotificationPreferencesRequest struct {
+	Preferences map[string]bool `json:"preferences" validate:"required,min=1,dive,keys,oneof=like comment reply follow mention follow_request,endkeys"`
+}
+
+// UpdateNotificationPreferences enables or disables the given notification types for the authenticated user
+// and returns all of them
+func (server *Server) UpdateNotificationPreferences(c echo.Context) error {
+	req := new(updateNotificationPreferencesRequest)
+	if err := bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	payload, err := getAuthorizationPayload(c)
+	if err != nil {
+		return err
+	}
+
+	arg := db.UpdateNotificationPreferencesParams{
+		UserID:      payload.UserID,
+		Preferences: req.Preferences,
+	}
+
+	_, err = server.queries.UpdateNotificationPreferences(context.TODO(), arg)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	user, err := server.queries.GetUser(context.TODO(), "_id", payload.UserID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, notificationPreferences(user))
+}
+
+// notificationPreferences fills the notification types missing in the preferences of the user, which are enabled
+func notificationPreferences(user db.User) map[string]bool {
+	preferences := make(map[string]bool, len(db.NotificationTypes))
+	for _, kind := range db.NotificationTypes {
+		enabled, ok := user.NotificationPreferences[kind]
+		preferences[kind] = !ok || enabled
+	}
+
+	return preferences
+}
@@ -2,8 +2,13 @@ package api
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	db "github.com/DMV-Nicolas/robotgram/backend/db/mongo"
 	"github.com/labstack/echo/v4"
@@ -11,8 +16,10 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
+var errInvalidCursor = errors.New("invalid cursor")
+
 type createPostRequest struct {
-	Images      []string `json:"images"`
+	Media       []string `json:"media" validate:"max=10,dive,len=24"`
 	Description string   `json:"description"`
 }
 
@@ -27,9 +34,14 @@ func (server *Server) CreatePost(c echo.Context) error {
 		return err
 	}
 
+	media, err := server.ownedMedia(payload.UserID, req.Media)
+	if err != nil {
+		return err
+	}
+
 	arg := db.CreatePostParams{
 		UserID:      payload.UserID,
-		Images:      req.Images,
+		Images:      mediaImages(media),
 		Description: req.Description,
 	}
 
@@ -64,6 +76,20 @@ func (server *Server) GetPost(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, err)
 	}
 
+	viewerID, err := getViewerID(c)
+	if err != nil {
+		return err
+	}
+
+	// a removed post is kept for the appeal of its author
+	if post.Removed && post.UserID != viewerID {
+		return echo.NewHTTPError(http.StatusNotFound, errRemoved)
+	}
+
+	if err = server.checkCanView(viewerID, post.UserID); err != nil {
+		return err
+	}
+
 	return c.JSON(http.StatusOK, post)
 }
 
@@ -88,10 +114,23 @@ func (server *Server) ListPosts(c echo.Context) error {
 		}
 	}
 
+	viewerID, err := getViewerID(c)
+	if err != nil {
+		return err
+	}
+
+	// the posts of everyone are filtered one by one, the ones of a private user are hidden all at once
+	if !userID.IsZero() {
+		if err = server.checkCanView(viewerID, userID); err != nil {
+			return err
+		}
+	}
+
 	arg := db.ListPostsParams{
-		Offset: req.Offset,
-		Limit:  req.Limit,
-		UserID: userID,
+		Offset:   req.Offset,
+		Limit:    req.Limit,
+		UserID:   userID,
+		ViewerID: viewerID,
 	}
 
 	posts, err := server.queries.ListPosts(context.TODO(), arg)
@@ -102,9 +141,91 @@ func (server *Server) ListPosts(c echo.Context) error {
 	return c.JSON(http.StatusOK, posts)
 }
 
+type listFeedRequest struct {
+	Cursor string `query:"cursor"`
+	Limit  int64  `query:"limit" validate:"min=1,max=50"`
+}
+
+type listFeedResponse struct {
+	Posts      []db.Post `json:"posts"`
+	NextCursor string    `json:"next_cursor"`
+}
+
+func (server *Server) ListFeed(c echo.Context) error {
+	req := new(listFeedRequest)
+	if err := bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	payload, err := getAuthorizationPayload(c)
+	if err != nil {
+		return err
+	}
+
+	arg := db.ListFeedParams{
+		UserID:      payload.UserID,
+		Limit:       req.Limit,
+		FanoutLimit: server.config.TimelineFanoutLimit,
+	}
+
+	if req.Cursor != "" {
+		arg.BeforeCreatedAt, arg.BeforeID, err = decodeFeedCursor(req.Cursor)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+	}
+
+	posts, err := server.queries.ListFeed(context.TODO(), arg)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	res := listFeedResponse{
+		Posts: posts,
+	}
+
+	if int64(len(posts)) == req.Limit {
+		last := posts[len(posts)-1]
+		res.NextCursor = encodeFeedCursor(last.CreatedAt, last.ID)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// encodeFeedCursor encodes the position of a post in the feed as an opaque string
+func encodeFeedCursor(createdAt time.Time, id primitive.ObjectID) string {
+	raw := fmt.Sprintf("%d_%s", createdAt.UnixMilli(), id.Hex())
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeFeedCursor decodes a cursor created by encodeFeedCursor
+func decodeFeedCursor(cursor string) (time.Time, primitive.ObjectID, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, primitive.NilObjectID, errInvalidCursor
+	}
+
+	millis, hexID, found := strings.Cut(string(raw), "_")
+	if !found {
+		return time.Time{}, primitive.NilObjectID, errInvalidCursor
+	}
+
+	unixMilli, err := strconv.ParseInt(millis, 10, 64)
+	if err != nil {
+		return time.Time{}, primitive.NilObjectID, errInvalidCursor
+	}
+
+	id, err := primitive.ObjectIDFromHex(hexID)
+	if err != nil {
+		return time.Time{}, primitive.NilObjectID, errInvalidCursor
+	}
+
+	return time.UnixMilli(unixMilli), id, nil
+}
+
 type updatePostRequest struct {
 	ID          string   `param:"id" validate:"required,len=24"`
-	Images      []string `json:"images"`
+	Media       []string `json:"media" validate:"max=10,dive,len=24"`
 	Description string   `json:"description"`
 }
 
@@ -124,14 +245,20 @@ func (server *Server) UpdatePost(c echo.Context) error {
 		return err
 	}
 
-	if gotPost.UserID != payload.UserID {
-		err = errors.New("account doesn't belong to the authenticated user")
-		return echo.NewHTTPError(http.StatusUnauthorized, err)
+	moderated, err := server.authorizeContent(payload, gotPost.UserID)
+	if err != nil {
+		return err
+	}
+
+	// a moderator can only keep or drop the media of the author
+	media, err := server.ownedMedia(gotPost.UserID, req.Media)
+	if err != nil {
+		return err
 	}
 
 	arg := db.UpdatePostParams{
 		ID:          gotPost.ID,
-		Images:      req.Images,
+		Images:      mediaImages(media),
 		Description: req.Description,
 	}
 
@@ -143,6 +270,13 @@ func (server *Server) UpdatePost(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, err)
 	}
 
+	if moderated {
+		err = server.attributeModeration(payload, db.ReportTargetPost, gotPost.ID, gotPost.UserID, db.ModerationEditContent)
+		if err != nil {
+			return err
+		}
+	}
+
 	return c.JSON(http.StatusOK, result)
 }
 
@@ -166,9 +300,9 @@ func (server *Server) DeletePost(c echo.Context) error {
 		return err
 	}
 
-	if gotPost.UserID != payload.UserID {
-		err = errors.New("account doesn't belong to the authenticated user")
-		return echo.NewHTTPError(http.StatusUnauthorized, err)
+	moderated, err := server.authorizeContent(payload, gotPost.UserID)
+	if err != nil {
+		return err
 	}
 
 	result, err := server.queries.DeletePost(context.TODO(), gotPost.ID)
@@ -179,6 +313,13 @@ func (server *Server) DeletePost(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, err)
 	}
 
+	if moderated {
+		err = server.attributeModeration(payload, db.ReportTargetPost, gotPost.ID, gotPost.UserID, db.ModerationDeleteContent)
+		if err != nil {
+			return err
+		}
+	}
+
 	return c.JSON(http.StatusOK, result)
 }
 
@@ -21,7 +21,9 @@ import (
 
 func TestCreatePostAPI(t *testing.T) {
 	user, _ := randomUser(t)
+	media := randomMedia(t, user.ID)
 	post := randomPost(t, user.ID)
+	post.Images = []db.Image{mediaImage(media)}
 	result := &mongo.InsertOneResult{InsertedID: post.ID}
 
 	testCases := []struct {
@@ -34,12 +36,17 @@ func TestCreatePostAPI(t *testing.T) {
 		{
 			name: "OK",
 			body: map[string]any{
-				"images":      post.Images,
+				"media":       []string{media.ID.Hex()},
 				"description": post.Description,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
 			}, buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetMedia(gomock.Any(), gomock.Eq(media.ID)).
+					Times(1).
+					Return(media, nil)
+
 				arg := db.CreatePostParams{
 					UserID:      user.ID,
 					Images:      post.Images,
@@ -59,12 +66,16 @@ func TestCreatePostAPI(t *testing.T) {
 		{
 			name: "InternalError",
 			body: map[string]any{
-				"images":      post.Images,
+				"media":       []string{media.ID.Hex()},
 				"description": post.Description,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
 			}, buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetMedia(gomock.Any(), gomock.Eq(media.ID)).
+					Times(1).
+					Return(media, nil)
 				querier.EXPECT().
 					CreatePost(gomock.Any(), gomock.Any()).
 					Times(1).
@@ -74,10 +85,64 @@ func TestCreatePostAPI(t *testing.T) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
 			},
 		},
+		{
+			name: "MediaNotFound",
+			body: map[string]any{
+				"media":       []string{media.ID.Hex()},
+				"description": post.Description,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			}, buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetMedia(gomock.Any(), gomock.Eq(media.ID)).
+					Times(1).
+					Return(db.Media{}, mongo.ErrNoDocuments)
+				querier.EXPECT().CreatePost(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "NonMediaOwner",
+			body: map[string]any{
+				"media":       []string{media.ID.Hex()},
+				"description": post.Description,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, primitive.NewObjectID(), time.Minute)
+			}, buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetMedia(gomock.Any(), gomock.Eq(media.ID)).
+					Times(1).
+					Return(media, nil)
+				querier.EXPECT().CreatePost(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "TooManyMedia",
+			body: map[string]any{
+				"media":       make([]string, 11),
+				"description": post.Description,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			}, buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().GetMedia(gomock.Any(), gomock.Any()).Times(0)
+				querier.EXPECT().CreatePost(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
 		{
 			name: "IncorrectBodyTypes",
 			body: map[string]any{
-				"images":      "incorrect",
+				"media":       "incorrect",
 				"description": 100,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
@@ -122,10 +187,15 @@ func TestCreatePostAPI(t *testing.T) {
 func TestGetPostAPI(t *testing.T) {
 	user, _ := randomUser(t)
 	post := randomPost(t, user.ID)
+	privateUser, _ := randomUser(t)
+	privateUser.IsPrivate = true
+	privatePost := randomPost(t, privateUser.ID)
+	viewer, _ := randomUser(t)
 
 	testCases := []struct {
 		name          string
 		id            any
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
 		buildStubs    func(store *mockdb.MockQuerier)
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
 	}{
@@ -137,12 +207,194 @@ func TestGetPostAPI(t *testing.T) {
 					GetPost(gomock.Any(), gomock.Eq("_id"), gomock.Eq(post.ID)).
 					Times(1).
 					Return(post, nil)
+				querier.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq("_id"), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
 				requireBodyMatchPost(t, recorder.Body, post)
 			},
 		},
+		{
+			name: "PrivateOwner",
+			id:   privatePost.ID.Hex(),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, privateUser.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetPost(gomock.Any(), gomock.Eq("_id"), gomock.Eq(privatePost.ID)).
+					Times(1).
+					Return(privatePost, nil)
+				querier.EXPECT().
+					GetUser(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchPost(t, recorder.Body, privatePost)
+			},
+		},
+		{
+			name: "PendingPurge",
+			id:   post.ID.Hex(),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, viewer.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				purging := user
+				purging.PurgeAt = time.Now().Add(time.Hour)
+
+				querier.EXPECT().
+					GetPost(gomock.Any(), gomock.Eq("_id"), gomock.Eq(post.ID)).
+					Times(1).
+					Return(post, nil)
+				querier.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq("_id"), gomock.Eq(user.ID)).
+					Times(1).
+					Return(purging, nil)
+				querier.EXPECT().
+					IsBlockedBetween(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "Removed",
+			id:   post.ID.Hex(),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, viewer.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				removedPost := post
+				removedPost.Removed = true
+
+				querier.EXPECT().
+					GetPost(gomock.Any(), gomock.Eq("_id"), gomock.Eq(post.ID)).
+					Times(1).
+					Return(removedPost, nil)
+				querier.EXPECT().
+					GetUser(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "RemovedOwner",
+			id:   post.ID.Hex(),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				removedPost := post
+				removedPost.Removed = true
+
+				querier.EXPECT().
+					GetPost(gomock.Any(), gomock.Eq("_id"), gomock.Eq(post.ID)).
+					Times(1).
+					Return(removedPost, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "PrivateFollower",
+			id:   privatePost.ID.Hex(),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, viewer.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				arg := db.FollowParams{
+					FollowerID:  viewer.ID,
+					FollowingID: privateUser.ID,
+				}
+
+				querier.EXPECT().
+					GetPost(gomock.Any(), gomock.Eq("_id"), gomock.Eq(privatePost.ID)).
+					Times(1).
+					Return(privatePost, nil)
+				querier.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq("_id"), gomock.Eq(privateUser.ID)).
+					Times(1).
+					Return(privateUser, nil)
+				expectNotBlocked(querier, viewer.ID, privateUser.ID)
+				querier.EXPECT().
+					IsFollowing(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(true, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchPost(t, recorder.Body, privatePost)
+			},
+		},
+		{
+			name: "PrivateNotFollower",
+			id:   privatePost.ID.Hex(),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, viewer.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetPost(gomock.Any(), gomock.Eq("_id"), gomock.Eq(privatePost.ID)).
+					Times(1).
+					Return(privatePost, nil)
+				querier.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq("_id"), gomock.Eq(privateUser.ID)).
+					Times(1).
+					Return(privateUser, nil)
+				expectNotBlocked(querier, viewer.ID, privateUser.ID)
+				querier.EXPECT().
+					IsFollowing(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(false, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "PrivateAnonymous",
+			id:   privatePost.ID.Hex(),
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetPost(gomock.Any(), gomock.Eq("_id"), gomock.Eq(privatePost.ID)).
+					Times(1).
+					Return(privatePost, nil)
+				querier.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq("_id"), gomock.Eq(privateUser.ID)).
+					Times(1).
+					Return(privateUser, nil)
+				querier.EXPECT().
+					IsFollowing(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidToken",
+			id:   post.ID.Hex(),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, viewer.ID, -time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetPost(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
 		{
 			name: "NotFound",
 			id:   post.ID.Hex(),
@@ -212,6 +464,9 @@ func TestGetPostAPI(t *testing.T) {
 			request.Header.Add("Content-Type", "application/json")
 			require.NoError(t, err)
 
+			if tc.setupAuth != nil {
+				tc.setupAuth(t, request, server.tokenMaker)
+			}
 			server.router.ServeHTTP(recorder, request)
 			tc.checkResponse(t, recorder)
 		})
@@ -225,10 +480,14 @@ func TestListPostsAPI(t *testing.T) {
 	for i := 0; i < limit-offset; i++ {
 		posts[i] = randomPost(t, user.ID)
 	}
+	privateUser, _ := randomUser(t)
+	privateUser.IsPrivate = true
+	viewer, _ := randomUser(t)
 
 	testCases := []struct {
 		name          string
 		query         map[string]any
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
 		buildStubs    func(store *mockdb.MockQuerier)
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
 	}{
@@ -285,6 +544,32 @@ func TestListPostsAPI(t *testing.T) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
 		},
+		{
+			name: "ViewerOK",
+			query: map[string]any{
+				"offset": offset,
+				"limit":  limit,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, viewer.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				arg := db.ListPostsParams{
+					Offset:   int64(offset),
+					Limit:    int64(limit),
+					ViewerID: viewer.ID,
+				}
+
+				querier.EXPECT().
+					ListPosts(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(posts, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchPosts(t, recorder.Body, posts)
+			},
+		},
 		{
 			name: "ByUserIDOK",
 			query: map[string]any{
@@ -299,6 +584,10 @@ func TestListPostsAPI(t *testing.T) {
 					UserID: user.ID,
 				}
 
+				querier.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq("_id"), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
 				querier.EXPECT().
 					ListPosts(gomock.Any(), gomock.Eq(arg)).
 					Times(1).
@@ -309,6 +598,54 @@ func TestListPostsAPI(t *testing.T) {
 				requireBodyMatchPosts(t, recorder.Body, posts)
 			},
 		},
+		{
+			name: "ByUserIDPrivate",
+			query: map[string]any{
+				"offset":  offset,
+				"limit":   limit,
+				"user_id": privateUser.ID.Hex(),
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, viewer.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq("_id"), gomock.Eq(privateUser.ID)).
+					Times(1).
+					Return(privateUser, nil)
+				expectNotBlocked(querier, viewer.ID, privateUser.ID)
+				querier.EXPECT().
+					IsFollowing(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(false, nil)
+				querier.EXPECT().
+					ListPosts(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "ByUserIDNotFound",
+			query: map[string]any{
+				"offset":  offset,
+				"limit":   limit,
+				"user_id": user.ID.Hex(),
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq("_id"), gomock.Eq(user.ID)).
+					Times(1).
+					Return(db.User{}, mongo.ErrNoDocuments)
+				querier.EXPECT().
+					ListPosts(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
 		{
 			name: "ByUserIDInvalidUserID",
 			query: map[string]any{
@@ -351,15 +688,215 @@ func TestListPostsAPI(t *testing.T) {
 			q.Add("user_id", fmt.Sprint(tc.query["user_id"]))
 			request.URL.RawQuery = q.Encode()
 
+			if tc.setupAuth != nil {
+				tc.setupAuth(t, request, server.tokenMaker)
+			}
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListFeedAPI(t *testing.T) {
+	limit := 5
+	user, _ := randomUser(t)
+	posts := make([]db.Post, limit)
+	for i := 0; i < limit; i++ {
+		posts[i] = randomPost(t, user.ID)
+		posts[i].CreatedAt = time.Now().Add(-time.Duration(i) * time.Minute).Truncate(time.Millisecond)
+	}
+	last := posts[limit-1]
+	cursor := encodeFeedCursor(last.CreatedAt, last.ID)
+
+	testCases := []struct {
+		name          string
+		query         map[string]any
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockQuerier)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "FirstPageOK",
+			query: map[string]any{
+				"limit": limit,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				arg := db.ListFeedParams{
+					UserID: user.ID,
+					Limit:  int64(limit),
+				}
+
+				querier.EXPECT().
+					ListFeed(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(posts, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchFeed(t, recorder.Body, posts, cursor)
+			},
+		},
+		{
+			name: "NextPageOK",
+			query: map[string]any{
+				"limit":  limit,
+				"cursor": cursor,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				arg := db.ListFeedParams{
+					UserID:          user.ID,
+					Limit:           int64(limit),
+					BeforeCreatedAt: last.CreatedAt,
+					BeforeID:        last.ID,
+				}
+
+				querier.EXPECT().
+					ListFeed(gomock.Any(), feedParamsMatcher{arg}).
+					Times(1).
+					Return(posts[:1], nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchFeed(t, recorder.Body, posts[:1], "")
+			},
+		},
+		{
+			name: "InternalError",
+			query: map[string]any{
+				"limit": limit,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					ListFeed(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, mongo.ErrClientDisconnected)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidCursor",
+			query: map[string]any{
+				"limit":  limit,
+				"cursor": "#v#",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					ListFeed(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "LimitTooBig",
+			query: map[string]any{
+				"limit": 1000,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					ListFeed(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NoAuthorization",
+			query: map[string]any{
+				"limit": limit,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					ListFeed(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			queries := mockdb.NewMockQuerier(ctrl)
+			tc.buildStubs(queries)
+
+			// start test server and send request
+			server := newTestServer(t, queries, util.RandomPassword(32))
+			recorder := httptest.NewRecorder()
+
+			url := "/v1/feed"
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			request.Header.Add("Content-Type", "application/json")
+
+			q := request.URL.Query()
+			q.Add("limit", fmt.Sprint(tc.query["limit"]))
+			if cursor, ok := tc.query["cursor"]; ok {
+				q.Add("cursor", fmt.Sprint(cursor))
+			}
+			request.URL.RawQuery = q.Encode()
+
+			tc.setupAuth(t, request, server.tokenMaker)
 			server.router.ServeHTTP(recorder, request)
 			tc.checkResponse(t, recorder)
 		})
 	}
 }
 
+// feedParamsMatcher compares the cursor time by instant instead of by location
+type feedParamsMatcher struct {
+	arg db.ListFeedParams
+}
+
+func (m feedParamsMatcher) Matches(x interface{}) bool {
+	arg, ok := x.(db.ListFeedParams)
+	if !ok {
+		return false
+	}
+
+	return arg.UserID == m.arg.UserID &&
+		arg.Limit == m.arg.Limit &&
+		arg.BeforeID == m.arg.BeforeID &&
+		arg.BeforeCreatedAt.Equal(m.arg.BeforeCreatedAt)
+}
+
+func (m feedParamsMatcher) String() string {
+	return fmt.Sprintf("matches arg %v", m.arg)
+}
+
 func TestUpdatePostAPI(t *testing.T) {
 	user, _ := randomUser(t)
+	media := randomMedia(t, user.ID)
 	post := randomPost(t, user.ID)
+	moderatorID := util.RandomID()
+	strangerID := util.RandomID()
+	post.Images = []db.Image{mediaImage(media)}
 	result := &mongo.UpdateResult{
 		MatchedCount:  1,
 		ModifiedCount: 1,
@@ -378,7 +915,7 @@ func TestUpdatePostAPI(t *testing.T) {
 			name: "OK",
 			body: map[string]any{
 				"id":          post.ID.Hex(),
-				"images":      post.Images,
+				"media":       []string{media.ID.Hex()},
 				"description": post.Description,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
@@ -388,6 +925,10 @@ func TestUpdatePostAPI(t *testing.T) {
 					GetPost(gomock.Any(), gomock.Eq("_id"), gomock.Eq(post.ID)).
 					Times(1).
 					Return(post, nil)
+				querier.EXPECT().
+					GetMedia(gomock.Any(), gomock.Eq(media.ID)).
+					Times(1).
+					Return(media, nil)
 
 				arg := db.UpdatePostParams{
 					ID:          post.ID,
@@ -409,7 +950,7 @@ func TestUpdatePostAPI(t *testing.T) {
 			name: "InternalError",
 			body: map[string]any{
 				"id":          post.ID.Hex(),
-				"images":      post.Images,
+				"media":       []string{media.ID.Hex()},
 				"description": post.Description,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
@@ -419,6 +960,10 @@ func TestUpdatePostAPI(t *testing.T) {
 					GetPost(gomock.Any(), gomock.Eq("_id"), gomock.Eq(post.ID)).
 					Times(1).
 					Return(post, nil)
+				querier.EXPECT().
+					GetMedia(gomock.Any(), gomock.Eq(media.ID)).
+					Times(1).
+					Return(media, nil)
 				querier.EXPECT().
 					UpdatePost(gomock.Any(), gomock.Any()).
 					Times(1).
@@ -432,7 +977,7 @@ func TestUpdatePostAPI(t *testing.T) {
 			name: "PostNotFound",
 			body: map[string]any{
 				"id":          post.ID.Hex(),
-				"images":      post.Images,
+				"media":       []string{media.ID.Hex()},
 				"description": post.Description,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
@@ -442,6 +987,10 @@ func TestUpdatePostAPI(t *testing.T) {
 					GetPost(gomock.Any(), gomock.Eq("_id"), gomock.Eq(post.ID)).
 					Times(1).
 					Return(post, nil)
+				querier.EXPECT().
+					GetMedia(gomock.Any(), gomock.Eq(media.ID)).
+					Times(1).
+					Return(media, nil)
 				querier.EXPECT().
 					UpdatePost(gomock.Any(), gomock.Any()).
 					Times(1).
@@ -451,33 +1000,75 @@ func TestUpdatePostAPI(t *testing.T) {
 				require.Equal(t, http.StatusNotFound, recorder.Code)
 			},
 		},
+		{
+			name: "Moderator",
+			body: map[string]any{
+				"id":          post.ID.Hex(),
+				"media":       []string{media.ID.Hex()},
+				"description": post.Description,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addRoleAuthorization(t, request, tokenMaker, authorizationTypeBearer, moderatorID, util.RoleModerator, time.Minute)
+			}, buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetPost(gomock.Any(), gomock.Eq("_id"), gomock.Eq(post.ID)).
+					Times(1).
+					Return(post, nil)
+				expectStoredRole(querier, moderatorID, util.RoleModerator)
+				querier.EXPECT().
+					GetMedia(gomock.Any(), gomock.Eq(media.ID)).
+					Times(1).
+					Return(media, nil)
+				querier.EXPECT().
+					UpdatePost(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(result, nil)
+				querier.EXPECT().
+					CreateModerationAction(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CreateModerationActionParams) (db.ModerationAction, error) {
+						require.Equal(t, moderatorID, arg.ModeratorID)
+						require.Equal(t, db.ReportTargetPost, arg.TargetType)
+						require.Equal(t, post.ID, arg.TargetID)
+						require.Equal(t, user.ID, arg.UserID)
+						require.Equal(t, db.ModerationEditContent, arg.Action)
+
+						return db.ModerationAction{ID: util.RandomID()}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchUpdateResult(t, recorder.Body, result)
+			},
+		},
 		{
 			name: "NonPostOwner",
 			body: map[string]any{
 				"id":          post.ID.Hex(),
-				"images":      post.Images,
+				"media":       []string{media.ID.Hex()},
 				"description": post.Description,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, primitive.NewObjectID(), time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, strangerID, time.Minute)
 			}, buildStubs: func(querier *mockdb.MockQuerier) {
 				querier.EXPECT().
 					GetPost(gomock.Any(), gomock.Eq("_id"), gomock.Eq(post.ID)).
 					Times(1).
 					Return(post, nil)
+				expectStoredRole(querier, strangerID, util.RoleUser)
 				querier.EXPECT().
 					UpdatePost(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Equal(t, http.StatusForbidden, recorder.Code)
 			},
 		},
 		{
 			name: "InvalidPost",
 			body: map[string]any{
 				"id":          "qwertyuiopasdfghjklñzxcv",
-				"images":      post.Images,
+				"media":       []string{media.ID.Hex()},
 				"description": post.Description,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
@@ -498,7 +1089,7 @@ func TestUpdatePostAPI(t *testing.T) {
 			name: "IDLenIsNot24",
 			body: map[string]any{
 				"id":          ":D",
-				"images":      post.Images,
+				"media":       []string{media.ID.Hex()},
 				"description": post.Description,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
@@ -549,6 +1140,8 @@ func TestUpdatePostAPI(t *testing.T) {
 func TestDeletePostAPI(t *testing.T) {
 	user, _ := randomUser(t)
 	post := randomPost(t, user.ID)
+	moderatorID := util.RandomID()
+	strangerID := util.RandomID()
 	result := &mongo.DeleteResult{
 		DeletedCount: 1,
 	}
@@ -618,22 +1211,123 @@ func TestDeletePostAPI(t *testing.T) {
 				require.Equal(t, http.StatusNotFound, recorder.Code)
 			},
 		},
+		{
+			name: "Moderator",
+			id:   post.ID.Hex(),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addRoleAuthorization(t, request, tokenMaker, authorizationTypeBearer, moderatorID, util.RoleModerator, time.Minute)
+			}, buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetPost(gomock.Any(), gomock.Eq("_id"), gomock.Eq(post.ID)).
+					Times(1).
+					Return(post, nil)
+				expectStoredRole(querier, moderatorID, util.RoleModerator)
+				querier.EXPECT().
+					DeletePost(gomock.Any(), gomock.Eq(post.ID)).
+					Times(1).
+					Return(result, nil)
+				querier.EXPECT().
+					CreateModerationAction(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CreateModerationActionParams) (db.ModerationAction, error) {
+						require.Equal(t, moderatorID, arg.ModeratorID)
+						require.Equal(t, db.ReportTargetPost, arg.TargetType)
+						require.Equal(t, post.ID, arg.TargetID)
+						require.Equal(t, user.ID, arg.UserID)
+						require.Equal(t, db.ModerationDeleteContent, arg.Action)
+
+						return db.ModerationAction{ID: util.RandomID()}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchDeleteResult(t, recorder.Body, result)
+			},
+		},
+		{
+			name: "ModerationActionInternalError",
+			id:   post.ID.Hex(),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addRoleAuthorization(t, request, tokenMaker, authorizationTypeBearer, moderatorID, util.RoleModerator, time.Minute)
+			}, buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetPost(gomock.Any(), gomock.Eq("_id"), gomock.Eq(post.ID)).
+					Times(1).
+					Return(post, nil)
+				expectStoredRole(querier, moderatorID, util.RoleModerator)
+				querier.EXPECT().
+					DeletePost(gomock.Any(), gomock.Eq(post.ID)).
+					Times(1).
+					Return(result, nil)
+				querier.EXPECT().
+					CreateModerationAction(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ModerationAction{}, mongo.ErrClientDisconnected)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name: "PromotedModerator",
+			id:   post.ID.Hex(),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, moderatorID, time.Minute)
+			}, buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetPost(gomock.Any(), gomock.Eq("_id"), gomock.Eq(post.ID)).
+					Times(1).
+					Return(post, nil)
+				expectStoredRole(querier, moderatorID, util.RoleModerator)
+				querier.EXPECT().
+					DeletePost(gomock.Any(), gomock.Eq(post.ID)).
+					Times(1).
+					Return(result, nil)
+				querier.EXPECT().
+					CreateModerationAction(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ModerationAction{ID: util.RandomID()}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "DemotedModerator",
+			id:   post.ID.Hex(),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addRoleAuthorization(t, request, tokenMaker, authorizationTypeBearer, moderatorID, util.RoleModerator, time.Minute)
+			}, buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetPost(gomock.Any(), gomock.Eq("_id"), gomock.Eq(post.ID)).
+					Times(1).
+					Return(post, nil)
+				expectStoredRole(querier, moderatorID, util.RoleUser)
+				querier.EXPECT().
+					DeletePost(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
 		{
 			name: "NonPostOwner",
 			id:   post.ID.Hex(),
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, primitive.NewObjectID(), time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, strangerID, time.Minute)
 			}, buildStubs: func(querier *mockdb.MockQuerier) {
 				querier.EXPECT().
 					GetPost(gomock.Any(), gomock.Eq("_id"), gomock.Eq(post.ID)).
 					Times(1).
 					Return(post, nil)
+				expectStoredRole(querier, strangerID, util.RoleUser)
 				querier.EXPECT().
 					DeletePost(gomock.Any(), gomock.Eq(post.ID)).
 					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Equal(t, http.StatusForbidden, recorder.Code)
 			},
 		},
 		{
@@ -734,10 +1428,31 @@ func TestDeletePostAPI(t *testing.T) {
 }
 
 func randomPost(t *testing.T, userID primitive.ObjectID) db.Post {
+	media := randomMedia(t, userID)
 	return db.Post{
 		ID:          util.RandomID(),
 		UserID:      userID,
-		Images:      util.RandomImages(1),
+		Images:      []db.Image{mediaImage(media)},
 		Description: util.RandomDescription(100),
 	}
 }
+
+// expectVisiblePost stubs the lookups that checkCanViewTarget makes for a post of a public account
+// seen by the viewer, which can be anonymous
+func expectVisiblePost(querier *mockdb.MockQuerier, post db.Post, viewerID primitive.ObjectID) {
+	querier.EXPECT().
+		GetPost(gomock.Any(), gomock.Eq("_id"), gomock.Eq(post.ID)).
+		Times(1).
+		Return(post, nil)
+	if viewerID == post.UserID {
+		return
+	}
+
+	querier.EXPECT().
+		GetUser(gomock.Any(), gomock.Eq("_id"), gomock.Eq(post.UserID)).
+		Times(1).
+		Return(db.User{ID: post.UserID}, nil)
+	if !viewerID.IsZero() {
+		expectNotBlocked(querier, viewerID, post.UserID)
+	}
+}
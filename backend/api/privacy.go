@@ -0,0 +1,158 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	db "github.com/DMV-Nicolas/robotgram/backend/db/mongo"
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	errPrivateAccount = errors.New("the account is private")
+	errBlocked        = errors.New("there is a block between the users")
+	errRemoved        = errors.New("the content was removed by a moderator")
+)
+
+// checkNotBlocked returns a forbidden error if any of the two users blocked the other one.
+// Every handler that shows a user's things to another user, or lets it act on them, goes through it
+func (server *Server) checkNotBlocked(userID, otherID primitive.ObjectID) error {
+	if userID.IsZero() || otherID.IsZero() || userID == otherID {
+		return nil
+	}
+
+	arg := db.BlockParams{
+		BlockerID: otherID,
+		BlockedID: userID,
+	}
+
+	blocked, err := server.queries.IsBlockedBetween(context.TODO(), arg)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	if blocked {
+		return echo.NewHTTPError(http.StatusForbidden, errBlocked)
+	}
+
+	return nil
+}
+
+// checkCanView returns a forbidden error if there is a block between the viewer and the owner,
+// or if the owner is a private account that the viewer doesn't follow. The accounts in their deletion
+// grace period are not found
+func (server *Server) checkCanView(viewerID, ownerID primitive.ObjectID) error {
+	if viewerID == ownerID {
+		return nil
+	}
+
+	owner, err := server.queries.GetUser(context.TODO(), "_id", ownerID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return echo.NewHTTPError(http.StatusNotFound, err)
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	if !owner.PurgeAt.IsZero() {
+		return echo.NewHTTPError(http.StatusNotFound, mongo.ErrNoDocuments)
+	}
+
+	if err = server.checkNotBlocked(viewerID, ownerID); err != nil {
+		return err
+	}
+
+	if !owner.IsPrivate {
+		return nil
+	}
+
+	if viewerID.IsZero() {
+		return echo.NewHTTPError(http.StatusForbidden, errPrivateAccount)
+	}
+
+	arg := db.FollowParams{
+		FollowerID:  viewerID,
+		FollowingID: ownerID,
+	}
+
+	following, err := server.queries.IsFollowing(context.TODO(), arg)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	if !following {
+		return echo.NewHTTPError(http.StatusForbidden, errPrivateAccount)
+	}
+
+	return nil
+}
+
+// checkCommentAuthor returns a not found error for the comments of the accounts in their deletion
+// grace period, and a forbidden one if there is a block between the viewer and the author
+func (server *Server) checkCommentAuthor(viewerID, authorID primitive.ObjectID) error {
+	// the tombstones have no author
+	if authorID.IsZero() || authorID == viewerID {
+		return nil
+	}
+
+	author, err := server.queries.GetUser(context.TODO(), "_id", authorID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return echo.NewHTTPError(http.StatusNotFound, err)
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	if !author.PurgeAt.IsZero() {
+		return echo.NewHTTPError(http.StatusNotFound, mongo.ErrNoDocuments)
+	}
+
+	return server.checkNotBlocked(viewerID, authorID)
+}
+
+// checkCanViewTarget checks the viewer against the author of the target, which is either a post
+// or a comment. The author of a comment must not be blocked either, besides the one of its post.
+// The removed targets are only found by their authors
+func (server *Server) checkCanViewTarget(viewerID, targetID primitive.ObjectID) error {
+	return server.checkTarget(viewerID, targetID, false)
+}
+
+// checkCanLikeTarget works like checkCanViewTarget, but a removed target is refused to its author too
+func (server *Server) checkCanLikeTarget(viewerID, targetID primitive.ObjectID) error {
+	return server.checkTarget(viewerID, targetID, true)
+}
+
+func (server *Server) checkTarget(viewerID, targetID primitive.ObjectID, refuseRemoved bool) error {
+	post, err := server.queries.GetPost(context.TODO(), "_id", targetID)
+	if err == mongo.ErrNoDocuments {
+		var comment db.Comment
+		comment, err = server.queries.GetComment(context.TODO(), targetID)
+		if err == nil {
+			if comment.Removed && (refuseRemoved || comment.UserID != viewerID) {
+				return echo.NewHTTPError(http.StatusNotFound, errRemoved)
+			}
+
+			if err = server.checkCommentAuthor(viewerID, comment.UserID); err != nil {
+				return err
+			}
+
+			post, err = server.queries.GetPost(context.TODO(), "_id", comment.PostID())
+		}
+	}
+
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return echo.NewHTTPError(http.StatusNotFound, err)
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	if post.Removed && (refuseRemoved || post.UserID != viewerID) {
+		return echo.NewHTTPError(http.StatusNotFound, errRemoved)
+	}
+
+	return server.checkCanView(viewerID, post.UserID)
+}
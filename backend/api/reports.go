@@ -0,0 +1,242 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	db "github.com/DMV-Nicolas/robotgram/backend/db/mongo"
+	"github.com/DMV-Nicolas/robotgram/backend/token"
+	"github.com/DMV-Nicolas/robotgram/backend/util"
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type createReportRequest struct {
+	TargetType string `json:"target_type" validate:"required,oneof=post comment user"`
+	TargetID   string `json:"target_id" validate:"required,len=24"`
+	Reason     string `json:"reason" validate:"required,oneof=spam harassment hate_speech violence nudity self_harm misinformation impersonation other"`
+	Details    string `json:"details" validate:"max=1000"`
+}
+
+// CreateReport reports a post, comment or user to the moderators
+func (server *Server) CreateReport(c echo.Context) error {
+	req := new(createReportRequest)
+	if err := bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	payload, err := getAuthorizationPayload(c)
+	if err != nil {
+		return err
+	}
+
+	var targetID primitive.ObjectID
+	switch req.TargetType {
+	case db.ReportTargetPost:
+		post, err := server.validPost(c, req.TargetID)
+		if err != nil {
+			return err
+		}
+		targetID = post.ID
+	case db.ReportTargetComment:
+		comment, err := server.validComment(c, req.TargetID)
+		if err != nil {
+			return err
+		}
+		targetID = comment.ID
+	case db.ReportTargetUser:
+		user, err := server.validUser(c, req.TargetID)
+		if err != nil {
+			return err
+		}
+		targetID = user.ID
+	}
+
+	arg := db.CreateReportParams{
+		ReporterID: payload.UserID,
+		TargetType: req.TargetType,
+		TargetID:   targetID,
+		Reason:     req.Reason,
+		Details:    req.Details,
+	}
+
+	report, err := server.queries.CreateReport(context.TODO(), arg)
+	if err != nil {
+		if err == db.ErrAlreadyReported {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusCreated, report)
+}
+
+type listReportGroupsRequest struct {
+	TargetType string `query:"target_type" validate:"omitempty,oneof=post comment user"`
+	Offset     int64  `query:"offset" validate:"min=0"`
+	Limit      int64  `query:"limit" validate:"min=1,max=50"`
+}
+
+// ListReportGroups lists the moderation queue, the reported targets with their open reports grouped
+func (server *Server) ListReportGroups(c echo.Context) error {
+	req := new(listReportGroupsRequest)
+	if err := bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	arg := db.ListReportGroupsParams{
+		TargetType: req.TargetType,
+		Offset:     req.Offset,
+		Limit:      req.Limit,
+	}
+
+	groups, err := server.queries.ListReportGroups(context.TODO(), arg)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, groups)
+}
+
+type listReportsRequest struct {
+	TargetID string `param:"target_id" validate:"required,len=24"`
+	Offset   int64  `query:"offset" validate:"min=0"`
+	Limit    int64  `query:"limit" validate:"min=1,max=50"`
+}
+
+// ListReports lists every report made on the target, the resolved ones too
+func (server *Server) ListReports(c echo.Context) error {
+	req := new(listReportsRequest)
+	if err := bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	targetID, err := primitive.ObjectIDFromHex(req.TargetID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	arg := db.ListReportsParams{
+		TargetID: targetID,
+		Offset:   req.Offset,
+		Limit:    req.Limit,
+	}
+
+	reports, err := server.queries.ListReports(context.TODO(), arg)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, reports)
+}
+
+type resolveReportsRequest struct {
+	TargetID string `param:"target_id" validate:"required,len=24"`
+	Action   string `json:"action" validate:"required,oneof=dismiss remove_content suspend_user"`
+	Note     string `json:"note" validate:"max=1000"`
+}
+
+// ResolveReports takes the moderator action on the target and closes its open reports
+func (server *Server) ResolveReports(c echo.Context) error {
+	req := new(resolveReportsRequest)
+	if err := bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	targetID, err := primitive.ObjectIDFromHex(req.TargetID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	payload, err := getAuthorizationPayload(c)
+	if err != nil {
+		return err
+	}
+
+	arg := db.ResolveReportsParams{
+		ModeratorID: payload.UserID,
+		TargetID:    targetID,
+		Action:      req.Action,
+		Note:        req.Note,
+	}
+
+	action, err := server.queries.ResolveReports(context.TODO(), arg)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return echo.NewHTTPError(http.StatusNotFound, err)
+		}
+		if err == db.ErrInvalidModerationAction || err == db.ErrNoAuthor {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusCreated, action)
+}
+
+type listModerationActionsRequest struct {
+	TargetID string `query:"target_id" validate:"omitempty,len=24"`
+	Offset   int64  `query:"offset" validate:"min=0"`
+	Limit    int64  `query:"limit" validate:"min=1,max=50"`
+}
+
+// ListModerationActions lists the moderation history, of a single target if one is given
+func (server *Server) ListModerationActions(c echo.Context) error {
+	req := new(listModerationActionsRequest)
+	if err := bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	arg := db.ListModerationActionsParams{
+		Offset: req.Offset,
+		Limit:  req.Limit,
+	}
+
+	if req.TargetID != "" {
+		targetID, err := primitive.ObjectIDFromHex(req.TargetID)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+		arg.TargetID = targetID
+	}
+
+	actions, err := server.queries.ListModerationActions(context.TODO(), arg)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, actions)
+}
+
+// authorizeContent lets the author act on its post or comment and the moderators on anyone's.
+// It reports whether the action is taken by a moderator, to be attributed with attributeModeration
+func (server *Server) authorizeContent(payload *token.Payload, authorID primitive.ObjectID) (bool, error) {
+	if authorID == payload.UserID {
+		return false, nil
+	}
+
+	if err := server.checkPermission(payload, util.PermissionModerateContent); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// attributeModeration records in the moderation history the action of a moderator on the content of another user
+func (server *Server) attributeModeration(payload *token.Payload, targetType string, targetID, authorID primitive.ObjectID, action string) error {
+	arg := db.CreateModerationActionParams{
+		ModeratorID: payload.UserID,
+		TargetType:  targetType,
+		TargetID:    targetID,
+		UserID:      authorID,
+		Action:      action,
+	}
+
+	_, err := server.queries.CreateModerationAction(context.TODO(), arg)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return nil
+}
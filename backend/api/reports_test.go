@@ -0,0 +1,644 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/DMV-Nicolas/robotgram/backend/db/mock"
+	db "github.com/DMV-Nicolas/robotgram/backend/db/mongo"
+	"github.com/DMV-Nicolas/robotgram/backend/token"
+	"github.com/DMV-Nicolas/robotgram/backend/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestCreateReportAPI(t *testing.T) {
+	reporter, _ := randomUser(t)
+	user, _ := randomUser(t)
+	post := randomPost(t, user.ID)
+	comment := randomComment(t, user.ID, post.ID)
+	postReport := randomReport(t, reporter.ID, db.ReportTargetPost, post.ID)
+	userReport := randomReport(t, reporter.ID, db.ReportTargetUser, user.ID)
+
+	testCases := []struct {
+		name          string
+		body          map[string]any
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockQuerier)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "PostOK",
+			body: map[string]any{
+				"target_type": db.ReportTargetPost,
+				"target_id":   post.ID.Hex(),
+				"reason":      postReport.Reason,
+				"details":     postReport.Details,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, reporter.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				arg := db.CreateReportParams{
+					ReporterID: reporter.ID,
+					TargetType: db.ReportTargetPost,
+					TargetID:   post.ID,
+					Reason:     postReport.Reason,
+					Details:    postReport.Details,
+				}
+
+				querier.EXPECT().
+					GetPost(gomock.Any(), gomock.Eq("_id"), gomock.Eq(post.ID)).
+					Times(1).
+					Return(post, nil)
+				querier.EXPECT().
+					CreateReport(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(postReport, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+				requireBodyMatchReport(t, recorder.Body, postReport)
+			},
+		},
+		{
+			name: "UserOK",
+			body: map[string]any{
+				"target_type": db.ReportTargetUser,
+				"target_id":   user.ID.Hex(),
+				"reason":      userReport.Reason,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, reporter.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				arg := db.CreateReportParams{
+					ReporterID: reporter.ID,
+					TargetType: db.ReportTargetUser,
+					TargetID:   user.ID,
+					Reason:     userReport.Reason,
+				}
+
+				querier.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq("_id"), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				querier.EXPECT().
+					CreateReport(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(userReport, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+				requireBodyMatchReport(t, recorder.Body, userReport)
+			},
+		},
+		{
+			name: "CommentNotFound",
+			body: map[string]any{
+				"target_type": db.ReportTargetComment,
+				"target_id":   comment.ID.Hex(),
+				"reason":      db.ReportReasonSpam,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, reporter.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetComment(gomock.Any(), gomock.Eq(comment.ID)).
+					Times(1).
+					Return(db.Comment{}, mongo.ErrNoDocuments)
+				querier.EXPECT().
+					CreateReport(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "AlreadyReported",
+			body: map[string]any{
+				"target_type": db.ReportTargetPost,
+				"target_id":   post.ID.Hex(),
+				"reason":      db.ReportReasonSpam,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, reporter.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetPost(gomock.Any(), gomock.Eq("_id"), gomock.Eq(post.ID)).
+					Times(1).
+					Return(post, nil)
+				querier.EXPECT().
+					CreateReport(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Report{}, db.ErrAlreadyReported)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			body: map[string]any{
+				"target_type": db.ReportTargetPost,
+				"target_id":   post.ID.Hex(),
+				"reason":      db.ReportReasonSpam,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, reporter.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetPost(gomock.Any(), gomock.Eq("_id"), gomock.Eq(post.ID)).
+					Times(1).
+					Return(post, nil)
+				querier.EXPECT().
+					CreateReport(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Report{}, mongo.ErrClientDisconnected)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidReason",
+			body: map[string]any{
+				"target_type": db.ReportTargetPost,
+				"target_id":   post.ID.Hex(),
+				"reason":      "boring",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, reporter.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					CreateReport(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NoAuthorization",
+			body: map[string]any{
+				"target_type": db.ReportTargetPost,
+				"target_id":   post.ID.Hex(),
+				"reason":      db.ReportReasonSpam,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					CreateReport(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			queries := mockdb.NewMockQuerier(ctrl)
+			tc.buildStubs(queries)
+
+			// marshal data body to json
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			// start test server and send request
+			server := newTestServer(t, queries, util.RandomPassword(32))
+			recorder := httptest.NewRecorder()
+
+			url := "/v1/reports"
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			request.Header.Add("Content-Type", "application/json")
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListReportGroupsAPI(t *testing.T) {
+	moderator, _ := randomUser(t)
+	user, _ := randomUser(t)
+	limit := 3
+	groups := make([]db.ReportGroup, limit)
+	for i := range groups {
+		groups[i] = db.ReportGroup{
+			TargetType:      db.ReportTargetPost,
+			TargetID:        util.RandomID(),
+			ReportCount:     int64(limit - i),
+			Reasons:         []string{db.ReportReasonSpam},
+			FirstReportedAt: time.Now().Add(-time.Hour),
+			LastReportedAt:  time.Now(),
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		query         string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockQuerier)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			query: fmt.Sprintf("target_type=post&limit=%d", limit),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addRoleAuthorization(t, request, tokenMaker, authorizationTypeBearer, moderator.ID, util.RoleModerator, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				expectStoredRole(querier, moderator.ID, util.RoleModerator)
+				arg := db.ListReportGroupsParams{
+					TargetType: db.ReportTargetPost,
+					Limit:      int64(limit),
+				}
+
+				querier.EXPECT().
+					ListReportGroups(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(groups, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchReportGroups(t, recorder.Body, groups)
+			},
+		},
+		{
+			name:  "InternalError",
+			query: fmt.Sprintf("limit=%d", limit),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addRoleAuthorization(t, request, tokenMaker, authorizationTypeBearer, moderator.ID, util.RoleModerator, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				expectStoredRole(querier, moderator.ID, util.RoleModerator)
+				querier.EXPECT().
+					ListReportGroups(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, mongo.ErrClientDisconnected)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name:  "InvalidTargetType",
+			query: fmt.Sprintf("target_type=message&limit=%d", limit),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addRoleAuthorization(t, request, tokenMaker, authorizationTypeBearer, moderator.ID, util.RoleModerator, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				expectStoredRole(querier, moderator.ID, util.RoleModerator)
+				querier.EXPECT().
+					ListReportGroups(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "NotModerator",
+			query: fmt.Sprintf("limit=%d", limit),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				expectStoredRole(querier, user.ID, util.RoleUser)
+				querier.EXPECT().
+					ListReportGroups(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			queries := mockdb.NewMockQuerier(ctrl)
+			tc.buildStubs(queries)
+
+			// start test server and send request
+			server := newTestServer(t, queries, util.RandomPassword(32))
+			recorder := httptest.NewRecorder()
+
+			url := "/v1/moderation/queue?" + tc.query
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestResolveReportsAPI(t *testing.T) {
+	moderator, _ := randomUser(t)
+	user, _ := randomUser(t)
+	post := randomPost(t, user.ID)
+	action := randomModerationAction(t, moderator.ID, post)
+
+	testCases := []struct {
+		name          string
+		targetID      string
+		body          map[string]any
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockQuerier)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			targetID: post.ID.Hex(),
+			body: map[string]any{
+				"action": action.Action,
+				"note":   action.Note,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addRoleAuthorization(t, request, tokenMaker, authorizationTypeBearer, moderator.ID, util.RoleModerator, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				expectStoredRole(querier, moderator.ID, util.RoleModerator)
+				arg := db.ResolveReportsParams{
+					ModeratorID: moderator.ID,
+					TargetID:    post.ID,
+					Action:      action.Action,
+					Note:        action.Note,
+				}
+
+				querier.EXPECT().
+					ResolveReports(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(action, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+				requireBodyMatchModerationAction(t, recorder.Body, action)
+			},
+		},
+		{
+			name:     "NoOpenReports",
+			targetID: post.ID.Hex(),
+			body: map[string]any{
+				"action": db.ModerationDismiss,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addRoleAuthorization(t, request, tokenMaker, authorizationTypeBearer, moderator.ID, util.RoleModerator, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				expectStoredRole(querier, moderator.ID, util.RoleModerator)
+				querier.EXPECT().
+					ResolveReports(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ModerationAction{}, mongo.ErrNoDocuments)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:     "ActionNotAllowed",
+			targetID: user.ID.Hex(),
+			body: map[string]any{
+				"action": db.ModerationRemoveContent,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addRoleAuthorization(t, request, tokenMaker, authorizationTypeBearer, moderator.ID, util.RoleModerator, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				expectStoredRole(querier, moderator.ID, util.RoleModerator)
+				querier.EXPECT().
+					ResolveReports(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ModerationAction{}, db.ErrInvalidModerationAction)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "InternalError",
+			targetID: post.ID.Hex(),
+			body: map[string]any{
+				"action": db.ModerationDismiss,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addRoleAuthorization(t, request, tokenMaker, authorizationTypeBearer, moderator.ID, util.RoleModerator, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				expectStoredRole(querier, moderator.ID, util.RoleModerator)
+				querier.EXPECT().
+					ResolveReports(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ModerationAction{}, mongo.ErrClientDisconnected)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name:     "InvalidAction",
+			targetID: post.ID.Hex(),
+			body: map[string]any{
+				"action": "ban_forever",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addRoleAuthorization(t, request, tokenMaker, authorizationTypeBearer, moderator.ID, util.RoleModerator, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				expectStoredRole(querier, moderator.ID, util.RoleModerator)
+				querier.EXPECT().
+					ResolveReports(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "NotModerator",
+			targetID: post.ID.Hex(),
+			body: map[string]any{
+				"action": db.ModerationDismiss,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				expectStoredRole(querier, user.ID, util.RoleUser)
+				querier.EXPECT().
+					ResolveReports(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			queries := mockdb.NewMockQuerier(ctrl)
+			tc.buildStubs(queries)
+
+			// marshal data body to json
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			// start test server and send request
+			server := newTestServer(t, queries, util.RandomPassword(32))
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/v1/moderation/reports/%s/resolve", tc.targetID)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			request.Header.Add("Content-Type", "application/json")
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListModerationActionsAPI(t *testing.T) {
+	moderator, _ := randomUser(t)
+	post := randomPost(t, primitive.NewObjectID())
+	actions := []db.ModerationAction{
+		randomModerationAction(t, moderator.ID, post),
+		randomModerationAction(t, moderator.ID, post),
+	}
+
+	testCases := []struct {
+		name          string
+		query         string
+		buildStubs    func(store *mockdb.MockQuerier)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			query: "limit=10",
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				expectStoredRole(querier, moderator.ID, util.RoleModerator)
+				querier.EXPECT().
+					ListModerationActions(gomock.Any(), gomock.Eq(db.ListModerationActionsParams{Limit: 10})).
+					Times(1).
+					Return(actions, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchModerationActions(t, recorder.Body, actions)
+			},
+		},
+		{
+			name:  "ByTarget",
+			query: "limit=10&target_id=" + post.ID.Hex(),
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				expectStoredRole(querier, moderator.ID, util.RoleModerator)
+				arg := db.ListModerationActionsParams{
+					TargetID: post.ID,
+					Limit:    10,
+				}
+
+				querier.EXPECT().
+					ListModerationActions(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(actions, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchModerationActions(t, recorder.Body, actions)
+			},
+		},
+		{
+			name:  "InvalidTargetID",
+			query: "limit=10&target_id=qwertyuiopasdfghjklñzxcv",
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				expectStoredRole(querier, moderator.ID, util.RoleModerator)
+				querier.EXPECT().
+					ListModerationActions(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			queries := mockdb.NewMockQuerier(ctrl)
+			tc.buildStubs(queries)
+
+			// start test server and send request
+			server := newTestServer(t, queries, util.RandomPassword(32))
+			recorder := httptest.NewRecorder()
+
+			url := "/v1/moderation/actions?" + tc.query
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			addRoleAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, moderator.ID, util.RoleModerator, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func randomReport(t *testing.T, reporterID primitive.ObjectID, targetType string, targetID primitive.ObjectID) db.Report {
+	return db.Report{
+		ID:         util.RandomID(),
+		ReporterID: reporterID,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Reason:     db.ReportReasonHarassment,
+		Details:    util.RandomString(30),
+		Status:     db.ReportOpen,
+		CreatedAt:  time.Now(),
+	}
+}
+
+func randomModerationAction(t *testing.T, moderatorID primitive.ObjectID, post db.Post) db.ModerationAction {
+	return db.ModerationAction{
+		ID:          util.RandomID(),
+		ModeratorID: moderatorID,
+		TargetType:  db.ReportTargetPost,
+		TargetID:    post.ID,
+		UserID:      post.UserID,
+		Action:      db.ModerationRemoveContent,
+		Note:        util.RandomString(20),
+		ReportCount: 2,
+		CreatedAt:   time.Now(),
+	}
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	db "github.com/DMV-Nicolas/robotgram/backend/db/mongo"
+	"github.com/DMV-Nicolas/robotgram/backend/util"
+	"github.com/labstack/echo/v4"
+)
+
+// The kinds of results of a search
+const (
+	searchTypeUsers = "users"
+	searchTypePosts = "posts"
+	searchTypeTags  = "tags"
+)
+
+var errEmptySearch = errors.New("the search has no words")
+
+type searchRequest struct {
+	Query  string `query:"q" validate:"required,max=100"`
+	Type   string `query:"type" validate:"required,oneof=users posts tags"`
+	Offset int64  `query:"offset" validate:"min=0,max=1000"`
+	Limit  int64  `query:"limit" validate:"min=1,max=50"`
+}
+
+// Search finds the users, the posts or the hashtags that match the query, the best matches first.
+// The users are found by the start of their username and the words of their full name, the posts by the
+// words of their description and the hashtags by their start
+func (server *Server) Search(c echo.Context) error {
+	req := new(searchRequest)
+	if err := bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	viewerID, err := getViewerID(c)
+	if err != nil {
+		return err
+	}
+
+	arg := db.SearchParams{
+		Query:    req.Query,
+		Offset:   req.Offset,
+		Limit:    req.Limit,
+		ViewerID: viewerID,
+	}
+
+	var results any
+	switch req.Type {
+	case searchTypeUsers:
+		if len(util.SearchTerms(req.Query)) == 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, errEmptySearch)
+		}
+		results, err = server.queries.SearchUsers(context.TODO(), arg)
+	case searchTypePosts:
+		if len(util.SearchTerms(req.Query)) == 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, errEmptySearch)
+		}
+		results, err = server.queries.SearchPosts(context.TODO(), arg)
+	case searchTypeTags:
+		prefix := util.NormalizeHashtag(req.Query)
+		if prefix == "" {
+			return echo.NewHTTPError(http.StatusBadRequest, errInvalidHashtag)
+		}
+
+		results, err = server.queries.SearchHashtags(context.TODO(), db.SearchHashtagsParams{
+			Prefix: prefix,
+			Offset: req.Offset,
+			Limit:  req.Limit,
+		})
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, results)
+}
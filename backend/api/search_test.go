@@ -0,0 +1,169 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mockdb "github.com/DMV-Nicolas/robotgram/backend/db/mock"
+	db "github.com/DMV-Nicolas/robotgram/backend/db/mongo"
+	"github.com/DMV-Nicolas/robotgram/backend/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestSearchAPI(t *testing.T) {
+	offset, limit := 0, 3
+	users := make([]db.User, limit)
+	posts := make([]db.Post, limit)
+	hashtags := make([]db.Hashtag, limit)
+	for i := 0; i < limit; i++ {
+		users[i], _ = randomUser(t)
+		posts[i] = randomPost(t, users[i].ID)
+		hashtags[i] = randomHashtag()
+	}
+
+	testCases := []struct {
+		name          string
+		query         map[string]string
+		buildStubs    func(querier *mockdb.MockQuerier)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "Users",
+			query: map[string]string{"q": "José Pé", "type": "users"},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				arg := db.SearchParams{
+					Query:  "José Pé",
+					Offset: int64(offset),
+					Limit:  int64(limit),
+				}
+
+				querier.EXPECT().
+					SearchUsers(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(users, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchUsers(t, recorder.Body, users)
+			},
+		},
+		{
+			name:  "Posts",
+			query: map[string]string{"q": "robots en la playa", "type": "posts"},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				arg := db.SearchParams{
+					Query:  "robots en la playa",
+					Offset: int64(offset),
+					Limit:  int64(limit),
+				}
+
+				querier.EXPECT().
+					SearchPosts(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(posts, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchPosts(t, recorder.Body, posts)
+			},
+		},
+		{
+			name:  "Tags",
+			query: map[string]string{"q": "#Robo", "type": "tags"},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				arg := db.SearchHashtagsParams{
+					Prefix: "robo",
+					Offset: int64(offset),
+					Limit:  int64(limit),
+				}
+
+				querier.EXPECT().
+					SearchHashtags(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(hashtags, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchHashtags(t, recorder.Body, hashtags)
+			},
+		},
+		{
+			name:  "NoWords",
+			query: map[string]string{"q": "@!?", "type": "users"},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					SearchUsers(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "InvalidType",
+			query: map[string]string{"q": "robot", "type": "comments"},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "NoQuery",
+			query: map[string]string{"type": "posts"},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					SearchPosts(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "InternalError",
+			query: map[string]string{"q": "robot", "type": "users"},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					SearchUsers(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, mongo.ErrClientDisconnected)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			queries := mockdb.NewMockQuerier(ctrl)
+			tc.buildStubs(queries)
+
+			// start test server and send request
+			server := newTestServer(t, queries, util.RandomPassword(32))
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/v1/search", nil)
+			require.NoError(t, err)
+
+			q := request.URL.Query()
+			q.Add("offset", fmt.Sprint(offset))
+			q.Add("limit", fmt.Sprint(limit))
+			for key, value := range tc.query {
+				q.Add(key, value)
+			}
+			request.URL.RawQuery = q.Encode()
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -2,6 +2,8 @@ package api
 
 import (
 	db "github.com/DMV-Nicolas/robotgram/backend/db/mongo"
+	"github.com/DMV-Nicolas/robotgram/backend/realtime"
+	"github.com/DMV-Nicolas/robotgram/backend/storage"
 	"github.com/DMV-Nicolas/robotgram/backend/token"
 	"github.com/DMV-Nicolas/robotgram/backend/util"
 	"github.com/go-playground/validator/v10"
@@ -13,19 +15,28 @@ type Server struct {
 	config     util.Config
 	queries    db.Querier
 	tokenMaker token.Maker
+	storage    storage.Backend
+	broker     realtime.Broker
 	router     *echo.Echo
 }
 
-func NewServer(config util.Config, queries db.Querier) (*Server, error) {
+func NewServer(config util.Config, queries db.Querier, broker realtime.Broker) (*Server, error) {
 	tokenMaker, err := token.NewPasetoMaker(config.TokenSymmetricKey)
 	if err != nil {
 		return nil, err
 	}
 
+	mediaStorage, err := storage.NewBackend(config)
+	if err != nil {
+		return nil, err
+	}
+
 	server := &Server{
 		config:     config,
 		queries:    queries,
 		tokenMaker: tokenMaker,
+		storage:    mediaStorage,
+		broker:     broker,
 	}
 
 	e := echo.New()
@@ -37,10 +48,13 @@ func NewServer(config util.Config, queries db.Querier) (*Server, error) {
 	return server, nil
 }
 
+// allowedOrigins are the origins of the frontend, which can call the API from the browser
+var allowedOrigins = []string{"http://localhost:5173"}
+
 func (server *Server) setupRouter(e *echo.Echo) {
 	v1 := e.Group("/v1")
 	v1.Use(middleware.CORSWithConfig(middleware.CORSConfig{
-		AllowOrigins:     []string{"http://localhost:5173"},
+		AllowOrigins:     allowedOrigins,
 		AllowHeaders:     []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization},
 		AllowCredentials: true,
 	}))
@@ -48,25 +62,94 @@ func (server *Server) setupRouter(e *echo.Echo) {
 
 	v1.POST("/users", server.CreateUser)
 	v1.POST("/users/login", server.LoginUser)
-	v1.GET("/users/:id", server.GetUser)
+	v1.GET("/users/:id", optionalAuthMiddleware(server.GetUser, server.tokenMaker))
+	v1.PUT("/users/:id", authMiddleware(server.UpdateUser, server.tokenMaker))
+	v1.DELETE("/users/:id", authMiddleware(server.DeleteUser, server.tokenMaker))
+	v1.GET("/users/:id/deletion", authMiddleware(server.GetUserDeletion, server.tokenMaker))
+	v1.DELETE("/users/:id/deletion", authMiddleware(server.CancelUserDeletion, server.tokenMaker))
 	v1.GET("/users", server.ListUsers)
+	v1.POST("/users/:id/follow", authMiddleware(server.FollowUser, server.tokenMaker))
+	v1.DELETE("/users/:id/follow", authMiddleware(server.UnfollowUser, server.tokenMaker))
+	v1.GET("/users/:id/follow", authMiddleware(server.IsFollowing, server.tokenMaker))
+	v1.GET("/users/:id/followers", optionalAuthMiddleware(server.ListFollowers, server.tokenMaker))
+	v1.GET("/users/:id/following", optionalAuthMiddleware(server.ListFollowing, server.tokenMaker))
+	v1.GET("/follow-requests", authMiddleware(server.ListFollowRequests, server.tokenMaker))
+	v1.PUT("/follow-requests/:id", authMiddleware(server.ApproveFollowRequest, server.tokenMaker))
+	v1.DELETE("/follow-requests/:id", authMiddleware(server.RejectFollowRequest, server.tokenMaker))
+	v1.POST("/users/:id/block", authMiddleware(server.BlockUser, server.tokenMaker))
+	v1.DELETE("/users/:id/block", authMiddleware(server.UnblockUser, server.tokenMaker))
+	v1.GET("/blocks", authMiddleware(server.ListBlocks, server.tokenMaker))
+	v1.POST("/users/:id/mute", authMiddleware(server.MuteUser, server.tokenMaker))
+	v1.DELETE("/users/:id/mute", authMiddleware(server.UnmuteUser, server.tokenMaker))
+	v1.GET("/mutes", authMiddleware(server.ListMutes, server.tokenMaker))
+
+	v1.POST("/media", authMiddleware(server.UploadMedia, server.tokenMaker))
+	v1.GET("/media/:id/:rendition", server.GetMedia)
 
 	v1.POST("/posts", authMiddleware(server.CreatePost, server.tokenMaker))
-	v1.GET("/posts", server.ListPosts)
-	v1.GET("/posts/:id", server.GetPost)
+	v1.GET("/posts", optionalAuthMiddleware(server.ListPosts, server.tokenMaker))
+	v1.GET("/posts/:id", optionalAuthMiddleware(server.GetPost, server.tokenMaker))
 	v1.PUT("/posts/:id", authMiddleware(server.UpdatePost, server.tokenMaker))
 	v1.DELETE("/posts/:id", authMiddleware(server.DeletePost, server.tokenMaker))
 
+	v1.GET("/search", optionalAuthMiddleware(server.Search, server.tokenMaker))
+
+	v1.GET("/hashtags", server.SearchHashtags)
+	v1.GET("/hashtags/:tag", server.GetHashtag)
+	v1.GET("/hashtags/:tag/posts", optionalAuthMiddleware(server.ListHashtagPosts, server.tokenMaker))
+
+	v1.GET("/mentions", authMiddleware(server.ListMentions, server.tokenMaker))
+
+	v1.GET("/notifications", authMiddleware(server.ListNotifications, server.tokenMaker))
+	v1.GET("/notifications/unread", authMiddleware(server.CountUnreadNotifications, server.tokenMaker))
+	v1.PUT("/notifications/read", authMiddleware(server.MarkNotificationsRead, server.tokenMaker))
+	v1.GET("/notifications/preferences", authMiddleware(server.GetNotificationPreferences, server.tokenMaker))
+	v1.PUT("/notifications/preferences", authMiddleware(server.UpdateNotificationPreferences, server.tokenMaker))
+
+	v1.POST("/conversations", authMiddleware(server.CreateConversation, server.tokenMaker))
+	v1.GET("/conversations", authMiddleware(server.ListConversations, server.tokenMaker))
+	v1.GET("/conversations/:id", authMiddleware(server.GetConversation, server.tokenMaker))
+	v1.GET("/conversations/:id/unread", authMiddleware(server.CountUnreadMessages, server.tokenMaker))
+	v1.PUT("/conversations/:id/read", authMiddleware(server.MarkConversationRead, server.tokenMaker))
+	v1.POST("/conversations/:id/messages", authMiddleware(server.CreateMessage, server.tokenMaker))
+	v1.GET("/conversations/:id/messages", authMiddleware(server.ListMessages, server.tokenMaker))
+	v1.PUT("/messages/:id", authMiddleware(server.UpdateMessage, server.tokenMaker))
+	v1.DELETE("/messages/:id", authMiddleware(server.DeleteMessage, server.tokenMaker))
+
+	v1.GET("/stream", streamAuthMiddleware(server.StreamWebSocket, server.tokenMaker))
+	v1.GET("/stream/sse", streamAuthMiddleware(server.StreamSSE, server.tokenMaker))
+
+	v1.GET("/feed", authMiddleware(server.ListFeed, server.tokenMaker))
+
 	v1.POST("/likes", authMiddleware(server.ToggleLike, server.tokenMaker))
-	v1.GET("/likes/:target_id", server.ListLikes)
-	v1.GET("/likes/:target_id/count", server.CountLikes)
+	v1.GET("/likes/:target_id", optionalAuthMiddleware(server.ListLikes, server.tokenMaker))
+	v1.PUT("/likes/:target_id", authMiddleware(server.LikeTarget, server.tokenMaker))
+	v1.DELETE("/likes/:target_id", authMiddleware(server.UnlikeTarget, server.tokenMaker))
+	v1.GET("/likes/:target_id/count", optionalAuthMiddleware(server.CountLikes, server.tokenMaker))
 	v1.GET("/likes/:target_id/liked", authMiddleware(server.IsLiked, server.tokenMaker))
 
 	v1.POST("/comments", authMiddleware(server.CreateComment, server.tokenMaker))
-	v1.GET("/comments/:target_id", server.ListComments)
+	v1.GET("/comments/:target_id", optionalAuthMiddleware(server.ListComments, server.tokenMaker))
+	v1.GET("/comments/:id/replies", optionalAuthMiddleware(server.ListReplies, server.tokenMaker))
 	v1.PUT("/comments/:id", authMiddleware(server.UpdateComment, server.tokenMaker))
 	v1.DELETE("/comments/:id", authMiddleware(server.DeleteComment, server.tokenMaker))
 
+	v1.GET("/admin/timeline", authMiddleware(server.permissionMiddleware(server.GetTimelineStats, util.PermissionViewJobs), server.tokenMaker))
+	v1.GET("/admin/timeline/jobs", authMiddleware(server.permissionMiddleware(server.ListTimelineJobs, util.PermissionViewJobs), server.tokenMaker))
+	v1.GET("/admin/purges", authMiddleware(server.permissionMiddleware(server.ListPurgeJobs, util.PermissionViewJobs), server.tokenMaker))
+	v1.PUT("/admin/users/:id/role", authMiddleware(server.permissionMiddleware(server.UpdateUserRole, util.PermissionManageUsers), server.tokenMaker))
+
+	v1.POST("/reports", authMiddleware(server.CreateReport, server.tokenMaker))
+	v1.GET("/moderation/queue", authMiddleware(server.permissionMiddleware(server.ListReportGroups, util.PermissionModerateContent), server.tokenMaker))
+	v1.GET("/moderation/reports/:target_id", authMiddleware(server.permissionMiddleware(server.ListReports, util.PermissionModerateContent), server.tokenMaker))
+	v1.POST("/moderation/reports/:target_id/resolve", authMiddleware(server.permissionMiddleware(server.ResolveReports, util.PermissionModerateContent), server.tokenMaker))
+	v1.GET("/moderation/actions", authMiddleware(server.permissionMiddleware(server.ListModerationActions, util.PermissionModerateContent), server.tokenMaker))
+
+	v1.GET("/sessions", authMiddleware(server.ListSessions, server.tokenMaker))
+	v1.DELETE("/sessions", authMiddleware(server.LogoutEverywhere, server.tokenMaker))
+	v1.DELETE("/sessions/current", authMiddleware(server.Logout, server.tokenMaker))
+	v1.DELETE("/sessions/:id", authMiddleware(server.RevokeSession, server.tokenMaker))
+
 	v1.GET("/token/data", authMiddleware(server.GetTokenData, server.tokenMaker))
 	v1.POST("/token/refresh", server.RefreshToken)
 
@@ -0,0 +1,180 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	db "github.com/DMV-Nicolas/robotgram/backend/db/mongo"
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type sessionResponse struct {
+	ID         primitive.ObjectID `json:"id"`
+	Device     string             `json:"device"`
+	UserAgent  string             `json:"user_agent"`
+	ClientIP   string             `json:"client_ip"`
+	Current    bool               `json:"current"`
+	LastUsedAt time.Time          `json:"last_used_at"`
+	CreatedAt  time.Time          `json:"created_at"`
+	ExpiresAt  time.Time          `json:"expires_at"`
+}
+
+func newSessionResponse(session db.Session, currentID primitive.ObjectID) sessionResponse {
+	return sessionResponse{
+		ID:         session.ID,
+		Device:     deviceName(session.UserAgent),
+		UserAgent:  session.UserAgent,
+		ClientIP:   session.ClientIP,
+		Current:    session.ID == currentID,
+		LastUsedAt: session.LastUsedAt,
+		CreatedAt:  session.CreatedAt,
+		ExpiresAt:  session.ExpiresAt,
+	}
+}
+
+// ListSessions lists the active sessions of the authenticated user
+func (server *Server) ListSessions(c echo.Context) error {
+	payload, err := getAuthorizationPayload(c)
+	if err != nil {
+		return err
+	}
+
+	sessions, err := server.queries.ListSessions(context.TODO(), payload.UserID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	res := make([]sessionResponse, len(sessions))
+	for i, session := range sessions {
+		res[i] = newSessionResponse(session, payload.SessionID)
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// Logout revokes the session of the authenticated user. Revoking a session stops the refresh tokens of its family,
+// while the access tokens already issued stay valid until they expire, which is why they are short lived
+func (server *Server) Logout(c echo.Context) error {
+	payload, err := getAuthorizationPayload(c)
+	if err != nil {
+		return err
+	}
+
+	result, err := server.queries.BlockSessionFamily(context.TODO(), payload.SessionID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return echo.NewHTTPError(http.StatusNotFound, err)
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// LogoutEverywhere revokes all the sessions of the authenticated user, the current one included
+func (server *Server) LogoutEverywhere(c echo.Context) error {
+	payload, err := getAuthorizationPayload(c)
+	if err != nil {
+		return err
+	}
+
+	arg := db.BlockUserSessionsParams{
+		UserID: payload.UserID,
+	}
+
+	result, err := server.queries.BlockUserSessions(context.TODO(), arg)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+type revokeSessionRequest struct {
+	ID string `param:"id" validate:"required,len=24"`
+}
+
+// RevokeSession revokes one of the sessions of the authenticated user
+func (server *Server) RevokeSession(c echo.Context) error {
+	req := new(revokeSessionRequest)
+	if err := bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	id, err := primitive.ObjectIDFromHex(req.ID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	session, err := server.queries.GetSession(context.TODO(), id)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return echo.NewHTTPError(http.StatusNotFound, err)
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	payload, err := getAuthorizationPayload(c)
+	if err != nil {
+		return err
+	}
+
+	if session.UserID != payload.UserID {
+		err = errors.New("session doesn't belong to the authenticated user")
+		return echo.NewHTTPError(http.StatusUnauthorized, err)
+	}
+
+	result, err := server.queries.BlockSessionFamily(context.TODO(), session.ID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// deviceName describes the browser and the operating system of the user agent, like "Firefox on Linux"
+func deviceName(userAgent string) string {
+	if userAgent == "" {
+		return "Unknown device"
+	}
+
+	browsers := []struct{ token, name string }{
+		{"Edg/", "Edge"},
+		{"OPR/", "Opera"},
+		{"Firefox/", "Firefox"},
+		{"Chrome/", "Chrome"},
+		{"Safari/", "Safari"},
+		{"curl/", "curl"},
+	}
+
+	systems := []struct{ token, name string }{
+		{"Android", "Android"},
+		{"iPhone", "iOS"},
+		{"iPad", "iPadOS"},
+		{"Windows", "Windows"},
+		{"Mac OS X", "macOS"},
+		{"CrOS", "ChromeOS"},
+		{"Linux", "Linux"},
+	}
+
+	browser := "Unknown browser"
+	for _, b := range browsers {
+		if strings.Contains(userAgent, b.token) {
+			browser = b.name
+			break
+		}
+	}
+
+	for _, s := range systems {
+		if strings.Contains(userAgent, s.token) {
+			return browser + " on " + s.name
+		}
+	}
+
+	return browser
+}
@@ -0,0 +1,332 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/DMV-Nicolas/robotgram/backend/realtime"
+	"github.com/DMV-Nicolas/robotgram/backend/token"
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// accessTokenQueryKey carries the access token of the stream requests, because the browsers
+	// can't set headers when opening a WebSocket or an EventSource
+	accessTokenQueryKey = "access_token"
+
+	// maxStreamTopics is the number of targets a connection can be subscribed to at once
+	maxStreamTopics = 50
+
+	defaultPingInterval = 30 * time.Second
+	streamWriteTimeout  = 10 * time.Second
+)
+
+// The actions a WebSocket client can send
+const (
+	streamActionSubscribe   = "subscribe"
+	streamActionUnsubscribe = "unsubscribe"
+)
+
+var errTooManyTopics = fmt.Errorf("a stream can't be subscribed to more than %d topics", maxStreamTopics)
+
+// streamAuthMiddleware works like authMiddleware, also taking the access token from the query
+func streamAuthMiddleware(next echo.HandlerFunc, tokenMaker token.Maker) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		request := c.Request()
+		accessToken := c.QueryParam(accessTokenQueryKey)
+		if accessToken != "" && request.Header.Get(authorizationHeaderKey) == "" {
+			request.Header.Set(authorizationHeaderKey, authorizationTypeBearer+" "+accessToken)
+		}
+
+		return authMiddleware(next, tokenMaker)(c)
+	}
+}
+
+// parseTargetTopic checks that the topic is the topic of a post or a comment and returns its target,
+// the user topics can only be received by their own user
+func parseTargetTopic(topic string) (primitive.ObjectID, error) {
+	hex, ok := strings.CutPrefix(topic, "target:")
+	if !ok {
+		return primitive.NilObjectID, fmt.Errorf("invalid topic: %s", topic)
+	}
+
+	targetID, err := primitive.ObjectIDFromHex(hex)
+	if err != nil {
+		return primitive.NilObjectID, fmt.Errorf("invalid topic: %s", topic)
+	}
+
+	return targetID, nil
+}
+
+// topicError tells the client why it can't subscribe to the topic, leaving out the status code
+// of the check that failed
+func topicError(topic string, err error) error {
+	if httpErr, ok := err.(*echo.HTTPError); ok && httpErr.Code == http.StatusInternalServerError {
+		return fmt.Errorf("cannot subscribe to %s, try again later", topic)
+	}
+
+	return fmt.Errorf("cannot view topic: %s", topic)
+}
+
+func (server *Server) pingInterval() time.Duration {
+	if server.config.RealtimePingInterval <= 0 {
+		return defaultPingInterval
+	}
+	return server.config.RealtimePingInterval
+}
+
+// subscribeStream opens the subscription of the stream of the user
+func (server *Server) subscribeStream(userID primitive.ObjectID) realtime.Subscription {
+	sub := server.broker.Subscribe()
+	sub.Add(realtime.UserTopic(userID))
+
+	return sub
+}
+
+type streamMessage struct {
+	Action string   `json:"action"`
+	Topics []string `json:"topics"`
+}
+
+var upgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" || origin == "http://"+r.Host || origin == "https://"+r.Host {
+			return true
+		}
+
+		for _, allowed := range allowedOrigins {
+			if origin == allowed {
+				return true
+			}
+		}
+
+		return false
+	},
+}
+
+// StreamWebSocket pushes the events of the user over a WebSocket. The notifications of the user are
+// always received, and the client subscribes to the posts and comments it is viewing by sending
+// {"action": "subscribe", "topics": ["target:<id>"]}, or "unsubscribe" when they leave the screen.
+// The topics of the targets the user can't view are refused with an error message.
+// The connection is closed with the try again later code when the client can't keep up with its events
+func (server *Server) StreamWebSocket(c echo.Context) error {
+	payload, err := getAuthorizationPayload(c)
+	if err != nil {
+		return err
+	}
+
+	sub := server.subscribeStream(payload.UserID)
+	defer sub.Close()
+
+	conn, err := upgrader.Upgrade(c.Response(), c.Request(), nil)
+	if err != nil {
+		// the upgrader already answered the request
+		return nil
+	}
+	defer conn.Close()
+
+	pingInterval := server.pingInterval()
+	conn.SetReadLimit(4096)
+	conn.SetReadDeadline(time.Now().Add(2 * pingInterval))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(2 * pingInterval))
+	})
+
+	// the messages of the client are read apart, the writes stay on this goroutine
+	done := make(chan struct{})
+	replies := make(chan error, 1)
+	go func() {
+		defer close(done)
+		topics := make(map[string]bool)
+
+		for {
+			var msg streamMessage
+			if err := conn.ReadJSON(&msg); err != nil {
+				return
+			}
+
+			err := server.handleStreamMessage(sub, payload.UserID, msg, topics)
+			select {
+			case replies <- err:
+			default:
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case event, ok := <-sub.Events():
+			if !ok {
+				closeStream(conn, websocket.CloseTryAgainLater, "too many pending events")
+				return nil
+			}
+
+			conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+			if err := conn.WriteJSON(event); err != nil {
+				return nil
+			}
+		case err := <-replies:
+			if err != nil {
+				conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+				if err := conn.WriteJSON(echo.Map{"error": err.Error()}); err != nil {
+					return nil
+				}
+			}
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(streamWriteTimeout)); err != nil {
+				return nil
+			}
+		case <-done:
+			return nil
+		}
+	}
+}
+
+// handleStreamMessage applies the message of the client to its subscription, topics holds the
+// target topics it is subscribed to
+func (server *Server) handleStreamMessage(sub realtime.Subscription, viewerID primitive.ObjectID, msg streamMessage, topics map[string]bool) error {
+	targetIDs := make([]primitive.ObjectID, len(msg.Topics))
+	for i, topic := range msg.Topics {
+		var err error
+		targetIDs[i], err = parseTargetTopic(topic)
+		if err != nil {
+			return err
+		}
+	}
+
+	switch msg.Action {
+	case streamActionSubscribe:
+		added := make(map[string]primitive.ObjectID)
+		for _, targetID := range targetIDs {
+			topic := realtime.TargetTopic(targetID)
+			if !topics[topic] {
+				added[topic] = targetID
+			}
+		}
+
+		if len(topics)+len(added) > maxStreamTopics {
+			return errTooManyTopics
+		}
+
+		for topic, targetID := range added {
+			if err := server.checkCanViewTarget(viewerID, targetID); err != nil {
+				return topicError(topic, err)
+			}
+		}
+
+		for topic := range added {
+			topics[topic] = true
+			sub.Add(topic)
+		}
+	case streamActionUnsubscribe:
+		for _, targetID := range targetIDs {
+			topic := realtime.TargetTopic(targetID)
+			if topics[topic] {
+				delete(topics, topic)
+				sub.Remove(topic)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown action: %s", msg.Action)
+	}
+
+	return nil
+}
+
+func closeStream(conn *websocket.Conn, code int, text string) {
+	msg := websocket.FormatCloseMessage(code, text)
+	conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(streamWriteTimeout))
+}
+
+type streamSSERequest struct {
+	Topics string `query:"topics"`
+}
+
+// StreamSSE pushes the events of the user with server-sent events, for the clients that can't use
+// WebSockets. The topics of the posts and comments being viewed are given at once in the topics query,
+// separated by commas, and changing them means reconnecting. Every target must be viewable by the user
+func (server *Server) StreamSSE(c echo.Context) error {
+	req := new(streamSSERequest)
+	if err := bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	payload, err := getAuthorizationPayload(c)
+	if err != nil {
+		return err
+	}
+
+	targets := make(map[string]primitive.ObjectID)
+	if req.Topics != "" {
+		for _, topic := range strings.Split(req.Topics, ",") {
+			targetID, err := parseTargetTopic(topic)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, err)
+			}
+			targets[realtime.TargetTopic(targetID)] = targetID
+		}
+	}
+
+	if len(targets) > maxStreamTopics {
+		return echo.NewHTTPError(http.StatusBadRequest, errTooManyTopics)
+	}
+
+	topics := make([]string, 0, len(targets))
+	for topic, targetID := range targets {
+		if err = server.checkCanViewTarget(payload.UserID, targetID); err != nil {
+			return err
+		}
+		topics = append(topics, topic)
+	}
+
+	sub := server.subscribeStream(payload.UserID)
+	defer sub.Close()
+	sub.Add(topics...)
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set(echo.HeaderConnection, "keep-alive")
+	res.WriteHeader(http.StatusOK)
+	res.Flush()
+
+	ticker := time.NewTicker(server.pingInterval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case event, ok := <-sub.Events():
+			if !ok {
+				// the client reconnects by itself
+				return nil
+			}
+
+			data, err := json.Marshal(event)
+			if err != nil {
+				return nil
+			}
+
+			_, err = fmt.Fprintf(res, "event: %s\ndata: %s\n\n", event.Type, data)
+			if err != nil {
+				return nil
+			}
+			res.Flush()
+		case <-ticker.C:
+			if _, err := fmt.Fprint(res, ": ping\n\n"); err != nil {
+				return nil
+			}
+			res.Flush()
+		case <-c.Request().Context().Done():
+			return nil
+		}
+	}
+}
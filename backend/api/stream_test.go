@@ -0,0 +1,228 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	mockdb "github.com/DMV-Nicolas/robotgram/backend/db/mock"
+	db "github.com/DMV-Nicolas/robotgram/backend/db/mongo"
+	"github.com/DMV-Nicolas/robotgram/backend/realtime"
+	"github.com/DMV-Nicolas/robotgram/backend/token"
+	"github.com/DMV-Nicolas/robotgram/backend/util"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func newTestStreamServer(t *testing.T) (*Server, *httptest.Server, *mockdb.MockQuerier) {
+	ctrl := gomock.NewController(t)
+	querier := mockdb.NewMockQuerier(ctrl)
+	server := newTestServer(t, querier, util.RandomPassword(32))
+
+	httpServer := httptest.NewServer(server.router)
+	t.Cleanup(httpServer.Close)
+
+	return server, httpServer, querier
+}
+
+func streamURL(t *testing.T, server *Server, baseURL, path string, userID primitive.ObjectID, query url.Values) string {
+	accessToken, _, err := server.tokenMaker.CreateToken(token.KindAccess, userID, primitive.NewObjectID(), util.RoleUser, time.Minute)
+	require.NoError(t, err)
+
+	query.Set(accessTokenQueryKey, accessToken)
+	return baseURL + path + "?" + query.Encode()
+}
+
+// waitForTopic waits until the hub of the server has a subscription to the topic
+func waitForTopic(t *testing.T, server *Server, topic string) {
+	hub := server.broker.(*realtime.Hub)
+	require.Eventually(t, func() bool {
+		return hub.Topics()[topic] > 0
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestStreamWebSocketAPI(t *testing.T) {
+	server, httpServer, querier := newTestStreamServer(t)
+	wsURL := "ws" + strings.TrimPrefix(httpServer.URL, "http")
+	userID := util.RandomID()
+	targetID := util.RandomID()
+	post := db.Post{ID: targetID, UserID: util.RandomID()}
+	removedPost := db.Post{ID: util.RandomID(), UserID: util.RandomID(), Removed: true}
+
+	_, res, err := websocket.DefaultDialer.Dial(wsURL+"/v1/stream", nil)
+	require.Error(t, err)
+	require.Equal(t, http.StatusUnauthorized, res.StatusCode)
+
+	conn, _, err := websocket.DefaultDialer.Dial(streamURL(t, server, wsURL, "/v1/stream", userID, url.Values{}), nil)
+	require.NoError(t, err)
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+
+	// the notifications of the user are received without subscribing
+	waitForTopic(t, server, realtime.UserTopic(userID))
+	server.broker.Publish(realtime.Event{Topic: realtime.UserTopic(userID), Type: realtime.EventNotification, Data: "hi"})
+
+	var event realtime.Event
+	require.NoError(t, conn.ReadJSON(&event))
+	require.Equal(t, realtime.Event{Topic: realtime.UserTopic(userID), Type: realtime.EventNotification, Data: "hi"}, event)
+
+	// the topics of other users can't be subscribed to
+	err = conn.WriteJSON(streamMessage{Action: streamActionSubscribe, Topics: []string{realtime.UserTopic(util.RandomID())}})
+	require.NoError(t, err)
+
+	var reply map[string]string
+	require.NoError(t, conn.ReadJSON(&reply))
+	require.Contains(t, reply["error"], "invalid topic")
+
+	// neither can the topics of the targets the user can't view
+	querier.EXPECT().GetPost(gomock.Any(), gomock.Eq("_id"), gomock.Eq(removedPost.ID)).Times(1).Return(removedPost, nil)
+	err = conn.WriteJSON(streamMessage{Action: streamActionSubscribe, Topics: []string{realtime.TargetTopic(removedPost.ID)}})
+	require.NoError(t, err)
+
+	require.NoError(t, conn.ReadJSON(&reply))
+	require.Equal(t, "cannot view topic: "+realtime.TargetTopic(removedPost.ID), reply["error"])
+
+	expectVisiblePost(querier, post, userID)
+	err = conn.WriteJSON(streamMessage{Action: streamActionSubscribe, Topics: []string{realtime.TargetTopic(targetID)}})
+	require.NoError(t, err)
+	waitForTopic(t, server, realtime.TargetTopic(targetID))
+
+	server.broker.Publish(realtime.Event{Topic: realtime.TargetTopic(targetID), Type: realtime.EventLikeCount, Data: float64(3)})
+	require.NoError(t, conn.ReadJSON(&event))
+	require.Equal(t, realtime.Event{Topic: realtime.TargetTopic(targetID), Type: realtime.EventLikeCount, Data: float64(3)}, event)
+
+	// closing the connection ends the subscriptions
+	conn.Close()
+	require.Eventually(t, func() bool {
+		return len(server.broker.(*realtime.Hub).Topics()) == 0
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestHandleStreamMessage(t *testing.T) {
+	server, _, querier := newTestStreamServer(t)
+	viewerID := util.RandomID()
+	post := db.Post{ID: util.RandomID(), UserID: viewerID}
+	topic := realtime.TargetTopic(post.ID)
+
+	sub := server.broker.Subscribe()
+	defer sub.Close()
+	topics := make(map[string]bool)
+
+	// the target is checked once, subscribing to it again counts it once
+	querier.EXPECT().GetPost(gomock.Any(), gomock.Eq("_id"), gomock.Eq(post.ID)).Times(1).Return(post, nil)
+	for i := 0; i <= maxStreamTopics; i++ {
+		err := server.handleStreamMessage(sub, viewerID, streamMessage{Action: streamActionSubscribe, Topics: []string{topic, topic}}, topics)
+		require.NoError(t, err)
+	}
+	require.Equal(t, map[string]bool{topic: true}, topics)
+
+	// unsubscribing from a topic that wasn't subscribed doesn't free a place
+	other := realtime.TargetTopic(util.RandomID())
+	err := server.handleStreamMessage(sub, viewerID, streamMessage{Action: streamActionUnsubscribe, Topics: []string{other}}, topics)
+	require.NoError(t, err)
+	require.Len(t, topics, 1)
+
+	err = server.handleStreamMessage(sub, viewerID, streamMessage{Action: streamActionUnsubscribe, Topics: []string{topic, topic}}, topics)
+	require.NoError(t, err)
+	require.Empty(t, topics)
+	require.Zero(t, server.broker.(*realtime.Hub).Topics()[topic])
+
+	tooMany := make([]string, maxStreamTopics+1)
+	for i := range tooMany {
+		tooMany[i] = realtime.TargetTopic(util.RandomID())
+	}
+	err = server.handleStreamMessage(sub, viewerID, streamMessage{Action: streamActionSubscribe, Topics: tooMany}, topics)
+	require.ErrorIs(t, err, errTooManyTopics)
+	require.Empty(t, topics)
+}
+
+func TestStreamWebSocketLagging(t *testing.T) {
+	server, httpServer, _ := newTestStreamServer(t)
+	wsURL := "ws" + strings.TrimPrefix(httpServer.URL, "http")
+	userID := util.RandomID()
+
+	conn, _, err := websocket.DefaultDialer.Dial(streamURL(t, server, wsURL, "/v1/stream", userID, url.Values{}), nil)
+	require.NoError(t, err)
+	defer conn.Close()
+	waitForTopic(t, server, realtime.UserTopic(userID))
+
+	// the client doesn't read while many more events than the buffer are published
+	for i := 0; i < 10000; i++ {
+		server.broker.Publish(realtime.Event{Topic: realtime.UserTopic(userID), Type: realtime.EventNotification, Data: i})
+	}
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		_, _, err = conn.ReadMessage()
+		if err != nil {
+			break
+		}
+	}
+	require.True(t, websocket.IsCloseError(err, websocket.CloseTryAgainLater))
+}
+
+func TestStreamSSEAPI(t *testing.T) {
+	server, httpServer, querier := newTestStreamServer(t)
+	userID := util.RandomID()
+	targetID := util.RandomID()
+	post := db.Post{ID: targetID, UserID: util.RandomID()}
+	removedPost := db.Post{ID: util.RandomID(), UserID: util.RandomID(), Removed: true}
+
+	res, err := http.Get(streamURL(t, server, httpServer.URL, "/v1/stream/sse", userID, url.Values{"topics": {"user:" + userID.Hex()}}))
+	require.NoError(t, err)
+	res.Body.Close()
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	querier.EXPECT().GetPost(gomock.Any(), gomock.Eq("_id"), gomock.Eq(removedPost.ID)).Times(1).Return(removedPost, nil)
+	res, err = http.Get(streamURL(t, server, httpServer.URL, "/v1/stream/sse", userID, url.Values{"topics": {realtime.TargetTopic(removedPost.ID)}}))
+	require.NoError(t, err)
+	res.Body.Close()
+	require.Equal(t, http.StatusNotFound, res.StatusCode)
+
+	expectVisiblePost(querier, post, userID)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	query := url.Values{"topics": {realtime.TargetTopic(targetID)}}
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, streamURL(t, server, httpServer.URL, "/v1/stream/sse", userID, query), nil)
+	require.NoError(t, err)
+
+	res, err = http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+
+	waitForTopic(t, server, realtime.UserTopic(userID))
+	waitForTopic(t, server, realtime.TargetTopic(targetID))
+
+	event := realtime.Event{Topic: realtime.TargetTopic(targetID), Type: realtime.EventComment, Data: "hi"}
+	server.broker.Publish(event)
+
+	reader := bufio.NewReader(res.Body)
+	line, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, "event: comment\n", line)
+
+	line, err = reader.ReadString('\n')
+	require.NoError(t, err)
+
+	var gotEvent realtime.Event
+	require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &gotEvent))
+	require.Equal(t, event, gotEvent)
+
+	// the subscriptions end with the request
+	cancel()
+	require.Eventually(t, func() bool {
+		return len(server.broker.(*realtime.Hub).Topics()) == 0
+	}, time.Second, 10*time.Millisecond)
+}
@@ -6,7 +6,10 @@ import (
 	"net/http"
 	"time"
 
+	db "github.com/DMV-Nicolas/robotgram/backend/db/mongo"
+	"github.com/DMV-Nicolas/robotgram/backend/token"
 	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
@@ -15,10 +18,16 @@ type refreshTokenRequest struct {
 }
 
 type refreshTokenResponse struct {
-	AccessToken          string    `json:"access_token"`
-	AccessTokenExpiresAt time.Time `json:"access_token_expires_at"`
+	SessionID             string    `json:"session_id"`
+	AccessToken           string    `json:"access_token"`
+	AccessTokenExpiresAt  time.Time `json:"access_token_expires_at"`
+	RefreshToken          string    `json:"refresh_token"`
+	RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at"`
 }
 
+// RefreshToken rotates the refresh token: the session is replaced by a new one of the same family and
+// the refresh token can't be used again. A reused refresh token means it was probably stolen, so the
+// whole family is blocked, logging out both the thief and the user
 func (server *Server) RefreshToken(c echo.Context) error {
 	req := new(refreshTokenRequest)
 	if err := bindAndValidate(c, req); err != nil {
@@ -30,7 +39,12 @@ func (server *Server) RefreshToken(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, err)
 	}
 
-	session, err := server.queries.GetSession(context.TODO(), refreshPayload.ID)
+	if refreshPayload.Kind != token.KindRefresh {
+		err := errors.New("the token is not a refresh token")
+		return echo.NewHTTPError(http.StatusUnauthorized, err)
+	}
+
+	session, err := server.queries.GetSession(context.TODO(), refreshPayload.SessionID)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return echo.NewHTTPError(http.StatusNotFound, err)
@@ -53,19 +67,75 @@ func (server *Server) RefreshToken(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusUnauthorized, err)
 	}
 
-	accessToken, accessPayload, err := server.tokenMaker.CreateToken(session.UserID, server.config.AccessTokenDuration)
+	if !session.RotatedAt.IsZero() {
+		return server.refreshTokenReused(session)
+	}
+
+	// the role is read again so a promotion or a demotion applies on the next refresh
+	user, err := server.queries.GetUser(context.TODO(), "_id", session.UserID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return echo.NewHTTPError(http.StatusNotFound, err)
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	if !user.SuspendedAt.IsZero() {
+		err := errors.New("the account is suspended")
+		return echo.NewHTTPError(http.StatusForbidden, err)
+	}
+
+	sessionID := primitive.NewObjectID()
+
+	refreshToken, refreshPayload, err := server.tokenMaker.CreateToken(token.KindRefresh, session.UserID, sessionID, user.Role, server.config.RefreshTokenDuration)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	arg := db.RotateSessionParams{
+		ID:           session.ID,
+		NewID:        sessionID,
+		RefreshToken: refreshToken,
+		UserAgent:    c.Request().UserAgent(),
+		ClientIP:     c.RealIP(),
+		ExpiresAt:    refreshPayload.ExpiresAt,
+	}
+
+	_, err = server.queries.RotateSession(context.TODO(), arg)
+	if err != nil {
+		if err == db.ErrSessionRotated {
+			// another request rotated it first
+			return server.refreshTokenReused(session)
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	accessToken, accessPayload, err := server.tokenMaker.CreateToken(token.KindAccess, session.UserID, sessionID, user.Role, server.config.AccessTokenDuration)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err)
 	}
 
 	res := refreshTokenResponse{
-		AccessToken:          accessToken,
-		AccessTokenExpiresAt: accessPayload.ExpiresAt,
+		SessionID:             sessionID.Hex(),
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  accessPayload.ExpiresAt,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: refreshPayload.ExpiresAt,
 	}
 
 	return c.JSON(http.StatusOK, res)
 }
 
+func (server *Server) refreshTokenReused(session db.Session) error {
+	_, err := server.queries.BlockSessionFamily(context.TODO(), session.ID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	err = errors.New("refresh token reused")
+	return echo.NewHTTPError(http.StatusUnauthorized, err)
+}
+
 func (server *Server) GetTokenData(c echo.Context) error {
 	payload, err := getAuthorizationPayload(c)
 	if err != nil {
@@ -24,10 +24,18 @@ func TestRefreshTokenAPI(t *testing.T) {
 	require.NoError(t, err)
 
 	user, _ := randomUser(t)
+	moderator := user
+	moderator.Role = util.RoleModerator
+	suspended := user
+	suspended.SuspendedAt = time.Now()
 	session := randomSession(t, user.ID, time.Minute, false, maker)
 	expiredSession := randomSession(t, user.ID, -time.Minute, false, maker)
 	pepitoSession := randomSession(t, primitive.NewObjectID(), time.Minute, false, maker)
 	blockedSession := randomSession(t, user.ID, time.Minute, true, maker)
+	rotatedSession := randomSession(t, user.ID, time.Minute, false, maker)
+	rotatedSession.RotatedAt = time.Now()
+	accessToken, _, err := maker.CreateToken(token.KindAccess, user.ID, session.ID, util.RoleUser, time.Minute)
+	require.NoError(t, err)
 
 	testCases := []struct {
 		name          string
@@ -45,9 +53,142 @@ func TestRefreshTokenAPI(t *testing.T) {
 					GetSession(gomock.Any(), gomock.Eq(session.ID)).
 					Times(1).
 					Return(session, nil)
+				querier.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq("_id"), gomock.Eq(user.ID)).
+					Times(1).
+					Return(moderator, nil)
+				querier.EXPECT().
+					RotateSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.RotateSessionParams) (db.Session, error) {
+						require.Equal(t, session.ID, arg.ID)
+						require.NotEqual(t, session.ID, arg.NewID)
+						require.NotEqual(t, session.RefreshToken, arg.RefreshToken)
+
+						payload, err := maker.VerifyToken(arg.RefreshToken)
+						require.NoError(t, err)
+						require.Equal(t, arg.NewID, payload.SessionID)
+						require.Equal(t, user.ID, payload.UserID)
+						require.Equal(t, util.RoleModerator, payload.Role)
+
+						return db.Session{ID: arg.NewID, UserID: user.ID, FamilyID: session.ID, ParentID: session.ID}, nil
+					})
+				querier.EXPECT().BlockSessionFamily(gomock.Any(), gomock.Any()).Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchRefreshToken(t, recorder.Body, session, maker)
+			},
+		},
+		{
+			name: "ReusedToken",
+			body: map[string]any{
+				"refresh_token": rotatedSession.RefreshToken,
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetSession(gomock.Any(), gomock.Eq(rotatedSession.ID)).
+					Times(1).
+					Return(rotatedSession, nil)
+				querier.EXPECT().RotateSession(gomock.Any(), gomock.Any()).Times(0)
+				querier.EXPECT().
+					BlockSessionFamily(gomock.Any(), gomock.Eq(rotatedSession.ID)).
+					Times(1).
+					Return(&mongo.UpdateResult{MatchedCount: 2, ModifiedCount: 2}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "ConcurrentRotation",
+			body: map[string]any{
+				"refresh_token": session.RefreshToken,
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetSession(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(session, nil)
+				querier.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq("_id"), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				querier.EXPECT().
+					RotateSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Session{}, db.ErrSessionRotated)
+				querier.EXPECT().
+					BlockSessionFamily(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(&mongo.UpdateResult{MatchedCount: 2, ModifiedCount: 2}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "RotateInternalError",
+			body: map[string]any{
+				"refresh_token": session.RefreshToken,
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetSession(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(session, nil)
+				querier.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq("_id"), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				querier.EXPECT().
+					RotateSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Session{}, mongo.ErrClientDisconnected)
+				querier.EXPECT().BlockSessionFamily(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name: "Suspended",
+			body: map[string]any{
+				"refresh_token": session.RefreshToken,
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetSession(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(session, nil)
+				querier.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq("_id"), gomock.Eq(user.ID)).
+					Times(1).
+					Return(suspended, nil)
+				querier.EXPECT().RotateSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "UserNotFound",
+			body: map[string]any{
+				"refresh_token": session.RefreshToken,
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetSession(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(session, nil)
+				querier.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq("_id"), gomock.Eq(user.ID)).
+					Times(1).
+					Return(db.User{}, mongo.ErrNoDocuments)
+				querier.EXPECT().RotateSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
 			},
 		},
 		{
@@ -139,6 +280,20 @@ func TestRefreshTokenAPI(t *testing.T) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
 		},
+		{
+			name: "AccessToken",
+			body: map[string]any{
+				"refresh_token": accessToken,
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
 		{
 			name: "NoToken",
 			body: map[string]any{
@@ -225,15 +380,15 @@ func TestGetTokenDataAPI(t *testing.T) {
 }
 
 func randomSession(t *testing.T, userID primitive.ObjectID, duration time.Duration, isBlocked bool, tokenMaker token.Maker) db.Session {
-
-	refreshToken, refreshPayload, err := tokenMaker.CreateToken(userID, duration)
+	refreshToken, refreshPayload, err := tokenMaker.CreateToken(token.KindRefresh, userID, primitive.NewObjectID(), util.RoleUser, duration)
 	require.NoError(t, err)
 	require.NotEmpty(t, refreshToken)
 	require.NotEmpty(t, refreshPayload)
 
 	return db.Session{
-		ID:           refreshPayload.ID,
+		ID:           refreshPayload.SessionID,
 		UserID:       userID,
+		FamilyID:     refreshPayload.SessionID,
 		RefreshToken: refreshToken,
 		UserAgent:    "",
 		ClientIP:     "",
@@ -241,3 +396,23 @@ func randomSession(t *testing.T, userID primitive.ObjectID, duration time.Durati
 		ExpiresAt:    refreshPayload.ExpiresAt,
 	}
 }
+
+func requireBodyMatchRefreshToken(t *testing.T, body *bytes.Buffer, session db.Session, tokenMaker token.Maker) {
+	var res refreshTokenResponse
+	err := json.NewDecoder(body).Decode(&res)
+	require.NoError(t, err)
+
+	require.NotEqual(t, session.ID.Hex(), res.SessionID)
+	require.NotEqual(t, session.RefreshToken, res.RefreshToken)
+
+	accessPayload, err := tokenMaker.VerifyToken(res.AccessToken)
+	require.NoError(t, err)
+	require.Equal(t, res.SessionID, accessPayload.SessionID.Hex())
+	require.Equal(t, session.UserID, accessPayload.UserID)
+	require.WithinDuration(t, accessPayload.ExpiresAt, res.AccessTokenExpiresAt, time.Second)
+
+	refreshPayload, err := tokenMaker.VerifyToken(res.RefreshToken)
+	require.NoError(t, err)
+	require.Equal(t, res.SessionID, refreshPayload.SessionID.Hex())
+	require.WithinDuration(t, refreshPayload.ExpiresAt, res.RefreshTokenExpiresAt, time.Second)
+}
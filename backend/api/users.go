@@ -2,10 +2,12 @@ package api
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"time"
 
 	db "github.com/DMV-Nicolas/robotgram/backend/db/mongo"
+	"github.com/DMV-Nicolas/robotgram/backend/token"
 	"github.com/DMV-Nicolas/robotgram/backend/util"
 	"github.com/labstack/echo/v4"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -17,7 +19,6 @@ type createUserRequest struct {
 	Password string `json:"password" validate:"required,min=8"`
 	FullName string `json:"full_name" validate:"required"`
 	Email    string `json:"email" validate:"required,email"`
-	Avatar   string `json:"avatar" validate:"required"`
 	Gender   string `json:"gender" validate:"required,oneof=male female"`
 }
 
@@ -37,7 +38,6 @@ func (server *Server) CreateUser(c echo.Context) error {
 		HashedPassword: hashedPassword,
 		FullName:       req.FullName,
 		Email:          req.Email,
-		Avatar:         req.Avatar,
 		Gender:         req.Gender,
 	}
 
@@ -92,24 +92,32 @@ func (server *Server) LoginUser(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusUnauthorized, err)
 	}
 
-	accessToken, accessPayload, err := server.tokenMaker.CreateToken(user.ID, server.config.AccessTokenDuration)
+	if !user.SuspendedAt.IsZero() {
+		err := errors.New("the account is suspended")
+		return echo.NewHTTPError(http.StatusForbidden, err)
+	}
+
+	// both tokens belong to the session created below
+	sessionID := primitive.NewObjectID()
+
+	accessToken, accessPayload, err := server.tokenMaker.CreateToken(token.KindAccess, user.ID, sessionID, user.Role, server.config.AccessTokenDuration)
 	if err != nil {
 		// impossible
 		return echo.NewHTTPError(http.StatusInternalServerError, err)
 	}
 
-	refreshToken, refreshPayload, err := server.tokenMaker.CreateToken(user.ID, server.config.RefreshTokenDuration)
+	refreshToken, refreshPayload, err := server.tokenMaker.CreateToken(token.KindRefresh, user.ID, sessionID, user.Role, server.config.RefreshTokenDuration)
 	if err != nil {
 		// impossible
 		return echo.NewHTTPError(http.StatusInternalServerError, err)
 	}
 
 	arg := db.CreateSessionParams{
-		ID:           refreshPayload.ID,
+		ID:           sessionID,
 		UserID:       refreshPayload.UserID,
 		RefreshToken: refreshToken,
-		UserAgent:    "",
-		ClientIP:     "",
+		UserAgent:    c.Request().UserAgent(),
+		ClientIP:     c.RealIP(),
 		IsBlocked:    false,
 		ExpiresAt:    refreshPayload.ExpiresAt,
 	}
@@ -120,7 +128,7 @@ func (server *Server) LoginUser(c echo.Context) error {
 	}
 
 	res := loginUserResponse{
-		SessionID:             refreshPayload.ID.Hex(),
+		SessionID:             sessionID.Hex(),
 		AccessToken:           accessToken,
 		AccessTokenExpiresAt:  accessPayload.ExpiresAt,
 		RefreshToken:          refreshToken,
@@ -142,6 +150,8 @@ type getUserResponse struct {
 	Avatar      string             `json:"avatar"`
 	Description string             `json:"description"`
 	Gender      string             `json:"gender"`
+	Followers   int64              `json:"followers"`
+	Following   int64              `json:"following"`
 	CreatedAt   time.Time          `json:"created_at"`
 }
 
@@ -164,6 +174,30 @@ func (server *Server) GetUser(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, err)
 	}
 
+	// the accounts in their deletion grace period are hidden
+	if !user.PurgeAt.IsZero() {
+		return echo.NewHTTPError(http.StatusNotFound, mongo.ErrNoDocuments)
+	}
+
+	viewerID, err := getViewerID(c)
+	if err != nil {
+		return err
+	}
+
+	if err = server.checkNotBlocked(viewerID, user.ID); err != nil {
+		return err
+	}
+
+	nFollowers, err := server.queries.CountFollowers(context.TODO(), user.ID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	nFollowing, err := server.queries.CountFollowing(context.TODO(), user.ID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
 	res := getUserResponse{
 		ID:          user.ID,
 		Username:    user.Username,
@@ -172,6 +206,8 @@ func (server *Server) GetUser(c echo.Context) error {
 		Avatar:      user.Avatar,
 		Description: user.Description,
 		Gender:      user.Gender,
+		Followers:   nFollowers,
+		Following:   nFollowing,
 		CreatedAt:   user.CreatedAt,
 	}
 
@@ -201,3 +237,205 @@ func (server *Server) ListUsers(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, users)
 }
+
+type updateUserRequest struct {
+	ID              string  `param:"id" validate:"required,len=24"`
+	FullName        *string `json:"full_name" validate:"omitempty,min=1"`
+	Description     *string `json:"description" validate:"omitempty,max=500"`
+	Gender          *string `json:"gender" validate:"omitempty,oneof=male female"`
+	AvatarID        *string `json:"avatar_id" validate:"omitempty,len=24"`
+	Password        *string `json:"password" validate:"omitempty,min=8"`
+	CurrentPassword string  `json:"current_password"`
+	IsPrivate       *bool   `json:"is_private"`
+}
+
+// UpdateUser changes only the fields present in the request. Changing the password requires
+// the current one and revokes every other session of the user
+func (server *Server) UpdateUser(c echo.Context) error {
+	req := new(updateUserRequest)
+	if err := bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	if req.FullName == nil && req.Description == nil && req.Gender == nil && req.AvatarID == nil && req.Password == nil && req.IsPrivate == nil {
+		return echo.NewHTTPError(http.StatusBadRequest, db.ErrNothingToUpdate)
+	}
+
+	user, payload, err := server.ownUser(c, req.ID)
+	if err != nil {
+		return err
+	}
+
+	arg := db.UpdateUserParams{
+		ID:          user.ID,
+		FullName:    req.FullName,
+		Description: req.Description,
+		Gender:      req.Gender,
+		IsPrivate:   req.IsPrivate,
+	}
+
+	if req.AvatarID != nil {
+		media, err := server.ownedMedia(payload.UserID, []string{*req.AvatarID})
+		if err != nil {
+			return err
+		}
+
+		avatar := renditionURL(media[0], "thumbnail")
+		arg.AvatarID = &media[0].ID
+		arg.Avatar = &avatar
+	}
+
+	if req.Password != nil {
+		if req.CurrentPassword == "" {
+			err = errors.New("the current password is required to change the password")
+			return echo.NewHTTPError(http.StatusUnauthorized, err)
+		}
+
+		if err := util.CheckPassword(req.CurrentPassword, user.HashedPassword); err != nil {
+			return echo.NewHTTPError(http.StatusUnauthorized, err)
+		}
+
+		hashedPassword, err := util.HashPassword(*req.Password)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+		arg.HashedPassword = &hashedPassword
+	}
+
+	result, err := server.queries.UpdateUser(context.TODO(), arg)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	if req.Password != nil {
+		_, err = server.queries.BlockUserSessions(context.TODO(), db.BlockUserSessionsParams{
+			UserID:   user.ID,
+			ExceptID: payload.SessionID,
+		})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+type deleteUserRequest struct {
+	ID string `param:"id" validate:"required,len=24"`
+}
+
+// DeleteUser schedules the deletion of the account, which is run in background once the grace period
+// is over. Until then the account is hidden along with its posts, comments and follows, its sessions are
+// revoked and the deletion can be canceled
+func (server *Server) DeleteUser(c echo.Context) error {
+	req := new(deleteUserRequest)
+	if err := bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	user, _, err := server.ownUser(c, req.ID)
+	if err != nil {
+		return err
+	}
+
+	arg := db.SchedulePurgeParams{
+		UserID:     user.ID,
+		PurgeAfter: time.Now().Add(server.config.PurgeGracePeriod),
+	}
+
+	job, err := server.queries.SchedulePurge(context.TODO(), arg)
+	if err != nil {
+		if err == db.ErrPurgeScheduled {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusAccepted, job)
+}
+
+// GetUserDeletion reports the progress of the deletion of the account
+func (server *Server) GetUserDeletion(c echo.Context) error {
+	req := new(deleteUserRequest)
+	if err := bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	user, _, err := server.ownUser(c, req.ID)
+	if err != nil {
+		return err
+	}
+
+	job, err := server.queries.GetUserPurge(context.TODO(), user.ID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return echo.NewHTTPError(http.StatusNotFound, err)
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, job)
+}
+
+// CancelUserDeletion undoes the deletion of the account while it is in its grace period
+func (server *Server) CancelUserDeletion(c echo.Context) error {
+	req := new(deleteUserRequest)
+	if err := bindAndValidate(c, req); err != nil {
+		return err
+	}
+
+	user, _, err := server.ownUser(c, req.ID)
+	if err != nil {
+		return err
+	}
+
+	result, err := server.queries.CancelPurge(context.TODO(), user.ID)
+	if err != nil {
+		if err == db.ErrPurgeNotCancelable {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+func (server *Server) validUser(c echo.Context, idStr string) (db.User, error) {
+	id, err := primitive.ObjectIDFromHex(idStr)
+	if err != nil {
+		err = echo.NewHTTPError(http.StatusBadRequest, err)
+		return db.User{}, err
+	}
+
+	user, err := server.queries.GetUser(context.TODO(), "_id", id)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			err = echo.NewHTTPError(http.StatusNotFound, err)
+			return db.User{}, err
+		}
+		err = echo.NewHTTPError(http.StatusInternalServerError, err)
+		return db.User{}, err
+	}
+
+	return user, nil
+}
+
+// ownUser returns the user of the given id if it is the authenticated one
+func (server *Server) ownUser(c echo.Context, idStr string) (db.User, *token.Payload, error) {
+	user, err := server.validUser(c, idStr)
+	if err != nil {
+		return db.User{}, nil, err
+	}
+
+	payload, err := getAuthorizationPayload(c)
+	if err != nil {
+		return db.User{}, nil, err
+	}
+
+	if user.ID != payload.UserID {
+		err = errors.New("account doesn't belong to the authenticated user")
+		return db.User{}, nil, echo.NewHTTPError(http.StatusUnauthorized, err)
+	}
+
+	return user, payload, nil
+}
@@ -12,8 +12,10 @@ import (
 
 	mockdb "github.com/DMV-Nicolas/robotgram/backend/db/mock"
 	db "github.com/DMV-Nicolas/robotgram/backend/db/mongo"
+	"github.com/DMV-Nicolas/robotgram/backend/token"
 	"github.com/DMV-Nicolas/robotgram/backend/util"
 	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -43,6 +45,30 @@ func (e eqCreateUserParamsMatcher) String() string {
 	return fmt.Sprintf("matches arg %v and password %v", e.arg, e.password)
 }
 
+type eqUpdateUserParamsMatcher struct {
+	arg      db.UpdateUserParams
+	password string
+}
+
+func (e eqUpdateUserParamsMatcher) Matches(x interface{}) bool {
+	arg, ok := x.(db.UpdateUserParams)
+	if !ok || arg.HashedPassword == nil {
+		return false
+	}
+
+	err := util.CheckPassword(e.password, *arg.HashedPassword)
+	if err != nil {
+		return false
+	}
+
+	e.arg.HashedPassword = arg.HashedPassword
+	return reflect.DeepEqual(e.arg, arg)
+}
+
+func (e eqUpdateUserParamsMatcher) String() string {
+	return fmt.Sprintf("matches arg %v and password %v", e.arg, e.password)
+}
+
 func TestCreateUserAPI(t *testing.T) {
 	user, password := randomUser(t)
 	result := &mongo.InsertOneResult{InsertedID: user.ID}
@@ -60,7 +86,6 @@ func TestCreateUserAPI(t *testing.T) {
 				"password":  password,
 				"full_name": user.FullName,
 				"email":     user.Email,
-				"avatar":    user.Avatar,
 				"gender":    user.Gender,
 			},
 			buildStubs: func(querier *mockdb.MockQuerier) {
@@ -69,7 +94,6 @@ func TestCreateUserAPI(t *testing.T) {
 					HashedPassword: user.HashedPassword,
 					FullName:       user.FullName,
 					Email:          user.Email,
-					Avatar:         user.Avatar,
 					Gender:         user.Gender,
 				}
 
@@ -90,7 +114,6 @@ func TestCreateUserAPI(t *testing.T) {
 				"password":  password,
 				"full_name": user.FullName,
 				"email":     user.Email,
-				"avatar":    user.Avatar,
 				"gender":    user.Gender,
 			},
 			buildStubs: func(querier *mockdb.MockQuerier) {
@@ -110,7 +133,6 @@ func TestCreateUserAPI(t *testing.T) {
 				"password":  password,
 				"full_name": user.FullName,
 				"email":     user.Email,
-				"avatar":    user.Avatar,
 				"gender":    user.Gender,
 			},
 			buildStubs: func(querier *mockdb.MockQuerier) {
@@ -130,7 +152,6 @@ func TestCreateUserAPI(t *testing.T) {
 				"password":  password,
 				"full_name": user.FullName,
 				"email":     user.Email,
-				"avatar":    user.Avatar,
 				"gender":    user.Gender,
 			},
 			buildStubs: func(querier *mockdb.MockQuerier) {
@@ -150,7 +171,6 @@ func TestCreateUserAPI(t *testing.T) {
 				"password":  util.RandomPassword(100),
 				"full_name": user.FullName,
 				"email":     user.Email,
-				"avatar":    user.Avatar,
 				"gender":    user.Gender,
 			},
 			buildStubs: func(querier *mockdb.MockQuerier) {
@@ -225,7 +245,13 @@ func TestLoginUserAPI(t *testing.T) {
 					Return(user, nil)
 				querier.EXPECT().
 					CreateSession(gomock.Any(), gomock.Any()).
-					Times(1)
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CreateSessionParams) (*mongo.InsertOneResult, error) {
+						require.Equal(t, user.ID, arg.UserID)
+						require.Equal(t, "robotgram-test/1.0", arg.UserAgent)
+						require.Equal(t, "203.0.113.7", arg.ClientIP)
+						return &mongo.InsertOneResult{InsertedID: arg.ID}, nil
+					})
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
@@ -250,6 +276,28 @@ func TestLoginUserAPI(t *testing.T) {
 				require.Equal(t, http.StatusOK, recorder.Code)
 			},
 		},
+		{
+			name: "Suspended",
+			body: map[string]any{
+				"username_or_email": user.Username,
+				"password":          password,
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				suspendedUser := user
+				suspendedUser.SuspendedAt = time.Now()
+
+				querier.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq("username"), gomock.Eq(user.Username)).
+					Times(1).
+					Return(suspendedUser, nil)
+				querier.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
 		{
 			name: "IncorrectPassword",
 			body: map[string]any{
@@ -384,6 +432,8 @@ func TestLoginUserAPI(t *testing.T) {
 			url := "/v1/users/login"
 			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
 			request.Header.Add("Content-Type", "application/json")
+			request.Header.Set("User-Agent", "robotgram-test/1.0")
+			request.Header.Set(echo.HeaderXRealIP, "203.0.113.7")
 			require.NoError(t, err)
 
 			server.router.ServeHTTP(recorder, request)
@@ -394,10 +444,12 @@ func TestLoginUserAPI(t *testing.T) {
 
 func TestGetUserAPI(t *testing.T) {
 	user, _ := randomUser(t)
+	viewer, _ := randomUser(t)
 
 	testCases := []struct {
 		name          string
 		id            any
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
 		buildStubs    func(store *mockdb.MockQuerier)
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
 	}{
@@ -409,10 +461,81 @@ func TestGetUserAPI(t *testing.T) {
 					GetUser(gomock.Any(), gomock.Eq("_id"), gomock.Eq(user.ID)).
 					Times(1).
 					Return(user, nil)
+				querier.EXPECT().
+					CountFollowers(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(int64(10), nil)
+				querier.EXPECT().
+					CountFollowing(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(int64(5), nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
-				requireBodyMatchUser(t, recorder.Body, user)
+				requireBodyMatchUserWithFollows(t, recorder.Body, user, 10, 5)
+			},
+		},
+		{
+			name: "Blocked",
+			id:   user.ID.Hex(),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, viewer.ID, time.Minute)
+			},
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				arg := db.BlockParams{
+					BlockerID: user.ID,
+					BlockedID: viewer.ID,
+				}
+
+				querier.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq("_id"), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				querier.EXPECT().
+					IsBlockedBetween(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(true, nil)
+				querier.EXPECT().CountFollowers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "ScheduledForDeletion",
+			id:   user.ID.Hex(),
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				deletedUser := user
+				deletedUser.PurgeAt = time.Now().Add(time.Hour)
+
+				querier.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq("_id"), gomock.Eq(user.ID)).
+					Times(1).
+					Return(deletedUser, nil)
+				querier.EXPECT().CountFollowers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "CountFollowersInternalError",
+			id:   user.ID.Hex(),
+			buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq("_id"), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				querier.EXPECT().
+					CountFollowers(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), mongo.ErrClientDisconnected)
+				querier.EXPECT().
+					CountFollowing(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
 			},
 		},
 		{
@@ -484,6 +607,9 @@ func TestGetUserAPI(t *testing.T) {
 			request.Header.Add("Content-Type", "application/json")
 			require.NoError(t, err)
 
+			if tc.setupAuth != nil {
+				tc.setupAuth(t, request, server.tokenMaker)
+			}
 			server.router.ServeHTTP(recorder, request)
 			tc.checkResponse(t, recorder)
 		})
@@ -588,6 +714,666 @@ func TestListUsersAPI(t *testing.T) {
 	}
 }
 
+func TestUpdateUserAPI(t *testing.T) {
+	user, password := randomUser(t)
+	media := randomMedia(t, user.ID)
+	result := &mongo.UpdateResult{MatchedCount: 1, ModifiedCount: 1}
+
+	fullName := util.RandomUsername()
+	description := util.RandomDescription(10)
+	avatar := renditionURL(media, "thumbnail")
+	newPassword := util.RandomPassword(16)
+
+	testCases := []struct {
+		name          string
+		id            string
+		body          map[string]any
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockQuerier)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			id:   user.ID.Hex(),
+			body: map[string]any{
+				"full_name":   fullName,
+				"description": description,
+				"avatar_id":   media.ID.Hex(),
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			}, buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq("_id"), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				querier.EXPECT().
+					GetMedia(gomock.Any(), gomock.Eq(media.ID)).
+					Times(1).
+					Return(media, nil)
+
+				arg := db.UpdateUserParams{
+					ID:          user.ID,
+					FullName:    &fullName,
+					Description: &description,
+					AvatarID:    &media.ID,
+					Avatar:      &avatar,
+				}
+
+				querier.EXPECT().
+					UpdateUser(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(result, nil)
+				querier.EXPECT().BlockUserSessions(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchUpdateResult(t, recorder.Body, result)
+			},
+		},
+		{
+			name: "ChangePassword",
+			id:   user.ID.Hex(),
+			body: map[string]any{
+				"password":         newPassword,
+				"current_password": password,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			}, buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq("_id"), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+
+				arg := db.UpdateUserParams{ID: user.ID}
+				querier.EXPECT().
+					UpdateUser(gomock.Any(), eqUpdateUserParamsMatcher{arg, newPassword}).
+					Times(1).
+					Return(result, nil)
+				querier.EXPECT().
+					BlockUserSessions(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.BlockUserSessionsParams) (*mongo.UpdateResult, error) {
+						require.Equal(t, user.ID, arg.UserID)
+						require.NotEqual(t, primitive.NilObjectID, arg.ExceptID)
+						return &mongo.UpdateResult{MatchedCount: 2, ModifiedCount: 2}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchUpdateResult(t, recorder.Body, result)
+			},
+		},
+		{
+			name: "WrongCurrentPassword",
+			id:   user.ID.Hex(),
+			body: map[string]any{
+				"password":         newPassword,
+				"current_password": util.RandomPassword(16),
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			}, buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetUser(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(user, nil)
+				querier.EXPECT().UpdateUser(gomock.Any(), gomock.Any()).Times(0)
+				querier.EXPECT().BlockUserSessions(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "MissingCurrentPassword",
+			id:   user.ID.Hex(),
+			body: map[string]any{
+				"password": newPassword,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			}, buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetUser(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(user, nil)
+				querier.EXPECT().UpdateUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			id:   user.ID.Hex(),
+			body: map[string]any{
+				"full_name": fullName,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			}, buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetUser(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(user, nil)
+				querier.EXPECT().
+					UpdateUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, mongo.ErrClientDisconnected)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name: "NonAccountOwner",
+			id:   user.ID.Hex(),
+			body: map[string]any{
+				"full_name": fullName,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, primitive.NewObjectID(), time.Minute)
+			}, buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetUser(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(user, nil)
+				querier.EXPECT().UpdateUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "NonMediaOwner",
+			id:   user.ID.Hex(),
+			body: map[string]any{
+				"avatar_id": media.ID.Hex(),
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			}, buildStubs: func(querier *mockdb.MockQuerier) {
+				otherMedia := media
+				otherMedia.UserID = primitive.NewObjectID()
+
+				querier.EXPECT().
+					GetUser(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(user, nil)
+				querier.EXPECT().
+					GetMedia(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(otherMedia, nil)
+				querier.EXPECT().UpdateUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "UserNotFound",
+			id:   user.ID.Hex(),
+			body: map[string]any{
+				"full_name": fullName,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			}, buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetUser(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, mongo.ErrNoDocuments)
+				querier.EXPECT().UpdateUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "NothingToUpdate",
+			id:   user.ID.Hex(),
+			body: map[string]any{},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			}, buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().GetUser(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				querier.EXPECT().UpdateUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidGender",
+			id:   user.ID.Hex(),
+			body: map[string]any{
+				"gender": "robot",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			}, buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().GetUser(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				querier.EXPECT().UpdateUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "ShortPassword",
+			id:   user.ID.Hex(),
+			body: map[string]any{
+				"password":         "short",
+				"current_password": password,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			}, buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().GetUser(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				querier.EXPECT().UpdateUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Unauthorized",
+			id:   user.ID.Hex(),
+			body: map[string]any{
+				"full_name": fullName,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			}, buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().GetUser(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				querier.EXPECT().UpdateUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			queries := mockdb.NewMockQuerier(ctrl)
+			tc.buildStubs(queries)
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			server := newTestServer(t, queries, util.RandomPassword(32))
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/v1/users/%s", tc.id)
+			request, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(data))
+			require.NoError(t, err)
+			request.Header.Add("Content-Type", "application/json")
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestDeleteUserAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	job := randomPurgeJob(user.ID, db.PurgeJobScheduled)
+
+	testCases := []struct {
+		name          string
+		id            string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockQuerier)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			id:   user.ID.Hex(),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			}, buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq("_id"), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				querier.EXPECT().
+					SchedulePurge(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.SchedulePurgeParams) (db.PurgeJob, error) {
+						require.Equal(t, user.ID, arg.UserID)
+						require.WithinDuration(t, time.Now().Add(time.Hour), arg.PurgeAfter, time.Second)
+						return job, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+				requireBodyMatchPurgeJob(t, recorder.Body, job)
+			},
+		},
+		{
+			name: "AlreadyScheduled",
+			id:   user.ID.Hex(),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			}, buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetUser(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(user, nil)
+				querier.EXPECT().
+					SchedulePurge(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.PurgeJob{}, db.ErrPurgeScheduled)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			id:   user.ID.Hex(),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			}, buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetUser(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(user, nil)
+				querier.EXPECT().
+					SchedulePurge(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.PurgeJob{}, mongo.ErrClientDisconnected)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name: "NonAccountOwner",
+			id:   user.ID.Hex(),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, primitive.NewObjectID(), time.Minute)
+			}, buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetUser(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(user, nil)
+				querier.EXPECT().SchedulePurge(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "UserNotFound",
+			id:   user.ID.Hex(),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			}, buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetUser(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, mongo.ErrNoDocuments)
+				querier.EXPECT().SchedulePurge(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidID",
+			id:   "invalid-id-invalid-id-:D",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			}, buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().GetUser(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				querier.EXPECT().SchedulePurge(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			queries := mockdb.NewMockQuerier(ctrl)
+			tc.buildStubs(queries)
+
+			server := newTestServer(t, queries, util.RandomPassword(32))
+			server.config.PurgeGracePeriod = time.Hour
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/v1/users/%s", tc.id)
+			request, err := http.NewRequest(http.MethodDelete, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestGetUserDeletionAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	job := randomPurgeJob(user.ID, db.PurgeJobProcessing)
+
+	testCases := []struct {
+		name          string
+		id            string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockQuerier)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			id:   user.ID.Hex(),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			}, buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq("_id"), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				querier.EXPECT().
+					GetUserPurge(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(job, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchPurgeJob(t, recorder.Body, job)
+			},
+		},
+		{
+			name: "NotScheduled",
+			id:   user.ID.Hex(),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			}, buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetUser(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(user, nil)
+				querier.EXPECT().
+					GetUserPurge(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.PurgeJob{}, mongo.ErrNoDocuments)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "NonAccountOwner",
+			id:   user.ID.Hex(),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, primitive.NewObjectID(), time.Minute)
+			}, buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetUser(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(user, nil)
+				querier.EXPECT().GetUserPurge(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			queries := mockdb.NewMockQuerier(ctrl)
+			tc.buildStubs(queries)
+
+			server := newTestServer(t, queries, util.RandomPassword(32))
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/v1/users/%s/deletion", tc.id)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestCancelUserDeletionAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	user.PurgeAt = time.Now().Add(time.Hour)
+	result := &mongo.UpdateResult{MatchedCount: 1, ModifiedCount: 1}
+
+	testCases := []struct {
+		name          string
+		id            string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockQuerier)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			id:   user.ID.Hex(),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			}, buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq("_id"), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				querier.EXPECT().
+					CancelPurge(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(result, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchUpdateResult(t, recorder.Body, result)
+			},
+		},
+		{
+			name: "NotCancelable",
+			id:   user.ID.Hex(),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			}, buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetUser(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(user, nil)
+				querier.EXPECT().
+					CancelPurge(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, db.ErrPurgeNotCancelable)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			id:   user.ID.Hex(),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			}, buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().
+					GetUser(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(user, nil)
+				querier.EXPECT().
+					CancelPurge(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, mongo.ErrClientDisconnected)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name: "Unauthorized",
+			id:   user.ID.Hex(),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			}, buildStubs: func(querier *mockdb.MockQuerier) {
+				querier.EXPECT().GetUser(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				querier.EXPECT().CancelPurge(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			queries := mockdb.NewMockQuerier(ctrl)
+			tc.buildStubs(queries)
+
+			server := newTestServer(t, queries, util.RandomPassword(32))
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/v1/users/%s/deletion", tc.id)
+			request, err := http.NewRequest(http.MethodDelete, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func randomPurgeJob(userID primitive.ObjectID, status string) db.PurgeJob {
+	now := time.Now().Truncate(time.Millisecond)
+	return db.PurgeJob{
+		ID:         primitive.NewObjectID(),
+		UserID:     userID,
+		Status:     status,
+		Step:       db.PurgeStepPosts,
+		Processed:  120,
+		PurgeAfter: now.Add(time.Hour),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+}
+
 func randomUser(t *testing.T) (db.User, string) {
 	password := util.RandomPassword(16)
 	hashedPassword, err := util.HashPassword(password)
@@ -599,7 +1385,7 @@ func randomUser(t *testing.T) (db.User, string) {
 		HashedPassword: hashedPassword,
 		FullName:       util.RandomUsername(),
 		Email:          util.RandomEmail(),
-		Avatar:         util.RandomPassword(10),
+		Avatar:         util.RandomImage(),
 		Description:    util.RandomDescription(10),
 		Gender:         "male",
 		CreatedAt:      time.Now(),
@@ -1,25 +0,0 @@
-// This command works for to drop the database
-package main
-
-import (
-	"context"
-	"fmt"
-	"log"
-
-	"github.com/DMV-Nicolas/robotgram/backend/util"
-	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
-)
-
-func main() {
-	config, err := util.LoadConfig(".")
-	if err != nil {
-		log.Fatal("cannot load config: ", err)
-	}
-
-	uri := fmt.Sprintf("mongodb://%s:%s@%s:%s", config.DBUsername, config.DBPassword, config.DBHost, config.DBPort)
-	client, err := mongo.Connect(context.TODO(), options.Client().ApplyURI(uri))
-	db := client.Database(config.DBName)
-
-	db.Drop(context.TODO())
-}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	db "github.com/DMV-Nicolas/robotgram/backend/db/mongo"
+)
+
+var errNotConfirmed = errors.New("the confirmation doesn't match the database name, nothing was done")
+
+func ensureIndexes(ctx context.Context, a *admin, args []string) error {
+	flags := flag.NewFlagSet("ensure-indexes", flag.ExitOnError)
+	flags.Parse(args)
+
+	if err := db.CreateIndexes(ctx, a.database); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(a.out, "the indexes of %s are up to date\n", a.config.DBName)
+	return nil
+}
+
+func recount(ctx context.Context, a *admin, args []string) error {
+	flags := flag.NewFlagSet("recount", flag.ExitOnError)
+	flags.Parse(args)
+
+	repaired, err := a.queries.RepairCounters(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot repair counters: %w", err)
+	}
+
+	fmt.Fprintf(a.out, "repaired the counters of %d documents\n", repaired)
+
+	repaired, err = a.queries.RepairSearchKeys(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot repair search keys: %w", err)
+	}
+
+	fmt.Fprintf(a.out, "repaired the search keys of %d users\n", repaired)
+
+	repaired, err = a.queries.RepairCommentThreads(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot repair comment threads: %w", err)
+	}
+
+	fmt.Fprintf(a.out, "repaired the threads of %d comments\n", repaired)
+	return nil
+}
+
+func dropDatabase(ctx context.Context, a *admin, args []string) error {
+	flags := flag.NewFlagSet("drop-db", flag.ExitOnError)
+	confirmation := flags.String("confirm", "", "name of the database, to skip the question in scripts")
+	flags.Parse(args)
+
+	if err := a.confirm(*confirmation); err != nil {
+		return err
+	}
+
+	if err := a.database.Drop(ctx); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(a.out, "dropped %s\n", a.config.DBName)
+	return nil
+}
+
+func resetDatabase(ctx context.Context, a *admin, args []string) error {
+	flags := flag.NewFlagSet("reset-db", flag.ExitOnError)
+	confirmation := flags.String("confirm", "", "name of the database, to skip the question in scripts")
+	flags.Parse(args)
+
+	if err := a.confirm(*confirmation); err != nil {
+		return err
+	}
+
+	if err := a.database.Drop(ctx); err != nil {
+		return err
+	}
+
+	if err := db.CreateIndexes(ctx, a.database); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(a.out, "reset %s, it is empty with its indexes\n", a.config.DBName)
+	return nil
+}
+
+// confirm guards the destructive subcommands, which only go on once the name of the database
+// is given, either by the flag or typed when asked
+func (a *admin) confirm(confirmation string) error {
+	if a.config.DBName == "" {
+		return errors.New("no database name is configured")
+	}
+
+	if confirmation == "" {
+		fmt.Fprintf(a.out, "every document of %s will be deleted, type its name to confirm: ", a.config.DBName)
+
+		line, err := bufio.NewReader(a.in).ReadString('\n')
+		if err != nil && line == "" {
+			return errNotConfirmed
+		}
+		confirmation = strings.TrimSpace(line)
+	}
+
+	if confirmation != a.config.DBName {
+		return errNotConfirmed
+	}
+
+	return nil
+}
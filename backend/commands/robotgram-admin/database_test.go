@@ -0,0 +1,45 @@
+package main
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/DMV-Nicolas/robotgram/backend/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfirm(t *testing.T) {
+	testCases := []struct {
+		name         string
+		dbName       string
+		confirmation string
+		input        string
+		confirmed    bool
+	}{
+		{name: "Flag", dbName: "robotgram", confirmation: "robotgram", confirmed: true},
+		{name: "WrongFlag", dbName: "robotgram", confirmation: "robotgram_test", input: "robotgram\n"},
+		{name: "Typed", dbName: "robotgram", input: "robotgram\n", confirmed: true},
+		{name: "TypedWithoutNewline", dbName: "robotgram", input: "robotgram", confirmed: true},
+		{name: "WrongTyped", dbName: "robotgram", input: "yes\n"},
+		{name: "NothingTyped", dbName: "robotgram", input: ""},
+		{name: "NoDatabaseName", dbName: "", input: "\n"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			a := &admin{
+				config: util.Config{DBName: tc.dbName},
+				in:     strings.NewReader(tc.input),
+				out:    io.Discard,
+			}
+
+			err := a.confirm(tc.confirmation)
+			if tc.confirmed {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+// robotgram-admin administers the users and the database of robotgram from the terminal.
+// Run it without arguments to list its subcommands, and with a subcommand and -h to list its flags
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	db "github.com/DMV-Nicolas/robotgram/backend/db/mongo"
+	"github.com/DMV-Nicolas/robotgram/backend/util"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// admin holds what the subcommands work with
+type admin struct {
+	config   util.Config
+	database *mongo.Database
+	queries  db.Querier
+	in       io.Reader
+	out      io.Writer
+}
+
+type command struct {
+	name        string
+	description string
+	run         func(ctx context.Context, a *admin, args []string) error
+}
+
+var commands = []command{
+	{"create-user", "create a user, optionally with a role other than user", createUser},
+	{"promote", "change the role of a user", promoteUser},
+	{"suspend", "suspend a user and block its sessions", suspendUser},
+	{"reset-password", "set a new password for a user and block its sessions", resetPassword},
+	{"revoke-sessions", "block every session of a user", revokeSessions},
+	{"ensure-indexes", "create the missing indexes of the database", ensureIndexes},
+	{"recount", "recompute the counters, the search keys and the comment threads that drifted", recount},
+	{"drop-db", "drop the database, asking for its name first", dropDatabase},
+	{"reset-db", "drop the database and create its indexes again, asking for its name first", resetDatabase},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	cmd, found := findCommand(os.Args[1])
+	if !found {
+		fmt.Fprintf(os.Stderr, "unknown subcommand %q\n\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+
+	config, err := util.LoadConfig(".")
+	if err != nil {
+		log.Fatal("cannot load config: ", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	uri := fmt.Sprintf("mongodb://%s:%s@%s:%s", config.DBUsername, config.DBPassword, config.DBHost, config.DBPort)
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		log.Fatal("cannot connect to database: ", err)
+	}
+	defer client.Disconnect(context.Background())
+
+	// the connection is lazy, fail here rather than halfway through a subcommand
+	if err = client.Ping(ctx, nil); err != nil {
+		log.Fatal("cannot connect to database: ", err)
+	}
+
+	database := client.Database(config.DBName)
+	a := &admin{
+		config:   config,
+		database: database,
+		queries:  db.NewQuerier(database, nil),
+		in:       os.Stdin,
+		out:      os.Stdout,
+	}
+
+	if err = cmd.run(ctx, a, os.Args[2:]); err != nil {
+		log.Fatalf("%s: %v", cmd.name, err)
+	}
+}
+
+func findCommand(name string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+
+	return command{}, false
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: robotgram-admin <subcommand> [flags]")
+	fmt.Fprintln(os.Stderr, "\nsubcommands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-16s %s\n", cmd.name, cmd.description)
+	}
+}
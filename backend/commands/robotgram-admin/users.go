@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+
+	db "github.com/DMV-Nicolas/robotgram/backend/db/mongo"
+	"github.com/DMV-Nicolas/robotgram/backend/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func createUser(ctx context.Context, a *admin, args []string) error {
+	flags := flag.NewFlagSet("create-user", flag.ExitOnError)
+	username := flags.String("username", "", "username of the user")
+	email := flags.String("email", "", "email of the user")
+	password := flags.String("password", "", "password of the user, at least 8 characters")
+	fullName := flags.String("full-name", "", "full name of the user, the username by default")
+	gender := flags.String("gender", "male", "gender of the user, male or female")
+	role := flags.String("role", util.RoleUser, "role of the user: user, moderator or admin")
+	flags.Parse(args)
+
+	if *username == "" {
+		return errors.New("the username is required")
+	}
+	addr, ok := util.ValidMailAddress(*email)
+	if !ok {
+		return fmt.Errorf("invalid email %q", *email)
+	}
+	if len(*password) < 8 {
+		return errors.New("the password must have at least 8 characters")
+	}
+	if *gender != "male" && *gender != "female" {
+		return fmt.Errorf("invalid gender %q", *gender)
+	}
+	if !util.IsSupportedRole(*role) {
+		return fmt.Errorf("unsupported role %q", *role)
+	}
+	if *fullName == "" {
+		*fullName = *username
+	}
+
+	hashedPassword, err := util.HashPassword(*password)
+	if err != nil {
+		return err
+	}
+
+	arg := db.CreateUserParams{
+		Username:       *username,
+		HashedPassword: hashedPassword,
+		FullName:       *fullName,
+		Email:          addr,
+		Gender:         *gender,
+	}
+
+	result, err := a.queries.CreateUser(ctx, arg)
+	if err != nil {
+		return err
+	}
+	userID := result.InsertedID.(primitive.ObjectID)
+
+	// the users are always created as regular ones
+	if *role != util.RoleUser {
+		_, err = a.queries.UpdateUser(ctx, db.UpdateUserParams{ID: userID, Role: role})
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(a.out, "created the %s %s with id %s\n", *role, *username, userID.Hex())
+	return nil
+}
+
+func promoteUser(ctx context.Context, a *admin, args []string) error {
+	flags := flag.NewFlagSet("promote", flag.ExitOnError)
+	ref := flags.String("user", "", "id, username or email of the user")
+	role := flags.String("role", util.RoleModerator, "new role of the user: user, moderator or admin")
+	flags.Parse(args)
+
+	if !util.IsSupportedRole(*role) {
+		return fmt.Errorf("unsupported role %q", *role)
+	}
+
+	user, err := findUser(ctx, a.queries, *ref)
+	if err != nil {
+		return err
+	}
+
+	_, err = a.queries.UpdateUser(ctx, db.UpdateUserParams{ID: user.ID, Role: role})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(a.out, "%s is now a %s, its tokens carry the new role once they are refreshed\n", user.Username, *role)
+	return nil
+}
+
+func suspendUser(ctx context.Context, a *admin, args []string) error {
+	flags := flag.NewFlagSet("suspend", flag.ExitOnError)
+	ref := flags.String("user", "", "id, username or email of the user")
+	flags.Parse(args)
+
+	user, err := findUser(ctx, a.queries, *ref)
+	if err != nil {
+		return err
+	}
+
+	if err = a.queries.SuspendUser(ctx, user.ID); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(a.out, "suspended %s\n", user.Username)
+	return nil
+}
+
+func resetPassword(ctx context.Context, a *admin, args []string) error {
+	flags := flag.NewFlagSet("reset-password", flag.ExitOnError)
+	ref := flags.String("user", "", "id, username or email of the user")
+	password := flags.String("password", "", "new password, a random one is generated and printed if empty")
+	flags.Parse(args)
+
+	user, err := findUser(ctx, a.queries, *ref)
+	if err != nil {
+		return err
+	}
+
+	generated := *password == ""
+	if generated {
+		*password, err = generatePassword()
+		if err != nil {
+			return err
+		}
+	}
+	if len(*password) < 8 {
+		return errors.New("the password must have at least 8 characters")
+	}
+
+	hashedPassword, err := util.HashPassword(*password)
+	if err != nil {
+		return err
+	}
+
+	_, err = a.queries.UpdateUser(ctx, db.UpdateUserParams{ID: user.ID, HashedPassword: &hashedPassword})
+	if err != nil {
+		return err
+	}
+
+	// whoever knew the old password must log in again
+	_, err = a.queries.BlockUserSessions(ctx, db.BlockUserSessionsParams{UserID: user.ID})
+	if err != nil {
+		return err
+	}
+
+	if generated {
+		fmt.Fprintf(a.out, "the new password of %s is %s\n", user.Username, *password)
+	} else {
+		fmt.Fprintf(a.out, "changed the password of %s\n", user.Username)
+	}
+	return nil
+}
+
+func revokeSessions(ctx context.Context, a *admin, args []string) error {
+	flags := flag.NewFlagSet("revoke-sessions", flag.ExitOnError)
+	ref := flags.String("user", "", "id, username or email of the user")
+	flags.Parse(args)
+
+	user, err := findUser(ctx, a.queries, *ref)
+	if err != nil {
+		return err
+	}
+
+	result, err := a.queries.BlockUserSessions(ctx, db.BlockUserSessionsParams{UserID: user.ID})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(a.out, "blocked %d sessions of %s\n", result.ModifiedCount, user.Username)
+	return nil
+}
+
+// findUser gets the user by its id, its email or its username, in that order
+func findUser(ctx context.Context, queries db.Querier, ref string) (db.User, error) {
+	if ref == "" {
+		return db.User{}, errors.New("the user is required")
+	}
+
+	var user db.User
+	var err error
+	if id, idErr := primitive.ObjectIDFromHex(ref); idErr == nil {
+		user, err = queries.GetUser(ctx, "_id", id)
+	} else if addr, isMail := util.ValidMailAddress(ref); isMail {
+		user, err = queries.GetUser(ctx, "email", addr)
+	} else {
+		user, err = queries.GetUser(ctx, "username", ref)
+	}
+
+	if err == mongo.ErrNoDocuments {
+		return db.User{}, fmt.Errorf("user %q not found", ref)
+	}
+
+	return user, err
+}
+
+// generatePassword generates a password to be told to the user, so it can't be predicted like the random test values
+func generatePassword() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
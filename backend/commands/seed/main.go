@@ -0,0 +1,164 @@
+// seed fills an empty development database with generated users, follows, posts,
+// comments, replies and likes. The same flags always generate the same dataset, so it can be shared
+// by giving them along with a bug report or a load test
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	db "github.com/DMV-Nicolas/robotgram/backend/db/mongo"
+	"github.com/DMV-Nicolas/robotgram/backend/seed"
+	"github.com/DMV-Nicolas/robotgram/backend/timeline"
+	"github.com/DMV-Nicolas/robotgram/backend/util"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// insertBatchSize is how many documents are sent to the database at once
+const insertBatchSize = 1000
+
+func main() {
+	seedValue := flag.Int64("seed", 1, "seed of the generated dataset")
+	users := flag.Int("users", 100, "number of users")
+	follows := flag.Int("follows", 20, "mean number of accounts followed by each user")
+	posts := flag.Int("posts", 5, "mean number of posts of each user")
+	comments := flag.Int("comments", 3, "mean number of comments of each post")
+	replyRate := flag.Float64("replies", 0.3, "probability of a comment getting one more reply")
+	likes := flag.Int("likes", 10, "mean number of likes of each post")
+	until := flag.String("until", "2024-06-01", "date of the latest activity of the dataset")
+	days := flag.Int("days", 365, "number of days covered by the dataset")
+	password := flag.String("password", "robotgram", "password of every generated user")
+	flag.Parse()
+
+	untilDate, err := time.Parse(time.DateOnly, *until)
+	if err != nil {
+		log.Fatal("invalid until date: ", err)
+	}
+
+	config, err := util.LoadConfig(".")
+	if err != nil {
+		log.Fatal("cannot load config: ", err)
+	}
+
+	ctx := context.Background()
+
+	uri := fmt.Sprintf("mongodb://%s:%s@%s:%s", config.DBUsername, config.DBPassword, config.DBHost, config.DBPort)
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		log.Fatal("cannot connect to database: ", err)
+	}
+	defer client.Disconnect(ctx)
+
+	if err = client.Ping(ctx, nil); err != nil {
+		log.Fatal("cannot connect to database: ", err)
+	}
+
+	database := client.Database(config.DBName)
+	if err = db.CreateIndexes(ctx, database); err != nil {
+		log.Fatal("cannot create database indexes: ", err)
+	}
+
+	// the generated usernames would clash with the ones of a previous run
+	existing, err := database.Collection("users").CountDocuments(ctx, bson.M{})
+	if err != nil {
+		log.Fatal("cannot count users: ", err)
+	}
+	if existing > 0 {
+		log.Fatalf("%s already has %d users, reset it first with robotgram-admin reset-db", config.DBName, existing)
+	}
+
+	hashedPassword, err := util.HashPassword(*password)
+	if err != nil {
+		log.Fatal("cannot hash password: ", err)
+	}
+
+	dataset, err := seed.Generate(seed.Options{
+		Seed:            *seedValue,
+		Users:           *users,
+		FollowsPerUser:  *follows,
+		PostsPerUser:    *posts,
+		CommentsPerPost: *comments,
+		ReplyRate:       *replyRate,
+		LikesPerPost:    *likes,
+		Until:           untilDate,
+		Span:            time.Duration(*days) * 24 * time.Hour,
+		HashedPassword:  hashedPassword,
+	})
+	if err != nil {
+		log.Fatal("cannot generate dataset: ", err)
+	}
+
+	collections := []struct {
+		name string
+		docs []any
+	}{
+		{"users", documents(dataset.Users)},
+		{"follows", documents(dataset.Follows)},
+		{"posts", documents(dataset.Posts)},
+		{"comments", documents(dataset.Comments)},
+		{"likes", documents(dataset.Likes)},
+		{"hashtags", documents(dataset.Hashtags)},
+	}
+
+	for _, collection := range collections {
+		if err = insertAll(ctx, database.Collection(collection.name), collection.docs); err != nil {
+			log.Fatalf("cannot insert %s: %v", collection.name, err)
+		}
+
+		fmt.Printf("inserted %d %s\n", len(collection.docs), collection.name)
+	}
+
+	// the timelines are filled by the worker like after a real follow, leaving out the accounts pulled at read time
+	queries := db.NewQuerier(database, nil)
+	for _, follow := range dataset.Follows {
+		_, err = queries.EnqueueTimelineJob(ctx, db.EnqueueTimelineJobParams{
+			Kind:     db.TimelineJobBackfill,
+			AuthorID: follow.FollowingID,
+			OwnerID:  follow.FollowerID,
+		})
+		if err != nil {
+			log.Fatal("cannot enqueue timeline backfill: ", err)
+		}
+	}
+
+	worker := timeline.NewWorker(queries, config)
+	for {
+		processed, err := worker.ProcessNext(ctx)
+		if err != nil {
+			log.Fatal("cannot backfill timelines: ", err)
+		}
+		if !processed {
+			break
+		}
+	}
+
+	fmt.Printf("filled the timelines of %d follows\n", len(dataset.Follows))
+	fmt.Printf("every user logs in with the password %q, %s for example\n", *password, dataset.Users[0].Username)
+}
+
+func documents[T any](items []T) []any {
+	docs := make([]any, len(items))
+	for i, item := range items {
+		docs[i] = item
+	}
+
+	return docs
+}
+
+func insertAll(ctx context.Context, coll *mongo.Collection, docs []any) error {
+	for start := 0; start < len(docs); start += insertBatchSize {
+		end := min(start+insertBatchSize, len(docs))
+
+		_, err := coll.InsertMany(ctx, docs[start:end])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
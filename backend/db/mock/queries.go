@@ -37,6 +37,51 @@ func (m *MockQuerier) EXPECT() *MockQuerierMockRecorder {
 	return m.recorder
 }
 
+// AddTimelineEntries mocks base method.
+func (m *MockQuerier) AddTimelineEntries(arg0 context.Context, arg1 []db.TimelineEntry) (*mongo.BulkWriteResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddTimelineEntries", arg0, arg1)
+	ret0, _ := ret[0].(*mongo.BulkWriteResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddTimelineEntries indicates an expected call of AddTimelineEntries.
+func (mr *MockQuerierMockRecorder) AddTimelineEntries(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddTimelineEntries", reflect.TypeOf((*MockQuerier)(nil).AddTimelineEntries), arg0, arg1)
+}
+
+// ApproveFollowRequest mocks base method.
+func (m *MockQuerier) ApproveFollowRequest(arg0 context.Context, arg1 db.FollowParams) (*mongo.InsertOneResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApproveFollowRequest", arg0, arg1)
+	ret0, _ := ret[0].(*mongo.InsertOneResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ApproveFollowRequest indicates an expected call of ApproveFollowRequest.
+func (mr *MockQuerierMockRecorder) ApproveFollowRequest(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApproveFollowRequest", reflect.TypeOf((*MockQuerier)(nil).ApproveFollowRequest), arg0, arg1)
+}
+
+// BackfillTimeline mocks base method.
+func (m *MockQuerier) BackfillTimeline(arg0 context.Context, arg1 db.BackfillTimelineParams) (*mongo.BulkWriteResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BackfillTimeline", arg0, arg1)
+	ret0, _ := ret[0].(*mongo.BulkWriteResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BackfillTimeline indicates an expected call of BackfillTimeline.
+func (mr *MockQuerierMockRecorder) BackfillTimeline(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BackfillTimeline", reflect.TypeOf((*MockQuerier)(nil).BackfillTimeline), arg0, arg1)
+}
+
 // BlockSession mocks base method.
 func (m *MockQuerier) BlockSession(arg0 context.Context, arg1 primitive.ObjectID) (*mongo.UpdateResult, error) {
 	m.ctrl.T.Helper()
@@ -52,6 +97,126 @@ func (mr *MockQuerierMockRecorder) BlockSession(arg0, arg1 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockSession", reflect.TypeOf((*MockQuerier)(nil).BlockSession), arg0, arg1)
 }
 
+// BlockSessionFamily mocks base method.
+func (m *MockQuerier) BlockSessionFamily(arg0 context.Context, arg1 primitive.ObjectID) (*mongo.UpdateResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockSessionFamily", arg0, arg1)
+	ret0, _ := ret[0].(*mongo.UpdateResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BlockSessionFamily indicates an expected call of BlockSessionFamily.
+func (mr *MockQuerierMockRecorder) BlockSessionFamily(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockSessionFamily", reflect.TypeOf((*MockQuerier)(nil).BlockSessionFamily), arg0, arg1)
+}
+
+// BlockUser mocks base method.
+func (m *MockQuerier) BlockUser(arg0 context.Context, arg1 db.BlockParams) (db.Block, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockUser", arg0, arg1)
+	ret0, _ := ret[0].(db.Block)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BlockUser indicates an expected call of BlockUser.
+func (mr *MockQuerierMockRecorder) BlockUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockUser", reflect.TypeOf((*MockQuerier)(nil).BlockUser), arg0, arg1)
+}
+
+// BlockUserSessions mocks base method.
+func (m *MockQuerier) BlockUserSessions(arg0 context.Context, arg1 db.BlockUserSessionsParams) (*mongo.UpdateResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockUserSessions", arg0, arg1)
+	ret0, _ := ret[0].(*mongo.UpdateResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BlockUserSessions indicates an expected call of BlockUserSessions.
+func (mr *MockQuerierMockRecorder) BlockUserSessions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockUserSessions", reflect.TypeOf((*MockQuerier)(nil).BlockUserSessions), arg0, arg1)
+}
+
+// CancelPurge mocks base method.
+func (m *MockQuerier) CancelPurge(arg0 context.Context, arg1 primitive.ObjectID) (*mongo.UpdateResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelPurge", arg0, arg1)
+	ret0, _ := ret[0].(*mongo.UpdateResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelPurge indicates an expected call of CancelPurge.
+func (mr *MockQuerierMockRecorder) CancelPurge(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelPurge", reflect.TypeOf((*MockQuerier)(nil).CancelPurge), arg0, arg1)
+}
+
+// ClaimPurgeJob mocks base method.
+func (m *MockQuerier) ClaimPurgeJob(arg0 context.Context) (db.PurgeJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimPurgeJob", arg0)
+	ret0, _ := ret[0].(db.PurgeJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimPurgeJob indicates an expected call of ClaimPurgeJob.
+func (mr *MockQuerierMockRecorder) ClaimPurgeJob(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimPurgeJob", reflect.TypeOf((*MockQuerier)(nil).ClaimPurgeJob), arg0)
+}
+
+// ClaimTimelineJob mocks base method.
+func (m *MockQuerier) ClaimTimelineJob(arg0 context.Context) (db.TimelineJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimTimelineJob", arg0)
+	ret0, _ := ret[0].(db.TimelineJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimTimelineJob indicates an expected call of ClaimTimelineJob.
+func (mr *MockQuerierMockRecorder) ClaimTimelineJob(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimTimelineJob", reflect.TypeOf((*MockQuerier)(nil).ClaimTimelineJob), arg0)
+}
+
+// CountFollowers mocks base method.
+func (m *MockQuerier) CountFollowers(arg0 context.Context, arg1 primitive.ObjectID) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountFollowers", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountFollowers indicates an expected call of CountFollowers.
+func (mr *MockQuerierMockRecorder) CountFollowers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountFollowers", reflect.TypeOf((*MockQuerier)(nil).CountFollowers), arg0, arg1)
+}
+
+// CountFollowing mocks base method.
+func (m *MockQuerier) CountFollowing(arg0 context.Context, arg1 primitive.ObjectID) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountFollowing", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountFollowing indicates an expected call of CountFollowing.
+func (mr *MockQuerierMockRecorder) CountFollowing(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountFollowing", reflect.TypeOf((*MockQuerier)(nil).CountFollowing), arg0, arg1)
+}
+
 // CountLikes mocks base method.
 func (m *MockQuerier) CountLikes(arg0 context.Context, arg1 primitive.ObjectID) (int64, error) {
 	m.ctrl.T.Helper()
@@ -67,6 +232,36 @@ func (mr *MockQuerierMockRecorder) CountLikes(arg0, arg1 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountLikes", reflect.TypeOf((*MockQuerier)(nil).CountLikes), arg0, arg1)
 }
 
+// CountUnreadMessages mocks base method.
+func (m *MockQuerier) CountUnreadMessages(arg0 context.Context, arg1 db.CountUnreadMessagesParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUnreadMessages", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUnreadMessages indicates an expected call of CountUnreadMessages.
+func (mr *MockQuerierMockRecorder) CountUnreadMessages(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUnreadMessages", reflect.TypeOf((*MockQuerier)(nil).CountUnreadMessages), arg0, arg1)
+}
+
+// CountUnreadNotifications mocks base method.
+func (m *MockQuerier) CountUnreadNotifications(arg0 context.Context, arg1 primitive.ObjectID) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUnreadNotifications", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUnreadNotifications indicates an expected call of CountUnreadNotifications.
+func (mr *MockQuerierMockRecorder) CountUnreadNotifications(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUnreadNotifications", reflect.TypeOf((*MockQuerier)(nil).CountUnreadNotifications), arg0, arg1)
+}
+
 // CreateComment mocks base method.
 func (m *MockQuerier) CreateComment(arg0 context.Context, arg1 db.CreateCommentParams) (*mongo.InsertOneResult, error) {
 	m.ctrl.T.Helper()
@@ -82,6 +277,81 @@ func (mr *MockQuerierMockRecorder) CreateComment(arg0, arg1 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateComment", reflect.TypeOf((*MockQuerier)(nil).CreateComment), arg0, arg1)
 }
 
+// CreateConversation mocks base method.
+func (m *MockQuerier) CreateConversation(arg0 context.Context, arg1 db.CreateConversationParams) (db.Conversation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateConversation", arg0, arg1)
+	ret0, _ := ret[0].(db.Conversation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateConversation indicates an expected call of CreateConversation.
+func (mr *MockQuerierMockRecorder) CreateConversation(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateConversation", reflect.TypeOf((*MockQuerier)(nil).CreateConversation), arg0, arg1)
+}
+
+// CreateLike mocks base method.
+func (m *MockQuerier) CreateLike(arg0 context.Context, arg1 db.LikeParams) (db.Like, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateLike", arg0, arg1)
+	ret0, _ := ret[0].(db.Like)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateLike indicates an expected call of CreateLike.
+func (mr *MockQuerierMockRecorder) CreateLike(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateLike", reflect.TypeOf((*MockQuerier)(nil).CreateLike), arg0, arg1)
+}
+
+// CreateMedia mocks base method.
+func (m *MockQuerier) CreateMedia(arg0 context.Context, arg1 db.CreateMediaParams) (*mongo.InsertOneResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateMedia", arg0, arg1)
+	ret0, _ := ret[0].(*mongo.InsertOneResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateMedia indicates an expected call of CreateMedia.
+func (mr *MockQuerierMockRecorder) CreateMedia(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMedia", reflect.TypeOf((*MockQuerier)(nil).CreateMedia), arg0, arg1)
+}
+
+// CreateMessage mocks base method.
+func (m *MockQuerier) CreateMessage(arg0 context.Context, arg1 db.CreateMessageParams) (db.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateMessage", arg0, arg1)
+	ret0, _ := ret[0].(db.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateMessage indicates an expected call of CreateMessage.
+func (mr *MockQuerierMockRecorder) CreateMessage(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMessage", reflect.TypeOf((*MockQuerier)(nil).CreateMessage), arg0, arg1)
+}
+
+// CreateModerationAction mocks base method.
+func (m *MockQuerier) CreateModerationAction(arg0 context.Context, arg1 db.CreateModerationActionParams) (db.ModerationAction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateModerationAction", arg0, arg1)
+	ret0, _ := ret[0].(db.ModerationAction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateModerationAction indicates an expected call of CreateModerationAction.
+func (mr *MockQuerierMockRecorder) CreateModerationAction(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateModerationAction", reflect.TypeOf((*MockQuerier)(nil).CreateModerationAction), arg0, arg1)
+}
+
 // CreatePost mocks base method.
 func (m *MockQuerier) CreatePost(arg0 context.Context, arg1 db.CreatePostParams) (*mongo.InsertOneResult, error) {
 	m.ctrl.T.Helper()
@@ -97,6 +367,21 @@ func (mr *MockQuerierMockRecorder) CreatePost(arg0, arg1 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePost", reflect.TypeOf((*MockQuerier)(nil).CreatePost), arg0, arg1)
 }
 
+// CreateReport mocks base method.
+func (m *MockQuerier) CreateReport(arg0 context.Context, arg1 db.CreateReportParams) (db.Report, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateReport", arg0, arg1)
+	ret0, _ := ret[0].(db.Report)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateReport indicates an expected call of CreateReport.
+func (mr *MockQuerierMockRecorder) CreateReport(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateReport", reflect.TypeOf((*MockQuerier)(nil).CreateReport), arg0, arg1)
+}
+
 // CreateSession mocks base method.
 func (m *MockQuerier) CreateSession(arg0 context.Context, arg1 db.CreateSessionParams) (*mongo.InsertOneResult, error) {
 	m.ctrl.T.Helper()
@@ -142,6 +427,66 @@ func (mr *MockQuerierMockRecorder) DeleteComment(arg0, arg1 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteComment", reflect.TypeOf((*MockQuerier)(nil).DeleteComment), arg0, arg1)
 }
 
+// DeleteFollowRequest mocks base method.
+func (m *MockQuerier) DeleteFollowRequest(arg0 context.Context, arg1 db.FollowParams) (*mongo.DeleteResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFollowRequest", arg0, arg1)
+	ret0, _ := ret[0].(*mongo.DeleteResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteFollowRequest indicates an expected call of DeleteFollowRequest.
+func (mr *MockQuerierMockRecorder) DeleteFollowRequest(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFollowRequest", reflect.TypeOf((*MockQuerier)(nil).DeleteFollowRequest), arg0, arg1)
+}
+
+// DeleteLike mocks base method.
+func (m *MockQuerier) DeleteLike(arg0 context.Context, arg1 db.LikeParams) (*mongo.DeleteResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteLike", arg0, arg1)
+	ret0, _ := ret[0].(*mongo.DeleteResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteLike indicates an expected call of DeleteLike.
+func (mr *MockQuerierMockRecorder) DeleteLike(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteLike", reflect.TypeOf((*MockQuerier)(nil).DeleteLike), arg0, arg1)
+}
+
+// DeleteMedia mocks base method.
+func (m *MockQuerier) DeleteMedia(arg0 context.Context, arg1 primitive.ObjectID) (*mongo.DeleteResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteMedia", arg0, arg1)
+	ret0, _ := ret[0].(*mongo.DeleteResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteMedia indicates an expected call of DeleteMedia.
+func (mr *MockQuerierMockRecorder) DeleteMedia(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMedia", reflect.TypeOf((*MockQuerier)(nil).DeleteMedia), arg0, arg1)
+}
+
+// DeleteMessage mocks base method.
+func (m *MockQuerier) DeleteMessage(arg0 context.Context, arg1 primitive.ObjectID) (*mongo.UpdateResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteMessage", arg0, arg1)
+	ret0, _ := ret[0].(*mongo.UpdateResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteMessage indicates an expected call of DeleteMessage.
+func (mr *MockQuerierMockRecorder) DeleteMessage(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMessage", reflect.TypeOf((*MockQuerier)(nil).DeleteMessage), arg0, arg1)
+}
+
 // DeletePost mocks base method.
 func (m *MockQuerier) DeletePost(arg0 context.Context, arg1 primitive.ObjectID) (*mongo.DeleteResult, error) {
 	m.ctrl.T.Helper()
@@ -187,6 +532,36 @@ func (mr *MockQuerierMockRecorder) DeleteUser(arg0, arg1 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockQuerier)(nil).DeleteUser), arg0, arg1)
 }
 
+// EnqueueTimelineJob mocks base method.
+func (m *MockQuerier) EnqueueTimelineJob(arg0 context.Context, arg1 db.EnqueueTimelineJobParams) (*mongo.InsertOneResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnqueueTimelineJob", arg0, arg1)
+	ret0, _ := ret[0].(*mongo.InsertOneResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnqueueTimelineJob indicates an expected call of EnqueueTimelineJob.
+func (mr *MockQuerierMockRecorder) EnqueueTimelineJob(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnqueueTimelineJob", reflect.TypeOf((*MockQuerier)(nil).EnqueueTimelineJob), arg0, arg1)
+}
+
+// Follow mocks base method.
+func (m *MockQuerier) Follow(arg0 context.Context, arg1 db.FollowParams) (*mongo.InsertOneResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Follow", arg0, arg1)
+	ret0, _ := ret[0].(*mongo.InsertOneResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Follow indicates an expected call of Follow.
+func (mr *MockQuerierMockRecorder) Follow(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Follow", reflect.TypeOf((*MockQuerier)(nil).Follow), arg0, arg1)
+}
+
 // GetComment mocks base method.
 func (m *MockQuerier) GetComment(arg0 context.Context, arg1 primitive.ObjectID) (db.Comment, error) {
 	m.ctrl.T.Helper()
@@ -202,6 +577,36 @@ func (mr *MockQuerierMockRecorder) GetComment(arg0, arg1 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetComment", reflect.TypeOf((*MockQuerier)(nil).GetComment), arg0, arg1)
 }
 
+// GetConversation mocks base method.
+func (m *MockQuerier) GetConversation(arg0 context.Context, arg1 primitive.ObjectID) (db.Conversation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetConversation", arg0, arg1)
+	ret0, _ := ret[0].(db.Conversation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetConversation indicates an expected call of GetConversation.
+func (mr *MockQuerierMockRecorder) GetConversation(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConversation", reflect.TypeOf((*MockQuerier)(nil).GetConversation), arg0, arg1)
+}
+
+// GetHashtag mocks base method.
+func (m *MockQuerier) GetHashtag(arg0 context.Context, arg1 string) (db.Hashtag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHashtag", arg0, arg1)
+	ret0, _ := ret[0].(db.Hashtag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHashtag indicates an expected call of GetHashtag.
+func (mr *MockQuerierMockRecorder) GetHashtag(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHashtag", reflect.TypeOf((*MockQuerier)(nil).GetHashtag), arg0, arg1)
+}
+
 // GetLike mocks base method.
 func (m *MockQuerier) GetLike(arg0 context.Context, arg1 primitive.ObjectID) (db.Like, error) {
 	m.ctrl.T.Helper()
@@ -217,53 +622,158 @@ func (mr *MockQuerierMockRecorder) GetLike(arg0, arg1 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLike", reflect.TypeOf((*MockQuerier)(nil).GetLike), arg0, arg1)
 }
 
-// GetPost mocks base method.
-func (m *MockQuerier) GetPost(arg0 context.Context, arg1 string, arg2 interface{}) (db.Post, error) {
+// GetLikeCount mocks base method.
+func (m *MockQuerier) GetLikeCount(arg0 context.Context, arg1 primitive.ObjectID) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetPost", arg0, arg1, arg2)
-	ret0, _ := ret[0].(db.Post)
+	ret := m.ctrl.Call(m, "GetLikeCount", arg0, arg1)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetPost indicates an expected call of GetPost.
-func (mr *MockQuerierMockRecorder) GetPost(arg0, arg1, arg2 interface{}) *gomock.Call {
+// GetLikeCount indicates an expected call of GetLikeCount.
+func (mr *MockQuerierMockRecorder) GetLikeCount(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPost", reflect.TypeOf((*MockQuerier)(nil).GetPost), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLikeCount", reflect.TypeOf((*MockQuerier)(nil).GetLikeCount), arg0, arg1)
 }
 
-// GetSession mocks base method.
-func (m *MockQuerier) GetSession(arg0 context.Context, arg1 primitive.ObjectID) (db.Session, error) {
+// GetMedia mocks base method.
+func (m *MockQuerier) GetMedia(arg0 context.Context, arg1 primitive.ObjectID) (db.Media, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetSession", arg0, arg1)
-	ret0, _ := ret[0].(db.Session)
+	ret := m.ctrl.Call(m, "GetMedia", arg0, arg1)
+	ret0, _ := ret[0].(db.Media)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetSession indicates an expected call of GetSession.
-func (mr *MockQuerierMockRecorder) GetSession(arg0, arg1 interface{}) *gomock.Call {
+// GetMedia indicates an expected call of GetMedia.
+func (mr *MockQuerierMockRecorder) GetMedia(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSession", reflect.TypeOf((*MockQuerier)(nil).GetSession), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMedia", reflect.TypeOf((*MockQuerier)(nil).GetMedia), arg0, arg1)
 }
 
-// GetUser mocks base method.
-func (m *MockQuerier) GetUser(arg0 context.Context, arg1 string, arg2 interface{}) (db.User, error) {
+// GetMessage mocks base method.
+func (m *MockQuerier) GetMessage(arg0 context.Context, arg1 primitive.ObjectID) (db.Message, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUser", arg0, arg1, arg2)
-	ret0, _ := ret[0].(db.User)
+	ret := m.ctrl.Call(m, "GetMessage", arg0, arg1)
+	ret0, _ := ret[0].(db.Message)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetUser indicates an expected call of GetUser.
-func (mr *MockQuerierMockRecorder) GetUser(arg0, arg1, arg2 interface{}) *gomock.Call {
+// GetMessage indicates an expected call of GetMessage.
+func (mr *MockQuerierMockRecorder) GetMessage(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockQuerier)(nil).GetUser), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMessage", reflect.TypeOf((*MockQuerier)(nil).GetMessage), arg0, arg1)
 }
 
-// IsLiked mocks base method.
-func (m *MockQuerier) IsLiked(arg0 context.Context, arg1 db.IsLikedParams) (db.Like, bool, error) {
+// GetPost mocks base method.
+func (m *MockQuerier) GetPost(arg0 context.Context, arg1 string, arg2 interface{}) (db.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPost", arg0, arg1, arg2)
+	ret0, _ := ret[0].(db.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPost indicates an expected call of GetPost.
+func (mr *MockQuerierMockRecorder) GetPost(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPost", reflect.TypeOf((*MockQuerier)(nil).GetPost), arg0, arg1, arg2)
+}
+
+// GetSession mocks base method.
+func (m *MockQuerier) GetSession(arg0 context.Context, arg1 primitive.ObjectID) (db.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSession", arg0, arg1)
+	ret0, _ := ret[0].(db.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSession indicates an expected call of GetSession.
+func (mr *MockQuerierMockRecorder) GetSession(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSession", reflect.TypeOf((*MockQuerier)(nil).GetSession), arg0, arg1)
+}
+
+// GetTimelineStats mocks base method.
+func (m *MockQuerier) GetTimelineStats(arg0 context.Context) (db.TimelineStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTimelineStats", arg0)
+	ret0, _ := ret[0].(db.TimelineStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTimelineStats indicates an expected call of GetTimelineStats.
+func (mr *MockQuerierMockRecorder) GetTimelineStats(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTimelineStats", reflect.TypeOf((*MockQuerier)(nil).GetTimelineStats), arg0)
+}
+
+// GetUser mocks base method.
+func (m *MockQuerier) GetUser(arg0 context.Context, arg1 string, arg2 interface{}) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUser", arg0, arg1, arg2)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUser indicates an expected call of GetUser.
+func (mr *MockQuerierMockRecorder) GetUser(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockQuerier)(nil).GetUser), arg0, arg1, arg2)
+}
+
+// GetUserPurge mocks base method.
+func (m *MockQuerier) GetUserPurge(arg0 context.Context, arg1 primitive.ObjectID) (db.PurgeJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserPurge", arg0, arg1)
+	ret0, _ := ret[0].(db.PurgeJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserPurge indicates an expected call of GetUserPurge.
+func (mr *MockQuerierMockRecorder) GetUserPurge(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserPurge", reflect.TypeOf((*MockQuerier)(nil).GetUserPurge), arg0, arg1)
+}
+
+// IsBlockedBetween mocks base method.
+func (m *MockQuerier) IsBlockedBetween(arg0 context.Context, arg1 db.BlockParams) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsBlockedBetween", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsBlockedBetween indicates an expected call of IsBlockedBetween.
+func (mr *MockQuerierMockRecorder) IsBlockedBetween(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsBlockedBetween", reflect.TypeOf((*MockQuerier)(nil).IsBlockedBetween), arg0, arg1)
+}
+
+// IsFollowing mocks base method.
+func (m *MockQuerier) IsFollowing(arg0 context.Context, arg1 db.FollowParams) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsFollowing", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsFollowing indicates an expected call of IsFollowing.
+func (mr *MockQuerierMockRecorder) IsFollowing(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsFollowing", reflect.TypeOf((*MockQuerier)(nil).IsFollowing), arg0, arg1)
+}
+
+// IsLiked mocks base method.
+func (m *MockQuerier) IsLiked(arg0 context.Context, arg1 db.IsLikedParams) (db.Like, bool, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "IsLiked", arg0, arg1)
 	ret0, _ := ret[0].(db.Like)
@@ -278,6 +788,21 @@ func (mr *MockQuerierMockRecorder) IsLiked(arg0, arg1 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsLiked", reflect.TypeOf((*MockQuerier)(nil).IsLiked), arg0, arg1)
 }
 
+// ListBlocks mocks base method.
+func (m *MockQuerier) ListBlocks(arg0 context.Context, arg1 db.ListBlocksParams) ([]db.Block, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBlocks", arg0, arg1)
+	ret0, _ := ret[0].([]db.Block)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBlocks indicates an expected call of ListBlocks.
+func (mr *MockQuerierMockRecorder) ListBlocks(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBlocks", reflect.TypeOf((*MockQuerier)(nil).ListBlocks), arg0, arg1)
+}
+
 // ListComments mocks base method.
 func (m *MockQuerier) ListComments(arg0 context.Context, arg1 db.ListCommentsParams) ([]db.Comment, error) {
 	m.ctrl.T.Helper()
@@ -293,6 +818,111 @@ func (mr *MockQuerierMockRecorder) ListComments(arg0, arg1 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListComments", reflect.TypeOf((*MockQuerier)(nil).ListComments), arg0, arg1)
 }
 
+// ListConversations mocks base method.
+func (m *MockQuerier) ListConversations(arg0 context.Context, arg1 db.ListConversationsParams) ([]db.Conversation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListConversations", arg0, arg1)
+	ret0, _ := ret[0].([]db.Conversation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListConversations indicates an expected call of ListConversations.
+func (mr *MockQuerierMockRecorder) ListConversations(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListConversations", reflect.TypeOf((*MockQuerier)(nil).ListConversations), arg0, arg1)
+}
+
+// ListFeed mocks base method.
+func (m *MockQuerier) ListFeed(arg0 context.Context, arg1 db.ListFeedParams) ([]db.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFeed", arg0, arg1)
+	ret0, _ := ret[0].([]db.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFeed indicates an expected call of ListFeed.
+func (mr *MockQuerierMockRecorder) ListFeed(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFeed", reflect.TypeOf((*MockQuerier)(nil).ListFeed), arg0, arg1)
+}
+
+// ListFollowRequests mocks base method.
+func (m *MockQuerier) ListFollowRequests(arg0 context.Context, arg1 db.ListFollowsParams) ([]db.FollowRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFollowRequests", arg0, arg1)
+	ret0, _ := ret[0].([]db.FollowRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFollowRequests indicates an expected call of ListFollowRequests.
+func (mr *MockQuerierMockRecorder) ListFollowRequests(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFollowRequests", reflect.TypeOf((*MockQuerier)(nil).ListFollowRequests), arg0, arg1)
+}
+
+// ListFollowers mocks base method.
+func (m *MockQuerier) ListFollowers(arg0 context.Context, arg1 db.ListFollowsParams) ([]db.Follow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFollowers", arg0, arg1)
+	ret0, _ := ret[0].([]db.Follow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFollowers indicates an expected call of ListFollowers.
+func (mr *MockQuerierMockRecorder) ListFollowers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFollowers", reflect.TypeOf((*MockQuerier)(nil).ListFollowers), arg0, arg1)
+}
+
+// ListFollowersAfter mocks base method.
+func (m *MockQuerier) ListFollowersAfter(arg0 context.Context, arg1 db.ListFollowersAfterParams) ([]db.Follow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFollowersAfter", arg0, arg1)
+	ret0, _ := ret[0].([]db.Follow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFollowersAfter indicates an expected call of ListFollowersAfter.
+func (mr *MockQuerierMockRecorder) ListFollowersAfter(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFollowersAfter", reflect.TypeOf((*MockQuerier)(nil).ListFollowersAfter), arg0, arg1)
+}
+
+// ListFollowing mocks base method.
+func (m *MockQuerier) ListFollowing(arg0 context.Context, arg1 db.ListFollowsParams) ([]db.Follow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFollowing", arg0, arg1)
+	ret0, _ := ret[0].([]db.Follow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFollowing indicates an expected call of ListFollowing.
+func (mr *MockQuerierMockRecorder) ListFollowing(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFollowing", reflect.TypeOf((*MockQuerier)(nil).ListFollowing), arg0, arg1)
+}
+
+// ListHashtagPosts mocks base method.
+func (m *MockQuerier) ListHashtagPosts(arg0 context.Context, arg1 db.ListHashtagPostsParams) ([]db.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListHashtagPosts", arg0, arg1)
+	ret0, _ := ret[0].([]db.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListHashtagPosts indicates an expected call of ListHashtagPosts.
+func (mr *MockQuerierMockRecorder) ListHashtagPosts(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListHashtagPosts", reflect.TypeOf((*MockQuerier)(nil).ListHashtagPosts), arg0, arg1)
+}
+
 // ListLikes mocks base method.
 func (m *MockQuerier) ListLikes(arg0 context.Context, arg1 db.ListLikesParams) ([]db.Like, error) {
 	m.ctrl.T.Helper()
@@ -308,6 +938,81 @@ func (mr *MockQuerierMockRecorder) ListLikes(arg0, arg1 interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListLikes", reflect.TypeOf((*MockQuerier)(nil).ListLikes), arg0, arg1)
 }
 
+// ListMentions mocks base method.
+func (m *MockQuerier) ListMentions(arg0 context.Context, arg1 db.ListMentionsParams) ([]db.Mentioning, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMentions", arg0, arg1)
+	ret0, _ := ret[0].([]db.Mentioning)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMentions indicates an expected call of ListMentions.
+func (mr *MockQuerierMockRecorder) ListMentions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMentions", reflect.TypeOf((*MockQuerier)(nil).ListMentions), arg0, arg1)
+}
+
+// ListMessages mocks base method.
+func (m *MockQuerier) ListMessages(arg0 context.Context, arg1 db.ListMessagesParams) ([]db.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMessages", arg0, arg1)
+	ret0, _ := ret[0].([]db.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMessages indicates an expected call of ListMessages.
+func (mr *MockQuerierMockRecorder) ListMessages(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMessages", reflect.TypeOf((*MockQuerier)(nil).ListMessages), arg0, arg1)
+}
+
+// ListModerationActions mocks base method.
+func (m *MockQuerier) ListModerationActions(arg0 context.Context, arg1 db.ListModerationActionsParams) ([]db.ModerationAction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListModerationActions", arg0, arg1)
+	ret0, _ := ret[0].([]db.ModerationAction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListModerationActions indicates an expected call of ListModerationActions.
+func (mr *MockQuerierMockRecorder) ListModerationActions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListModerationActions", reflect.TypeOf((*MockQuerier)(nil).ListModerationActions), arg0, arg1)
+}
+
+// ListMutes mocks base method.
+func (m *MockQuerier) ListMutes(arg0 context.Context, arg1 db.ListMutesParams) ([]db.Mute, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMutes", arg0, arg1)
+	ret0, _ := ret[0].([]db.Mute)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMutes indicates an expected call of ListMutes.
+func (mr *MockQuerierMockRecorder) ListMutes(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMutes", reflect.TypeOf((*MockQuerier)(nil).ListMutes), arg0, arg1)
+}
+
+// ListNotifications mocks base method.
+func (m *MockQuerier) ListNotifications(arg0 context.Context, arg1 db.ListNotificationsParams) ([]db.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListNotifications", arg0, arg1)
+	ret0, _ := ret[0].([]db.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListNotifications indicates an expected call of ListNotifications.
+func (mr *MockQuerierMockRecorder) ListNotifications(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListNotifications", reflect.TypeOf((*MockQuerier)(nil).ListNotifications), arg0, arg1)
+}
+
 // ListPosts mocks base method.
 func (m *MockQuerier) ListPosts(arg0 context.Context, arg1 db.ListPostsParams) ([]db.Post, error) {
 	m.ctrl.T.Helper()
@@ -323,6 +1028,111 @@ func (mr *MockQuerierMockRecorder) ListPosts(arg0, arg1 interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPosts", reflect.TypeOf((*MockQuerier)(nil).ListPosts), arg0, arg1)
 }
 
+// ListPurgeJobs mocks base method.
+func (m *MockQuerier) ListPurgeJobs(arg0 context.Context, arg1 db.ListPurgeJobsParams) ([]db.PurgeJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPurgeJobs", arg0, arg1)
+	ret0, _ := ret[0].([]db.PurgeJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPurgeJobs indicates an expected call of ListPurgeJobs.
+func (mr *MockQuerierMockRecorder) ListPurgeJobs(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPurgeJobs", reflect.TypeOf((*MockQuerier)(nil).ListPurgeJobs), arg0, arg1)
+}
+
+// ListReplies mocks base method.
+func (m *MockQuerier) ListReplies(arg0 context.Context, arg1 db.ListRepliesParams) ([]db.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListReplies", arg0, arg1)
+	ret0, _ := ret[0].([]db.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListReplies indicates an expected call of ListReplies.
+func (mr *MockQuerierMockRecorder) ListReplies(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListReplies", reflect.TypeOf((*MockQuerier)(nil).ListReplies), arg0, arg1)
+}
+
+// ListReportGroups mocks base method.
+func (m *MockQuerier) ListReportGroups(arg0 context.Context, arg1 db.ListReportGroupsParams) ([]db.ReportGroup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListReportGroups", arg0, arg1)
+	ret0, _ := ret[0].([]db.ReportGroup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListReportGroups indicates an expected call of ListReportGroups.
+func (mr *MockQuerierMockRecorder) ListReportGroups(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListReportGroups", reflect.TypeOf((*MockQuerier)(nil).ListReportGroups), arg0, arg1)
+}
+
+// ListReports mocks base method.
+func (m *MockQuerier) ListReports(arg0 context.Context, arg1 db.ListReportsParams) ([]db.Report, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListReports", arg0, arg1)
+	ret0, _ := ret[0].([]db.Report)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListReports indicates an expected call of ListReports.
+func (mr *MockQuerierMockRecorder) ListReports(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListReports", reflect.TypeOf((*MockQuerier)(nil).ListReports), arg0, arg1)
+}
+
+// ListSessions mocks base method.
+func (m *MockQuerier) ListSessions(arg0 context.Context, arg1 primitive.ObjectID) ([]db.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSessions", arg0, arg1)
+	ret0, _ := ret[0].([]db.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSessions indicates an expected call of ListSessions.
+func (mr *MockQuerierMockRecorder) ListSessions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSessions", reflect.TypeOf((*MockQuerier)(nil).ListSessions), arg0, arg1)
+}
+
+// ListTimelineJobs mocks base method.
+func (m *MockQuerier) ListTimelineJobs(arg0 context.Context, arg1 db.ListTimelineJobsParams) ([]db.TimelineJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTimelineJobs", arg0, arg1)
+	ret0, _ := ret[0].([]db.TimelineJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTimelineJobs indicates an expected call of ListTimelineJobs.
+func (mr *MockQuerierMockRecorder) ListTimelineJobs(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTimelineJobs", reflect.TypeOf((*MockQuerier)(nil).ListTimelineJobs), arg0, arg1)
+}
+
+// ListUserMedia mocks base method.
+func (m *MockQuerier) ListUserMedia(arg0 context.Context, arg1 db.ListUserMediaParams) ([]db.Media, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserMedia", arg0, arg1)
+	ret0, _ := ret[0].([]db.Media)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserMedia indicates an expected call of ListUserMedia.
+func (mr *MockQuerierMockRecorder) ListUserMedia(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserMedia", reflect.TypeOf((*MockQuerier)(nil).ListUserMedia), arg0, arg1)
+}
+
 // ListUsers mocks base method.
 func (m *MockQuerier) ListUsers(arg0 context.Context, arg1 db.ListUsersParams) ([]db.User, error) {
 	m.ctrl.T.Helper()
@@ -338,14 +1148,252 @@ func (mr *MockQuerierMockRecorder) ListUsers(arg0, arg1 interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsers", reflect.TypeOf((*MockQuerier)(nil).ListUsers), arg0, arg1)
 }
 
+// MarkConversationRead mocks base method.
+func (m *MockQuerier) MarkConversationRead(arg0 context.Context, arg1 db.MarkConversationReadParams) (*mongo.UpdateResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkConversationRead", arg0, arg1)
+	ret0, _ := ret[0].(*mongo.UpdateResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkConversationRead indicates an expected call of MarkConversationRead.
+func (mr *MockQuerierMockRecorder) MarkConversationRead(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkConversationRead", reflect.TypeOf((*MockQuerier)(nil).MarkConversationRead), arg0, arg1)
+}
+
+// MarkNotificationsRead mocks base method.
+func (m *MockQuerier) MarkNotificationsRead(arg0 context.Context, arg1 db.MarkNotificationsReadParams) (*mongo.UpdateResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkNotificationsRead", arg0, arg1)
+	ret0, _ := ret[0].(*mongo.UpdateResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkNotificationsRead indicates an expected call of MarkNotificationsRead.
+func (mr *MockQuerierMockRecorder) MarkNotificationsRead(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkNotificationsRead", reflect.TypeOf((*MockQuerier)(nil).MarkNotificationsRead), arg0, arg1)
+}
+
+// MuteUser mocks base method.
+func (m *MockQuerier) MuteUser(arg0 context.Context, arg1 db.MuteParams) (db.Mute, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MuteUser", arg0, arg1)
+	ret0, _ := ret[0].(db.Mute)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MuteUser indicates an expected call of MuteUser.
+func (mr *MockQuerierMockRecorder) MuteUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MuteUser", reflect.TypeOf((*MockQuerier)(nil).MuteUser), arg0, arg1)
+}
+
+// PurgeUserStep mocks base method.
+func (m *MockQuerier) PurgeUserStep(arg0 context.Context, arg1 db.PurgeUserStepParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeUserStep", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeUserStep indicates an expected call of PurgeUserStep.
+func (mr *MockQuerierMockRecorder) PurgeUserStep(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeUserStep", reflect.TypeOf((*MockQuerier)(nil).PurgeUserStep), arg0, arg1)
+}
+
+// RemoveTimelineAuthor mocks base method.
+func (m *MockQuerier) RemoveTimelineAuthor(arg0 context.Context, arg1 db.RemoveTimelineAuthorParams) (*mongo.DeleteResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveTimelineAuthor", arg0, arg1)
+	ret0, _ := ret[0].(*mongo.DeleteResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveTimelineAuthor indicates an expected call of RemoveTimelineAuthor.
+func (mr *MockQuerierMockRecorder) RemoveTimelineAuthor(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveTimelineAuthor", reflect.TypeOf((*MockQuerier)(nil).RemoveTimelineAuthor), arg0, arg1)
+}
+
+// RepairCommentThreads mocks base method.
+func (m *MockQuerier) RepairCommentThreads(arg0 context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RepairCommentThreads", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RepairCommentThreads indicates an expected call of RepairCommentThreads.
+func (mr *MockQuerierMockRecorder) RepairCommentThreads(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RepairCommentThreads", reflect.TypeOf((*MockQuerier)(nil).RepairCommentThreads), arg0)
+}
+
+// RepairCounters mocks base method.
+func (m *MockQuerier) RepairCounters(arg0 context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RepairCounters", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RepairCounters indicates an expected call of RepairCounters.
+func (mr *MockQuerierMockRecorder) RepairCounters(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RepairCounters", reflect.TypeOf((*MockQuerier)(nil).RepairCounters), arg0)
+}
+
+// RepairSearchKeys mocks base method.
+func (m *MockQuerier) RepairSearchKeys(arg0 context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RepairSearchKeys", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RepairSearchKeys indicates an expected call of RepairSearchKeys.
+func (mr *MockQuerierMockRecorder) RepairSearchKeys(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RepairSearchKeys", reflect.TypeOf((*MockQuerier)(nil).RepairSearchKeys), arg0)
+}
+
+// RequestFollow mocks base method.
+func (m *MockQuerier) RequestFollow(arg0 context.Context, arg1 db.FollowParams) (db.FollowRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestFollow", arg0, arg1)
+	ret0, _ := ret[0].(db.FollowRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequestFollow indicates an expected call of RequestFollow.
+func (mr *MockQuerierMockRecorder) RequestFollow(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestFollow", reflect.TypeOf((*MockQuerier)(nil).RequestFollow), arg0, arg1)
+}
+
+// ResolveReports mocks base method.
+func (m *MockQuerier) ResolveReports(arg0 context.Context, arg1 db.ResolveReportsParams) (db.ModerationAction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolveReports", arg0, arg1)
+	ret0, _ := ret[0].(db.ModerationAction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResolveReports indicates an expected call of ResolveReports.
+func (mr *MockQuerierMockRecorder) ResolveReports(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveReports", reflect.TypeOf((*MockQuerier)(nil).ResolveReports), arg0, arg1)
+}
+
+// RotateSession mocks base method.
+func (m *MockQuerier) RotateSession(arg0 context.Context, arg1 db.RotateSessionParams) (db.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateSession", arg0, arg1)
+	ret0, _ := ret[0].(db.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RotateSession indicates an expected call of RotateSession.
+func (mr *MockQuerierMockRecorder) RotateSession(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateSession", reflect.TypeOf((*MockQuerier)(nil).RotateSession), arg0, arg1)
+}
+
+// SchedulePurge mocks base method.
+func (m *MockQuerier) SchedulePurge(arg0 context.Context, arg1 db.SchedulePurgeParams) (db.PurgeJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SchedulePurge", arg0, arg1)
+	ret0, _ := ret[0].(db.PurgeJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SchedulePurge indicates an expected call of SchedulePurge.
+func (mr *MockQuerierMockRecorder) SchedulePurge(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SchedulePurge", reflect.TypeOf((*MockQuerier)(nil).SchedulePurge), arg0, arg1)
+}
+
+// SearchHashtags mocks base method.
+func (m *MockQuerier) SearchHashtags(arg0 context.Context, arg1 db.SearchHashtagsParams) ([]db.Hashtag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchHashtags", arg0, arg1)
+	ret0, _ := ret[0].([]db.Hashtag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchHashtags indicates an expected call of SearchHashtags.
+func (mr *MockQuerierMockRecorder) SearchHashtags(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchHashtags", reflect.TypeOf((*MockQuerier)(nil).SearchHashtags), arg0, arg1)
+}
+
+// SearchPosts mocks base method.
+func (m *MockQuerier) SearchPosts(arg0 context.Context, arg1 db.SearchParams) ([]db.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchPosts", arg0, arg1)
+	ret0, _ := ret[0].([]db.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchPosts indicates an expected call of SearchPosts.
+func (mr *MockQuerierMockRecorder) SearchPosts(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchPosts", reflect.TypeOf((*MockQuerier)(nil).SearchPosts), arg0, arg1)
+}
+
+// SearchUsers mocks base method.
+func (m *MockQuerier) SearchUsers(arg0 context.Context, arg1 db.SearchParams) ([]db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchUsers", arg0, arg1)
+	ret0, _ := ret[0].([]db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchUsers indicates an expected call of SearchUsers.
+func (mr *MockQuerierMockRecorder) SearchUsers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchUsers", reflect.TypeOf((*MockQuerier)(nil).SearchUsers), arg0, arg1)
+}
+
+// SuspendUser mocks base method.
+func (m *MockQuerier) SuspendUser(arg0 context.Context, arg1 primitive.ObjectID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SuspendUser", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SuspendUser indicates an expected call of SuspendUser.
+func (mr *MockQuerierMockRecorder) SuspendUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SuspendUser", reflect.TypeOf((*MockQuerier)(nil).SuspendUser), arg0, arg1)
+}
+
 // ToggleLike mocks base method.
-func (m *MockQuerier) ToggleLike(arg0 context.Context, arg1 db.ToggleLikeParams) (*mongo.InsertOneResult, *mongo.DeleteResult, error) {
+func (m *MockQuerier) ToggleLike(arg0 context.Context, arg1 db.LikeParams) (bool, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "ToggleLike", arg0, arg1)
-	ret0, _ := ret[0].(*mongo.InsertOneResult)
-	ret1, _ := ret[1].(*mongo.DeleteResult)
-	ret2, _ := ret[2].(error)
-	return ret0, ret1, ret2
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // ToggleLike indicates an expected call of ToggleLike.
@@ -354,6 +1402,51 @@ func (mr *MockQuerierMockRecorder) ToggleLike(arg0, arg1 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ToggleLike", reflect.TypeOf((*MockQuerier)(nil).ToggleLike), arg0, arg1)
 }
 
+// UnblockUser mocks base method.
+func (m *MockQuerier) UnblockUser(arg0 context.Context, arg1 db.BlockParams) (*mongo.DeleteResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnblockUser", arg0, arg1)
+	ret0, _ := ret[0].(*mongo.DeleteResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UnblockUser indicates an expected call of UnblockUser.
+func (mr *MockQuerierMockRecorder) UnblockUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnblockUser", reflect.TypeOf((*MockQuerier)(nil).UnblockUser), arg0, arg1)
+}
+
+// Unfollow mocks base method.
+func (m *MockQuerier) Unfollow(arg0 context.Context, arg1 db.FollowParams) (*mongo.DeleteResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unfollow", arg0, arg1)
+	ret0, _ := ret[0].(*mongo.DeleteResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Unfollow indicates an expected call of Unfollow.
+func (mr *MockQuerierMockRecorder) Unfollow(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unfollow", reflect.TypeOf((*MockQuerier)(nil).Unfollow), arg0, arg1)
+}
+
+// UnmuteUser mocks base method.
+func (m *MockQuerier) UnmuteUser(arg0 context.Context, arg1 db.MuteParams) (*mongo.DeleteResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnmuteUser", arg0, arg1)
+	ret0, _ := ret[0].(*mongo.DeleteResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UnmuteUser indicates an expected call of UnmuteUser.
+func (mr *MockQuerierMockRecorder) UnmuteUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnmuteUser", reflect.TypeOf((*MockQuerier)(nil).UnmuteUser), arg0, arg1)
+}
+
 // UpdateComment mocks base method.
 func (m *MockQuerier) UpdateComment(arg0 context.Context, arg1 db.UpdateCommentParams) (*mongo.UpdateResult, error) {
 	m.ctrl.T.Helper()
@@ -369,6 +1462,36 @@ func (mr *MockQuerierMockRecorder) UpdateComment(arg0, arg1 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateComment", reflect.TypeOf((*MockQuerier)(nil).UpdateComment), arg0, arg1)
 }
 
+// UpdateMessage mocks base method.
+func (m *MockQuerier) UpdateMessage(arg0 context.Context, arg1 db.UpdateMessageParams) (*mongo.UpdateResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateMessage", arg0, arg1)
+	ret0, _ := ret[0].(*mongo.UpdateResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateMessage indicates an expected call of UpdateMessage.
+func (mr *MockQuerierMockRecorder) UpdateMessage(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMessage", reflect.TypeOf((*MockQuerier)(nil).UpdateMessage), arg0, arg1)
+}
+
+// UpdateNotificationPreferences mocks base method.
+func (m *MockQuerier) UpdateNotificationPreferences(arg0 context.Context, arg1 db.UpdateNotificationPreferencesParams) (*mongo.UpdateResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateNotificationPreferences", arg0, arg1)
+	ret0, _ := ret[0].(*mongo.UpdateResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateNotificationPreferences indicates an expected call of UpdateNotificationPreferences.
+func (mr *MockQuerierMockRecorder) UpdateNotificationPreferences(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateNotificationPreferences", reflect.TypeOf((*MockQuerier)(nil).UpdateNotificationPreferences), arg0, arg1)
+}
+
 // UpdatePost mocks base method.
 func (m *MockQuerier) UpdatePost(arg0 context.Context, arg1 db.UpdatePostParams) (*mongo.UpdateResult, error) {
 	m.ctrl.T.Helper()
@@ -384,6 +1507,36 @@ func (mr *MockQuerierMockRecorder) UpdatePost(arg0, arg1 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePost", reflect.TypeOf((*MockQuerier)(nil).UpdatePost), arg0, arg1)
 }
 
+// UpdatePurgeJob mocks base method.
+func (m *MockQuerier) UpdatePurgeJob(arg0 context.Context, arg1 db.UpdatePurgeJobParams) (*mongo.UpdateResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePurgeJob", arg0, arg1)
+	ret0, _ := ret[0].(*mongo.UpdateResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdatePurgeJob indicates an expected call of UpdatePurgeJob.
+func (mr *MockQuerierMockRecorder) UpdatePurgeJob(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePurgeJob", reflect.TypeOf((*MockQuerier)(nil).UpdatePurgeJob), arg0, arg1)
+}
+
+// UpdateTimelineJob mocks base method.
+func (m *MockQuerier) UpdateTimelineJob(arg0 context.Context, arg1 db.UpdateTimelineJobParams) (*mongo.UpdateResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTimelineJob", arg0, arg1)
+	ret0, _ := ret[0].(*mongo.UpdateResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateTimelineJob indicates an expected call of UpdateTimelineJob.
+func (mr *MockQuerierMockRecorder) UpdateTimelineJob(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTimelineJob", reflect.TypeOf((*MockQuerier)(nil).UpdateTimelineJob), arg0, arg1)
+}
+
 // UpdateUser mocks base method.
 func (m *MockQuerier) UpdateUser(arg0 context.Context, arg1 db.UpdateUserParams) (*mongo.UpdateResult, error) {
 	m.ctrl.T.Helper()
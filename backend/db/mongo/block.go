@@ -0,0 +1,245 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type BlockParams struct {
+	BlockerID primitive.ObjectID `json:"blocker_id" bson:"blocker_id"`
+	BlockedID primitive.ObjectID `json:"blocked_id" bson:"blocked_id"`
+}
+
+// BlockUser blocks the user and removes the follows and the follow requests between both users
+func (q *Queries) BlockUser(ctx context.Context, arg BlockParams) (Block, error) {
+	if arg.BlockerID == arg.BlockedID {
+		return Block{}, ErrSelfBlock
+	}
+
+	block := Block{
+		ID:        primitive.NewObjectID(),
+		BlockerID: arg.BlockerID,
+		BlockedID: arg.BlockedID,
+		CreatedAt: time.Now(),
+	}
+
+	coll := q.db.Collection("blocks")
+	_, err := coll.InsertOne(ctx, block)
+	if err != nil {
+		if duplicatedIndex(err) == blockIndex {
+			return Block{}, ErrAlreadyBlocked
+		}
+		return Block{}, err
+	}
+
+	follows := []FollowParams{
+		{FollowerID: arg.BlockerID, FollowingID: arg.BlockedID},
+		{FollowerID: arg.BlockedID, FollowingID: arg.BlockerID},
+	}
+	for _, follow := range follows {
+		_, err = q.Unfollow(ctx, follow)
+		if err != nil {
+			return Block{}, err
+		}
+
+		_, err = q.DeleteFollowRequest(ctx, follow)
+		if err != nil {
+			return Block{}, err
+		}
+	}
+
+	return block, nil
+}
+
+func (q *Queries) UnblockUser(ctx context.Context, arg BlockParams) (*mongo.DeleteResult, error) {
+	filter := bson.D{
+		primitive.E{Key: "blocker_id", Value: arg.BlockerID},
+		primitive.E{Key: "blocked_id", Value: arg.BlockedID},
+	}
+
+	coll := q.db.Collection("blocks")
+	return coll.DeleteOne(ctx, filter)
+}
+
+type ListBlocksParams struct {
+	UserID primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Offset int64              `json:"offset" bson:"offset"`
+	Limit  int64              `json:"limit" bson:"limit"`
+}
+
+// ListBlocks lists the users blocked by the given user, the latest first
+func (q *Queries) ListBlocks(ctx context.Context, arg ListBlocksParams) ([]Block, error) {
+	filter := bson.D{primitive.E{Key: "blocker_id", Value: arg.UserID}}
+	opts := options.Find().
+		SetSort(bson.D{primitive.E{Key: "_id", Value: -1}}).
+		SetSkip(arg.Offset).
+		SetLimit(arg.Limit)
+
+	var blocks []Block
+	coll := q.db.Collection("blocks")
+	cursor, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	for cursor.Next(ctx) {
+		var block Block
+		err = cursor.Decode(&block)
+		if err != nil {
+			return nil, err
+		}
+
+		blocks = append(blocks, block)
+	}
+
+	return blocks, nil
+}
+
+// IsBlockedBetween tells if any of the two users blocked the other one
+func (q *Queries) IsBlockedBetween(ctx context.Context, arg BlockParams) (bool, error) {
+	filter := bson.M{
+		"$or": bson.A{
+			bson.M{"blocker_id": arg.BlockerID, "blocked_id": arg.BlockedID},
+			bson.M{"blocker_id": arg.BlockedID, "blocked_id": arg.BlockerID},
+		},
+	}
+
+	coll := q.db.Collection("blocks")
+	n, err := coll.CountDocuments(ctx, filter, options.Count().SetLimit(1))
+	if err != nil {
+		return false, err
+	}
+
+	return n > 0, nil
+}
+
+// blockedUserIDs returns the users that the given user blocked or was blocked by
+func (q *Queries) blockedUserIDs(ctx context.Context, userID primitive.ObjectID) (bson.A, error) {
+	coll := q.db.Collection("blocks")
+	blocked, err := coll.Distinct(ctx, "blocked_id", bson.M{"blocker_id": userID})
+	if err != nil {
+		return nil, err
+	}
+
+	blockers, err := coll.Distinct(ctx, "blocker_id", bson.M{"blocked_id": userID})
+	if err != nil {
+		return nil, err
+	}
+
+	return append(blocked, blockers...), nil
+}
+
+type MuteParams struct {
+	MuterID primitive.ObjectID `json:"muter_id" bson:"muter_id"`
+	MutedID primitive.ObjectID `json:"muted_id" bson:"muted_id"`
+}
+
+// MuteUser hides the posts and the notifications of the user from the muter, without it knowing
+func (q *Queries) MuteUser(ctx context.Context, arg MuteParams) (Mute, error) {
+	if arg.MuterID == arg.MutedID {
+		return Mute{}, ErrSelfMute
+	}
+
+	mute := Mute{
+		ID:        primitive.NewObjectID(),
+		MuterID:   arg.MuterID,
+		MutedID:   arg.MutedID,
+		CreatedAt: time.Now(),
+	}
+
+	coll := q.db.Collection("mutes")
+	_, err := coll.InsertOne(ctx, mute)
+	if err != nil {
+		if duplicatedIndex(err) == muteIndex {
+			return Mute{}, ErrAlreadyMuted
+		}
+		return Mute{}, err
+	}
+
+	return mute, nil
+}
+
+func (q *Queries) UnmuteUser(ctx context.Context, arg MuteParams) (*mongo.DeleteResult, error) {
+	filter := bson.D{
+		primitive.E{Key: "muter_id", Value: arg.MuterID},
+		primitive.E{Key: "muted_id", Value: arg.MutedID},
+	}
+
+	coll := q.db.Collection("mutes")
+	return coll.DeleteOne(ctx, filter)
+}
+
+type ListMutesParams struct {
+	UserID primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Offset int64              `json:"offset" bson:"offset"`
+	Limit  int64              `json:"limit" bson:"limit"`
+}
+
+// ListMutes lists the users muted by the given user, the latest first
+func (q *Queries) ListMutes(ctx context.Context, arg ListMutesParams) ([]Mute, error) {
+	filter := bson.D{primitive.E{Key: "muter_id", Value: arg.UserID}}
+	opts := options.Find().
+		SetSort(bson.D{primitive.E{Key: "_id", Value: -1}}).
+		SetSkip(arg.Offset).
+		SetLimit(arg.Limit)
+
+	var mutes []Mute
+	coll := q.db.Collection("mutes")
+	cursor, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	for cursor.Next(ctx) {
+		var mute Mute
+		err = cursor.Decode(&mute)
+		if err != nil {
+			return nil, err
+		}
+
+		mutes = append(mutes, mute)
+	}
+
+	return mutes, nil
+}
+
+// mutedUserIDs returns the users muted by the given user
+func (q *Queries) mutedUserIDs(ctx context.Context, userID primitive.ObjectID) (bson.A, error) {
+	return q.db.Collection("mutes").Distinct(ctx, "muted_id", bson.M{"muter_id": userID})
+}
+
+// isSilenced tells if the actor can't reach the user, because the user muted it or there is a block between them
+func (q *Queries) isSilenced(ctx context.Context, userID, actorID primitive.ObjectID) (bool, error) {
+	filter := bson.M{"muter_id": userID, "muted_id": actorID}
+	n, err := q.db.Collection("mutes").CountDocuments(ctx, filter, options.Count().SetLimit(1))
+	if err != nil || n > 0 {
+		return n > 0, err
+	}
+
+	return q.IsBlockedBetween(ctx, BlockParams{BlockerID: userID, BlockedID: actorID})
+}
+
+// deleteBlocksAndMutes removes the blocks and the mutes made and received by the user
+func (q *Queries) deleteBlocksAndMutes(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	filters := map[string]bson.M{
+		"blocks": {"$or": bson.A{bson.M{"blocker_id": userID}, bson.M{"blocked_id": userID}}},
+		"mutes":  {"$or": bson.A{bson.M{"muter_id": userID}, bson.M{"muted_id": userID}}},
+	}
+
+	var n int64
+	for collection, filter := range filters {
+		result, err := q.db.Collection(collection).DeleteMany(ctx, filter)
+		if err != nil {
+			return 0, err
+		}
+
+		n += result.DeletedCount
+	}
+
+	return n, nil
+}
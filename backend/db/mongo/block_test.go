@@ -0,0 +1,114 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockUser(t *testing.T) {
+	blocker := randomUser(t)
+	blocked := randomUser(t)
+	randomFollow(t, blocker.ID, blocked.ID)
+	randomFollow(t, blocked.ID, blocker.ID)
+	arg := BlockParams{
+		BlockerID: blocker.ID,
+		BlockedID: blocked.ID,
+	}
+
+	block, err := testQueries.BlockUser(testCtx, arg)
+	require.NoError(t, err)
+	require.Equal(t, blocker.ID, block.BlockerID)
+	require.Equal(t, blocked.ID, block.BlockedID)
+	require.WithinDuration(t, time.Now(), block.CreatedAt, time.Second)
+
+	_, err = testQueries.BlockUser(testCtx, arg)
+	require.ErrorIs(t, err, ErrAlreadyBlocked)
+
+	_, err = testQueries.BlockUser(testCtx, BlockParams{BlockerID: blocker.ID, BlockedID: blocker.ID})
+	require.ErrorIs(t, err, ErrSelfBlock)
+
+	// the block removes the follows in both directions
+	for _, follow := range []FollowParams{
+		{FollowerID: blocker.ID, FollowingID: blocked.ID},
+		{FollowerID: blocked.ID, FollowingID: blocker.ID},
+	} {
+		following, err := testQueries.IsFollowing(testCtx, follow)
+		require.NoError(t, err)
+		require.False(t, following)
+	}
+
+	// and it is seen from both sides
+	for _, check := range []BlockParams{arg, {BlockerID: blocked.ID, BlockedID: blocker.ID}} {
+		isBlocked, err := testQueries.IsBlockedBetween(testCtx, check)
+		require.NoError(t, err)
+		require.True(t, isBlocked)
+	}
+
+	blocks, err := testQueries.ListBlocks(testCtx, ListBlocksParams{UserID: blocker.ID, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, blocks, 1)
+	require.Equal(t, block.ID, blocks[0].ID)
+
+	result, err := testQueries.UnblockUser(testCtx, arg)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), result.DeletedCount)
+
+	isBlocked, err := testQueries.IsBlockedBetween(testCtx, arg)
+	require.NoError(t, err)
+	require.False(t, isBlocked)
+}
+
+func TestBlockedPostsVisibility(t *testing.T) {
+	blocker := randomUser(t)
+	blocked := randomUser(t)
+	post := randomPostByUser(t, blocker.ID)
+
+	_, err := testQueries.BlockUser(testCtx, BlockParams{BlockerID: blocker.ID, BlockedID: blocked.ID})
+	require.NoError(t, err)
+
+	posts, err := testQueries.ListPosts(testCtx, ListPostsParams{UserID: blocker.ID, ViewerID: blocked.ID, Limit: 10})
+	require.NoError(t, err)
+	require.Empty(t, posts)
+
+	posts, err = testQueries.ListPosts(testCtx, ListPostsParams{UserID: blocker.ID, ViewerID: blocker.ID, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, posts, 1)
+	require.Equal(t, post.ID, posts[0].ID)
+}
+
+func TestMuteUser(t *testing.T) {
+	muter := randomUser(t)
+	muted := randomUser(t)
+	arg := MuteParams{
+		MuterID: muter.ID,
+		MutedID: muted.ID,
+	}
+
+	mute, err := testQueries.MuteUser(testCtx, arg)
+	require.NoError(t, err)
+	require.Equal(t, muter.ID, mute.MuterID)
+	require.Equal(t, muted.ID, mute.MutedID)
+
+	_, err = testQueries.MuteUser(testCtx, arg)
+	require.ErrorIs(t, err, ErrAlreadyMuted)
+
+	_, err = testQueries.MuteUser(testCtx, MuteParams{MuterID: muter.ID, MutedID: muter.ID})
+	require.ErrorIs(t, err, ErrSelfMute)
+
+	// the muted user doesn't notify the muter anymore
+	randomFollow(t, muted.ID, muter.ID)
+	notifications, err := testQueries.ListNotifications(testCtx, ListNotificationsParams{UserID: muter.ID, Limit: 10})
+	require.NoError(t, err)
+	require.Empty(t, notifications)
+
+	mutes, err := testQueries.ListMutes(testCtx, ListMutesParams{UserID: muter.ID, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, mutes, 1)
+	require.Equal(t, mute.ID, mutes[0].ID)
+
+	result, err := testQueries.UnmuteUser(testCtx, arg)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), result.DeletedCount)
+}
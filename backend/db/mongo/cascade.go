@@ -0,0 +1,294 @@
+package db
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// findIDs returns the ids of the documents of the collection that match the filter.
+// A limit of 0 returns all of them
+func (q *Queries) findIDs(ctx context.Context, collection string, filter any, limit int64) ([]primitive.ObjectID, error) {
+	opts := options.Find().SetProjection(bson.M{"_id": 1})
+	if limit > 0 {
+		opts.SetLimit(limit)
+	}
+
+	coll := q.db.Collection(collection)
+	cursor, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var ids []primitive.ObjectID
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+		err = cursor.Decode(&doc)
+		if err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, doc.ID)
+	}
+
+	return ids, cursor.Err()
+}
+
+// deletePosts removes the posts together with their comments, the likes given to the posts
+// and the comments, and the copies of the posts in the timelines. The posts stop counting for their hashtags
+func (q *Queries) deletePosts(ctx context.Context, ids []primitive.ObjectID) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	// the comments made before the threads existed only point to the post through their target
+	filter := bson.M{
+		"$or": bson.A{
+			bson.M{"root_post_id": bson.M{"$in": ids}},
+			bson.M{"target_id": bson.M{"$in": ids}},
+		},
+	}
+	commentIDs, err := q.findIDs(ctx, "comments", filter, 0)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = q.removeComments(ctx, commentIDs)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = q.db.Collection("likes").DeleteMany(ctx, bson.M{"target_id": bson.M{"$in": ids}})
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = q.db.Collection("timelines").DeleteMany(ctx, bson.M{"post_id": bson.M{"$in": ids}})
+	if err != nil {
+		return 0, err
+	}
+
+	err = q.decPostsHashtags(ctx, ids)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = q.db.Collection("notifications").DeleteMany(ctx, bson.M{"target_id": bson.M{"$in": ids}})
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := q.db.Collection("posts").DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return 0, err
+	}
+
+	return result.DeletedCount, nil
+}
+
+// deleteComments removes the comments together with their likes. The comments with replies are tombstoned
+// instead, losing their content, author, mentions and likes but keeping their place in the thread, and they are
+// removed along with their last reply
+func (q *Queries) deleteComments(ctx context.Context, ids []primitive.ObjectID) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	filter := bson.M{"_id": bson.M{"$in": ids}, "comment_count": bson.M{"$gt": 0}}
+	threadIDs, err := q.findIDs(ctx, "comments", filter, 0)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(threadIDs) > 0 {
+		_, err = q.db.Collection("likes").DeleteMany(ctx, bson.M{"target_id": bson.M{"$in": threadIDs}})
+		if err != nil {
+			return 0, err
+		}
+
+		update := bson.M{
+			"$set":   bson.M{"deleted": true, "content": "", counterLikes: 0},
+			"$unset": bson.M{"user_id": "", "mentions": ""},
+		}
+		_, err = q.db.Collection("comments").UpdateMany(ctx, bson.M{"_id": bson.M{"$in": threadIDs}}, update)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	var leafIDs []primitive.ObjectID
+	for _, id := range ids {
+		if !containsID(threadIDs, id) {
+			leafIDs = append(leafIDs, id)
+		}
+	}
+
+	if len(leafIDs) == 0 {
+		return int64(len(threadIDs)), nil
+	}
+
+	_, targetIDs, err := q.findTargetIDs(ctx, "comments", bson.M{"_id": bson.M{"$in": leafIDs}}, 0)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := q.removeComments(ctx, leafIDs)
+	if err != nil {
+		return 0, err
+	}
+
+	// the tombstones whose last reply was removed aren't needed anymore
+	filter = bson.M{"_id": bson.M{"$in": targetIDs}, "deleted": true, "comment_count": bson.M{"$lte": 0}}
+	emptyIDs, err := q.findIDs(ctx, "comments", filter, 0)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = q.deleteComments(ctx, emptyIDs)
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(len(threadIDs)) + n, nil
+}
+
+// removeComments removes the comments together with the likes given to them, keeping the comment count
+// of what they were commenting. Their replies are left to the caller
+func (q *Queries) removeComments(ctx context.Context, ids []primitive.ObjectID) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	_, targetIDs, err := q.findTargetIDs(ctx, "comments", bson.M{"_id": bson.M{"$in": ids}}, 0)
+	if err != nil {
+		return 0, err
+	}
+
+	err = q.decTargetCounters(ctx, targetIDs, counterComments)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = q.db.Collection("likes").DeleteMany(ctx, bson.M{"target_id": bson.M{"$in": ids}})
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = q.db.Collection("notifications").DeleteMany(ctx, bson.M{"target_id": bson.M{"$in": ids}})
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := q.db.Collection("comments").DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return 0, err
+	}
+
+	return result.DeletedCount, nil
+}
+
+func containsID(ids []primitive.ObjectID, id primitive.ObjectID) bool {
+	for _, other := range ids {
+		if other == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// deleteLikes removes up to limit likes that match the filter, keeping the like count of what they were liking
+func (q *Queries) deleteLikes(ctx context.Context, filter any, limit int64) (int64, error) {
+	ids, targetIDs, err := q.findTargetIDs(ctx, "likes", filter, limit)
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+
+	err = q.decTargetCounters(ctx, targetIDs, counterLikes)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := q.db.Collection("likes").DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return 0, err
+	}
+
+	return result.DeletedCount, nil
+}
+
+// deleteFollows removes the follows given and received by the user, keeping the followers count
+// of the accounts the user was following
+func (q *Queries) deleteFollows(ctx context.Context, userID primitive.ObjectID, limit int64) (int64, error) {
+	filter := bson.M{
+		"$or": bson.A{
+			bson.M{"follower_id": userID},
+			bson.M{"following_id": userID},
+		},
+	}
+	opts := options.Find()
+	if limit > 0 {
+		opts.SetLimit(limit)
+	}
+
+	coll := q.db.Collection("follows")
+	cursor, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var ids, followingIDs []primitive.ObjectID
+	for cursor.Next(ctx) {
+		var follow Follow
+		err = cursor.Decode(&follow)
+		if err != nil {
+			return 0, err
+		}
+
+		ids = append(ids, follow.ID)
+		if follow.FollowerID == userID {
+			followingIDs = append(followingIDs, follow.FollowingID)
+		}
+	}
+
+	if err = cursor.Err(); err != nil || len(ids) == 0 {
+		return 0, err
+	}
+
+	if len(followingIDs) > 0 {
+		filter := bson.M{"_id": bson.M{"$in": followingIDs}}
+		update := bson.M{"$inc": bson.M{"followers_count": -1}}
+		_, err = q.db.Collection("users").UpdateMany(ctx, filter, update)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	result, err := coll.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return 0, err
+	}
+
+	return result.DeletedCount, nil
+}
+
+// deleteUserDocuments removes the documents of the collection owned by the user through the given key
+func (q *Queries) deleteUserDocuments(ctx context.Context, collection, key string, userID primitive.ObjectID, limit int64) (int64, error) {
+	ids, err := q.findIDs(ctx, collection, bson.M{key: userID}, limit)
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+
+	result, err := q.db.Collection(collection).DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return 0, err
+	}
+
+	return result.DeletedCount, nil
+}
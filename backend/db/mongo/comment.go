@@ -10,23 +10,77 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// CreateCommentParams contains the post of the comment and, for a reply, the comment it answers
 type CreateCommentParams struct {
 	UserID   primitive.ObjectID `json:"user_id" bson:"user_id"`
-	TargetID primitive.ObjectID `json:"target_id" bson:"target_id"`
+	PostID   primitive.ObjectID `json:"post_id" bson:"post_id"`
+	ParentID primitive.ObjectID `json:"parent_id" bson:"parent_id"`
 	Content  string             `json:"content" bson:"content"`
 }
 
+// PostID returns the post of the thread of the comment. The comments created before the threads existed
+// have no root post until RepairCommentThreads runs, they were all top-level comments of their target
+func (comment Comment) PostID() primitive.ObjectID {
+	if comment.RootPostID.IsZero() {
+		return comment.TargetID
+	}
+
+	return comment.RootPostID
+}
+
+// CreateComment inserts the comment and increments the comment count of its target,
+// the parent comment for a reply and the post otherwise
 func (q *Queries) CreateComment(ctx context.Context, arg CreateCommentParams) (*mongo.InsertOneResult, error) {
+	mentions, err := q.resolveMentions(ctx, arg.UserID, arg.Content)
+	if err != nil {
+		return nil, err
+	}
+
 	comment := Comment{
-		ID:        primitive.NewObjectID(),
-		UserID:    arg.UserID,
-		TargetID:  arg.TargetID,
-		Content:   arg.Content,
-		CreatedAt: time.Now(),
+		ID:         primitive.NewObjectID(),
+		UserID:     arg.UserID,
+		TargetID:   arg.PostID,
+		ParentID:   arg.ParentID,
+		RootPostID: arg.PostID,
+		Content:    arg.Content,
+		Mentions:   mentions,
+		CreatedAt:  time.Now(),
 	}
 
-	coll := q.db.Collection("comments")
-	result, err := coll.InsertOne(ctx, comment)
+	if !arg.ParentID.IsZero() {
+		comment.TargetID = arg.ParentID
+	}
+
+	var result *mongo.InsertOneResult
+	err = q.execTx(ctx, func(ctx context.Context) error {
+		var err error
+		coll := q.db.Collection("comments")
+		result, err = coll.InsertOne(ctx, comment)
+		if err != nil {
+			return err
+		}
+
+		return q.incTargetCounter(ctx, comment.TargetID, counterComments, 1)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	kind := NotificationComment
+	if !arg.ParentID.IsZero() {
+		kind = NotificationReply
+	}
+
+	err = q.notifyTargetOwner(ctx, notifyParams{
+		Type:     kind,
+		ActorID:  comment.UserID,
+		TargetID: comment.TargetID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = q.notifyMentions(ctx, comment.UserID, comment.ID, comment.Mentions, nil)
 
 	return result, err
 }
@@ -43,17 +97,48 @@ func (q *Queries) GetComment(ctx context.Context, id primitive.ObjectID) (Commen
 }
 
 type ListCommentsParams struct {
-	TargetID primitive.ObjectID `json:"target_id" bson:"target_id"`
-	Offset   int64              `json:"offset" bson:"limit"`
-	Limit    int64              `json:"limit" bson:"limit"`
+	PostID primitive.ObjectID `json:"post_id" bson:"post_id"`
+	Offset int64              `json:"offset" bson:"offset"`
+	Limit  int64              `json:"limit" bson:"limit"`
 }
 
+// ListComments lists the top-level comments of the post, oldest first. The replies are listed with ListReplies
 func (q *Queries) ListComments(ctx context.Context, arg ListCommentsParams) ([]Comment, error) {
-	filter := bson.D{primitive.E{Key: "target_id", Value: arg.TargetID}}
+	filter := bson.D{primitive.E{Key: "target_id", Value: arg.PostID}}
+	return q.listComments(ctx, filter, arg.Offset, arg.Limit)
+}
+
+type ListRepliesParams struct {
+	CommentID primitive.ObjectID `json:"comment_id" bson:"comment_id"`
+	Offset    int64              `json:"offset" bson:"offset"`
+	Limit     int64              `json:"limit" bson:"limit"`
+}
+
+// ListReplies lists the replies to the comment, oldest first
+func (q *Queries) ListReplies(ctx context.Context, arg ListRepliesParams) ([]Comment, error) {
+	filter := bson.D{primitive.E{Key: "target_id", Value: arg.CommentID}}
+	return q.listComments(ctx, filter, arg.Offset, arg.Limit)
+}
+
+// listComments leaves out the comments removed by a moderator and the ones of the accounts being deleted
+func (q *Queries) listComments(ctx context.Context, filter bson.D, offset, limit int64) ([]Comment, error) {
+	hidden, err := q.pendingPurgeUserIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	filter = append(filter, primitive.E{Key: "removed", Value: bson.M{"$ne": true}})
+	if len(hidden) > 0 {
+		filter = append(filter, primitive.E{Key: "user_id", Value: bson.M{"$nin": hidden}})
+	}
+	opts := options.Find().
+		SetSort(bson.D{primitive.E{Key: "_id", Value: 1}}).
+		SetSkip(offset).
+		SetLimit(limit)
 
 	var comments []Comment
 	coll := q.db.Collection("comments")
-	cursor, err := coll.Find(ctx, filter, options.Find().SetSkip(arg.Offset).SetLimit(arg.Limit))
+	cursor, err := coll.Find(ctx, filter, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -76,25 +161,72 @@ type UpdateCommentParams struct {
 	Content string             `json:"content" bson:"content"`
 }
 
+// UpdateComment replaces the content of the comment, resolving its mentions again
 func (q *Queries) UpdateComment(ctx context.Context, arg UpdateCommentParams) (*mongo.UpdateResult, error) {
+	current, err := q.GetComment(ctx, arg.ID)
+	if err == mongo.ErrNoDocuments {
+		return &mongo.UpdateResult{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	mentions, err := q.resolveMentions(ctx, current.UserID, arg.Content)
+	if err != nil {
+		return nil, err
+	}
+
 	filter := bson.M{"_id": arg.ID}
 	update := bson.M{
 		"$set": bson.M{
-			"content": arg.Content,
+			"content":  arg.Content,
+			"mentions": mentions,
 		},
 	}
+	opts := options.FindOneAndUpdate().SetProjection(bson.M{"user_id": 1, "mentions": 1})
 
+	// the comment as it was before the update tells who was already mentioned
+	var old Comment
 	coll := q.db.Collection("comments")
-	result, err := coll.UpdateOne(ctx, filter, update)
+	err = coll.FindOneAndUpdate(ctx, filter, update, opts).Decode(&old)
+	if err == mongo.ErrNoDocuments {
+		return &mongo.UpdateResult{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := &mongo.UpdateResult{MatchedCount: 1, ModifiedCount: 1}
+	err = q.notifyMentions(ctx, old.UserID, arg.ID, mentions, old.Mentions)
 
 	return result, err
 }
 
+// DeleteComment removes the comment along with its likes and decrements the comment count of its target.
+// A comment with replies is tombstoned instead
 func (q *Queries) DeleteComment(ctx context.Context, id primitive.ObjectID) (*mongo.DeleteResult, error) {
-	filter := bson.M{"_id": id}
-
-	coll := q.db.Collection("comments")
-	result, err := coll.DeleteOne(ctx, filter)
+	result := &mongo.DeleteResult{}
+	err := q.execTx(ctx, func(ctx context.Context) error {
+		var err error
+		result.DeletedCount, err = q.deleteComments(ctx, []primitive.ObjectID{id})
+		return err
+	})
 
 	return result, err
 }
+
+// RepairCommentThreads sets the root post of the comments created before the threads existed,
+// and returns how many comments were updated
+func (q *Queries) RepairCommentThreads(ctx context.Context) (int64, error) {
+	filter := bson.M{"root_post_id": bson.M{"$exists": false}}
+	update := mongo.Pipeline{
+		bson.D{primitive.E{Key: "$set", Value: bson.M{"root_post_id": "$target_id"}}},
+	}
+
+	result, err := q.db.Collection("comments").UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.ModifiedCount, nil
+}
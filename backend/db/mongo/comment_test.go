@@ -6,17 +6,44 @@ import (
 
 	"github.com/DMV-Nicolas/robotgram/backend/util"
 	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
-func randomComment(t *testing.T, userID, targetID primitive.ObjectID) Comment {
+func randomComment(t *testing.T, userID, postID primitive.ObjectID) Comment {
+	arg := CreateCommentParams{
+		UserID:  userID,
+		PostID:  postID,
+		Content: util.RandomString(25),
+	}
+
+	comment := createComment(t, arg)
+	require.Equal(t, postID, comment.TargetID)
+	require.Equal(t, postID, comment.RootPostID)
+	require.True(t, comment.ParentID.IsZero())
+
+	return comment
+}
+
+func randomReply(t *testing.T, userID primitive.ObjectID, parent Comment) Comment {
 	arg := CreateCommentParams{
 		UserID:   userID,
-		TargetID: targetID,
+		PostID:   parent.RootPostID,
+		ParentID: parent.ID,
 		Content:  util.RandomString(25),
 	}
 
+	reply := createComment(t, arg)
+	require.Equal(t, parent.ID, reply.TargetID)
+	require.Equal(t, parent.ID, reply.ParentID)
+	require.Equal(t, parent.RootPostID, reply.RootPostID)
+
+	return reply
+}
+
+func createComment(t *testing.T, arg CreateCommentParams) Comment {
+
 	result, err := testQueries.CreateComment(testCtx, arg)
 	require.NoError(t, err)
 	require.NotEmpty(t, result)
@@ -31,8 +58,8 @@ func randomComment(t *testing.T, userID, targetID primitive.ObjectID) Comment {
 
 	require.Equal(t, insertedID, comment.ID)
 	require.Equal(t, arg.UserID, comment.UserID)
-	require.Equal(t, arg.TargetID, comment.TargetID)
 	require.Equal(t, arg.Content, comment.Content)
+	require.False(t, comment.Deleted)
 	require.WithinDuration(t, time.Now(), comment.CreatedAt, time.Second)
 
 	return comment
@@ -64,9 +91,9 @@ func TestListComments(t *testing.T) {
 	}
 
 	arg := ListCommentsParams{
-		TargetID: post.ID,
-		Offset:   int64(n / 2),
-		Limit:    int64(n / 2),
+		PostID: post.ID,
+		Offset: int64(n / 2),
+		Limit:  int64(n / 2),
 	}
 
 	comments, err := testQueries.ListComments(testCtx, arg)
@@ -78,6 +105,44 @@ func TestListComments(t *testing.T) {
 	}
 }
 
+func TestListCommentsSkipsReplies(t *testing.T) {
+	post := randomPost(t)
+	comment := randomComment(t, primitive.NewObjectID(), post.ID)
+	randomReply(t, primitive.NewObjectID(), comment)
+
+	comments, err := testQueries.ListComments(testCtx, ListCommentsParams{PostID: post.ID, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, comments, 1)
+	require.Equal(t, comment.ID, comments[0].ID)
+}
+
+func TestListReplies(t *testing.T) {
+	comment := randomComment(t, primitive.NewObjectID(), primitive.NewObjectID())
+	n := 6
+	replies := make([]Comment, n)
+	for i := 0; i < n; i++ {
+		replies[i] = randomReply(t, primitive.NewObjectID(), comment)
+	}
+
+	arg := ListRepliesParams{
+		CommentID: comment.ID,
+		Offset:    int64(n / 2),
+		Limit:     int64(n),
+	}
+
+	// the replies are listed oldest first
+	gotReplies, err := testQueries.ListReplies(testCtx, arg)
+	require.NoError(t, err)
+	require.Len(t, gotReplies, n/2)
+	for i, reply := range gotReplies {
+		require.Equal(t, replies[n/2+i].ID, reply.ID)
+	}
+
+	gotComment, err := testQueries.GetComment(testCtx, comment.ID)
+	require.NoError(t, err)
+	require.EqualValues(t, n, gotComment.CommentCount)
+}
+
 func TestUpdateComment(t *testing.T) {
 	comment1 := randomComment(t, primitive.NewObjectID(), primitive.NewObjectID())
 
@@ -116,3 +181,92 @@ func TestDeleteComment(t *testing.T) {
 	require.EqualError(t, mongo.ErrNoDocuments, err.Error())
 	require.Empty(t, comment2)
 }
+
+func TestDeleteCommentTombstone(t *testing.T) {
+	post := randomPost(t)
+	comment := randomComment(t, primitive.NewObjectID(), post.ID)
+	like := randomLike(t, primitive.NewObjectID(), comment.ID)
+	reply1 := randomReply(t, primitive.NewObjectID(), comment)
+	reply2 := randomReply(t, primitive.NewObjectID(), comment)
+
+	// the comment has replies so it's kept as a tombstone
+	_, err := testQueries.DeleteComment(testCtx, comment.ID)
+	require.NoError(t, err)
+
+	tombstone, err := testQueries.GetComment(testCtx, comment.ID)
+	require.NoError(t, err)
+	require.True(t, tombstone.Deleted)
+	require.Empty(t, tombstone.Content)
+	require.True(t, tombstone.UserID.IsZero())
+	require.Zero(t, tombstone.LikeCount)
+	require.EqualValues(t, 2, tombstone.CommentCount)
+
+	_, err = testQueries.GetLike(testCtx, like.ID)
+	require.ErrorIs(t, err, mongo.ErrNoDocuments)
+
+	replies, err := testQueries.ListReplies(testCtx, ListRepliesParams{CommentID: comment.ID, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, replies, 2)
+
+	gotPost, err := testQueries.GetPost(testCtx, "_id", post.ID)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, gotPost.CommentCount)
+
+	// the tombstone goes away with its last reply
+	_, err = testQueries.DeleteComment(testCtx, reply1.ID)
+	require.NoError(t, err)
+
+	_, err = testQueries.GetComment(testCtx, comment.ID)
+	require.NoError(t, err)
+
+	_, err = testQueries.DeleteComment(testCtx, reply2.ID)
+	require.NoError(t, err)
+
+	_, err = testQueries.GetComment(testCtx, comment.ID)
+	require.ErrorIs(t, err, mongo.ErrNoDocuments)
+
+	gotPost, err = testQueries.GetPost(testCtx, "_id", post.ID)
+	require.NoError(t, err)
+	require.Zero(t, gotPost.CommentCount)
+}
+
+func TestDeleteCommentCascade(t *testing.T) {
+	user := randomUser(t)
+	post := randomPost(t)
+	comment := randomComment(t, user.ID, post.ID)
+	like := randomLike(t, post.UserID, comment.ID)
+
+	result, err := testQueries.DeleteComment(testCtx, comment.ID)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, result.DeletedCount)
+
+	_, err = testQueries.GetLike(testCtx, like.ID)
+	require.ErrorIs(t, err, mongo.ErrNoDocuments)
+
+	_, err = testQueries.GetPost(testCtx, "_id", post.ID)
+	require.NoError(t, err)
+}
+
+func TestRepairCommentThreads(t *testing.T) {
+	user := randomUser(t)
+	post := randomPost(t)
+	comment := randomComment(t, user.ID, post.ID)
+
+	// the comments made before the threads existed have no root post
+	db := testQueries.(*Queries).db
+	_, err := db.Collection("comments").UpdateByID(testCtx, comment.ID, bson.M{"$unset": bson.M{"root_post_id": ""}})
+	require.NoError(t, err)
+
+	legacy, err := testQueries.GetComment(testCtx, comment.ID)
+	require.NoError(t, err)
+	require.True(t, legacy.RootPostID.IsZero())
+	require.Equal(t, post.ID, legacy.PostID())
+
+	repaired, err := testQueries.RepairCommentThreads(testCtx)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, repaired, int64(1))
+
+	repairedComment, err := testQueries.GetComment(testCtx, comment.ID)
+	require.NoError(t, err)
+	require.Equal(t, post.ID, repairedComment.RootPostID)
+}
@@ -0,0 +1,175 @@
+package db
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type CreateConversationParams struct {
+	MemberIDs []primitive.ObjectID `json:"member_ids" bson:"member_ids"`
+}
+
+// CreateConversation creates a conversation between the members, ignoring the repeated ones.
+// A conversation between two users is only created once, the next calls return the existing one
+func (q *Queries) CreateConversation(ctx context.Context, arg CreateConversationParams) (Conversation, error) {
+	now := time.Now()
+	conversation := Conversation{
+		ID:            primitive.NewObjectID(),
+		LastMessageAt: now,
+		CreatedAt:     now,
+	}
+
+	seen := make(map[primitive.ObjectID]bool)
+	for _, id := range arg.MemberIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		conversation.Members = append(conversation.Members, ConversationMember{UserID: id})
+	}
+
+	coll := q.db.Collection("conversations")
+	if len(conversation.Members) != 2 {
+		_, err := coll.InsertOne(ctx, conversation)
+		return conversation, err
+	}
+
+	conversation.DirectKey = directKey(conversation.Members[0].UserID, conversation.Members[1].UserID)
+
+	filter := bson.M{"direct_key": conversation.DirectKey}
+	update := bson.M{"$setOnInsert": conversation}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	// two requests may create the same conversation at once, the unique index keeps one of them
+	// and the other one is retried, finding it
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		var got Conversation
+		err = coll.FindOneAndUpdate(ctx, filter, update, opts).Decode(&got)
+		if err == nil {
+			return got, nil
+		}
+		if !mongo.IsDuplicateKeyError(err) {
+			break
+		}
+	}
+
+	return Conversation{}, err
+}
+
+// directKey identifies the one-to-one conversation of the two users, whatever their order
+func directKey(a, b primitive.ObjectID) string {
+	ids := []string{a.Hex(), b.Hex()}
+	sort.Strings(ids)
+	return strings.Join(ids, "_")
+}
+
+func (q *Queries) GetConversation(ctx context.Context, id primitive.ObjectID) (Conversation, error) {
+	filter := bson.D{primitive.E{Key: "_id", Value: id}}
+	opts := options.FindOne()
+
+	var conversation Conversation
+	coll := q.db.Collection("conversations")
+	err := coll.FindOne(ctx, filter, opts).Decode(&conversation)
+
+	return conversation, err
+}
+
+type ListConversationsParams struct {
+	UserID primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Offset int64              `json:"offset" bson:"offset"`
+	Limit  int64              `json:"limit" bson:"limit"`
+}
+
+// ListConversations lists the conversations of the user, the one with the latest message first
+func (q *Queries) ListConversations(ctx context.Context, arg ListConversationsParams) ([]Conversation, error) {
+	filter := bson.D{primitive.E{Key: "members.user_id", Value: arg.UserID}}
+	opts := options.Find().
+		SetSort(bson.D{
+			primitive.E{Key: "last_message_at", Value: -1},
+			primitive.E{Key: "_id", Value: -1},
+		}).
+		SetSkip(arg.Offset).
+		SetLimit(arg.Limit)
+
+	var conversations []Conversation
+	coll := q.db.Collection("conversations")
+	cursor, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	for cursor.Next(ctx) {
+		var conversation Conversation
+		err = cursor.Decode(&conversation)
+		if err != nil {
+			return nil, err
+		}
+
+		conversations = append(conversations, conversation)
+	}
+
+	return conversations, nil
+}
+
+type MarkConversationReadParams struct {
+	ConversationID primitive.ObjectID `json:"conversation_id" bson:"conversation_id"`
+	UserID         primitive.ObjectID `json:"user_id" bson:"user_id"`
+	MessageID      primitive.ObjectID `json:"message_id" bson:"message_id"`
+}
+
+// MarkConversationRead moves the read cursor of the member up to the message.
+// The cursor never moves back, so marking an older message changes nothing
+func (q *Queries) MarkConversationRead(ctx context.Context, arg MarkConversationReadParams) (*mongo.UpdateResult, error) {
+	filter := bson.M{"_id": arg.ConversationID, "members.user_id": arg.UserID}
+	update := bson.M{"$max": bson.M{"members.$.last_read_id": arg.MessageID}}
+
+	coll := q.db.Collection("conversations")
+	return coll.UpdateOne(ctx, filter, update)
+}
+
+type CountUnreadMessagesParams struct {
+	ConversationID primitive.ObjectID `json:"conversation_id" bson:"conversation_id"`
+	UserID         primitive.ObjectID `json:"user_id" bson:"user_id"`
+	LastReadID     primitive.ObjectID `json:"last_read_id" bson:"last_read_id"`
+}
+
+// CountUnreadMessages counts the messages of the other members after the read cursor of the user
+func (q *Queries) CountUnreadMessages(ctx context.Context, arg CountUnreadMessagesParams) (int64, error) {
+	filter := bson.M{
+		"conversation_id": arg.ConversationID,
+		"_id":             bson.M{"$gt": arg.LastReadID},
+		"user_id":         bson.M{"$ne": arg.UserID},
+		"deleted":         bson.M{"$ne": true},
+	}
+
+	coll := q.db.Collection("messages")
+	return coll.CountDocuments(ctx, filter)
+}
+
+// leaveConversations removes the user from its conversations, deleting the ones left without members
+func (q *Queries) leaveConversations(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	coll := q.db.Collection("conversations")
+	filter := bson.M{"members.user_id": userID}
+	update := bson.M{"$pull": bson.M{"members": bson.M{"user_id": userID}}}
+
+	result, err := coll.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = coll.DeleteMany(ctx, bson.M{"members": bson.M{"$size": 0}})
+	if err != nil {
+		return 0, err
+	}
+
+	return result.ModifiedCount, nil
+}
@@ -0,0 +1,224 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DMV-Nicolas/robotgram/backend/util"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func randomConversation(t *testing.T, memberIDs ...primitive.ObjectID) Conversation {
+	conversation, err := testQueries.CreateConversation(testCtx, CreateConversationParams{MemberIDs: memberIDs})
+	require.NoError(t, err)
+	require.NotEmpty(t, conversation)
+
+	require.Len(t, conversation.Members, len(memberIDs))
+	for i, member := range conversation.Members {
+		require.Equal(t, memberIDs[i], member.UserID)
+		require.True(t, member.LastReadID.IsZero())
+	}
+	require.WithinDuration(t, time.Now(), conversation.CreatedAt, time.Second)
+
+	return conversation
+}
+
+func randomMessage(t *testing.T, conversationID, userID primitive.ObjectID) Message {
+	arg := CreateMessageParams{
+		ConversationID: conversationID,
+		UserID:         userID,
+		Content:        util.RandomDescription(50),
+	}
+
+	message, err := testQueries.CreateMessage(testCtx, arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, message)
+
+	require.Equal(t, arg.ConversationID, message.ConversationID)
+	require.Equal(t, arg.UserID, message.UserID)
+	require.Equal(t, arg.Content, message.Content)
+	require.False(t, message.Deleted)
+
+	return message
+}
+
+func TestCreateDirectConversation(t *testing.T) {
+	user1 := randomUser(t)
+	user2 := randomUser(t)
+
+	conversation1 := randomConversation(t, user1.ID, user2.ID)
+
+	// the same two users always get the same conversation
+	conversation2, err := testQueries.CreateConversation(testCtx, CreateConversationParams{
+		MemberIDs: []primitive.ObjectID{user2.ID, user1.ID, user2.ID},
+	})
+	require.NoError(t, err)
+	require.Equal(t, conversation1.ID, conversation2.ID)
+
+	// a group with the same members is another conversation
+	group := randomConversation(t, user1.ID, user2.ID, randomUser(t).ID)
+	require.NotEqual(t, conversation1.ID, group.ID)
+}
+
+func TestListConversations(t *testing.T) {
+	user := randomUser(t)
+	conversation1 := randomConversation(t, user.ID, randomUser(t).ID)
+	conversation2 := randomConversation(t, user.ID, randomUser(t).ID)
+	randomConversation(t, randomUser(t).ID, randomUser(t).ID)
+
+	// a new message moves the conversation to the top
+	randomMessage(t, conversation1.ID, user.ID)
+
+	conversations, err := testQueries.ListConversations(testCtx, ListConversationsParams{UserID: user.ID, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, conversations, 2)
+	require.Equal(t, conversation1.ID, conversations[0].ID)
+	require.Equal(t, conversation2.ID, conversations[1].ID)
+}
+
+func TestUnreadMessages(t *testing.T) {
+	user1 := randomUser(t)
+	user2 := randomUser(t)
+	conversation := randomConversation(t, user1.ID, user2.ID)
+
+	randomMessage(t, conversation.ID, user1.ID)
+	message2 := randomMessage(t, conversation.ID, user2.ID)
+	message3 := randomMessage(t, conversation.ID, user2.ID)
+
+	countUnread := func(userID primitive.ObjectID) int64 {
+		conversation, err := testQueries.GetConversation(testCtx, conversation.ID)
+		require.NoError(t, err)
+
+		arg := CountUnreadMessagesParams{ConversationID: conversation.ID, UserID: userID}
+		for _, member := range conversation.Members {
+			if member.UserID == userID {
+				arg.LastReadID = member.LastReadID
+			}
+		}
+
+		n, err := testQueries.CountUnreadMessages(testCtx, arg)
+		require.NoError(t, err)
+		return n
+	}
+
+	// sending a message reads the conversation
+	require.Zero(t, countUnread(user2.ID))
+	require.EqualValues(t, 2, countUnread(user1.ID))
+
+	_, err := testQueries.MarkConversationRead(testCtx, MarkConversationReadParams{
+		ConversationID: conversation.ID,
+		UserID:         user1.ID,
+		MessageID:      message3.ID,
+	})
+	require.NoError(t, err)
+	require.Zero(t, countUnread(user1.ID))
+
+	// the read cursor doesn't go back
+	result, err := testQueries.MarkConversationRead(testCtx, MarkConversationReadParams{
+		ConversationID: conversation.ID,
+		UserID:         user1.ID,
+		MessageID:      message2.ID,
+	})
+	require.NoError(t, err)
+	require.Zero(t, result.ModifiedCount)
+	require.Zero(t, countUnread(user1.ID))
+
+	// the deleted messages aren't unread
+	message4 := randomMessage(t, conversation.ID, user2.ID)
+	require.EqualValues(t, 1, countUnread(user1.ID))
+	_, err = testQueries.DeleteMessage(testCtx, message4.ID)
+	require.NoError(t, err)
+	require.Zero(t, countUnread(user1.ID))
+}
+
+func TestListMessages(t *testing.T) {
+	user1 := randomUser(t)
+	user2 := randomUser(t)
+	conversation := randomConversation(t, user1.ID, user2.ID)
+
+	messages := make([]Message, 5)
+	for i := range messages {
+		messages[i] = randomMessage(t, conversation.ID, user1.ID)
+	}
+
+	page1, err := testQueries.ListMessages(testCtx, ListMessagesParams{ConversationID: conversation.ID, Limit: 3})
+	require.NoError(t, err)
+	require.Len(t, page1, 3)
+	require.Equal(t, messages[4].ID, page1[0].ID)
+	require.Equal(t, messages[2].ID, page1[2].ID)
+
+	page2, err := testQueries.ListMessages(testCtx, ListMessagesParams{
+		ConversationID: conversation.ID,
+		BeforeID:       page1[2].ID,
+		Limit:          3,
+	})
+	require.NoError(t, err)
+	require.Len(t, page2, 2)
+	require.Equal(t, messages[1].ID, page2[0].ID)
+	require.Equal(t, messages[0].ID, page2[1].ID)
+}
+
+func TestUpdateAndDeleteMessage(t *testing.T) {
+	user := randomUser(t)
+	post := randomPostByUser(t, user.ID)
+	conversation := randomConversation(t, user.ID, randomUser(t).ID)
+
+	message, err := testQueries.CreateMessage(testCtx, CreateMessageParams{
+		ConversationID: conversation.ID,
+		UserID:         user.ID,
+		PostID:         post.ID,
+	})
+	require.NoError(t, err)
+
+	result, err := testQueries.UpdateMessage(testCtx, UpdateMessageParams{ID: message.ID, Content: "look at this"})
+	require.NoError(t, err)
+	require.EqualValues(t, 1, result.ModifiedCount)
+
+	gotMessage, err := testQueries.GetMessage(testCtx, message.ID)
+	require.NoError(t, err)
+	require.Equal(t, "look at this", gotMessage.Content)
+	require.Equal(t, post.ID, gotMessage.PostID)
+	require.WithinDuration(t, time.Now(), gotMessage.EditedAt, time.Second)
+
+	result, err = testQueries.DeleteMessage(testCtx, message.ID)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, result.ModifiedCount)
+
+	gotMessage, err = testQueries.GetMessage(testCtx, message.ID)
+	require.NoError(t, err)
+	require.True(t, gotMessage.Deleted)
+	require.Empty(t, gotMessage.Content)
+	require.True(t, gotMessage.PostID.IsZero())
+
+	// a deleted message can't be edited
+	result, err = testQueries.UpdateMessage(testCtx, UpdateMessageParams{ID: message.ID, Content: "again"})
+	require.NoError(t, err)
+	require.Zero(t, result.MatchedCount)
+}
+
+func TestPurgeUserMessages(t *testing.T) {
+	user1 := randomUser(t)
+	user2 := randomUser(t)
+	direct := randomConversation(t, user1.ID, user2.ID)
+	group := randomConversation(t, user1.ID, randomUser(t).ID, randomUser(t).ID)
+	message1 := randomMessage(t, direct.ID, user1.ID)
+	message2 := randomMessage(t, direct.ID, user2.ID)
+
+	_, err := testQueries.DeleteUser(testCtx, user1.ID)
+	require.NoError(t, err)
+
+	_, err = testQueries.GetMessage(testCtx, message1.ID)
+	require.ErrorIs(t, err, mongo.ErrNoDocuments)
+	_, err = testQueries.GetMessage(testCtx, message2.ID)
+	require.NoError(t, err)
+
+	gotDirect, err := testQueries.GetConversation(testCtx, direct.ID)
+	require.NoError(t, err)
+	require.Equal(t, []ConversationMember{{UserID: user2.ID, LastReadID: message2.ID}}, gotDirect.Members)
+
+	gotGroup, err := testQueries.GetConversation(testCtx, group.ID)
+	require.NoError(t, err)
+	require.Equal(t, group.Members[1:], gotGroup.Members)
+}
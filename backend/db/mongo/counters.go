@@ -0,0 +1,175 @@
+package db
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// The denormalized counters of the posts and the comments
+const (
+	counterLikes    = "like_count"
+	counterComments = "comment_count"
+)
+
+// incTargetCounter adds n to the counter of the liked or commented target, which is either a post or a comment
+func (q *Queries) incTargetCounter(ctx context.Context, targetID primitive.ObjectID, counter string, n int64) error {
+	update := bson.M{"$inc": bson.M{counter: n}}
+
+	result, err := q.db.Collection("posts").UpdateByID(ctx, targetID, update)
+	if err != nil || result.MatchedCount > 0 {
+		return err
+	}
+
+	_, err = q.db.Collection("comments").UpdateByID(ctx, targetID, update)
+	return err
+}
+
+// GetLikeCount returns the stored like count of the target, which is either a post or a comment.
+// It returns mongo.ErrNoDocuments if there is no such target
+func (q *Queries) GetLikeCount(ctx context.Context, targetID primitive.ObjectID) (int64, error) {
+	filter := bson.M{"_id": targetID}
+	opts := options.FindOne().SetProjection(bson.M{counterLikes: 1})
+
+	var target struct {
+		LikeCount int64 `bson:"like_count"`
+	}
+	err := q.db.Collection("posts").FindOne(ctx, filter, opts).Decode(&target)
+	if err == mongo.ErrNoDocuments {
+		err = q.db.Collection("comments").FindOne(ctx, filter, opts).Decode(&target)
+	}
+
+	return target.LikeCount, err
+}
+
+// decTargetCounters subtracts from the counter of each target the number of times it appears in targetIDs
+func (q *Queries) decTargetCounters(ctx context.Context, targetIDs []primitive.ObjectID, counter string) error {
+	counts := make(map[primitive.ObjectID]int64)
+	for _, id := range targetIDs {
+		counts[id]++
+	}
+
+	for id, n := range counts {
+		err := q.incTargetCounter(ctx, id, counter, -n)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// findTargetIDs returns the ids of the documents of the collection that match the filter along with their targets
+func (q *Queries) findTargetIDs(ctx context.Context, collection string, filter any, limit int64) ([]primitive.ObjectID, []primitive.ObjectID, error) {
+	opts := options.Find().SetProjection(bson.M{"_id": 1, "target_id": 1})
+	if limit > 0 {
+		opts.SetLimit(limit)
+	}
+
+	cursor, err := q.db.Collection(collection).Find(ctx, filter, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var ids, targetIDs []primitive.ObjectID
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID       primitive.ObjectID `bson:"_id"`
+			TargetID primitive.ObjectID `bson:"target_id"`
+		}
+		err = cursor.Decode(&doc)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		ids = append(ids, doc.ID)
+		targetIDs = append(targetIDs, doc.TargetID)
+	}
+
+	return ids, targetIDs, cursor.Err()
+}
+
+// RepairCounters recomputes the like and comment counters of the posts and the comments from the likes
+// and comments collections, fixing the ones that drifted. It returns how many documents were fixed
+func (q *Queries) RepairCounters(ctx context.Context) (int64, error) {
+	var repaired int64
+	for _, collection := range []string{"posts", "comments"} {
+		n, err := q.repairCounters(ctx, collection)
+		if err != nil {
+			return repaired, err
+		}
+
+		repaired += n
+	}
+
+	return repaired, nil
+}
+
+func (q *Queries) repairCounters(ctx context.Context, collection string) (int64, error) {
+	// count the likes and comments of every document without loading them, and keep the documents
+	// whose stored counters differ
+	pipeline := mongo.Pipeline{
+		countLookup("likes", "likes"),
+		countLookup("comments", "comments"),
+		bson.D{primitive.E{Key: "$project", Value: bson.M{
+			counterLikes:    1,
+			counterComments: 1,
+			"likes":         bson.M{"$ifNull": bson.A{bson.M{"$arrayElemAt": bson.A{"$likes.n", 0}}, 0}},
+			"comments":      bson.M{"$ifNull": bson.A{bson.M{"$arrayElemAt": bson.A{"$comments.n", 0}}, 0}},
+		}}},
+		bson.D{primitive.E{Key: "$match", Value: bson.M{"$expr": bson.M{"$or": bson.A{
+			bson.M{"$ne": bson.A{"$" + counterLikes, "$likes"}},
+			bson.M{"$ne": bson.A{"$" + counterComments, "$comments"}},
+		}}}}},
+	}
+
+	coll := q.db.Collection(collection)
+	cursor, err := coll.Aggregate(ctx, pipeline)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var repaired int64
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID       primitive.ObjectID `bson:"_id"`
+			Likes    int64              `bson:"likes"`
+			Comments int64              `bson:"comments"`
+		}
+		err = cursor.Decode(&doc)
+		if err != nil {
+			return repaired, err
+		}
+
+		update := bson.M{"$set": bson.M{
+			counterLikes:    doc.Likes,
+			counterComments: doc.Comments,
+		}}
+		_, err = coll.UpdateByID(ctx, doc.ID, update)
+		if err != nil {
+			return repaired, err
+		}
+
+		repaired++
+	}
+
+	return repaired, cursor.Err()
+}
+
+// countLookup counts the documents of the collection that target the current document into as.n
+func countLookup(collection, as string) bson.D {
+	return bson.D{primitive.E{Key: "$lookup", Value: bson.M{
+		"from": collection,
+		"let":  bson.M{"id": "$_id"},
+		"pipeline": bson.A{
+			bson.M{"$match": bson.M{"$expr": bson.M{"$eq": bson.A{"$target_id", "$$id"}}}},
+			bson.M{"$count": "n"},
+		},
+		"as": as,
+	}}}
+}
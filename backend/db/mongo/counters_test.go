@@ -0,0 +1,143 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestLikeCounters(t *testing.T) {
+	post := randomPost(t)
+	comment := randomComment(t, primitive.NewObjectID(), post.ID)
+
+	for i := 0; i < 3; i++ {
+		randomLike(t, primitive.NewObjectID(), post.ID)
+	}
+	like := randomLike(t, primitive.NewObjectID(), comment.ID)
+
+	gotPost, err := testQueries.GetPost(testCtx, "_id", post.ID)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, gotPost.LikeCount)
+
+	gotComment, err := testQueries.GetComment(testCtx, comment.ID)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, gotComment.LikeCount)
+
+	// a rejected like doesn't count
+	_, err = testQueries.CreateLike(testCtx, LikeParams{UserID: like.UserID, TargetID: comment.ID})
+	require.ErrorIs(t, err, ErrDuplicatedLike)
+
+	_, err = testQueries.DeleteLike(testCtx, LikeParams{UserID: like.UserID, TargetID: comment.ID})
+	require.NoError(t, err)
+
+	// deleting a missing like doesn't count either
+	_, err = testQueries.DeleteLike(testCtx, LikeParams{UserID: like.UserID, TargetID: comment.ID})
+	require.NoError(t, err)
+
+	gotComment, err = testQueries.GetComment(testCtx, comment.ID)
+	require.NoError(t, err)
+	require.Zero(t, gotComment.LikeCount)
+}
+
+func TestGetLikeCount(t *testing.T) {
+	post := randomPost(t)
+	comment := randomComment(t, primitive.NewObjectID(), post.ID)
+
+	for i := 0; i < 2; i++ {
+		randomLike(t, primitive.NewObjectID(), post.ID)
+	}
+	randomLike(t, primitive.NewObjectID(), comment.ID)
+
+	nLikes, err := testQueries.GetLikeCount(testCtx, post.ID)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, nLikes)
+
+	nLikes, err = testQueries.GetLikeCount(testCtx, comment.ID)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, nLikes)
+
+	_, err = testQueries.GetLikeCount(testCtx, primitive.NewObjectID())
+	require.ErrorIs(t, err, mongo.ErrNoDocuments)
+}
+
+func TestCommentCounters(t *testing.T) {
+	post := randomPost(t)
+	comment := randomComment(t, primitive.NewObjectID(), post.ID)
+	reply := randomReply(t, primitive.NewObjectID(), comment)
+
+	gotPost, err := testQueries.GetPost(testCtx, "_id", post.ID)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, gotPost.CommentCount)
+
+	gotComment, err := testQueries.GetComment(testCtx, comment.ID)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, gotComment.CommentCount)
+
+	_, err = testQueries.DeleteComment(testCtx, reply.ID)
+	require.NoError(t, err)
+
+	gotComment, err = testQueries.GetComment(testCtx, comment.ID)
+	require.NoError(t, err)
+	require.Zero(t, gotComment.CommentCount)
+
+	_, err = testQueries.DeleteComment(testCtx, comment.ID)
+	require.NoError(t, err)
+
+	gotPost, err = testQueries.GetPost(testCtx, "_id", post.ID)
+	require.NoError(t, err)
+	require.Zero(t, gotPost.CommentCount)
+}
+
+func TestPurgeUserStepKeepsCounters(t *testing.T) {
+	user := randomUser(t)
+	post := randomPost(t)
+	randomLike(t, user.ID, post.ID)
+	randomLike(t, primitive.NewObjectID(), post.ID)
+	randomComment(t, user.ID, post.ID)
+
+	for _, step := range []string{PurgeStepComments, PurgeStepLikes} {
+		_, err := testQueries.PurgeUserStep(testCtx, PurgeUserStepParams{UserID: user.ID, Step: step})
+		require.NoError(t, err)
+	}
+
+	gotPost, err := testQueries.GetPost(testCtx, "_id", post.ID)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, gotPost.LikeCount)
+	require.Zero(t, gotPost.CommentCount)
+}
+
+func TestRepairCounters(t *testing.T) {
+	post := randomPost(t)
+	comment := randomComment(t, primitive.NewObjectID(), post.ID)
+	randomLike(t, primitive.NewObjectID(), post.ID)
+	randomLike(t, primitive.NewObjectID(), comment.ID)
+
+	// make the counters drift behind the queries back
+	db := testQueries.(*Queries).db
+	_, err := db.Collection("posts").UpdateByID(testCtx, post.ID, bson.M{"$set": bson.M{"like_count": 7, "comment_count": 0}})
+	require.NoError(t, err)
+	_, err = db.Collection("comments").UpdateByID(testCtx, comment.ID, bson.M{"$unset": bson.M{"like_count": ""}})
+	require.NoError(t, err)
+
+	repaired, err := testQueries.RepairCounters(testCtx)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, repaired, int64(2))
+
+	gotPost, err := testQueries.GetPost(testCtx, "_id", post.ID)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, gotPost.LikeCount)
+	require.EqualValues(t, 1, gotPost.CommentCount)
+
+	gotComment, err := testQueries.GetComment(testCtx, comment.ID)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, gotComment.LikeCount)
+	require.Zero(t, gotComment.CommentCount)
+
+	// the counters are right now, so nothing changes on a second run
+	repaired, err = testQueries.RepairCounters(testCtx)
+	require.NoError(t, err)
+	require.Zero(t, repaired)
+}
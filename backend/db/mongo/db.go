@@ -1,13 +1,27 @@
 package db
 
-import "go.mongodb.org/mongo-driver/mongo"
+import (
+	"sync"
 
-// NewQuerier creates a new querier
-func NewQuerier(db *mongo.Database) Querier {
-	return &Queries{db: db}
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// NewQuerier creates a new querier, the notifier may be nil when nobody listens to the notifications
+func NewQuerier(db *mongo.Database, notifier Notifier) Querier {
+	return &Queries{db: db, notifier: notifier}
+}
+
+// Notifier is told about every notification right after it is created or updated
+type Notifier interface {
+	Notify(notification Notification)
 }
 
 // Queries is an struct for to interact with the database
 type Queries struct {
-	db *mongo.Database
+	db       *mongo.Database
+	notifier Notifier
+
+	txMutex     sync.Mutex
+	txChecked   bool
+	txSupported bool
 }
@@ -0,0 +1,233 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type FollowParams struct {
+	FollowerID  primitive.ObjectID `json:"follower_id" bson:"follower_id"`
+	FollowingID primitive.ObjectID `json:"following_id" bson:"following_id"`
+}
+
+// Follow makes the follower follow the user. The unique index on (follower_id, following_id) rejects a
+// repeated follow with ErrAlreadyFollows, even when both requests arrive at the same time
+func (q *Queries) Follow(ctx context.Context, arg FollowParams) (*mongo.InsertOneResult, error) {
+	if arg.FollowerID == arg.FollowingID {
+		return nil, ErrSelfFollow
+	}
+
+	follow := Follow{
+		ID:          primitive.NewObjectID(),
+		FollowerID:  arg.FollowerID,
+		FollowingID: arg.FollowingID,
+		CreatedAt:   time.Now(),
+	}
+
+	coll := q.db.Collection("follows")
+	result, err := coll.InsertOne(ctx, follow)
+	if err != nil {
+		if duplicatedIndex(err) == followIndex {
+			return nil, ErrAlreadyFollows
+		}
+		return nil, err
+	}
+
+	err = q.incFollowersCount(ctx, arg.FollowingID, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	// a request left from when the account was private isn't pending anymore
+	_, err = q.DeleteFollowRequest(ctx, arg)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = q.EnqueueTimelineJob(ctx, EnqueueTimelineJobParams{
+		Kind:     TimelineJobBackfill,
+		AuthorID: arg.FollowingID,
+		OwnerID:  arg.FollowerID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = q.notify(ctx, notifyParams{
+		UserID:   arg.FollowingID,
+		Type:     NotificationFollow,
+		ActorID:  arg.FollowerID,
+		TargetID: arg.FollowingID,
+	})
+
+	return result, err
+}
+
+func (q *Queries) Unfollow(ctx context.Context, arg FollowParams) (*mongo.DeleteResult, error) {
+	filter := bson.D{
+		primitive.E{Key: "follower_id", Value: arg.FollowerID},
+		primitive.E{Key: "following_id", Value: arg.FollowingID},
+	}
+
+	coll := q.db.Collection("follows")
+	result, err := coll.DeleteOne(ctx, filter)
+	if err != nil || result.DeletedCount == 0 {
+		return result, err
+	}
+
+	err = q.incFollowersCount(ctx, arg.FollowingID, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = q.EnqueueTimelineJob(ctx, EnqueueTimelineJobParams{
+		Kind:     TimelineJobCleanup,
+		AuthorID: arg.FollowingID,
+		OwnerID:  arg.FollowerID,
+	})
+
+	return result, err
+}
+
+// incFollowersCount keeps the denormalized followers count of the user, used to pick the accounts
+// whose posts are pulled at read time instead of fanned out
+func (q *Queries) incFollowersCount(ctx context.Context, userID primitive.ObjectID, n int64) error {
+	update := bson.M{"$inc": bson.M{"followers_count": n}}
+
+	coll := q.db.Collection("users")
+	_, err := coll.UpdateByID(ctx, userID, update)
+
+	return err
+}
+
+type ListFollowsParams struct {
+	UserID primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Offset int64              `json:"offset" bson:"offset"`
+	Limit  int64              `json:"limit" bson:"limit"`
+}
+
+// ListFollowers lists the follows whose target is the given user
+func (q *Queries) ListFollowers(ctx context.Context, arg ListFollowsParams) ([]Follow, error) {
+	filter := bson.D{primitive.E{Key: "following_id", Value: arg.UserID}}
+	return q.listFollows(ctx, filter, arg)
+}
+
+type ListFollowersAfterParams struct {
+	UserID  primitive.ObjectID `json:"user_id" bson:"user_id"`
+	AfterID primitive.ObjectID `json:"after_id" bson:"after_id"`
+	Limit   int64              `json:"limit" bson:"limit"`
+}
+
+// ListFollowersAfter lists the follows whose target is the given user in the order they were made, starting
+// after the AfterID follow. Unlike an offset, the cursor doesn't move when follows are added or removed
+func (q *Queries) ListFollowersAfter(ctx context.Context, arg ListFollowersAfterParams) ([]Follow, error) {
+	filter := bson.D{
+		primitive.E{Key: "following_id", Value: arg.UserID},
+		primitive.E{Key: "_id", Value: bson.M{"$gt": arg.AfterID}},
+	}
+	opts := options.Find().
+		SetSort(bson.D{primitive.E{Key: "_id", Value: 1}}).
+		SetLimit(arg.Limit)
+
+	var follows []Follow
+	coll := q.db.Collection("follows")
+	cursor, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	for cursor.Next(ctx) {
+		var follow Follow
+		err = cursor.Decode(&follow)
+		if err != nil {
+			return nil, err
+		}
+
+		follows = append(follows, follow)
+	}
+
+	return follows, nil
+}
+
+// ListFollowing lists the follows made by the given user
+func (q *Queries) ListFollowing(ctx context.Context, arg ListFollowsParams) ([]Follow, error) {
+	filter := bson.D{primitive.E{Key: "follower_id", Value: arg.UserID}}
+	return q.listFollows(ctx, filter, arg)
+}
+
+// listFollows leaves out the follows of the accounts being deleted
+func (q *Queries) listFollows(ctx context.Context, filter bson.D, arg ListFollowsParams) ([]Follow, error) {
+	hidden, err := q.pendingPurgeUserIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(hidden) > 0 {
+		filter = append(filter,
+			primitive.E{Key: "follower_id", Value: bson.M{"$nin": hidden}},
+			primitive.E{Key: "following_id", Value: bson.M{"$nin": hidden}},
+		)
+	}
+
+	var follows []Follow
+	coll := q.db.Collection("follows")
+	// the newest first, sorting keeps the pages from overlapping
+	opts := options.Find().
+		SetSort(bson.D{primitive.E{Key: "_id", Value: -1}}).
+		SetSkip(arg.Offset).
+		SetLimit(arg.Limit)
+	cursor, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	for cursor.Next(ctx) {
+		var follow Follow
+		err = cursor.Decode(&follow)
+		if err != nil {
+			return nil, err
+		}
+
+		follows = append(follows, follow)
+	}
+
+	return follows, nil
+}
+
+func (q *Queries) IsFollowing(ctx context.Context, arg FollowParams) (bool, error) {
+	filter := bson.D{
+		primitive.E{Key: "follower_id", Value: arg.FollowerID},
+		primitive.E{Key: "following_id", Value: arg.FollowingID},
+	}
+
+	coll := q.db.Collection("follows")
+	n, err := coll.CountDocuments(ctx, filter, options.Count().SetLimit(1))
+	if err != nil {
+		return false, err
+	}
+
+	return n > 0, nil
+}
+
+func (q *Queries) CountFollowers(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	filter := bson.D{primitive.E{Key: "following_id", Value: userID}}
+
+	coll := q.db.Collection("follows")
+	nFollowers, err := coll.CountDocuments(ctx, filter, nil)
+
+	return nFollowers, err
+}
+
+func (q *Queries) CountFollowing(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	filter := bson.D{primitive.E{Key: "follower_id", Value: userID}}
+
+	coll := q.db.Collection("follows")
+	nFollowing, err := coll.CountDocuments(ctx, filter, nil)
+
+	return nFollowing, err
+}
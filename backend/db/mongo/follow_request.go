@@ -0,0 +1,124 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// RequestFollow asks the owner of a private account to approve the follow, notifying it
+func (q *Queries) RequestFollow(ctx context.Context, arg FollowParams) (FollowRequest, error) {
+	if arg.FollowerID == arg.FollowingID {
+		return FollowRequest{}, ErrSelfFollow
+	}
+
+	following, err := q.IsFollowing(ctx, arg)
+	if err != nil {
+		return FollowRequest{}, err
+	}
+
+	if following {
+		return FollowRequest{}, ErrAlreadyFollows
+	}
+
+	request := FollowRequest{
+		ID:          primitive.NewObjectID(),
+		FollowerID:  arg.FollowerID,
+		FollowingID: arg.FollowingID,
+		CreatedAt:   time.Now(),
+	}
+
+	coll := q.db.Collection("follow_requests")
+	_, err = coll.InsertOne(ctx, request)
+	if err != nil {
+		if duplicatedIndex(err) == followRequestIndex {
+			return FollowRequest{}, ErrFollowRequested
+		}
+		return FollowRequest{}, err
+	}
+
+	err = q.notify(ctx, notifyParams{
+		UserID:   arg.FollowingID,
+		Type:     NotificationFollowRequest,
+		ActorID:  arg.FollowerID,
+		TargetID: arg.FollowingID,
+	})
+
+	return request, err
+}
+
+// ListFollowRequests lists the pending follows of the given user, the newest first
+func (q *Queries) ListFollowRequests(ctx context.Context, arg ListFollowsParams) ([]FollowRequest, error) {
+	filter := bson.D{primitive.E{Key: "following_id", Value: arg.UserID}}
+	opts := options.Find().
+		SetSort(bson.D{primitive.E{Key: "_id", Value: -1}}).
+		SetSkip(arg.Offset).
+		SetLimit(arg.Limit)
+
+	var requests []FollowRequest
+	coll := q.db.Collection("follow_requests")
+	cursor, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	for cursor.Next(ctx) {
+		var request FollowRequest
+		err = cursor.Decode(&request)
+		if err != nil {
+			return nil, err
+		}
+
+		requests = append(requests, request)
+	}
+
+	return requests, nil
+}
+
+// ApproveFollowRequest turns the pending request into a follow. It returns mongo.ErrNoDocuments
+// if there is nothing to approve
+func (q *Queries) ApproveFollowRequest(ctx context.Context, arg FollowParams) (*mongo.InsertOneResult, error) {
+	result, err := q.DeleteFollowRequest(ctx, arg)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.DeletedCount == 0 {
+		return nil, mongo.ErrNoDocuments
+	}
+
+	return q.Follow(ctx, arg)
+}
+
+// DeleteFollowRequest removes the pending request, either rejected by the owner or canceled by the follower
+func (q *Queries) DeleteFollowRequest(ctx context.Context, arg FollowParams) (*mongo.DeleteResult, error) {
+	filter := bson.D{
+		primitive.E{Key: "follower_id", Value: arg.FollowerID},
+		primitive.E{Key: "following_id", Value: arg.FollowingID},
+	}
+
+	coll := q.db.Collection("follow_requests")
+	return coll.DeleteOne(ctx, filter)
+}
+
+// deleteFollowRequests removes the pending requests made and received by the user
+func (q *Queries) deleteFollowRequests(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	filter := bson.M{
+		"$or": bson.A{
+			bson.M{"follower_id": userID},
+			bson.M{"following_id": userID},
+		},
+	}
+
+	coll := q.db.Collection("follow_requests")
+	result, err := coll.DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.DeletedCount, nil
+}
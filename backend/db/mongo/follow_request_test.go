@@ -0,0 +1,139 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func randomPrivateUser(t *testing.T) User {
+	user := randomUser(t)
+	isPrivate := true
+
+	_, err := testQueries.UpdateUser(testCtx, UpdateUserParams{ID: user.ID, IsPrivate: &isPrivate})
+	require.NoError(t, err)
+
+	user.IsPrivate = true
+	return user
+}
+
+func TestRequestFollow(t *testing.T) {
+	owner := randomPrivateUser(t)
+	follower := randomUser(t)
+	arg := FollowParams{
+		FollowerID:  follower.ID,
+		FollowingID: owner.ID,
+	}
+
+	request, err := testQueries.RequestFollow(testCtx, arg)
+	require.NoError(t, err)
+	require.Equal(t, follower.ID, request.FollowerID)
+	require.Equal(t, owner.ID, request.FollowingID)
+	require.WithinDuration(t, time.Now(), request.CreatedAt, time.Second)
+
+	_, err = testQueries.RequestFollow(testCtx, arg)
+	require.ErrorIs(t, err, ErrFollowRequested)
+
+	arg.FollowerID = owner.ID
+	_, err = testQueries.RequestFollow(testCtx, arg)
+	require.ErrorIs(t, err, ErrSelfFollow)
+
+	notifications, err := testQueries.ListNotifications(testCtx, ListNotificationsParams{UserID: owner.ID, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, notifications, 1)
+	require.Equal(t, NotificationFollowRequest, notifications[0].Type)
+}
+
+func TestApproveFollowRequest(t *testing.T) {
+	owner := randomPrivateUser(t)
+	follower := randomUser(t)
+	arg := FollowParams{
+		FollowerID:  follower.ID,
+		FollowingID: owner.ID,
+	}
+
+	_, err := testQueries.ApproveFollowRequest(testCtx, arg)
+	require.ErrorIs(t, err, mongo.ErrNoDocuments)
+
+	request, err := testQueries.RequestFollow(testCtx, arg)
+	require.NoError(t, err)
+
+	requests, err := testQueries.ListFollowRequests(testCtx, ListFollowsParams{UserID: owner.ID, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, requests, 1)
+	require.Equal(t, request.ID, requests[0].ID)
+
+	result, err := testQueries.ApproveFollowRequest(testCtx, arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, result.InsertedID)
+
+	following, err := testQueries.IsFollowing(testCtx, arg)
+	require.NoError(t, err)
+	require.True(t, following)
+
+	requests, err = testQueries.ListFollowRequests(testCtx, ListFollowsParams{UserID: owner.ID, Limit: 10})
+	require.NoError(t, err)
+	require.Empty(t, requests)
+
+	_, err = testQueries.RequestFollow(testCtx, arg)
+	require.ErrorIs(t, err, ErrAlreadyFollows)
+}
+
+func TestDeleteFollowRequest(t *testing.T) {
+	owner := randomPrivateUser(t)
+	follower := randomUser(t)
+	arg := FollowParams{
+		FollowerID:  follower.ID,
+		FollowingID: owner.ID,
+	}
+
+	_, err := testQueries.RequestFollow(testCtx, arg)
+	require.NoError(t, err)
+
+	result, err := testQueries.DeleteFollowRequest(testCtx, arg)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), result.DeletedCount)
+
+	following, err := testQueries.IsFollowing(testCtx, arg)
+	require.NoError(t, err)
+	require.False(t, following)
+
+	// the request can be made again once rejected
+	_, err = testQueries.RequestFollow(testCtx, arg)
+	require.NoError(t, err)
+}
+
+func TestPrivatePostsVisibility(t *testing.T) {
+	owner := randomPrivateUser(t)
+	follower := randomUser(t)
+	stranger := randomUser(t)
+	randomFollow(t, follower.ID, owner.ID)
+	post := randomPostByUser(t, owner.ID)
+	require.True(t, post.Private)
+
+	arg := ListPostsParams{UserID: owner.ID, Limit: 10}
+	for _, viewer := range []User{owner, follower} {
+		arg.ViewerID = viewer.ID
+		posts, err := testQueries.ListPosts(testCtx, arg)
+		require.NoError(t, err)
+		require.Len(t, posts, 1)
+		require.Equal(t, post.ID, posts[0].ID)
+	}
+
+	arg.ViewerID = stranger.ID
+	posts, err := testQueries.ListPosts(testCtx, arg)
+	require.NoError(t, err)
+	require.Empty(t, posts)
+
+	// making the account public copies the flag to its posts
+	isPrivate := false
+	_, err = testQueries.UpdateUser(testCtx, UpdateUserParams{ID: owner.ID, IsPrivate: &isPrivate})
+	require.NoError(t, err)
+
+	posts, err = testQueries.ListPosts(testCtx, ListPostsParams{UserID: owner.ID, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, posts, 1)
+	require.False(t, posts[0].Private)
+}
@@ -0,0 +1,233 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func randomFollow(t *testing.T, followerID, followingID primitive.ObjectID) Follow {
+	arg := FollowParams{
+		FollowerID:  followerID,
+		FollowingID: followingID,
+	}
+
+	result, err := testQueries.Follow(testCtx, arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, result)
+
+	insertedID, ok := result.InsertedID.(primitive.ObjectID)
+	require.True(t, ok)
+	require.NotEqual(t, primitive.NilObjectID, insertedID)
+
+	follows, err := testQueries.ListFollowing(testCtx, ListFollowsParams{UserID: followerID, Limit: 100})
+	require.NoError(t, err)
+
+	var follow Follow
+	for _, f := range follows {
+		if f.ID == insertedID {
+			follow = f
+		}
+	}
+
+	require.Equal(t, insertedID, follow.ID)
+	require.Equal(t, arg.FollowerID, follow.FollowerID)
+	require.Equal(t, arg.FollowingID, follow.FollowingID)
+	require.WithinDuration(t, time.Now(), follow.CreatedAt, time.Second)
+
+	return follow
+}
+
+func TestFollow(t *testing.T) {
+	user1 := randomUser(t)
+	user2 := randomUser(t)
+	randomFollow(t, user1.ID, user2.ID)
+
+	arg := FollowParams{
+		FollowerID:  user1.ID,
+		FollowingID: user2.ID,
+	}
+
+	result, err := testQueries.Follow(testCtx, arg)
+	require.Error(t, err)
+	require.EqualError(t, ErrAlreadyFollows, err.Error())
+	require.Empty(t, result)
+
+	arg.FollowingID = user1.ID
+	result, err = testQueries.Follow(testCtx, arg)
+	require.Error(t, err)
+	require.EqualError(t, ErrSelfFollow, err.Error())
+	require.Empty(t, result)
+}
+
+func TestFollowConcurrent(t *testing.T) {
+	n := 10
+	user1 := randomUser(t)
+	user2 := randomUser(t)
+	arg := FollowParams{
+		FollowerID:  user1.ID,
+		FollowingID: user2.ID,
+	}
+
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := testQueries.Follow(testCtx, arg)
+			errs <- err
+		}()
+	}
+
+	created := 0
+	for i := 0; i < n; i++ {
+		err := <-errs
+		if err == nil {
+			created++
+			continue
+		}
+		require.ErrorIs(t, err, ErrAlreadyFollows)
+	}
+
+	require.Equal(t, 1, created)
+
+	nFollowers, err := testQueries.CountFollowers(testCtx, user2.ID)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, nFollowers)
+
+	user, err := testQueries.GetUser(testCtx, "_id", user2.ID)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, user.FollowersCount)
+}
+
+func TestUnfollow(t *testing.T) {
+	user1 := randomUser(t)
+	user2 := randomUser(t)
+	randomFollow(t, user1.ID, user2.ID)
+
+	arg := FollowParams{
+		FollowerID:  user1.ID,
+		FollowingID: user2.ID,
+	}
+
+	result, err := testQueries.Unfollow(testCtx, arg)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, result.DeletedCount)
+
+	following, err := testQueries.IsFollowing(testCtx, arg)
+	require.NoError(t, err)
+	require.False(t, following)
+
+	result, err = testQueries.Unfollow(testCtx, arg)
+	require.NoError(t, err)
+	require.Zero(t, result.DeletedCount)
+}
+
+func TestListFollowers(t *testing.T) {
+	user := randomUser(t)
+	n := 10
+	for i := 0; i < n; i++ {
+		randomFollow(t, primitive.NewObjectID(), user.ID)
+	}
+
+	arg := ListFollowsParams{
+		UserID: user.ID,
+		Offset: int64(n / 2),
+		Limit:  int64(n / 2),
+	}
+
+	follows, err := testQueries.ListFollowers(testCtx, arg)
+	require.NoError(t, err)
+	require.Len(t, follows, n/2)
+
+	for _, f := range follows {
+		require.NotEmpty(t, f)
+		require.Equal(t, user.ID, f.FollowingID)
+	}
+}
+
+func TestListFollowersAfter(t *testing.T) {
+	user := randomUser(t)
+	n := 10
+	var follows []Follow
+	for i := 0; i < n; i++ {
+		follows = append(follows, randomFollow(t, primitive.NewObjectID(), user.ID))
+	}
+
+	arg := ListFollowersAfterParams{
+		UserID: user.ID,
+		Limit:  int64(n / 2),
+	}
+
+	firstPage, err := testQueries.ListFollowersAfter(testCtx, arg)
+	require.NoError(t, err)
+	require.Len(t, firstPage, n/2)
+
+	// a follow removed between the pages doesn't shift the second one
+	_, err = testQueries.Unfollow(testCtx, FollowParams{FollowerID: follows[0].FollowerID, FollowingID: user.ID})
+	require.NoError(t, err)
+
+	arg.AfterID = firstPage[len(firstPage)-1].ID
+	secondPage, err := testQueries.ListFollowersAfter(testCtx, arg)
+	require.NoError(t, err)
+	require.Len(t, secondPage, n/2)
+
+	for i, f := range append(firstPage, secondPage...) {
+		require.Equal(t, follows[i].ID, f.ID)
+		require.Equal(t, user.ID, f.FollowingID)
+	}
+}
+
+func TestListFollowing(t *testing.T) {
+	user := randomUser(t)
+	n := 10
+	for i := 0; i < n; i++ {
+		randomFollow(t, user.ID, primitive.NewObjectID())
+	}
+
+	arg := ListFollowsParams{
+		UserID: user.ID,
+		Offset: int64(n / 2),
+		Limit:  int64(n / 2),
+	}
+
+	follows, err := testQueries.ListFollowing(testCtx, arg)
+	require.NoError(t, err)
+	require.Len(t, follows, n/2)
+
+	for _, f := range follows {
+		require.NotEmpty(t, f)
+		require.Equal(t, user.ID, f.FollowerID)
+	}
+}
+
+func TestIsFollowing(t *testing.T) {
+	user1 := randomUser(t)
+	user2 := randomUser(t)
+	randomFollow(t, user1.ID, user2.ID)
+
+	following, err := testQueries.IsFollowing(testCtx, FollowParams{FollowerID: user1.ID, FollowingID: user2.ID})
+	require.NoError(t, err)
+	require.True(t, following)
+
+	following, err = testQueries.IsFollowing(testCtx, FollowParams{FollowerID: user2.ID, FollowingID: user1.ID})
+	require.NoError(t, err)
+	require.False(t, following)
+}
+
+func TestCountFollows(t *testing.T) {
+	user := randomUser(t)
+	n := 10
+	for i := 0; i < n; i++ {
+		randomFollow(t, primitive.NewObjectID(), user.ID)
+	}
+	randomFollow(t, user.ID, primitive.NewObjectID())
+
+	nFollowers, err := testQueries.CountFollowers(testCtx, user.ID)
+	require.NoError(t, err)
+	require.EqualValues(t, n, nFollowers)
+
+	nFollowing, err := testQueries.CountFollowing(testCtx, user.ID)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, nFollowing)
+}
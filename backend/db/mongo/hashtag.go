@@ -0,0 +1,166 @@
+package db
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func (q *Queries) GetHashtag(ctx context.Context, name string) (Hashtag, error) {
+	filter := bson.D{primitive.E{Key: "_id", Value: name}}
+
+	var hashtag Hashtag
+	coll := q.db.Collection("hashtags")
+	err := coll.FindOne(ctx, filter).Decode(&hashtag)
+
+	return hashtag, err
+}
+
+type SearchHashtagsParams struct {
+	Prefix string `json:"prefix" bson:"prefix"`
+	Offset int64  `json:"offset" bson:"offset"`
+	Limit  int64  `json:"limit" bson:"limit"`
+}
+
+// SearchHashtags lists the used hashtags that start with the normalized prefix, the most used first
+func (q *Queries) SearchHashtags(ctx context.Context, arg SearchHashtagsParams) ([]Hashtag, error) {
+	filter := bson.D{
+		// an anchored regex without options is resolved with the _id index
+		primitive.E{Key: "_id", Value: primitive.Regex{Pattern: "^" + regexp.QuoteMeta(arg.Prefix)}},
+		primitive.E{Key: "post_count", Value: bson.M{"$gt": 0}},
+	}
+	opts := options.Find().
+		SetSort(bson.D{
+			primitive.E{Key: "post_count", Value: -1},
+			primitive.E{Key: "_id", Value: 1},
+		}).
+		SetSkip(arg.Offset).
+		SetLimit(arg.Limit)
+
+	var hashtags []Hashtag
+	coll := q.db.Collection("hashtags")
+	cursor, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	for cursor.Next(ctx) {
+		var hashtag Hashtag
+		err = cursor.Decode(&hashtag)
+		if err != nil {
+			return nil, err
+		}
+
+		hashtags = append(hashtags, hashtag)
+	}
+
+	return hashtags, nil
+}
+
+type ListHashtagPostsParams struct {
+	Hashtag  string             `json:"hashtag" bson:"hashtag"`
+	Offset   int64              `json:"offset" bson:"offset"`
+	Limit    int64              `json:"limit" bson:"limit"`
+	ViewerID primitive.ObjectID `json:"viewer_id" bson:"viewer_id"`
+}
+
+// ListHashtagPosts lists the posts that use the normalized hashtag and the viewer can see, the newest first
+func (q *Queries) ListHashtagPosts(ctx context.Context, arg ListHashtagPostsParams) ([]Post, error) {
+	filter, err := q.visiblePostsFilter(ctx, arg.ViewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	filter["hashtags"] = arg.Hashtag
+	opts := options.Find().
+		SetSort(bson.D{primitive.E{Key: "_id", Value: -1}}).
+		SetSkip(arg.Offset).
+		SetLimit(arg.Limit)
+
+	var posts []Post
+	coll := q.db.Collection("posts")
+	cursor, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	for cursor.Next(ctx) {
+		var post Post
+		err = cursor.Decode(&post)
+		if err != nil {
+			return nil, err
+		}
+
+		posts = append(posts, post)
+	}
+
+	return posts, nil
+}
+
+// incHashtags adds to the post count of each hashtag its value in counts, creating the hashtags that don't exist yet
+func (q *Queries) incHashtags(ctx context.Context, counts map[string]int64) error {
+	now := time.Now()
+	var models []mongo.WriteModel
+	for name, n := range counts {
+		if n == 0 {
+			continue
+		}
+
+		update := bson.M{
+			"$inc": bson.M{"post_count": n},
+			"$set": bson.M{"updated_at": now},
+		}
+		model := mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": name}).
+			SetUpdate(update).
+			SetUpsert(n > 0)
+
+		models = append(models, model)
+	}
+
+	if len(models) == 0 {
+		return nil
+	}
+
+	coll := q.db.Collection("hashtags")
+	_, err := coll.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+
+	return err
+}
+
+// decPostsHashtags subtracts the posts from the post count of their hashtags
+func (q *Queries) decPostsHashtags(ctx context.Context, ids []primitive.ObjectID) error {
+	filter := bson.M{"_id": bson.M{"$in": ids}, "hashtags.0": bson.M{"$exists": true}}
+	opts := options.Find().SetProjection(bson.M{"hashtags": 1})
+
+	coll := q.db.Collection("posts")
+	cursor, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	counts := make(map[string]int64)
+	for cursor.Next(ctx) {
+		var post Post
+		err = cursor.Decode(&post)
+		if err != nil {
+			return err
+		}
+
+		for _, name := range post.Hashtags {
+			counts[name]--
+		}
+	}
+
+	if err = cursor.Err(); err != nil {
+		return err
+	}
+
+	return q.incHashtags(ctx, counts)
+}
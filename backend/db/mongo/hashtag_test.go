@@ -0,0 +1,137 @@
+package db
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/DMV-Nicolas/robotgram/backend/util"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func randomHashtagPost(t *testing.T, hashtags ...string) Post {
+	description := util.RandomPassword(20)
+	for _, hashtag := range hashtags {
+		description += " #" + hashtag
+	}
+
+	return randomPostByUserWithDescription(t, randomUser(t).ID, description)
+}
+
+func requireHashtagCount(t *testing.T, name string, count int64) {
+	hashtag, err := testQueries.GetHashtag(testCtx, name)
+	require.NoError(t, err)
+	require.Equal(t, name, hashtag.Name)
+	require.Equal(t, count, hashtag.PostCount)
+}
+
+func TestCreatePostHashtags(t *testing.T) {
+	name := "robot" + util.RandomString(8)
+
+	post := randomHashtagPost(t, name, "Café"+name)
+	require.Equal(t, []string{name, "café" + name}, post.Hashtags)
+
+	randomHashtagPost(t, name)
+
+	requireHashtagCount(t, name, 2)
+	requireHashtagCount(t, "café"+name, 1)
+}
+
+func TestUpdatePostHashtags(t *testing.T) {
+	kept := "kept" + util.RandomString(8)
+	removed := "removed" + util.RandomString(8)
+	added := "added" + util.RandomString(8)
+
+	post := randomHashtagPost(t, kept, removed)
+
+	result, err := testQueries.UpdatePost(testCtx, UpdatePostParams{
+		ID:          post.ID,
+		Images:      post.Images,
+		Description: fmt.Sprintf("#%s #%s", kept, added),
+	})
+	require.NoError(t, err)
+	require.EqualValues(t, 1, result.MatchedCount)
+
+	gotPost, err := testQueries.GetPost(testCtx, "_id", post.ID)
+	require.NoError(t, err)
+	require.Equal(t, []string{kept, added}, gotPost.Hashtags)
+
+	requireHashtagCount(t, kept, 1)
+	requireHashtagCount(t, removed, 0)
+	requireHashtagCount(t, added, 1)
+
+	// updating a missing post doesn't touch the hashtags
+	result, err = testQueries.UpdatePost(testCtx, UpdatePostParams{
+		ID:          primitive.NewObjectID(),
+		Description: "#" + kept,
+	})
+	require.NoError(t, err)
+	require.Zero(t, result.MatchedCount)
+
+	requireHashtagCount(t, kept, 1)
+}
+
+func TestDeletePostHashtags(t *testing.T) {
+	name := "robot" + util.RandomString(8)
+	post := randomHashtagPost(t, name)
+	randomHashtagPost(t, name)
+
+	_, err := testQueries.DeletePost(testCtx, post.ID)
+	require.NoError(t, err)
+
+	requireHashtagCount(t, name, 1)
+}
+
+func TestListHashtagPosts(t *testing.T) {
+	name := "robot" + util.RandomString(8)
+	posts := make([]Post, 3)
+	for i := range posts {
+		posts[i] = randomHashtagPost(t, name)
+	}
+	randomHashtagPost(t, "other"+name)
+
+	gotPosts, err := testQueries.ListHashtagPosts(testCtx, ListHashtagPostsParams{
+		Hashtag: name,
+		Offset:  1,
+		Limit:   5,
+	})
+	require.NoError(t, err)
+	require.Len(t, gotPosts, 2)
+
+	// the newest first
+	require.Equal(t, posts[1].ID, gotPosts[0].ID)
+	require.Equal(t, posts[0].ID, gotPosts[1].ID)
+}
+
+func TestSearchHashtags(t *testing.T) {
+	prefix := "robot" + util.RandomString(8)
+	popular := prefix + "popular"
+	rare := prefix + "rare"
+	unused := prefix + "unused"
+
+	randomHashtagPost(t, popular, rare)
+	randomHashtagPost(t, popular)
+	post := randomHashtagPost(t, unused)
+	randomHashtagPost(t, "other"+prefix)
+
+	_, err := testQueries.DeletePost(testCtx, post.ID)
+	require.NoError(t, err)
+
+	hashtags, err := testQueries.SearchHashtags(testCtx, SearchHashtagsParams{Prefix: prefix, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, hashtags, 2)
+	require.Equal(t, popular, hashtags[0].Name)
+	require.EqualValues(t, 2, hashtags[0].PostCount)
+	require.Equal(t, rare, hashtags[1].Name)
+
+	// the prefix is matched literally
+	hashtags, err = testQueries.SearchHashtags(testCtx, SearchHashtagsParams{Prefix: ".*", Limit: 10})
+	require.NoError(t, err)
+	require.Empty(t, hashtags)
+}
+
+func TestGetHashtagNotFound(t *testing.T) {
+	_, err := testQueries.GetHashtag(testCtx, "missing"+util.RandomString(8))
+	require.ErrorIs(t, err, mongo.ErrNoDocuments)
+}
@@ -0,0 +1,339 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	usernameIndex = "username_unique"
+	emailIndex    = "email_unique"
+	likeIndex     = "like_unique"
+	followIndex   = "follow_unique"
+
+	followRequestIndex = "follow_request_unique"
+	blockIndex         = "block_unique"
+	muteIndex          = "mute_unique"
+	reportIndex        = "report_unique"
+)
+
+// caseInsensitive compares the strings ignoring the case, so "Robot" and "robot" are the same username.
+// The queries on the unique fields must use it too, otherwise they can't use the index
+var caseInsensitive = &options.Collation{Locale: "en", Strength: 2}
+
+// collectionIndexes contains the indexes that must exist in each collection, the target_id ones keep
+// counting the likes and comments of a post cheap
+var collectionIndexes = map[string][]mongo.IndexModel{
+	"users": {
+		{
+			Keys:    bson.D{primitive.E{Key: "username", Value: 1}},
+			Options: options.Index().SetName(usernameIndex).SetUnique(true).SetCollation(caseInsensitive),
+		},
+		{
+			Keys:    bson.D{primitive.E{Key: "email", Value: 1}},
+			Options: options.Index().SetName(emailIndex).SetUnique(true).SetCollation(caseInsensitive),
+		},
+		{
+			Keys:    bson.D{primitive.E{Key: "search_keys", Value: 1}},
+			Options: options.Index().SetName("user_search_keys"),
+		},
+		{
+			Keys: bson.D{primitive.E{Key: "purge_at", Value: 1}},
+			// only the accounts being deleted have it
+			Options: options.Index().SetName("user_purge_at").SetSparse(true),
+		},
+		{
+			Keys: bson.D{
+				primitive.E{Key: "username", Value: "text"},
+				primitive.E{Key: "full_name", Value: "text"},
+				primitive.E{Key: "description", Value: "text"},
+			},
+			// the names aren't stemmed, a collection can only have one text index
+			Options: options.Index().SetName("user_text").
+				SetDefaultLanguage("none").
+				SetWeights(bson.D{
+					primitive.E{Key: "username", Value: 10},
+					primitive.E{Key: "full_name", Value: 5},
+					primitive.E{Key: "description", Value: 1},
+				}),
+		},
+	},
+	"likes": {
+		{
+			Keys: bson.D{
+				primitive.E{Key: "user_id", Value: 1},
+				primitive.E{Key: "target_id", Value: 1},
+			},
+			Options: options.Index().SetName(likeIndex).SetUnique(true),
+		},
+		{
+			Keys:    bson.D{primitive.E{Key: "target_id", Value: 1}},
+			Options: options.Index().SetName("like_target"),
+		},
+	},
+	"posts": {
+		{
+			Keys: bson.D{
+				primitive.E{Key: "hashtags", Value: 1},
+				primitive.E{Key: "_id", Value: -1},
+			},
+			Options: options.Index().SetName("post_hashtags"),
+		},
+		{
+			Keys:    bson.D{primitive.E{Key: "mentions.user_id", Value: 1}},
+			Options: options.Index().SetName("post_mentions"),
+		},
+		{
+			Keys:    bson.D{primitive.E{Key: "user_id", Value: 1}},
+			Options: options.Index().SetName("post_user"),
+		},
+		{
+			Keys:    bson.D{primitive.E{Key: "description", Value: "text"}},
+			Options: options.Index().SetName("post_text").SetDefaultLanguage("spanish"),
+		},
+	},
+	"follows": {
+		{
+			Keys: bson.D{
+				primitive.E{Key: "follower_id", Value: 1},
+				primitive.E{Key: "following_id", Value: 1},
+			},
+			Options: options.Index().SetName(followIndex).SetUnique(true),
+		},
+		{
+			Keys: bson.D{
+				primitive.E{Key: "following_id", Value: 1},
+				primitive.E{Key: "_id", Value: 1},
+			},
+			Options: options.Index().SetName("follow_following"),
+		},
+	},
+	"follow_requests": {
+		{
+			Keys: bson.D{
+				primitive.E{Key: "follower_id", Value: 1},
+				primitive.E{Key: "following_id", Value: 1},
+			},
+			Options: options.Index().SetName(followRequestIndex).SetUnique(true),
+		},
+		{
+			Keys: bson.D{
+				primitive.E{Key: "following_id", Value: 1},
+				primitive.E{Key: "_id", Value: -1},
+			},
+			Options: options.Index().SetName("follow_request_following"),
+		},
+	},
+	"reports": {
+		{
+			Keys: bson.D{
+				primitive.E{Key: "reporter_id", Value: 1},
+				primitive.E{Key: "target_id", Value: 1},
+			},
+			// a target can be reported again once its reports are resolved
+			Options: options.Index().SetName(reportIndex).SetUnique(true).SetPartialFilterExpression(bson.M{"status": ReportOpen}),
+		},
+		{
+			Keys: bson.D{
+				primitive.E{Key: "status", Value: 1},
+				primitive.E{Key: "target_id", Value: 1},
+			},
+			Options: options.Index().SetName("report_status_target"),
+		},
+	},
+	"moderation_actions": {
+		{
+			Keys: bson.D{
+				primitive.E{Key: "target_id", Value: 1},
+				primitive.E{Key: "_id", Value: -1},
+			},
+			Options: options.Index().SetName("moderation_action_target"),
+		},
+	},
+	"blocks": {
+		{
+			Keys: bson.D{
+				primitive.E{Key: "blocker_id", Value: 1},
+				primitive.E{Key: "blocked_id", Value: 1},
+			},
+			Options: options.Index().SetName(blockIndex).SetUnique(true),
+		},
+		{
+			Keys:    bson.D{primitive.E{Key: "blocked_id", Value: 1}},
+			Options: options.Index().SetName("block_blocked"),
+		},
+	},
+	"mutes": {
+		{
+			Keys: bson.D{
+				primitive.E{Key: "muter_id", Value: 1},
+				primitive.E{Key: "muted_id", Value: 1},
+			},
+			Options: options.Index().SetName(muteIndex).SetUnique(true),
+		},
+	},
+	"notifications": {
+		{
+			Keys: bson.D{
+				primitive.E{Key: "user_id", Value: 1},
+				primitive.E{Key: "type", Value: 1},
+				primitive.E{Key: "target_id", Value: 1},
+			},
+			// a single unread notification per type and target, the read ones are kept apart
+			Options: options.Index().SetName("notification_unread").SetUnique(true).SetPartialFilterExpression(bson.M{"read": false}),
+		},
+		{
+			Keys: bson.D{
+				primitive.E{Key: "user_id", Value: 1},
+				primitive.E{Key: "updated_at", Value: -1},
+			},
+			Options: options.Index().SetName("notification_user"),
+		},
+		{
+			Keys:    bson.D{primitive.E{Key: "target_id", Value: 1}},
+			Options: options.Index().SetName("notification_target"),
+		},
+	},
+	"conversations": {
+		{
+			Keys: bson.D{
+				primitive.E{Key: "members.user_id", Value: 1},
+				primitive.E{Key: "last_message_at", Value: -1},
+			},
+			Options: options.Index().SetName("conversation_members"),
+		},
+		{
+			Keys: bson.D{primitive.E{Key: "direct_key", Value: 1}},
+			// the group conversations have no key
+			Options: options.Index().SetName("conversation_direct").SetUnique(true).SetPartialFilterExpression(bson.M{"direct_key": bson.M{"$exists": true}}),
+		},
+	},
+	"messages": {
+		{
+			Keys: bson.D{
+				primitive.E{Key: "conversation_id", Value: 1},
+				primitive.E{Key: "_id", Value: -1},
+			},
+			Options: options.Index().SetName("message_conversation"),
+		},
+		{
+			Keys:    bson.D{primitive.E{Key: "user_id", Value: 1}},
+			Options: options.Index().SetName("message_user"),
+		},
+	},
+	"comments": {
+		{
+			Keys:    bson.D{primitive.E{Key: "target_id", Value: 1}},
+			Options: options.Index().SetName("comment_target"),
+		},
+		{
+			Keys:    bson.D{primitive.E{Key: "root_post_id", Value: 1}},
+			Options: options.Index().SetName("comment_root_post"),
+		},
+		{
+			Keys:    bson.D{primitive.E{Key: "mentions.user_id", Value: 1}},
+			Options: options.Index().SetName("comment_mentions"),
+		},
+	},
+	"timelines": {
+		{
+			Keys: bson.D{
+				primitive.E{Key: "owner_id", Value: 1},
+				primitive.E{Key: "post_id", Value: 1},
+			},
+			// the server retries an upsert that loses the race on this index, so the fan-out and the backfill
+			// of the same post can't add it twice
+			Options: options.Index().SetName("timeline_unique").SetUnique(true),
+		},
+		{
+			Keys: bson.D{
+				primitive.E{Key: "owner_id", Value: 1},
+				primitive.E{Key: "created_at", Value: -1},
+				primitive.E{Key: "post_id", Value: -1},
+			},
+			Options: options.Index().SetName("timeline_owner"),
+		},
+		{
+			Keys: bson.D{
+				primitive.E{Key: "owner_id", Value: 1},
+				primitive.E{Key: "author_id", Value: 1},
+			},
+			Options: options.Index().SetName("timeline_owner_author"),
+		},
+	},
+	"timeline_jobs": {
+		{
+			Keys: bson.D{
+				primitive.E{Key: "status", Value: 1},
+				primitive.E{Key: "created_at", Value: 1},
+			},
+			Options: options.Index().SetName("timeline_job_status"),
+		},
+	},
+	"purge_jobs": {
+		{
+			Keys: bson.D{
+				primitive.E{Key: "status", Value: 1},
+				primitive.E{Key: "purge_after", Value: 1},
+			},
+			Options: options.Index().SetName("purge_job_status"),
+		},
+		{
+			Keys: bson.D{
+				primitive.E{Key: "user_id", Value: 1},
+				primitive.E{Key: "created_at", Value: -1},
+			},
+			Options: options.Index().SetName("purge_job_user"),
+		},
+	},
+	"sessions": {
+		{
+			Keys: bson.D{
+				primitive.E{Key: "user_id", Value: 1},
+				primitive.E{Key: "created_at", Value: -1},
+			},
+			Options: options.Index().SetName("session_user"),
+		},
+		{
+			Keys:    bson.D{primitive.E{Key: "family_id", Value: 1}},
+			Options: options.Index().SetName("session_family"),
+		},
+	},
+}
+
+// CreateIndexes creates the missing indexes of the database, the existing ones are left untouched.
+// It fails if the stored documents break a unique index, those must be fixed by hand before starting
+func CreateIndexes(ctx context.Context, db *mongo.Database) error {
+	for collection, indexes := range collectionIndexes {
+		_, err := db.Collection(collection).Indexes().CreateMany(ctx, indexes)
+		if err != nil {
+			return fmt.Errorf("cannot create the indexes of %s: %w", collection, err)
+		}
+	}
+
+	return nil
+}
+
+// duplicatedIndex returns the name of the unique index violated by err, or an empty string
+// if err isn't a duplicate key error
+func duplicatedIndex(err error) string {
+	if !mongo.IsDuplicateKeyError(err) {
+		return ""
+	}
+
+	// the server only reports the violated index in the message: "... index: username_unique dup key: ..."
+	msg := err.Error()
+	_, after, found := strings.Cut(msg, "index: ")
+	if !found {
+		return ""
+	}
+
+	name, _, _ := strings.Cut(after, " ")
+	return name
+}
@@ -10,40 +10,89 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-type ToggleLikeParams struct {
+type LikeParams struct {
 	UserID   primitive.ObjectID `json:"user_id" bson:"user_id"`
 	TargetID primitive.ObjectID `json:"target_id" bson:"target_id"`
 }
 
-func (q *Queries) ToggleLike(ctx context.Context, arg ToggleLikeParams) (*mongo.InsertOneResult, *mongo.DeleteResult, error) {
-	like, liked, err := q.IsLiked(ctx, IsLikedParams{TargetID: arg.TargetID, UserID: arg.UserID})
+// CreateLike gives the like of the user to the target and increments its like count. The unique index
+// on (user_id, target_id) rejects a repeated like with ErrDuplicatedLike, even when both requests arrive
+// at the same time
+func (q *Queries) CreateLike(ctx context.Context, arg LikeParams) (Like, error) {
+	like := Like{
+		ID:        primitive.NewObjectID(),
+		UserID:    arg.UserID,
+		TargetID:  arg.TargetID,
+		CreatedAt: time.Now(),
+	}
+
+	err := q.execTx(ctx, func(ctx context.Context) error {
+		coll := q.db.Collection("likes")
+		_, err := coll.InsertOne(ctx, like)
+		if err != nil {
+			if duplicatedIndex(err) == likeIndex {
+				return ErrDuplicatedLike
+			}
+			return err
+		}
 
+		return q.incTargetCounter(ctx, arg.TargetID, counterLikes, 1)
+	})
 	if err != nil {
-		return nil, nil, err
+		return Like{}, err
 	}
 
-	if liked {
-		// delete like
-		filter := bson.M{"_id": like.ID}
+	err = q.notifyTargetOwner(ctx, notifyParams{
+		Type:     NotificationLike,
+		ActorID:  arg.UserID,
+		TargetID: arg.TargetID,
+	})
+
+	return like, err
+}
+
+// DeleteLike removes the like of the user from the target and decrements its like count,
+// removing a missing like isn't an error
+func (q *Queries) DeleteLike(ctx context.Context, arg LikeParams) (*mongo.DeleteResult, error) {
+	filter := bson.D{
+		primitive.E{Key: "user_id", Value: arg.UserID},
+		primitive.E{Key: "target_id", Value: arg.TargetID},
+	}
 
+	result := &mongo.DeleteResult{}
+	err := q.execTx(ctx, func(ctx context.Context) error {
 		coll := q.db.Collection("likes")
-		result, err := coll.DeleteOne(ctx, filter)
-
-		return nil, result, err
-	} else {
-		// create like
-		like := Like{
-			ID:        primitive.NewObjectID(),
-			UserID:    arg.UserID,
-			TargetID:  arg.TargetID,
-			CreatedAt: time.Now(),
+		deleted, err := coll.DeleteOne(ctx, filter)
+		if err != nil || deleted.DeletedCount == 0 {
+			return err
 		}
 
-		coll := q.db.Collection("likes")
-		result, err := coll.InsertOne(ctx, like)
+		result.DeletedCount = deleted.DeletedCount
+		return q.incTargetCounter(ctx, arg.TargetID, counterLikes, -1)
+	})
 
-		return result, nil, err
+	return result, err
+}
+
+// ToggleLike removes the like of the user from the target if it exists, otherwise it creates it.
+// It returns true when the target ends liked
+func (q *Queries) ToggleLike(ctx context.Context, arg LikeParams) (bool, error) {
+	result, err := q.DeleteLike(ctx, arg)
+	if err != nil {
+		return false, err
 	}
+
+	if result.DeletedCount > 0 {
+		return false, nil
+	}
+
+	_, err = q.CreateLike(ctx, arg)
+	if err == ErrDuplicatedLike {
+		// a concurrent request gave the like first
+		return true, nil
+	}
+
+	return err == nil, err
 }
 
 func (q *Queries) GetLike(ctx context.Context, id primitive.ObjectID) (Like, error) {
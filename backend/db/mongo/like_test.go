@@ -6,33 +6,99 @@ import (
 
 	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 func randomLike(t *testing.T, userID primitive.ObjectID, targetID primitive.ObjectID) Like {
-	arg := ToggleLikeParams{
+	arg := LikeParams{
 		UserID:   userID,
 		TargetID: targetID,
 	}
 
-	createdResult, deletedResult, err := testQueries.ToggleLike(testCtx, arg)
+	like1, err := testQueries.CreateLike(testCtx, arg)
 	require.NoError(t, err)
-	require.NotEmpty(t, createdResult)
-	require.Empty(t, deletedResult)
+	require.NotEmpty(t, like1)
 
-	insertedID, ok := createdResult.InsertedID.(primitive.ObjectID)
-	require.True(t, ok)
-	require.NotEqual(t, primitive.NilObjectID, insertedID)
+	like2, err := testQueries.GetLike(testCtx, like1.ID)
+	require.NoError(t, err)
+	require.NotEmpty(t, like2)
+
+	require.Equal(t, like1.ID, like2.ID)
+	require.Equal(t, arg.UserID, like2.UserID)
+	require.Equal(t, arg.TargetID, like2.TargetID)
+	require.WithinDuration(t, time.Now(), like2.CreatedAt, time.Second)
 
-	like, err := testQueries.GetLike(testCtx, insertedID)
+	return like2
+}
+
+func TestCreateLikeDuplicated(t *testing.T) {
+	like1 := randomLike(t, primitive.NewObjectID(), primitive.NewObjectID())
+
+	arg := LikeParams{
+		UserID:   like1.UserID,
+		TargetID: like1.TargetID,
+	}
+
+	like2, err := testQueries.CreateLike(testCtx, arg)
+	require.ErrorIs(t, err, ErrDuplicatedLike)
+	require.Empty(t, like2)
+
+	nLikes, err := testQueries.CountLikes(testCtx, arg.TargetID)
 	require.NoError(t, err)
-	require.NotEmpty(t, like)
+	require.EqualValues(t, 1, nLikes)
+}
 
-	require.Equal(t, insertedID, like.ID)
-	require.Equal(t, arg.UserID, like.UserID)
-	require.Equal(t, arg.TargetID, like.TargetID)
-	require.WithinDuration(t, time.Now(), like.CreatedAt, time.Second)
+func TestCreateLikeConcurrent(t *testing.T) {
+	n := 10
+	arg := LikeParams{
+		UserID:   primitive.NewObjectID(),
+		TargetID: primitive.NewObjectID(),
+	}
 
-	return like
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := testQueries.CreateLike(testCtx, arg)
+			errs <- err
+		}()
+	}
+
+	created := 0
+	for i := 0; i < n; i++ {
+		err := <-errs
+		if err == nil {
+			created++
+			continue
+		}
+		require.ErrorIs(t, err, ErrDuplicatedLike)
+	}
+
+	require.Equal(t, 1, created)
+
+	nLikes, err := testQueries.CountLikes(testCtx, arg.TargetID)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, nLikes)
+}
+
+func TestDeleteLike(t *testing.T) {
+	like := randomLike(t, primitive.NewObjectID(), primitive.NewObjectID())
+
+	arg := LikeParams{
+		UserID:   like.UserID,
+		TargetID: like.TargetID,
+	}
+
+	result, err := testQueries.DeleteLike(testCtx, arg)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, result.DeletedCount)
+
+	// deleting it again changes nothing
+	result, err = testQueries.DeleteLike(testCtx, arg)
+	require.NoError(t, err)
+	require.Zero(t, result.DeletedCount)
+
+	_, err = testQueries.GetLike(testCtx, like.ID)
+	require.ErrorIs(t, err, mongo.ErrNoDocuments)
 }
 
 func TestToggleLike(t *testing.T) {
@@ -40,20 +106,22 @@ func TestToggleLike(t *testing.T) {
 	post := randomPost(t)
 	randomLike(t, user.ID, post.ID)
 
-	arg := ToggleLikeParams{
+	arg := LikeParams{
 		UserID:   user.ID,
 		TargetID: post.ID,
 	}
 
-	createdResult, deletedResult, err := testQueries.ToggleLike(testCtx, arg)
+	liked, err := testQueries.ToggleLike(testCtx, arg)
 	require.NoError(t, err)
-	require.Empty(t, createdResult)
-	require.NotEmpty(t, deletedResult)
+	require.False(t, liked)
 
-	createdResult, deletedResult, err = testQueries.ToggleLike(testCtx, arg)
+	liked, err = testQueries.ToggleLike(testCtx, arg)
+	require.NoError(t, err)
+	require.True(t, liked)
+
+	nLikes, err := testQueries.CountLikes(testCtx, post.ID)
 	require.NoError(t, err)
-	require.NotEmpty(t, createdResult)
-	require.Empty(t, deletedResult)
+	require.EqualValues(t, 1, nLikes)
 }
 
 func TestGetLike(t *testing.T) {
@@ -120,7 +188,7 @@ func TestIsLiked(t *testing.T) {
 	require.NotEmpty(t, like2)
 	require.Equal(t, like1, like2)
 
-	_, _, err = testQueries.ToggleLike(testCtx, ToggleLikeParams{UserID: arg.UserID, TargetID: arg.TargetID})
+	_, err = testQueries.DeleteLike(testCtx, LikeParams{UserID: arg.UserID, TargetID: arg.TargetID})
 	require.NoError(t, err)
 
 	like3, liked, err := testQueries.IsLiked(testCtx, arg)
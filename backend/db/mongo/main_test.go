@@ -29,7 +29,12 @@ func TestMain(m *testing.M) {
 	}
 
 	db := client.Database(config.DBName)
-	testQueries = NewQuerier(db)
+	err = CreateIndexes(testCtx, db)
+	if err != nil {
+		log.Fatal("Cannot create indexes:", err)
+	}
+
+	testQueries = NewQuerier(db, nil)
 
 	os.Exit(m.Run())
 }
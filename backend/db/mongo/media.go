@@ -0,0 +1,89 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type CreateMediaParams struct {
+	ID         primitive.ObjectID `json:"id" bson:"_id"`
+	UserID     primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Width      int                `json:"width" bson:"width"`
+	Height     int                `json:"height" bson:"height"`
+	Blurhash   string             `json:"blurhash" bson:"blurhash"`
+	Renditions []Rendition        `json:"renditions" bson:"renditions"`
+}
+
+func (q *Queries) CreateMedia(ctx context.Context, arg CreateMediaParams) (*mongo.InsertOneResult, error) {
+	media := Media{
+		ID:         arg.ID,
+		UserID:     arg.UserID,
+		Width:      arg.Width,
+		Height:     arg.Height,
+		Blurhash:   arg.Blurhash,
+		Renditions: arg.Renditions,
+		CreatedAt:  time.Now(),
+	}
+
+	coll := q.db.Collection("media")
+	result, err := coll.InsertOne(ctx, media)
+
+	return result, err
+}
+
+func (q *Queries) GetMedia(ctx context.Context, id primitive.ObjectID) (Media, error) {
+	filter := bson.D{primitive.E{Key: "_id", Value: id}}
+	opts := options.FindOne()
+
+	var media Media
+	coll := q.db.Collection("media")
+	err := coll.FindOne(ctx, filter, opts).Decode(&media)
+
+	return media, err
+}
+
+type ListUserMediaParams struct {
+	UserID primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Limit  int64              `json:"limit" bson:"limit"`
+}
+
+// ListUserMedia lists the oldest media uploaded by the user
+func (q *Queries) ListUserMedia(ctx context.Context, arg ListUserMediaParams) ([]Media, error) {
+	filter := bson.M{"user_id": arg.UserID}
+	opts := options.Find().
+		SetSort(bson.D{primitive.E{Key: "_id", Value: 1}}).
+		SetLimit(arg.Limit)
+
+	var medias []Media
+	coll := q.db.Collection("media")
+	cursor, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	for cursor.Next(ctx) {
+		var media Media
+		err = cursor.Decode(&media)
+		if err != nil {
+			return nil, err
+		}
+
+		medias = append(medias, media)
+	}
+
+	return medias, nil
+}
+
+func (q *Queries) DeleteMedia(ctx context.Context, id primitive.ObjectID) (*mongo.DeleteResult, error) {
+	filter := bson.M{"_id": id}
+
+	coll := q.db.Collection("media")
+	result, err := coll.DeleteOne(ctx, filter)
+
+	return result, err
+}
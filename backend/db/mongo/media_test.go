@@ -0,0 +1,102 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DMV-Nicolas/robotgram/backend/util"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func randomMedia(t *testing.T, userID primitive.ObjectID) Media {
+	id := primitive.NewObjectID()
+	arg := CreateMediaParams{
+		ID:       id,
+		UserID:   userID,
+		Width:    1080,
+		Height:   720,
+		Blurhash: util.RandomString(28),
+		Renditions: []Rendition{
+			{
+				Name:        "feed",
+				Key:         userID.Hex() + "/" + id.Hex() + "/feed.jpg",
+				URL:         util.RandomImage(),
+				ContentType: "image/jpeg",
+				Width:       1080,
+				Height:      720,
+				Size:        int64(len(util.RandomString(64))),
+			},
+		},
+	}
+
+	result, err := testQueries.CreateMedia(testCtx, arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, result)
+	require.Equal(t, id, result.InsertedID)
+
+	media, err := testQueries.GetMedia(testCtx, id)
+	require.NoError(t, err)
+	require.NotEmpty(t, media)
+
+	require.Equal(t, arg.ID, media.ID)
+	require.Equal(t, arg.UserID, media.UserID)
+	require.Equal(t, arg.Width, media.Width)
+	require.Equal(t, arg.Height, media.Height)
+	require.Equal(t, arg.Blurhash, media.Blurhash)
+	require.Equal(t, arg.Renditions, media.Renditions)
+	require.WithinDuration(t, time.Now(), media.CreatedAt, time.Second)
+
+	return media
+}
+
+func TestCreateMedia(t *testing.T) {
+	user := randomUser(t)
+	randomMedia(t, user.ID)
+}
+
+func TestGetMedia(t *testing.T) {
+	user := randomUser(t)
+	media1 := randomMedia(t, user.ID)
+
+	media2, err := testQueries.GetMedia(testCtx, media1.ID)
+	require.NoError(t, err)
+	require.Equal(t, media1.ID, media2.ID)
+	require.Equal(t, media1.Renditions, media2.Renditions)
+	require.WithinDuration(t, media1.CreatedAt, media2.CreatedAt, time.Second)
+}
+
+func TestDeleteMedia(t *testing.T) {
+	user := randomUser(t)
+	media1 := randomMedia(t, user.ID)
+
+	result, err := testQueries.DeleteMedia(testCtx, media1.ID)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, result.DeletedCount)
+
+	media2, err := testQueries.GetMedia(testCtx, media1.ID)
+	require.EqualError(t, err, mongo.ErrNoDocuments.Error())
+	require.Empty(t, media2)
+}
+
+func TestListUserMedia(t *testing.T) {
+	user := randomUser(t)
+	n := 4
+	for i := 0; i < n; i++ {
+		randomMedia(t, user.ID)
+	}
+
+	arg := ListUserMediaParams{
+		UserID: user.ID,
+		Limit:  int64(n / 2),
+	}
+
+	medias, err := testQueries.ListUserMedia(testCtx, arg)
+	require.NoError(t, err)
+	require.Len(t, medias, n/2)
+
+	for _, media := range medias {
+		require.Equal(t, user.ID, media.UserID)
+	}
+}
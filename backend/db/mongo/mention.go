@@ -0,0 +1,169 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/DMV-Nicolas/robotgram/backend/util"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// The kinds of the texts that mention a user
+const (
+	MentionKindPost    = "post"
+	MentionKindComment = "comment"
+)
+
+// resolveMentions finds the users mentioned by the author in the text. The usernames that don't belong
+// to anyone, or to someone with a block between it and the author, are left as plain text
+func (q *Queries) resolveMentions(ctx context.Context, authorID primitive.ObjectID, text string) ([]Mention, error) {
+	var mentions []Mention
+	users := make(map[string]primitive.ObjectID)
+	for _, token := range util.ExtractMentions(text) {
+		userID, ok := users[token.Username]
+		if !ok {
+			user, err := q.GetUser(ctx, "username", token.Username)
+			if err != nil && err != mongo.ErrNoDocuments {
+				return nil, err
+			}
+
+			if err == nil {
+				blocked, err := q.IsBlockedBetween(ctx, BlockParams{BlockerID: user.ID, BlockedID: authorID})
+				if err != nil {
+					return nil, err
+				}
+				if blocked {
+					user.ID = primitive.NilObjectID
+				}
+			}
+
+			userID = user.ID
+			users[token.Username] = userID
+		}
+
+		if userID.IsZero() {
+			continue
+		}
+
+		mentions = append(mentions, Mention{
+			UserID: userID,
+			Start:  token.Start,
+			End:    token.End,
+		})
+	}
+
+	return mentions, nil
+}
+
+// Mentioning is a post or a comment that mentions a user
+type Mentioning struct {
+	Kind      string    `json:"kind" bson:"kind"`
+	Post      *Post     `json:"post,omitempty" bson:"post,omitempty"`
+	Comment   *Comment  `json:"comment,omitempty" bson:"comment,omitempty"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+}
+
+type ListMentionsParams struct {
+	UserID primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Offset int64              `json:"offset" bson:"offset"`
+	Limit  int64              `json:"limit" bson:"limit"`
+}
+
+// ListMentions lists the posts and the comments that mention the user, the newest first. Only the ones
+// the user can see are listed: the comments must be visible themselves and be made on a visible post
+func (q *Queries) ListMentions(ctx context.Context, arg ListMentionsParams) ([]Mentioning, error) {
+	postFilter, err := q.visiblePostsFilter(ctx, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	hidden, err := q.pendingPurgeUserIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	blocked, err := q.blockedUserIDs(ctx, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	hidden = append(hidden, blocked...)
+
+	commentFilter := bson.M{
+		"mentions.user_id": arg.UserID,
+		"removed":          bson.M{"$ne": true},
+		"deleted":          bson.M{"$ne": true},
+	}
+	if len(hidden) > 0 {
+		commentFilter["user_id"] = bson.M{"$nin": hidden}
+	}
+
+	commentPipeline := bson.A{
+		bson.D{primitive.E{Key: "$match", Value: commentFilter}},
+		// the comments made before the threads existed only point to the post through their target
+		bson.D{primitive.E{Key: "$lookup", Value: bson.M{
+			"from": "posts",
+			"let":  bson.M{"post_id": bson.M{"$ifNull": bson.A{"$root_post_id", "$target_id"}}},
+			"pipeline": bson.A{
+				bson.D{primitive.E{Key: "$match", Value: bson.M{"$and": bson.A{
+					bson.M{"$expr": bson.M{"$eq": bson.A{"$_id", "$$post_id"}}},
+					postFilter,
+				}}}},
+				bson.D{primitive.E{Key: "$project", Value: bson.M{"_id": 1}}},
+			},
+			"as": "visible_post",
+		}}},
+		bson.D{primitive.E{Key: "$match", Value: bson.M{"visible_post": bson.M{"$ne": bson.A{}}}}},
+		bson.D{primitive.E{Key: "$unset", Value: "visible_post"}},
+		mentioningProject(MentionKindComment),
+	}
+
+	pipeline := mongo.Pipeline{
+		bson.D{primitive.E{Key: "$match", Value: bson.M{"$and": bson.A{
+			bson.M{"mentions.user_id": arg.UserID},
+			postFilter,
+		}}}},
+		mentioningProject(MentionKindPost),
+		bson.D{primitive.E{Key: "$unionWith", Value: bson.M{
+			"coll":     "comments",
+			"pipeline": commentPipeline,
+		}}},
+		bson.D{primitive.E{Key: "$sort", Value: bson.D{
+			primitive.E{Key: "created_at", Value: -1},
+			primitive.E{Key: "_id", Value: -1},
+		}}},
+		bson.D{primitive.E{Key: "$skip", Value: arg.Offset}},
+		bson.D{primitive.E{Key: "$limit", Value: arg.Limit}},
+	}
+
+	coll := q.db.Collection("posts")
+	cursor, err := coll.Aggregate(ctx, pipeline, options.Aggregate())
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var mentionings []Mentioning
+	for cursor.Next(ctx) {
+		var mentioning Mentioning
+		err = cursor.Decode(&mentioning)
+		if err != nil {
+			return nil, err
+		}
+
+		mentionings = append(mentionings, mentioning)
+	}
+
+	return mentionings, cursor.Err()
+}
+
+// mentioningProject wraps the current document in the field named after its kind
+func mentioningProject(kind string) bson.D {
+	return bson.D{primitive.E{Key: "$project", Value: bson.M{
+		"kind":       bson.M{"$literal": kind},
+		kind:         "$$ROOT",
+		"created_at": 1,
+	}}}
+}
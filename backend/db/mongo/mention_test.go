@@ -0,0 +1,189 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf16"
+
+	"github.com/DMV-Nicolas/robotgram/backend/util"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestPostMentions(t *testing.T) {
+	author := randomUser(t)
+	user := randomUser(t)
+	description := fmt.Sprintf("🤖 hi @%s, not @missing%s", user.Username, util.RandomString(8))
+
+	post := randomPostByUserWithDescription(t, author.ID, description)
+	require.Len(t, post.Mentions, 1)
+	require.Equal(t, user.ID, post.Mentions[0].UserID)
+
+	// the offsets slice the description like the clients do
+	units := utf16.Encode([]rune(description))
+	mentioned := string(utf16.Decode(units[post.Mentions[0].Start:post.Mentions[0].End]))
+	require.Equal(t, "@"+user.Username, mentioned)
+
+	_, err := testQueries.UpdatePost(testCtx, UpdatePostParams{
+		ID:          post.ID,
+		Images:      post.Images,
+		Description: "no mentions",
+	})
+	require.NoError(t, err)
+
+	gotPost, err := testQueries.GetPost(testCtx, "_id", post.ID)
+	require.NoError(t, err)
+	require.Empty(t, gotPost.Mentions)
+}
+
+func TestCommentMentions(t *testing.T) {
+	user := randomUser(t)
+	post := randomPost(t)
+
+	// the usernames are resolved ignoring the case
+	comment := createComment(t, CreateCommentParams{
+		UserID:  primitive.NewObjectID(),
+		PostID:  post.ID,
+		Content: fmt.Sprintf("@%s @%s", user.Username, strings.ToLower(user.Username)),
+	})
+	require.Len(t, comment.Mentions, 2)
+	require.Equal(t, user.ID, comment.Mentions[0].UserID)
+	require.Equal(t, user.ID, comment.Mentions[1].UserID)
+
+	reply := randomReply(t, primitive.NewObjectID(), comment)
+	require.NotEmpty(t, reply)
+
+	// a tombstone doesn't mention anyone
+	_, err := testQueries.DeleteComment(testCtx, comment.ID)
+	require.NoError(t, err)
+
+	gotComment, err := testQueries.GetComment(testCtx, comment.ID)
+	require.NoError(t, err)
+	require.True(t, gotComment.Deleted)
+	require.Empty(t, gotComment.Mentions)
+}
+
+func TestListMentions(t *testing.T) {
+	user := randomUser(t)
+	mention := "hello @" + user.Username
+
+	post := randomPostByUserWithDescription(t, randomUser(t).ID, mention)
+	comment := createComment(t, CreateCommentParams{
+		UserID:  primitive.NewObjectID(),
+		PostID:  randomPost(t).ID,
+		Content: mention,
+	})
+	randomPost(t)
+
+	// the mentions keep pointing to the user after a username change
+	db := testQueries.(*Queries).db
+	_, err := db.Collection("users").UpdateByID(testCtx, user.ID, bson.M{"$set": bson.M{"username": util.RandomUsername()}})
+	require.NoError(t, err)
+
+	mentionings, err := testQueries.ListMentions(testCtx, ListMentionsParams{UserID: user.ID, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, mentionings, 2)
+
+	require.Equal(t, MentionKindComment, mentionings[0].Kind)
+	require.NotNil(t, mentionings[0].Comment)
+	require.Equal(t, comment.ID, mentionings[0].Comment.ID)
+
+	require.Equal(t, MentionKindPost, mentionings[1].Kind)
+	require.NotNil(t, mentionings[1].Post)
+	require.Equal(t, post.ID, mentionings[1].Post.ID)
+
+	mentionings, err = testQueries.ListMentions(testCtx, ListMentionsParams{UserID: user.ID, Offset: 1, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, mentionings, 1)
+	require.Equal(t, post.ID, mentionings[0].Post.ID)
+}
+
+func TestListMentionsHidden(t *testing.T) {
+	testCases := []struct {
+		name      string
+		hide      func(t *testing.T, user, author User, post Post, comment Comment)
+		remaining int
+	}{
+		{
+			name: "Private",
+			hide: func(t *testing.T, user, author User, post Post, comment Comment) {
+				isPrivate := true
+				_, err := testQueries.UpdateUser(testCtx, UpdateUserParams{ID: author.ID, IsPrivate: &isPrivate})
+				require.NoError(t, err)
+			},
+			// the comment of the private account on a public post stays visible
+			remaining: 1,
+		},
+		{
+			name: "Removed",
+			hide: func(t *testing.T, user, author User, post Post, comment Comment) {
+				db := testQueries.(*Queries).db
+				_, err := db.Collection("posts").UpdateByID(testCtx, post.ID, bson.M{"$set": bson.M{"removed": true}})
+				require.NoError(t, err)
+				_, err = db.Collection("comments").UpdateByID(testCtx, comment.ID, bson.M{"$set": bson.M{"removed": true}})
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "Blocked",
+			hide: func(t *testing.T, user, author User, post Post, comment Comment) {
+				_, err := testQueries.BlockUser(testCtx, BlockParams{BlockerID: author.ID, BlockedID: user.ID})
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "PendingPurge",
+			hide: func(t *testing.T, user, author User, post Post, comment Comment) {
+				randomPurgeJob(t, author, time.Now().Add(time.Hour))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			user := randomUser(t)
+			author := randomUser(t)
+			mention := "hello @" + user.Username
+
+			// the post of the author, a comment of the author on a public post and a comment on the post
+			post := randomPostByUserWithDescription(t, author.ID, mention)
+			comment := createComment(t, CreateCommentParams{
+				UserID:  author.ID,
+				PostID:  randomPost(t).ID,
+				Content: mention,
+			})
+			createComment(t, CreateCommentParams{
+				UserID:  randomUser(t).ID,
+				PostID:  post.ID,
+				Content: mention,
+			})
+
+			mentionings, err := testQueries.ListMentions(testCtx, ListMentionsParams{UserID: user.ID, Limit: 10})
+			require.NoError(t, err)
+			require.Len(t, mentionings, 3)
+
+			tc.hide(t, user, author, post, comment)
+
+			mentionings, err = testQueries.ListMentions(testCtx, ListMentionsParams{UserID: user.ID, Limit: 10})
+			require.NoError(t, err)
+			require.Len(t, mentionings, tc.remaining)
+		})
+	}
+}
+
+func randomPostByUserWithDescription(t *testing.T, userID primitive.ObjectID, description string) Post {
+	result, err := testQueries.CreatePost(testCtx, CreatePostParams{
+		UserID:      userID,
+		Images:      []Image{randomImage(t, userID)},
+		Description: description,
+	})
+	require.NoError(t, err)
+
+	post, err := testQueries.GetPost(testCtx, "_id", result.InsertedID)
+	require.NoError(t, err)
+
+	return post
+}
@@ -0,0 +1,132 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CreateMessageParams contains the content of the message and, to share a post, its id
+type CreateMessageParams struct {
+	ConversationID primitive.ObjectID `json:"conversation_id" bson:"conversation_id"`
+	UserID         primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Content        string             `json:"content" bson:"content"`
+	PostID         primitive.ObjectID `json:"post_id" bson:"post_id"`
+}
+
+// CreateMessage inserts the message and moves the conversation to the top of the list of its members.
+// The sender has read its own message, so its read cursor moves to it
+func (q *Queries) CreateMessage(ctx context.Context, arg CreateMessageParams) (Message, error) {
+	message := Message{
+		ID:             primitive.NewObjectID(),
+		ConversationID: arg.ConversationID,
+		UserID:         arg.UserID,
+		Content:        arg.Content,
+		PostID:         arg.PostID,
+		CreatedAt:      time.Now(),
+	}
+
+	err := q.execTx(ctx, func(ctx context.Context) error {
+		_, err := q.db.Collection("messages").InsertOne(ctx, message)
+		if err != nil {
+			return err
+		}
+
+		filter := bson.M{"_id": arg.ConversationID, "members.user_id": arg.UserID}
+		update := bson.M{
+			"$set": bson.M{
+				"last_message_at":        message.CreatedAt,
+				"members.$.last_read_id": message.ID,
+			},
+		}
+
+		_, err = q.db.Collection("conversations").UpdateOne(ctx, filter, update)
+		return err
+	})
+
+	return message, err
+}
+
+func (q *Queries) GetMessage(ctx context.Context, id primitive.ObjectID) (Message, error) {
+	filter := bson.D{primitive.E{Key: "_id", Value: id}}
+	opts := options.FindOne()
+
+	var message Message
+	coll := q.db.Collection("messages")
+	err := coll.FindOne(ctx, filter, opts).Decode(&message)
+
+	return message, err
+}
+
+type ListMessagesParams struct {
+	ConversationID primitive.ObjectID `json:"conversation_id" bson:"conversation_id"`
+	BeforeID       primitive.ObjectID `json:"before_id" bson:"before_id"`
+	Limit          int64              `json:"limit" bson:"limit"`
+}
+
+// ListMessages lists the messages of the conversation, newest first.
+// When BeforeID is set only the messages older than it are listed, so the history is read backwards
+func (q *Queries) ListMessages(ctx context.Context, arg ListMessagesParams) ([]Message, error) {
+	filter := bson.M{"conversation_id": arg.ConversationID}
+	if !arg.BeforeID.IsZero() {
+		filter["_id"] = bson.M{"$lt": arg.BeforeID}
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{primitive.E{Key: "_id", Value: -1}}).
+		SetLimit(arg.Limit)
+
+	var messages []Message
+	coll := q.db.Collection("messages")
+	cursor, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	for cursor.Next(ctx) {
+		var message Message
+		err = cursor.Decode(&message)
+		if err != nil {
+			return nil, err
+		}
+
+		messages = append(messages, message)
+	}
+
+	return messages, nil
+}
+
+type UpdateMessageParams struct {
+	ID      primitive.ObjectID `json:"id" bson:"_id"`
+	Content string             `json:"content" bson:"content"`
+}
+
+// UpdateMessage replaces the content of the message, the deleted messages can't be edited
+func (q *Queries) UpdateMessage(ctx context.Context, arg UpdateMessageParams) (*mongo.UpdateResult, error) {
+	filter := bson.M{"_id": arg.ID, "deleted": bson.M{"$ne": true}}
+	update := bson.M{
+		"$set": bson.M{
+			"content":   arg.Content,
+			"edited_at": time.Now(),
+		},
+	}
+
+	coll := q.db.Collection("messages")
+	return coll.UpdateOne(ctx, filter, update)
+}
+
+// DeleteMessage empties the message and marks it as deleted, it is kept in the history as a tombstone
+func (q *Queries) DeleteMessage(ctx context.Context, id primitive.ObjectID) (*mongo.UpdateResult, error) {
+	filter := bson.M{"_id": id, "deleted": bson.M{"$ne": true}}
+	update := bson.M{
+		"$set":   bson.M{"content": "", "deleted": true},
+		"$unset": bson.M{"post_id": "", "edited_at": ""},
+	}
+
+	coll := q.db.Collection("messages")
+	return coll.UpdateOne(ctx, filter, update)
+}
@@ -6,24 +6,90 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// User disables the notification types set to false in NotificationPreferences, the missing ones are enabled.
+// SearchKeys are the folded words of the username and the full name, see util.UserSearchKeys
 type User struct {
-	ID             primitive.ObjectID `json:"id" bson:"_id"`
-	Username       string             `json:"username" bson:"username"`
-	HashedPassword string             `json:"hashed_password" bson:"hashed_password"`
-	FullName       string             `json:"full_name" bson:"full_name"`
-	Email          string             `json:"email" bson:"email"`
-	Avatar         string             `json:"avatar" bson:"avatar"`
-	Description    string             `json:"description" bson:"description"`
-	Gender         string             `json:"gender" bson:"gender"`
-	CreatedAt      time.Time          `json:"created_at" bson:"created_at"`
+	ID                      primitive.ObjectID `json:"id" bson:"_id"`
+	Username                string             `json:"username" bson:"username"`
+	HashedPassword          string             `json:"hashed_password" bson:"hashed_password"`
+	FullName                string             `json:"full_name" bson:"full_name"`
+	Email                   string             `json:"email" bson:"email"`
+	Avatar                  string             `json:"avatar" bson:"avatar"`
+	AvatarID                primitive.ObjectID `json:"avatar_id" bson:"avatar_id,omitempty"`
+	Description             string             `json:"description" bson:"description"`
+	Gender                  string             `json:"gender" bson:"gender"`
+	FollowersCount          int64              `json:"followers_count" bson:"followers_count"`
+	IsPrivate               bool               `json:"is_private" bson:"is_private"`
+	Role                    string             `json:"role" bson:"role,omitempty"`
+	NotificationPreferences map[string]bool    `json:"notification_preferences" bson:"notification_preferences,omitempty"`
+	SearchKeys              []string           `json:"-" bson:"search_keys,omitempty"`
+	PurgeAt                 time.Time          `json:"purge_at" bson:"purge_at,omitempty"`
+	SuspendedAt             time.Time          `json:"suspended_at" bson:"suspended_at,omitempty"`
+	CreatedAt               time.Time          `json:"created_at" bson:"created_at"`
 }
 
+// Post keeps denormalized counts of its likes and comments, updated along with them
+// and recomputed by RepairCounters if they drift. The hashtags and the mentions are parsed from the description.
+// Private copies the IsPrivate flag of the author so the listings can hide it without a lookup.
+// A post removed by a moderator is hidden from everyone but kept for an appeal
 type Post struct {
-	ID          primitive.ObjectID `json:"id" bson:"_id"`
-	UserID      primitive.ObjectID `json:"user_id" bson:"user_id"`
-	Images      []string           `json:"images" bson:"images"`
-	Description string             `json:"description" bson:"description"`
-	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
+	ID           primitive.ObjectID `json:"id" bson:"_id"`
+	UserID       primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Images       []Image            `json:"images" bson:"images"`
+	Description  string             `json:"description" bson:"description"`
+	Hashtags     []string           `json:"hashtags" bson:"hashtags"`
+	Mentions     []Mention          `json:"mentions" bson:"mentions,omitempty"`
+	LikeCount    int64              `json:"like_count" bson:"like_count"`
+	CommentCount int64              `json:"comment_count" bson:"comment_count"`
+	Private      bool               `json:"-" bson:"private,omitempty"`
+	Removed      bool               `json:"removed" bson:"removed,omitempty"`
+	CreatedAt    time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// Image is a copy of the media attached to a post with everything needed to display it
+type Image struct {
+	MediaID      primitive.ObjectID `json:"media_id" bson:"media_id"`
+	URL          string             `json:"url" bson:"url"`
+	ThumbnailURL string             `json:"thumbnail_url" bson:"thumbnail_url"`
+	OriginalURL  string             `json:"original_url" bson:"original_url"`
+	Width        int                `json:"width" bson:"width"`
+	Height       int                `json:"height" bson:"height"`
+	Blurhash     string             `json:"blurhash" bson:"blurhash"`
+}
+
+// Mention links a piece of a text to the mentioned user, so it keeps pointing to them after a username change.
+// Start and End are counted in UTF-16 code units, see util.ExtractMentions
+type Mention struct {
+	UserID primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Start  int                `json:"start" bson:"start"`
+	End    int                `json:"end" bson:"end"`
+}
+
+// Hashtag counts the posts that use the normalized hashtag
+type Hashtag struct {
+	Name      string    `json:"name" bson:"_id"`
+	PostCount int64     `json:"post_count" bson:"post_count"`
+	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"`
+}
+
+type Media struct {
+	ID         primitive.ObjectID `json:"id" bson:"_id"`
+	UserID     primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Width      int                `json:"width" bson:"width"`
+	Height     int                `json:"height" bson:"height"`
+	Blurhash   string             `json:"blurhash" bson:"blurhash"`
+	Renditions []Rendition        `json:"renditions" bson:"renditions"`
+	CreatedAt  time.Time          `json:"created_at" bson:"created_at"`
+}
+
+type Rendition struct {
+	Name        string `json:"name" bson:"name"`
+	Key         string `json:"-" bson:"key"`
+	URL         string `json:"url" bson:"url"`
+	ContentType string `json:"content_type" bson:"content_type"`
+	Width       int    `json:"width" bson:"width"`
+	Height      int    `json:"height" bson:"height"`
+	Size        int64  `json:"size" bson:"size"`
 }
 
 type Like struct {
@@ -33,20 +99,180 @@ type Like struct {
 	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
 }
 
+// Comment is either a top-level comment of a post or a reply to another comment of the same post.
+// The target is what it answers: the post for a top-level comment and the parent for a reply.
+// It keeps denormalized counts like Post, its comment count is the number of replies.
+// A deleted comment with replies is kept as a tombstone, without content or author, so the replies keep their place.
+// A comment removed by a moderator is hidden like a post
 type Comment struct {
+	ID           primitive.ObjectID `json:"id" bson:"_id"`
+	UserID       primitive.ObjectID `json:"user_id" bson:"user_id,omitempty"`
+	TargetID     primitive.ObjectID `json:"target_id" bson:"target_id"`
+	ParentID     primitive.ObjectID `json:"parent_id" bson:"parent_id,omitempty"`
+	RootPostID   primitive.ObjectID `json:"root_post_id" bson:"root_post_id"`
+	Content      string             `json:"content" bson:"content"`
+	Mentions     []Mention          `json:"mentions" bson:"mentions,omitempty"`
+	LikeCount    int64              `json:"like_count" bson:"like_count"`
+	CommentCount int64              `json:"comment_count" bson:"comment_count"`
+	Deleted      bool               `json:"deleted" bson:"deleted,omitempty"`
+	Removed      bool               `json:"removed" bson:"removed,omitempty"`
+	CreatedAt    time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// Notification groups the events of the same type on the same target while it is unread, so it can be shown
+// as "X and 12 others liked your post". ActorIDs keeps the latest actors, the newest first, and ActorCount
+// how many there were. The target is the liked, commented or mentioning post or comment, or the followed user
+type Notification struct {
+	ID         primitive.ObjectID   `json:"id" bson:"_id"`
+	UserID     primitive.ObjectID   `json:"user_id" bson:"user_id"`
+	Type       string               `json:"type" bson:"type"`
+	TargetID   primitive.ObjectID   `json:"target_id" bson:"target_id"`
+	ActorIDs   []primitive.ObjectID `json:"actor_ids" bson:"actor_ids"`
+	ActorCount int64                `json:"actor_count" bson:"actor_count"`
+	Read       bool                 `json:"read" bson:"read"`
+	CreatedAt  time.Time            `json:"created_at" bson:"created_at"`
+	UpdatedAt  time.Time            `json:"updated_at" bson:"updated_at"`
+}
+
+// Conversation is a one-to-one or small group chat. A one-to-one conversation has a DirectKey made of
+// its two members, so each pair of users shares a single one
+type Conversation struct {
+	ID            primitive.ObjectID   `json:"id" bson:"_id"`
+	Members       []ConversationMember `json:"members" bson:"members"`
+	DirectKey     string               `json:"-" bson:"direct_key,omitempty"`
+	LastMessageAt time.Time            `json:"last_message_at" bson:"last_message_at"`
+	CreatedAt     time.Time            `json:"created_at" bson:"created_at"`
+}
+
+// ConversationMember keeps the read cursor of the member, the last message it has read.
+// The later messages of the other members are the unread ones
+type ConversationMember struct {
+	UserID     primitive.ObjectID `json:"user_id" bson:"user_id"`
+	LastReadID primitive.ObjectID `json:"last_read_id" bson:"last_read_id"`
+}
+
+// Message is a text of a conversation, which may share a post. A deleted message is kept without content
+// or post, so the history shows where it was
+type Message struct {
+	ID             primitive.ObjectID `json:"id" bson:"_id"`
+	ConversationID primitive.ObjectID `json:"conversation_id" bson:"conversation_id"`
+	UserID         primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Content        string             `json:"content" bson:"content"`
+	PostID         primitive.ObjectID `json:"post_id" bson:"post_id,omitempty"`
+	Deleted        bool               `json:"deleted" bson:"deleted,omitempty"`
+	EditedAt       time.Time          `json:"edited_at" bson:"edited_at,omitempty"`
+	CreatedAt      time.Time          `json:"created_at" bson:"created_at"`
+}
+
+type Follow struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id"`
+	FollowerID  primitive.ObjectID `json:"follower_id" bson:"follower_id"`
+	FollowingID primitive.ObjectID `json:"following_id" bson:"following_id"`
+	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// FollowRequest is a pending follow of a private account, waiting for its owner to approve it
+type FollowRequest struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id"`
+	FollowerID  primitive.ObjectID `json:"follower_id" bson:"follower_id"`
+	FollowingID primitive.ObjectID `json:"following_id" bson:"following_id"`
+	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// Block keeps the blocked user away from the blocker, in both directions
+type Block struct {
 	ID        primitive.ObjectID `json:"id" bson:"_id"`
-	UserID    primitive.ObjectID `json:"user_id" bson:"user_id"`
-	TargetID  primitive.ObjectID `json:"target_id" bson:"target_id"`
-	Content   string             `json:"content" bson:"content"`
+	BlockerID primitive.ObjectID `json:"blocker_id" bson:"blocker_id"`
+	BlockedID primitive.ObjectID `json:"blocked_id" bson:"blocked_id"`
 	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
 }
 
+// Mute hides the muted user from the feed and the notifications of the muter
+type Mute struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id"`
+	MuterID   primitive.ObjectID `json:"muter_id" bson:"muter_id"`
+	MutedID   primitive.ObjectID `json:"muted_id" bson:"muted_id"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// Report flags a post, comment or user for the moderators. The open reports of a target are resolved
+// together by a moderation action, which ResolutionID points to
+type Report struct {
+	ID           primitive.ObjectID `json:"id" bson:"_id"`
+	ReporterID   primitive.ObjectID `json:"reporter_id" bson:"reporter_id"`
+	TargetType   string             `json:"target_type" bson:"target_type"`
+	TargetID     primitive.ObjectID `json:"target_id" bson:"target_id"`
+	Reason       string             `json:"reason" bson:"reason"`
+	Details      string             `json:"details" bson:"details"`
+	Status       string             `json:"status" bson:"status"`
+	ResolutionID primitive.ObjectID `json:"resolution_id" bson:"resolution_id,omitempty"`
+	CreatedAt    time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// ModerationAction is an entry of the moderation history. UserID is the author of the target,
+// the one suspended by a suspension
+type ModerationAction struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id"`
+	ModeratorID primitive.ObjectID `json:"moderator_id" bson:"moderator_id"`
+	TargetType  string             `json:"target_type" bson:"target_type"`
+	TargetID    primitive.ObjectID `json:"target_id" bson:"target_id"`
+	UserID      primitive.ObjectID `json:"user_id" bson:"user_id,omitempty"`
+	Action      string             `json:"action" bson:"action"`
+	Note        string             `json:"note" bson:"note"`
+	ReportCount int64              `json:"report_count" bson:"report_count"`
+	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
+}
+
+type TimelineEntry struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id"`
+	OwnerID   primitive.ObjectID `json:"owner_id" bson:"owner_id"`
+	PostID    primitive.ObjectID `json:"post_id" bson:"post_id"`
+	AuthorID  primitive.ObjectID `json:"author_id" bson:"author_id"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// TimelineJob is a queued change of the timelines. A fan-out saves the last follow it went through,
+// so an interrupted one resumes after it
+type TimelineJob struct {
+	ID           primitive.ObjectID `json:"id" bson:"_id"`
+	Kind         string             `json:"kind" bson:"kind"`
+	PostID       primitive.ObjectID `json:"post_id" bson:"post_id"`
+	AuthorID     primitive.ObjectID `json:"author_id" bson:"author_id"`
+	OwnerID      primitive.ObjectID `json:"owner_id" bson:"owner_id"`
+	Status       string             `json:"status" bson:"status"`
+	Processed    int64              `json:"processed" bson:"processed"`
+	LastFollowID primitive.ObjectID `json:"last_follow_id" bson:"last_follow_id"`
+	Error        string             `json:"error" bson:"error"`
+	CreatedAt    time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt    time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// PurgeJob tracks the deletion of an account, which is run in steps so it can resume after an interruption
+type PurgeJob struct {
+	ID         primitive.ObjectID `json:"id" bson:"_id"`
+	UserID     primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Status     string             `json:"status" bson:"status"`
+	Step       string             `json:"step" bson:"step"`
+	Processed  int64              `json:"processed" bson:"processed"`
+	Error      string             `json:"error" bson:"error"`
+	PurgeAfter time.Time          `json:"purge_after" bson:"purge_after"`
+	CreatedAt  time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt  time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// Session holds a refresh token. Every refresh rotates the session into a new one of the same family,
+// the sessions created from the same login
 type Session struct {
 	ID           primitive.ObjectID `json:"id" bson:"_id"`
 	UserID       primitive.ObjectID `json:"user_id" bson:"user_id"`
+	FamilyID     primitive.ObjectID `json:"family_id" bson:"family_id"`
+	ParentID     primitive.ObjectID `json:"parent_id" bson:"parent_id,omitempty"`
 	RefreshToken string             `json:"refresh_token" bson:"refresh_token"`
 	UserAgent    string             `json:"user_agent" bson:"user_agent"`
 	ClientIP     string             `json:"client_ip" bson:"client_ip"`
 	IsBlocked    bool               `json:"is_blocked" bson:"is_blocked"`
+	RotatedAt    time.Time          `json:"rotated_at" bson:"rotated_at,omitempty"`
 	ExpiresAt    time.Time          `json:"expires_at" bson:"expires_at"`
+	LastUsedAt   time.Time          `json:"last_used_at" bson:"last_used_at,omitempty"`
+	CreatedAt    time.Time          `json:"created_at" bson:"created_at"`
 }
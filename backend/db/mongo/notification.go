@@ -0,0 +1,269 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// The types of the notifications
+const (
+	NotificationLike    = "like"
+	NotificationComment = "comment"
+	NotificationReply   = "reply"
+	NotificationFollow  = "follow"
+	NotificationMention = "mention"
+
+	NotificationFollowRequest = "follow_request"
+)
+
+// NotificationTypes are all the notification types, which can be disabled one by one
+var NotificationTypes = []string{
+	NotificationLike,
+	NotificationComment,
+	NotificationReply,
+	NotificationFollow,
+	NotificationMention,
+	NotificationFollowRequest,
+}
+
+// maxNotificationActors is the number of latest actors kept in a notification
+const maxNotificationActors = 3
+
+type notifyParams struct {
+	UserID   primitive.ObjectID
+	Type     string
+	ActorID  primitive.ObjectID
+	TargetID primitive.ObjectID
+}
+
+// notify adds the event to the unread notification of the user with the same type and target, creating
+// it if needed, and passes the result to the notifier. Nothing is notified when users act on their own things,
+// when they disabled the type or when they muted or blocked the actor. An actor that repeats the event while being among the latest ones isn't
+// counted again
+func (q *Queries) notify(ctx context.Context, arg notifyParams) error {
+	notification, err := q.upsertNotification(ctx, arg)
+	if err != nil || notification == nil {
+		return err
+	}
+
+	if q.notifier != nil {
+		q.notifier.Notify(*notification)
+	}
+
+	return nil
+}
+
+func (q *Queries) upsertNotification(ctx context.Context, arg notifyParams) (*Notification, error) {
+	if arg.UserID.IsZero() || arg.UserID == arg.ActorID {
+		return nil, nil
+	}
+
+	user, err := q.GetUser(ctx, "_id", arg.UserID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if enabled, ok := user.NotificationPreferences[arg.Type]; ok && !enabled {
+		return nil, nil
+	}
+
+	silenced, err := q.isSilenced(ctx, arg.UserID, arg.ActorID)
+	if err != nil || silenced {
+		return nil, err
+	}
+
+	now := time.Now()
+	filter := bson.M{
+		"user_id":   arg.UserID,
+		"type":      arg.Type,
+		"target_id": arg.TargetID,
+		"read":      false,
+	}
+	coll := q.db.Collection("notifications")
+
+	// two requests may create the same notification at once, the unique index keeps one of them
+	// and the other one is retried, finding it
+	for attempt := 0; ; attempt++ {
+		newActor := bson.M{"actor_ids": bson.M{"$ne": arg.ActorID}}
+		for key, value := range filter {
+			newActor[key] = value
+		}
+
+		update := bson.M{
+			"$push": bson.M{"actor_ids": bson.M{
+				"$each":     bson.A{arg.ActorID},
+				"$position": 0,
+				"$slice":    maxNotificationActors,
+			}},
+			"$inc": bson.M{"actor_count": 1},
+			"$set": bson.M{"updated_at": now},
+		}
+		opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+		var notification Notification
+		err := coll.FindOneAndUpdate(ctx, newActor, update, opts).Decode(&notification)
+		if err != mongo.ErrNoDocuments {
+			return &notification, err
+		}
+
+		insert := bson.M{
+			"$setOnInsert": bson.M{
+				"_id":         primitive.NewObjectID(),
+				"actor_ids":   bson.A{arg.ActorID},
+				"actor_count": 1,
+				"created_at":  now,
+			},
+			"$set": bson.M{"updated_at": now},
+		}
+		opts.SetUpsert(true)
+
+		err = coll.FindOneAndUpdate(ctx, filter, insert, opts).Decode(&notification)
+		if err == nil || !mongo.IsDuplicateKeyError(err) || attempt > 0 {
+			return &notification, err
+		}
+	}
+}
+
+// notifyTargetOwner notifies the author of the post or comment the event is about
+func (q *Queries) notifyTargetOwner(ctx context.Context, arg notifyParams) error {
+	var target struct {
+		UserID primitive.ObjectID `bson:"user_id"`
+	}
+
+	opts := options.FindOne().SetProjection(bson.M{"user_id": 1})
+	err := q.db.Collection("posts").FindOne(ctx, bson.M{"_id": arg.TargetID}, opts).Decode(&target)
+	if err == mongo.ErrNoDocuments {
+		err = q.db.Collection("comments").FindOne(ctx, bson.M{"_id": arg.TargetID}, opts).Decode(&target)
+	}
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil
+		}
+		return err
+	}
+
+	arg.UserID = target.UserID
+	return q.notify(ctx, arg)
+}
+
+// notifyMentions notifies the users mentioned in the post or comment, skipping the ones in previous,
+// which were already mentioned before an edit
+func (q *Queries) notifyMentions(ctx context.Context, actorID, targetID primitive.ObjectID, mentions, previous []Mention) error {
+	notified := make(map[primitive.ObjectID]bool)
+	for _, mention := range previous {
+		notified[mention.UserID] = true
+	}
+
+	for _, mention := range mentions {
+		if notified[mention.UserID] {
+			continue
+		}
+		notified[mention.UserID] = true
+
+		err := q.notify(ctx, notifyParams{
+			UserID:   mention.UserID,
+			Type:     NotificationMention,
+			ActorID:  actorID,
+			TargetID: targetID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type ListNotificationsParams struct {
+	UserID primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Offset int64              `json:"offset" bson:"offset"`
+	Limit  int64              `json:"limit" bson:"limit"`
+}
+
+// ListNotifications lists the notifications of the user, the most recently updated first
+func (q *Queries) ListNotifications(ctx context.Context, arg ListNotificationsParams) ([]Notification, error) {
+	filter := bson.D{primitive.E{Key: "user_id", Value: arg.UserID}}
+	opts := options.Find().
+		SetSort(bson.D{
+			primitive.E{Key: "updated_at", Value: -1},
+			primitive.E{Key: "_id", Value: -1},
+		}).
+		SetSkip(arg.Offset).
+		SetLimit(arg.Limit)
+
+	var notifications []Notification
+	coll := q.db.Collection("notifications")
+	cursor, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	for cursor.Next(ctx) {
+		var notification Notification
+		err = cursor.Decode(&notification)
+		if err != nil {
+			return nil, err
+		}
+
+		notifications = append(notifications, notification)
+	}
+
+	return notifications, nil
+}
+
+func (q *Queries) CountUnreadNotifications(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	filter := bson.D{
+		primitive.E{Key: "user_id", Value: userID},
+		primitive.E{Key: "read", Value: false},
+	}
+
+	coll := q.db.Collection("notifications")
+	return coll.CountDocuments(ctx, filter)
+}
+
+type MarkNotificationsReadParams struct {
+	UserID primitive.ObjectID   `json:"user_id" bson:"user_id"`
+	IDs    []primitive.ObjectID `json:"ids" bson:"ids"`
+}
+
+// MarkNotificationsRead marks the given notifications of the user as read, or all of them when IDs is empty.
+// The next events start new notifications
+func (q *Queries) MarkNotificationsRead(ctx context.Context, arg MarkNotificationsReadParams) (*mongo.UpdateResult, error) {
+	filter := bson.M{"user_id": arg.UserID, "read": false}
+	if len(arg.IDs) > 0 {
+		filter["_id"] = bson.M{"$in": arg.IDs}
+	}
+	update := bson.M{"$set": bson.M{"read": true}}
+
+	coll := q.db.Collection("notifications")
+	return coll.UpdateMany(ctx, filter, update)
+}
+
+type UpdateNotificationPreferencesParams struct {
+	UserID      primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Preferences map[string]bool    `json:"preferences" bson:"preferences"`
+}
+
+// UpdateNotificationPreferences enables or disables the given notification types of the user,
+// leaving the other ones as they were
+func (q *Queries) UpdateNotificationPreferences(ctx context.Context, arg UpdateNotificationPreferencesParams) (*mongo.UpdateResult, error) {
+	set := bson.M{}
+	for kind, enabled := range arg.Preferences {
+		set["notification_preferences."+kind] = enabled
+	}
+
+	if len(set) == 0 {
+		return nil, ErrNothingToUpdate
+	}
+
+	coll := q.db.Collection("users")
+	return coll.UpdateByID(ctx, arg.UserID, bson.M{"$set": set})
+}
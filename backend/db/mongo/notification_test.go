@@ -0,0 +1,193 @@
+package db
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func listUserNotifications(t *testing.T, userID primitive.ObjectID) []Notification {
+	notifications, err := testQueries.ListNotifications(testCtx, ListNotificationsParams{UserID: userID, Limit: 10})
+	require.NoError(t, err)
+
+	return notifications
+}
+
+func TestLikeNotificationsAggregate(t *testing.T) {
+	author := randomUser(t)
+	post := randomPostByUser(t, author.ID)
+
+	actors := make([]primitive.ObjectID, 5)
+	for i := range actors {
+		actors[i] = randomUser(t).ID
+		randomLike(t, actors[i], post.ID)
+	}
+
+	// the likes of the author and a repeated like of a recent actor don't count
+	randomLike(t, author.ID, post.ID)
+	_, err := testQueries.DeleteLike(testCtx, LikeParams{UserID: actors[4], TargetID: post.ID})
+	require.NoError(t, err)
+	randomLike(t, actors[4], post.ID)
+
+	notifications := listUserNotifications(t, author.ID)
+	require.Len(t, notifications, 1)
+	require.Equal(t, NotificationLike, notifications[0].Type)
+	require.Equal(t, post.ID, notifications[0].TargetID)
+	require.EqualValues(t, 5, notifications[0].ActorCount)
+	require.Equal(t, []primitive.ObjectID{actors[4], actors[3], actors[2]}, notifications[0].ActorIDs)
+	require.False(t, notifications[0].Read)
+}
+
+func TestNotifyConcurrent(t *testing.T) {
+	author := randomUser(t)
+	post := randomPostByUser(t, author.ID)
+
+	n := 10
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		actorID := randomUser(t).ID
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := testQueries.CreateLike(testCtx, LikeParams{UserID: actorID, TargetID: post.ID})
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	notifications := listUserNotifications(t, author.ID)
+	require.Len(t, notifications, 1)
+	require.EqualValues(t, n, notifications[0].ActorCount)
+}
+
+func TestCommentNotifications(t *testing.T) {
+	author := randomUser(t)
+	commenter := randomUser(t)
+	replier := randomUser(t)
+	post := randomPostByUser(t, author.ID)
+
+	comment := randomComment(t, commenter.ID, post.ID)
+	reply := randomReply(t, replier.ID, comment)
+
+	notifications := listUserNotifications(t, author.ID)
+	require.Len(t, notifications, 1)
+	require.Equal(t, NotificationComment, notifications[0].Type)
+	require.Equal(t, post.ID, notifications[0].TargetID)
+	require.Equal(t, []primitive.ObjectID{commenter.ID}, notifications[0].ActorIDs)
+
+	notifications = listUserNotifications(t, commenter.ID)
+	require.Len(t, notifications, 1)
+	require.Equal(t, NotificationReply, notifications[0].Type)
+	require.Equal(t, comment.ID, notifications[0].TargetID)
+	require.Equal(t, []primitive.ObjectID{replier.ID}, notifications[0].ActorIDs)
+
+	// the notifications go away with what they are about
+	_, err := testQueries.DeleteComment(testCtx, reply.ID)
+	require.NoError(t, err)
+	require.Empty(t, listUserNotifications(t, commenter.ID))
+
+	_, err = testQueries.DeletePost(testCtx, post.ID)
+	require.NoError(t, err)
+	require.Empty(t, listUserNotifications(t, author.ID))
+}
+
+func TestFollowAndMentionNotifications(t *testing.T) {
+	user := randomUser(t)
+	follower := randomUser(t)
+
+	randomFollow(t, follower.ID, user.ID)
+	post := randomPostByUserWithDescription(t, follower.ID, "hi @"+user.Username)
+
+	// editing the post doesn't notify the mention again
+	_, err := testQueries.UpdatePost(testCtx, UpdatePostParams{
+		ID:          post.ID,
+		Images:      post.Images,
+		Description: "hello @" + user.Username,
+	})
+	require.NoError(t, err)
+
+	notifications := listUserNotifications(t, user.ID)
+	require.Len(t, notifications, 2)
+
+	require.Equal(t, NotificationMention, notifications[0].Type)
+	require.Equal(t, post.ID, notifications[0].TargetID)
+	require.EqualValues(t, 1, notifications[0].ActorCount)
+
+	require.Equal(t, NotificationFollow, notifications[1].Type)
+	require.Equal(t, user.ID, notifications[1].TargetID)
+	require.Equal(t, []primitive.ObjectID{follower.ID}, notifications[1].ActorIDs)
+}
+
+func TestMarkNotificationsRead(t *testing.T) {
+	author := randomUser(t)
+	post1 := randomPostByUser(t, author.ID)
+	post2 := randomPostByUser(t, author.ID)
+	randomLike(t, randomUser(t).ID, post1.ID)
+	randomLike(t, randomUser(t).ID, post2.ID)
+
+	nUnread, err := testQueries.CountUnreadNotifications(testCtx, author.ID)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, nUnread)
+
+	notifications := listUserNotifications(t, author.ID)
+	result, err := testQueries.MarkNotificationsRead(testCtx, MarkNotificationsReadParams{
+		UserID: author.ID,
+		IDs:    []primitive.ObjectID{notifications[0].ID},
+	})
+	require.NoError(t, err)
+	require.EqualValues(t, 1, result.ModifiedCount)
+
+	nUnread, err = testQueries.CountUnreadNotifications(testCtx, author.ID)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, nUnread)
+
+	// another user can't mark them
+	result, err = testQueries.MarkNotificationsRead(testCtx, MarkNotificationsReadParams{UserID: randomUser(t).ID})
+	require.NoError(t, err)
+	require.Zero(t, result.ModifiedCount)
+
+	result, err = testQueries.MarkNotificationsRead(testCtx, MarkNotificationsReadParams{UserID: author.ID})
+	require.NoError(t, err)
+	require.EqualValues(t, 1, result.ModifiedCount)
+
+	// a new like after reading starts a new notification
+	randomLike(t, randomUser(t).ID, post1.ID)
+
+	nUnread, err = testQueries.CountUnreadNotifications(testCtx, author.ID)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, nUnread)
+	require.Len(t, listUserNotifications(t, author.ID), 3)
+}
+
+func TestNotificationPreferences(t *testing.T) {
+	author := randomUser(t)
+	post := randomPostByUser(t, author.ID)
+
+	_, err := testQueries.UpdateNotificationPreferences(testCtx, UpdateNotificationPreferencesParams{
+		UserID:      author.ID,
+		Preferences: map[string]bool{NotificationLike: false},
+	})
+	require.NoError(t, err)
+
+	_, err = testQueries.UpdateNotificationPreferences(testCtx, UpdateNotificationPreferencesParams{UserID: author.ID})
+	require.ErrorIs(t, err, ErrNothingToUpdate)
+
+	randomLike(t, randomUser(t).ID, post.ID)
+	randomComment(t, randomUser(t).ID, post.ID)
+
+	gotUser, err := testQueries.GetUser(testCtx, "_id", author.ID)
+	require.NoError(t, err)
+	require.Equal(t, map[string]bool{NotificationLike: false}, gotUser.NotificationPreferences)
+
+	notifications := listUserNotifications(t, author.ID)
+	require.Len(t, notifications, 1)
+	require.Equal(t, NotificationComment, notifications[0].Type)
+}
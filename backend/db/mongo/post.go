@@ -2,31 +2,90 @@ package db
 
 import (
 	"context"
+	"errors"
 	"time"
 
+	"github.com/DMV-Nicolas/robotgram/backend/util"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
 )
 
+// UnmarshalBSONValue also reads the images of the posts created before the media uploads,
+// which were stored as a bare URL
+func (image *Image) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	if t == bson.TypeString {
+		url, _, ok := bsoncore.ReadString(data)
+		if !ok {
+			return errors.New("invalid image url")
+		}
+
+		*image = Image{URL: url, ThumbnailURL: url, OriginalURL: url}
+		return nil
+	}
+
+	// the alias doesn't have this method, so decoding it doesn't come back here
+	type storedImage Image
+	return bson.UnmarshalValue(t, data, (*storedImage)(image))
+}
+
 type CreatePostParams struct {
 	UserID      primitive.ObjectID `json:"user_id" bson:"user_id"`
-	Images      []string           `json:"images" bson:"images"`
+	Images      []Image            `json:"images" bson:"images"`
 	Description string             `json:"description" bson:"description"`
 }
 
+// CreatePost inserts the post with the hashtags and the mentions of its description
 func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (*mongo.InsertOneResult, error) {
+	author, err := q.GetUser(ctx, "_id", arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	mentions, err := q.resolveMentions(ctx, arg.UserID, arg.Description)
+	if err != nil {
+		return nil, err
+	}
+
 	post := Post{
 		ID:          primitive.NewObjectID(),
 		UserID:      arg.UserID,
 		Images:      arg.Images,
 		Description: arg.Description,
+		Hashtags:    util.ExtractHashtags(arg.Description),
+		Mentions:    mentions,
+		Private:     author.IsPrivate,
 		CreatedAt:   time.Now(),
 	}
 
-	coll := q.db.Collection("posts")
-	result, err := coll.InsertOne(ctx, post)
+	var result *mongo.InsertOneResult
+	err = q.execTx(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = q.db.Collection("posts").InsertOne(ctx, post)
+		if err != nil {
+			return err
+		}
+
+		return q.incHashtags(ctx, hashtagCounts(post.Hashtags, nil))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// the post is copied to the followers timelines in background
+	_, err = q.EnqueueTimelineJob(ctx, EnqueueTimelineJobParams{
+		Kind:     TimelineJobFanout,
+		PostID:   post.ID,
+		AuthorID: post.UserID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = q.notifyMentions(ctx, post.UserID, post.ID, post.Mentions, nil)
 
 	return result, err
 }
@@ -43,22 +102,33 @@ func (q *Queries) GetPost(ctx context.Context, key string, value any) (Post, err
 }
 
 type ListPostsParams struct {
-	Offset int64              `json:"offset" bson:"offset"`
-	Limit  int64              `json:"limit" bson:"limit"`
-	UserID primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Offset   int64              `json:"offset" bson:"offset"`
+	Limit    int64              `json:"limit" bson:"limit"`
+	UserID   primitive.ObjectID `json:"user_id" bson:"user_id"`
+	ViewerID primitive.ObjectID `json:"viewer_id" bson:"viewer_id"`
 }
 
+// ListPosts lists the posts of the user, or of everyone if no user is given, the newest first. It hides
+// the posts of the private accounts the viewer doesn't follow
 func (q *Queries) ListPosts(ctx context.Context, arg ListPostsParams) ([]Post, error) {
-	var filter bson.D
-	if arg.UserID.IsZero() {
-		filter = bson.D{}
-	} else {
-		filter = bson.D{primitive.E{Key: "user_id", Value: arg.UserID}}
+	filter, err := q.visiblePostsFilter(ctx, arg.ViewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !arg.UserID.IsZero() {
+		filter["user_id"] = arg.UserID
 	}
 
+	// the pages must follow a stable order, the filter alone doesn't give one
+	opts := options.Find().
+		SetSort(bson.D{primitive.E{Key: "_id", Value: -1}}).
+		SetSkip(arg.Offset).
+		SetLimit(arg.Limit)
+
 	var posts []Post
 	coll := q.db.Collection("posts")
-	cursor, err := coll.Find(ctx, filter, options.Find().SetSkip(arg.Offset).SetLimit(arg.Limit))
+	cursor, err := coll.Find(ctx, filter, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -76,32 +146,138 @@ func (q *Queries) ListPosts(ctx context.Context, arg ListPostsParams) ([]Post, e
 	return posts, nil
 }
 
+// visiblePostsFilter matches the posts the viewer can see: the public ones, its own ones and the ones of
+// the private accounts it follows, leaving out the users blocked in any direction, the accounts being
+// deleted and the posts removed by a moderator. A zero viewer only sees the public posts
+func (q *Queries) visiblePostsFilter(ctx context.Context, viewerID primitive.ObjectID) (bson.M, error) {
+	hidden, err := q.pendingPurgeUserIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if viewerID.IsZero() {
+		filter := bson.M{"private": bson.M{"$ne": true}, "removed": bson.M{"$ne": true}}
+		if len(hidden) > 0 {
+			// under $and, so the callers can still filter on the user
+			filter["$and"] = bson.A{bson.M{"user_id": bson.M{"$nin": hidden}}}
+		}
+		return filter, nil
+	}
+
+	followees, err := q.db.Collection("follows").Distinct(ctx, "following_id", bson.M{"follower_id": viewerID})
+	if err != nil {
+		return nil, err
+	}
+
+	blocked, err := q.blockedUserIDs(ctx, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	hidden = append(hidden, blocked...)
+
+	visible := bson.M{
+		"$or": bson.A{
+			bson.M{"private": bson.M{"$ne": true}},
+			bson.M{"user_id": bson.M{"$in": append(followees, viewerID)}},
+		},
+		"removed": bson.M{"$ne": true},
+	}
+	if len(hidden) == 0 {
+		return visible, nil
+	}
+
+	filter := bson.M{
+		"$and": bson.A{
+			visible,
+			bson.M{"user_id": bson.M{"$nin": hidden}},
+		},
+	}
+
+	return filter, nil
+}
+
 type UpdatePostParams struct {
 	ID          primitive.ObjectID `json:"id" bson:"_id"`
-	Images      []string           `json:"images" bson:"images"`
+	Images      []Image            `json:"images" bson:"images"`
 	Description string             `json:"description" bson:"description"`
 }
 
+// UpdatePost replaces the images and the description of the post, parsing its hashtags and mentions again
 func (q *Queries) UpdatePost(ctx context.Context, arg UpdatePostParams) (*mongo.UpdateResult, error) {
+	current, err := q.GetPost(ctx, "_id", arg.ID)
+	if err == mongo.ErrNoDocuments {
+		return &mongo.UpdateResult{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	mentions, err := q.resolveMentions(ctx, current.UserID, arg.Description)
+	if err != nil {
+		return nil, err
+	}
+
+	hashtags := util.ExtractHashtags(arg.Description)
 	filter := bson.M{"_id": arg.ID}
 	update := bson.M{
 		"$set": bson.M{
 			"images":      arg.Images,
 			"description": arg.Description,
+			"hashtags":    hashtags,
+			"mentions":    mentions,
 		},
 	}
+	opts := options.FindOneAndUpdate().SetProjection(bson.M{"user_id": 1, "hashtags": 1, "mentions": 1})
 
-	coll := q.db.Collection("posts")
-	result, err := coll.UpdateOne(ctx, filter, update)
+	var old Post
+	result := &mongo.UpdateResult{}
+	err = q.execTx(ctx, func(ctx context.Context) error {
+		// the post as it was before the update tells which hashtags stopped being used
+		err := q.db.Collection("posts").FindOneAndUpdate(ctx, filter, update, opts).Decode(&old)
+		if err == mongo.ErrNoDocuments {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		result.MatchedCount = 1
+		result.ModifiedCount = 1
+
+		return q.incHashtags(ctx, hashtagCounts(hashtags, old.Hashtags))
+	})
+	if err != nil || result.MatchedCount == 0 {
+		return result, err
+	}
+
+	// only the users added by the edit are notified
+	err = q.notifyMentions(ctx, old.UserID, arg.ID, mentions, old.Mentions)
 
 	return result, err
 }
 
-func (q *Queries) DeletePost(ctx context.Context, id primitive.ObjectID) (*mongo.DeleteResult, error) {
-	filter := bson.M{"_id": id}
+// hashtagCounts returns how the post count of each hashtag changes when a post goes from using
+// the removed hashtags to using the added ones
+func hashtagCounts(added, removed []string) map[string]int64 {
+	counts := make(map[string]int64)
+	for _, name := range added {
+		counts[name]++
+	}
+	for _, name := range removed {
+		counts[name]--
+	}
 
-	coll := q.db.Collection("posts")
-	result, err := coll.DeleteOne(ctx, filter)
+	return counts
+}
+
+// DeletePost removes the post along with its comments, its likes and its timeline entries
+func (q *Queries) DeletePost(ctx context.Context, id primitive.ObjectID) (*mongo.DeleteResult, error) {
+	result := &mongo.DeleteResult{}
+	err := q.execTx(ctx, func(ctx context.Context) error {
+		var err error
+		result.DeletedCount, err = q.deletePosts(ctx, []primitive.ObjectID{id})
+		return err
+	})
 
 	return result, err
 }
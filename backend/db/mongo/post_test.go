@@ -6,15 +6,20 @@ import (
 
 	"github.com/DMV-Nicolas/robotgram/backend/util"
 	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
 func randomPost(t *testing.T) Post {
 	user := randomUser(t)
+	return randomPostByUser(t, user.ID)
+}
+
+func randomPostByUser(t *testing.T, userID primitive.ObjectID) Post {
 	arg := CreatePostParams{
-		UserID:      user.ID,
-		Images:      util.RandomImages(3),
+		UserID:      userID,
+		Images:      []Image{randomImage(t, userID)},
 		Description: util.RandomPassword(100),
 	}
 
@@ -39,6 +44,19 @@ func randomPost(t *testing.T) Post {
 	return post
 }
 
+func randomImage(t *testing.T, userID primitive.ObjectID) Image {
+	media := randomMedia(t, userID)
+	return Image{
+		MediaID:      media.ID,
+		URL:          media.Renditions[0].URL,
+		ThumbnailURL: media.Renditions[0].URL,
+		OriginalURL:  media.Renditions[0].URL,
+		Width:        media.Width,
+		Height:       media.Height,
+		Blurhash:     media.Blurhash,
+	}
+}
+
 func TestCreatePost(t *testing.T) {
 	randomPost(t)
 }
@@ -57,6 +75,30 @@ func TestGetPost(t *testing.T) {
 	require.WithinDuration(t, post1.CreatedAt, post2.CreatedAt, time.Second)
 }
 
+func TestGetLegacyPost(t *testing.T) {
+	user := randomUser(t)
+	image := util.RandomImage()
+
+	// the posts created before the media uploads stored the images as bare URLs
+	postID := primitive.NewObjectID()
+	db := testQueries.(*Queries).db
+	_, err := db.Collection("posts").InsertOne(testCtx, bson.M{
+		"_id":           postID,
+		"user_id":       user.ID,
+		"images":        bson.A{image},
+		"description":   util.RandomDescription(10),
+		"hashtags":      bson.A{},
+		"like_count":    0,
+		"comment_count": 0,
+		"created_at":    time.Now(),
+	})
+	require.NoError(t, err)
+
+	post, err := testQueries.GetPost(testCtx, "_id", postID)
+	require.NoError(t, err)
+	require.Equal(t, []Image{{URL: image, ThumbnailURL: image, OriginalURL: image}}, post.Images)
+}
+
 func TestListPosts(t *testing.T) {
 	n := 10
 	lastPost := Post{}
@@ -89,12 +131,33 @@ func TestListPosts(t *testing.T) {
 	require.Equal(t, lastPost, posts[0])
 }
 
+func TestListPostsOrder(t *testing.T) {
+	user := randomUser(t)
+	n := 5
+	ids := make([]primitive.ObjectID, n)
+	for i := 0; i < n; i++ {
+		ids[n-1-i] = randomPostByUser(t, user.ID).ID
+	}
+
+	// the pages follow each other without repeating or skipping posts
+	var got []primitive.ObjectID
+	for offset := 0; offset < n; offset += 2 {
+		posts, err := testQueries.ListPosts(testCtx, ListPostsParams{UserID: user.ID, Offset: int64(offset), Limit: 2})
+		require.NoError(t, err)
+
+		for _, post := range posts {
+			got = append(got, post.ID)
+		}
+	}
+	require.Equal(t, ids, got)
+}
+
 func TestUpdatePost(t *testing.T) {
 	post1 := randomPost(t)
 
 	arg := UpdatePostParams{
 		ID:          post1.ID,
-		Images:      util.RandomImages(5),
+		Images:      []Image{randomImage(t, post1.UserID)},
 		Description: util.RandomPassword(200),
 	}
 
@@ -110,7 +173,7 @@ func TestUpdatePost(t *testing.T) {
 
 	require.Equal(t, post1.ID, post2.ID)
 	require.Equal(t, post1.UserID, post2.UserID)
-	require.NotEqual(t, post1.Images, post2.Images)
+	require.Equal(t, arg.Images, post2.Images)
 	require.NotEqual(t, post1.Description, post2.Description)
 	require.WithinDuration(t, post1.CreatedAt, post2.CreatedAt, time.Second)
 }
@@ -128,3 +191,26 @@ func TestDeletePost(t *testing.T) {
 	require.EqualError(t, mongo.ErrNoDocuments, err.Error())
 	require.Empty(t, post2)
 }
+
+func TestDeletePostCascade(t *testing.T) {
+	post := randomPost(t)
+	user := randomUser(t)
+	postLike := randomLike(t, user.ID, post.ID)
+	comment := randomComment(t, user.ID, post.ID)
+	commentLike := randomLike(t, post.UserID, comment.ID)
+	reply := randomReply(t, post.UserID, comment)
+
+	result, err := testQueries.DeletePost(testCtx, post.ID)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, result.DeletedCount)
+
+	for _, c := range []Comment{comment, reply} {
+		_, err = testQueries.GetComment(testCtx, c.ID)
+		require.ErrorIs(t, err, mongo.ErrNoDocuments)
+	}
+
+	for _, like := range []Like{postLike, commentLike} {
+		_, err = testQueries.GetLike(testCtx, like.ID)
+		require.ErrorIs(t, err, mongo.ErrNoDocuments)
+	}
+}
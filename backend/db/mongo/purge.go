@@ -0,0 +1,308 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// The steps of an account purge, in the order they are run
+const (
+	PurgeStepPosts         = "posts"
+	PurgeStepComments      = "comments"
+	PurgeStepLikes         = "likes"
+	PurgeStepFollows       = "follows"
+	PurgeStepTimeline      = "timeline"
+	PurgeStepSessions      = "sessions"
+	PurgeStepNotifications = "notifications"
+	PurgeStepMessages      = "messages"
+	PurgeStepMedia         = "media"
+	PurgeStepUser          = "user"
+)
+
+const (
+	PurgeJobScheduled  = "scheduled"
+	PurgeJobProcessing = "processing"
+	PurgeJobDone       = "done"
+	PurgeJobFailed     = "failed"
+	PurgeJobCanceled   = "canceled"
+)
+
+// purgeJobLease is the time after which a processing job is considered abandoned, or a failed
+// one is retried
+const purgeJobLease = 5 * time.Minute
+
+type PurgeUserStepParams struct {
+	UserID primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Step   string             `json:"step" bson:"step"`
+	Limit  int64              `json:"limit" bson:"limit"`
+}
+
+// PurgeUserStep removes up to limit documents of the user for the given step, along with what depends
+// on them, and returns how many were removed. A limit of 0 removes all of them.
+// The media files aren't reachable from here so the media step isn't supported
+func (q *Queries) PurgeUserStep(ctx context.Context, arg PurgeUserStepParams) (int64, error) {
+	var n int64
+	err := q.execTx(ctx, func(ctx context.Context) error {
+		var err error
+		n, err = q.purgeUserStep(ctx, arg)
+		return err
+	})
+
+	return n, err
+}
+
+func (q *Queries) purgeUserStep(ctx context.Context, arg PurgeUserStepParams) (int64, error) {
+	switch arg.Step {
+	case PurgeStepPosts:
+		ids, err := q.findIDs(ctx, "posts", bson.M{"user_id": arg.UserID}, arg.Limit)
+		if err != nil {
+			return 0, err
+		}
+		return q.deletePosts(ctx, ids)
+	case PurgeStepComments:
+		ids, err := q.findIDs(ctx, "comments", bson.M{"user_id": arg.UserID}, arg.Limit)
+		if err != nil {
+			return 0, err
+		}
+		return q.deleteComments(ctx, ids)
+	case PurgeStepLikes:
+		return q.deleteLikes(ctx, bson.M{"user_id": arg.UserID}, arg.Limit)
+	case PurgeStepFollows:
+		n, err := q.deleteFollows(ctx, arg.UserID, arg.Limit)
+		if err != nil || (arg.Limit > 0 && n == arg.Limit) {
+			return n, err
+		}
+
+		// the pending requests, the blocks and the mutes go away along with the last follows
+		requests, err := q.deleteFollowRequests(ctx, arg.UserID)
+		if err != nil {
+			return 0, err
+		}
+
+		blocks, err := q.deleteBlocksAndMutes(ctx, arg.UserID)
+		return n + requests + blocks, err
+	case PurgeStepTimeline:
+		return q.deleteUserDocuments(ctx, "timelines", "owner_id", arg.UserID, arg.Limit)
+	case PurgeStepSessions:
+		return q.deleteUserDocuments(ctx, "sessions", "user_id", arg.UserID, arg.Limit)
+	case PurgeStepNotifications:
+		return q.deleteUserDocuments(ctx, "notifications", "user_id", arg.UserID, arg.Limit)
+	case PurgeStepMessages:
+		n, err := q.deleteUserDocuments(ctx, "messages", "user_id", arg.UserID, arg.Limit)
+		if err != nil || (arg.Limit > 0 && n == arg.Limit) {
+			return n, err
+		}
+
+		// the user leaves its conversations once its messages are gone
+		left, err := q.leaveConversations(ctx, arg.UserID)
+		return n + left, err
+	default:
+		return 0, fmt.Errorf("unknown purge step: %s", arg.Step)
+	}
+}
+
+type SchedulePurgeParams struct {
+	UserID     primitive.ObjectID `json:"user_id" bson:"user_id"`
+	PurgeAfter time.Time          `json:"purge_after" bson:"purge_after"`
+}
+
+// SchedulePurge queues the purge of the account after the grace period and blocks all its sessions.
+// It returns ErrPurgeScheduled if the account is already being deleted
+func (q *Queries) SchedulePurge(ctx context.Context, arg SchedulePurgeParams) (PurgeJob, error) {
+	now := time.Now()
+	job := PurgeJob{
+		ID:         primitive.NewObjectID(),
+		UserID:     arg.UserID,
+		Status:     PurgeJobScheduled,
+		Step:       PurgeStepPosts,
+		Processed:  0,
+		Error:      "",
+		PurgeAfter: arg.PurgeAfter,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	err := q.execTx(ctx, func(ctx context.Context) error {
+		filter := bson.M{
+			"user_id": arg.UserID,
+			"status":  bson.M{"$in": bson.A{PurgeJobScheduled, PurgeJobProcessing, PurgeJobFailed}},
+		}
+
+		coll := q.db.Collection("purge_jobs")
+		n, err := coll.CountDocuments(ctx, filter, options.Count().SetLimit(1))
+		if err != nil {
+			return err
+		}
+
+		if n > 0 {
+			return ErrPurgeScheduled
+		}
+
+		_, err = coll.InsertOne(ctx, job)
+		if err != nil {
+			return err
+		}
+
+		update := bson.M{"$set": bson.M{"purge_at": arg.PurgeAfter}}
+		_, err = q.db.Collection("users").UpdateByID(ctx, arg.UserID, update)
+		if err != nil {
+			return err
+		}
+
+		_, err = q.BlockUserSessions(ctx, BlockUserSessionsParams{UserID: arg.UserID})
+		return err
+	})
+
+	return job, err
+}
+
+// CancelPurge cancels the scheduled purge of the account while it is in its grace period.
+// It returns ErrPurgeNotCancelable when there isn't such a purge
+func (q *Queries) CancelPurge(ctx context.Context, userID primitive.ObjectID) (*mongo.UpdateResult, error) {
+	var result *mongo.UpdateResult
+	err := q.execTx(ctx, func(ctx context.Context) error {
+		filter := bson.M{
+			"user_id":     userID,
+			"status":      PurgeJobScheduled,
+			"purge_after": bson.M{"$gt": time.Now()},
+		}
+		update := bson.M{
+			"$set": bson.M{
+				"status":     PurgeJobCanceled,
+				"updated_at": time.Now(),
+			},
+		}
+
+		var err error
+		result, err = q.db.Collection("purge_jobs").UpdateOne(ctx, filter, update)
+		if err != nil {
+			return err
+		}
+
+		if result.MatchedCount == 0 {
+			return ErrPurgeNotCancelable
+		}
+
+		update = bson.M{"$unset": bson.M{"purge_at": ""}}
+		_, err = q.db.Collection("users").UpdateByID(ctx, userID, update)
+		return err
+	})
+
+	return result, err
+}
+
+// pendingPurgeUserIDs returns the accounts waiting out the grace period of their deletion,
+// whose posts, comments and follows are hidden until the purge removes them or it is canceled
+func (q *Queries) pendingPurgeUserIDs(ctx context.Context) (bson.A, error) {
+	return q.db.Collection("users").Distinct(ctx, "_id", bson.M{"purge_at": bson.M{"$exists": true}})
+}
+
+// GetUserPurge returns the latest purge job of the account
+func (q *Queries) GetUserPurge(ctx context.Context, userID primitive.ObjectID) (PurgeJob, error) {
+	filter := bson.M{"user_id": userID}
+	opts := options.FindOne().SetSort(bson.D{primitive.E{Key: "created_at", Value: -1}})
+
+	var job PurgeJob
+	coll := q.db.Collection("purge_jobs")
+	err := coll.FindOne(ctx, filter, opts).Decode(&job)
+
+	return job, err
+}
+
+// ClaimPurgeJob marks the oldest purge whose grace period is over, or an abandoned or failed one,
+// as processing and returns it. It returns mongo.ErrNoDocuments when there is nothing to purge
+func (q *Queries) ClaimPurgeJob(ctx context.Context) (PurgeJob, error) {
+	now := time.Now()
+	filter := bson.M{
+		"$or": bson.A{
+			bson.M{"status": PurgeJobScheduled, "purge_after": bson.M{"$lte": now}},
+			bson.M{
+				"status":     bson.M{"$in": bson.A{PurgeJobProcessing, PurgeJobFailed}},
+				"updated_at": bson.M{"$lt": now.Add(-purgeJobLease)},
+			},
+		},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"status":     PurgeJobProcessing,
+			"updated_at": now,
+		},
+	}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{primitive.E{Key: "purge_after", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var job PurgeJob
+	coll := q.db.Collection("purge_jobs")
+	err := coll.FindOneAndUpdate(ctx, filter, update, opts).Decode(&job)
+
+	return job, err
+}
+
+type UpdatePurgeJobParams struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id"`
+	Status    string             `json:"status" bson:"status"`
+	Step      string             `json:"step" bson:"step"`
+	Processed int64              `json:"processed" bson:"processed"`
+	Error     string             `json:"error" bson:"error"`
+}
+
+func (q *Queries) UpdatePurgeJob(ctx context.Context, arg UpdatePurgeJobParams) (*mongo.UpdateResult, error) {
+	update := bson.M{
+		"$set": bson.M{
+			"status":     arg.Status,
+			"step":       arg.Step,
+			"processed":  arg.Processed,
+			"error":      arg.Error,
+			"updated_at": time.Now(),
+		},
+	}
+
+	coll := q.db.Collection("purge_jobs")
+	result, err := coll.UpdateByID(ctx, arg.ID, update)
+
+	return result, err
+}
+
+type ListPurgeJobsParams struct {
+	Status string `json:"status" bson:"status"`
+	Offset int64  `json:"offset" bson:"offset"`
+	Limit  int64  `json:"limit" bson:"limit"`
+}
+
+func (q *Queries) ListPurgeJobs(ctx context.Context, arg ListPurgeJobsParams) ([]PurgeJob, error) {
+	filter := bson.M{}
+	if arg.Status != "" {
+		filter["status"] = arg.Status
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{primitive.E{Key: "created_at", Value: -1}}).
+		SetSkip(arg.Offset).
+		SetLimit(arg.Limit)
+
+	var jobs []PurgeJob
+	coll := q.db.Collection("purge_jobs")
+	cursor, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	for cursor.Next(ctx) {
+		var job PurgeJob
+		err = cursor.Decode(&job)
+		if err != nil {
+			return nil, err
+		}
+
+		jobs = append(jobs, job)
+	}
+
+	return jobs, nil
+}
@@ -0,0 +1,206 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func randomPurgeJob(t *testing.T, user User, purgeAfter time.Time) PurgeJob {
+	randomUserSession(t, user)
+
+	arg := SchedulePurgeParams{
+		UserID:     user.ID,
+		PurgeAfter: purgeAfter,
+	}
+
+	job, err := testQueries.SchedulePurge(testCtx, arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, job)
+
+	require.Equal(t, arg.UserID, job.UserID)
+	require.Equal(t, PurgeJobScheduled, job.Status)
+	require.Equal(t, PurgeStepPosts, job.Step)
+	require.Zero(t, job.Processed)
+	require.WithinDuration(t, arg.PurgeAfter, job.PurgeAfter, time.Second)
+
+	return job
+}
+
+func TestSchedulePurge(t *testing.T) {
+	user1 := randomUser(t)
+	session := randomUserSession(t, user1)
+	purgeAfter := time.Now().Add(time.Hour)
+	randomPurgeJob(t, user1, purgeAfter)
+
+	user2, err := testQueries.GetUser(testCtx, "_id", user1.ID)
+	require.NoError(t, err)
+	require.WithinDuration(t, purgeAfter, user2.PurgeAt, time.Second)
+
+	session, err = testQueries.GetSession(testCtx, session.ID)
+	require.NoError(t, err)
+	require.True(t, session.IsBlocked)
+
+	_, err = testQueries.SchedulePurge(testCtx, SchedulePurgeParams{UserID: user1.ID, PurgeAfter: purgeAfter})
+	require.ErrorIs(t, err, ErrPurgeScheduled)
+}
+
+func TestSchedulePurgeHidesUser(t *testing.T) {
+	user := randomUser(t)
+	other := randomUser(t)
+	post := randomPostByUser(t, other.ID)
+	randomPostByUser(t, user.ID)
+	randomComment(t, user.ID, post.ID)
+	randomFollow(t, user.ID, other.ID)
+
+	count := func() (int, int, int) {
+		posts, err := testQueries.ListPosts(testCtx, ListPostsParams{UserID: user.ID, Limit: 10})
+		require.NoError(t, err)
+
+		comments, err := testQueries.ListComments(testCtx, ListCommentsParams{PostID: post.ID, Limit: 10})
+		require.NoError(t, err)
+
+		followers, err := testQueries.ListFollowers(testCtx, ListFollowsParams{UserID: other.ID, Limit: 10})
+		require.NoError(t, err)
+
+		return len(posts), len(comments), len(followers)
+	}
+
+	nPosts, nComments, nFollowers := count()
+	require.Equal(t, []int{1, 1, 1}, []int{nPosts, nComments, nFollowers})
+
+	randomPurgeJob(t, user, time.Now().Add(time.Hour))
+	nPosts, nComments, nFollowers = count()
+	require.Equal(t, []int{0, 0, 0}, []int{nPosts, nComments, nFollowers})
+
+	// canceling the deletion shows them again
+	_, err := testQueries.CancelPurge(testCtx, user.ID)
+	require.NoError(t, err)
+	nPosts, nComments, nFollowers = count()
+	require.Equal(t, []int{1, 1, 1}, []int{nPosts, nComments, nFollowers})
+}
+
+func TestCancelPurge(t *testing.T) {
+	user1 := randomUser(t)
+	randomPurgeJob(t, user1, time.Now().Add(time.Hour))
+
+	result, err := testQueries.CancelPurge(testCtx, user1.ID)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, result.ModifiedCount)
+
+	user2, err := testQueries.GetUser(testCtx, "_id", user1.ID)
+	require.NoError(t, err)
+	require.True(t, user2.PurgeAt.IsZero())
+
+	job, err := testQueries.GetUserPurge(testCtx, user1.ID)
+	require.NoError(t, err)
+	require.Equal(t, PurgeJobCanceled, job.Status)
+
+	_, err = testQueries.CancelPurge(testCtx, user1.ID)
+	require.ErrorIs(t, err, ErrPurgeNotCancelable)
+
+	// once the grace period is over the purge can't be undone
+	user3 := randomUser(t)
+	randomPurgeJob(t, user3, time.Now().Add(-time.Second))
+
+	_, err = testQueries.CancelPurge(testCtx, user3.ID)
+	require.ErrorIs(t, err, ErrPurgeNotCancelable)
+}
+
+func TestClaimPurgeJob(t *testing.T) {
+	user := randomUser(t)
+	job1 := randomPurgeJob(t, user, time.Now().Add(-time.Hour))
+
+	job2, err := testQueries.ClaimPurgeJob(testCtx)
+	require.NoError(t, err)
+	require.Equal(t, PurgeJobProcessing, job2.Status)
+
+	// a purge still in its grace period is never claimed
+	waiting := randomPurgeJob(t, randomUser(t), time.Now().Add(time.Hour))
+	for {
+		job, err := testQueries.ClaimPurgeJob(testCtx)
+		if err == mongo.ErrNoDocuments {
+			break
+		}
+		require.NoError(t, err)
+		require.NotEqual(t, waiting.ID, job.ID)
+	}
+
+	job3, err := testQueries.GetUserPurge(testCtx, job1.UserID)
+	require.NoError(t, err)
+	require.Equal(t, PurgeJobProcessing, job3.Status)
+}
+
+func TestUpdatePurgeJob(t *testing.T) {
+	job1 := randomPurgeJob(t, randomUser(t), time.Now().Add(time.Hour))
+
+	arg := UpdatePurgeJobParams{
+		ID:        job1.ID,
+		Status:    PurgeJobFailed,
+		Step:      PurgeStepLikes,
+		Processed: 42,
+		Error:     "boom",
+	}
+
+	result, err := testQueries.UpdatePurgeJob(testCtx, arg)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, result.ModifiedCount)
+
+	job2, err := testQueries.GetUserPurge(testCtx, job1.UserID)
+	require.NoError(t, err)
+	require.Equal(t, arg.Status, job2.Status)
+	require.Equal(t, arg.Step, job2.Step)
+	require.Equal(t, arg.Processed, job2.Processed)
+	require.Equal(t, arg.Error, job2.Error)
+}
+
+func TestListPurgeJobs(t *testing.T) {
+	for i := 0; i < 4; i++ {
+		randomPurgeJob(t, randomUser(t), time.Now().Add(time.Hour))
+	}
+
+	arg := ListPurgeJobsParams{
+		Status: PurgeJobScheduled,
+		Offset: 0,
+		Limit:  3,
+	}
+
+	jobs, err := testQueries.ListPurgeJobs(testCtx, arg)
+	require.NoError(t, err)
+	require.Len(t, jobs, 3)
+
+	for _, job := range jobs {
+		require.Equal(t, PurgeJobScheduled, job.Status)
+	}
+}
+
+func TestPurgeUserStep(t *testing.T) {
+	user := randomUser(t)
+	for i := 0; i < 3; i++ {
+		randomPostByUser(t, user.ID)
+	}
+
+	arg := PurgeUserStepParams{
+		UserID: user.ID,
+		Step:   PurgeStepPosts,
+		Limit:  2,
+	}
+
+	n, err := testQueries.PurgeUserStep(testCtx, arg)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, n)
+
+	n, err = testQueries.PurgeUserStep(testCtx, arg)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, n)
+
+	n, err = testQueries.PurgeUserStep(testCtx, arg)
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	arg.Step = PurgeStepMedia
+	_, err = testQueries.PurgeUserStep(testCtx, arg)
+	require.Error(t, err)
+}
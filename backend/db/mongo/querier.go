@@ -15,28 +15,119 @@ type Querier interface {
 	UpdateUser(ctx context.Context, arg UpdateUserParams) (*mongo.UpdateResult, error)
 	DeleteUser(ctx context.Context, id primitive.ObjectID) (*mongo.DeleteResult, error)
 
+	SchedulePurge(ctx context.Context, arg SchedulePurgeParams) (PurgeJob, error)
+	CancelPurge(ctx context.Context, userID primitive.ObjectID) (*mongo.UpdateResult, error)
+	GetUserPurge(ctx context.Context, userID primitive.ObjectID) (PurgeJob, error)
+	ClaimPurgeJob(ctx context.Context) (PurgeJob, error)
+	UpdatePurgeJob(ctx context.Context, arg UpdatePurgeJobParams) (*mongo.UpdateResult, error)
+	ListPurgeJobs(ctx context.Context, arg ListPurgeJobsParams) ([]PurgeJob, error)
+	PurgeUserStep(ctx context.Context, arg PurgeUserStepParams) (int64, error)
+
+	CreateMedia(ctx context.Context, arg CreateMediaParams) (*mongo.InsertOneResult, error)
+	GetMedia(ctx context.Context, id primitive.ObjectID) (Media, error)
+	ListUserMedia(ctx context.Context, arg ListUserMediaParams) ([]Media, error)
+	DeleteMedia(ctx context.Context, id primitive.ObjectID) (*mongo.DeleteResult, error)
+
 	CreatePost(ctx context.Context, arg CreatePostParams) (*mongo.InsertOneResult, error)
 	GetPost(ctx context.Context, key string, value any) (Post, error)
 	ListPosts(ctx context.Context, arg ListPostsParams) ([]Post, error)
+	ListFeed(ctx context.Context, arg ListFeedParams) ([]Post, error)
 	UpdatePost(ctx context.Context, arg UpdatePostParams) (*mongo.UpdateResult, error)
 	DeletePost(ctx context.Context, id primitive.ObjectID) (*mongo.DeleteResult, error)
 
+	SearchUsers(ctx context.Context, arg SearchParams) ([]User, error)
+	SearchPosts(ctx context.Context, arg SearchParams) ([]Post, error)
+	RepairSearchKeys(ctx context.Context) (int64, error)
+
+	GetHashtag(ctx context.Context, name string) (Hashtag, error)
+	SearchHashtags(ctx context.Context, arg SearchHashtagsParams) ([]Hashtag, error)
+	ListHashtagPosts(ctx context.Context, arg ListHashtagPostsParams) ([]Post, error)
+
+	ListMentions(ctx context.Context, arg ListMentionsParams) ([]Mentioning, error)
+
+	ListNotifications(ctx context.Context, arg ListNotificationsParams) ([]Notification, error)
+	CountUnreadNotifications(ctx context.Context, userID primitive.ObjectID) (int64, error)
+	MarkNotificationsRead(ctx context.Context, arg MarkNotificationsReadParams) (*mongo.UpdateResult, error)
+	UpdateNotificationPreferences(ctx context.Context, arg UpdateNotificationPreferencesParams) (*mongo.UpdateResult, error)
+
+	CreateConversation(ctx context.Context, arg CreateConversationParams) (Conversation, error)
+	GetConversation(ctx context.Context, id primitive.ObjectID) (Conversation, error)
+	ListConversations(ctx context.Context, arg ListConversationsParams) ([]Conversation, error)
+	MarkConversationRead(ctx context.Context, arg MarkConversationReadParams) (*mongo.UpdateResult, error)
+	CountUnreadMessages(ctx context.Context, arg CountUnreadMessagesParams) (int64, error)
+
+	CreateMessage(ctx context.Context, arg CreateMessageParams) (Message, error)
+	GetMessage(ctx context.Context, id primitive.ObjectID) (Message, error)
+	ListMessages(ctx context.Context, arg ListMessagesParams) ([]Message, error)
+	UpdateMessage(ctx context.Context, arg UpdateMessageParams) (*mongo.UpdateResult, error)
+	DeleteMessage(ctx context.Context, id primitive.ObjectID) (*mongo.UpdateResult, error)
+
 	GetLike(ctx context.Context, id primitive.ObjectID) (Like, error)
 	ListLikes(ctx context.Context, arg ListLikesParams) ([]Like, error)
 	CountLikes(ctx context.Context, targetID primitive.ObjectID) (int64, error)
-	ToggleLike(ctx context.Context, arg ToggleLikeParams) (*mongo.InsertOneResult, *mongo.DeleteResult, error)
+	GetLikeCount(ctx context.Context, targetID primitive.ObjectID) (int64, error)
+	CreateLike(ctx context.Context, arg LikeParams) (Like, error)
+	DeleteLike(ctx context.Context, arg LikeParams) (*mongo.DeleteResult, error)
+	ToggleLike(ctx context.Context, arg LikeParams) (bool, error)
 	IsLiked(ctx context.Context, arg IsLikedParams) (Like, bool, error)
 
 	CreateComment(ctx context.Context, arg CreateCommentParams) (*mongo.InsertOneResult, error)
 	GetComment(ctx context.Context, id primitive.ObjectID) (Comment, error)
 	ListComments(ctx context.Context, arg ListCommentsParams) ([]Comment, error)
+	ListReplies(ctx context.Context, arg ListRepliesParams) ([]Comment, error)
 	UpdateComment(ctx context.Context, arg UpdateCommentParams) (*mongo.UpdateResult, error)
 	DeleteComment(ctx context.Context, id primitive.ObjectID) (*mongo.DeleteResult, error)
+	RepairCommentThreads(ctx context.Context) (int64, error)
+
+	RepairCounters(ctx context.Context) (int64, error)
+
+	Follow(ctx context.Context, arg FollowParams) (*mongo.InsertOneResult, error)
+	Unfollow(ctx context.Context, arg FollowParams) (*mongo.DeleteResult, error)
+	ListFollowers(ctx context.Context, arg ListFollowsParams) ([]Follow, error)
+	ListFollowersAfter(ctx context.Context, arg ListFollowersAfterParams) ([]Follow, error)
+	ListFollowing(ctx context.Context, arg ListFollowsParams) ([]Follow, error)
+	IsFollowing(ctx context.Context, arg FollowParams) (bool, error)
+	CountFollowers(ctx context.Context, userID primitive.ObjectID) (int64, error)
+	CountFollowing(ctx context.Context, userID primitive.ObjectID) (int64, error)
+
+	RequestFollow(ctx context.Context, arg FollowParams) (FollowRequest, error)
+	ListFollowRequests(ctx context.Context, arg ListFollowsParams) ([]FollowRequest, error)
+	ApproveFollowRequest(ctx context.Context, arg FollowParams) (*mongo.InsertOneResult, error)
+	DeleteFollowRequest(ctx context.Context, arg FollowParams) (*mongo.DeleteResult, error)
+
+	BlockUser(ctx context.Context, arg BlockParams) (Block, error)
+	UnblockUser(ctx context.Context, arg BlockParams) (*mongo.DeleteResult, error)
+	ListBlocks(ctx context.Context, arg ListBlocksParams) ([]Block, error)
+	IsBlockedBetween(ctx context.Context, arg BlockParams) (bool, error)
+	MuteUser(ctx context.Context, arg MuteParams) (Mute, error)
+	UnmuteUser(ctx context.Context, arg MuteParams) (*mongo.DeleteResult, error)
+	ListMutes(ctx context.Context, arg ListMutesParams) ([]Mute, error)
+
+	CreateReport(ctx context.Context, arg CreateReportParams) (Report, error)
+	ListReports(ctx context.Context, arg ListReportsParams) ([]Report, error)
+	ListReportGroups(ctx context.Context, arg ListReportGroupsParams) ([]ReportGroup, error)
+	ResolveReports(ctx context.Context, arg ResolveReportsParams) (ModerationAction, error)
+	SuspendUser(ctx context.Context, userID primitive.ObjectID) error
+	CreateModerationAction(ctx context.Context, arg CreateModerationActionParams) (ModerationAction, error)
+	ListModerationActions(ctx context.Context, arg ListModerationActionsParams) ([]ModerationAction, error)
+
+	EnqueueTimelineJob(ctx context.Context, arg EnqueueTimelineJobParams) (*mongo.InsertOneResult, error)
+	ClaimTimelineJob(ctx context.Context) (TimelineJob, error)
+	UpdateTimelineJob(ctx context.Context, arg UpdateTimelineJobParams) (*mongo.UpdateResult, error)
+	ListTimelineJobs(ctx context.Context, arg ListTimelineJobsParams) ([]TimelineJob, error)
+	GetTimelineStats(ctx context.Context) (TimelineStats, error)
+	AddTimelineEntries(ctx context.Context, entries []TimelineEntry) (*mongo.BulkWriteResult, error)
+	BackfillTimeline(ctx context.Context, arg BackfillTimelineParams) (*mongo.BulkWriteResult, error)
+	RemoveTimelineAuthor(ctx context.Context, arg RemoveTimelineAuthorParams) (*mongo.DeleteResult, error)
 
 	CreateSession(ctx context.Context, arg CreateSessionParams) (*mongo.InsertOneResult, error)
 	GetSession(ctx context.Context, id primitive.ObjectID) (Session, error)
+	ListSessions(ctx context.Context, userID primitive.ObjectID) ([]Session, error)
 	DeleteSession(ctx context.Context, id primitive.ObjectID) (*mongo.DeleteResult, error)
 	BlockSession(ctx context.Context, id primitive.ObjectID) (*mongo.UpdateResult, error)
+	BlockUserSessions(ctx context.Context, arg BlockUserSessionsParams) (*mongo.UpdateResult, error)
+	BlockSessionFamily(ctx context.Context, id primitive.ObjectID) (*mongo.UpdateResult, error)
+	RotateSession(ctx context.Context, arg RotateSessionParams) (Session, error)
 }
 
 var _ Querier = (*Queries)(nil)
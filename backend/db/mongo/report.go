@@ -0,0 +1,391 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// The kinds of things that can be reported
+const (
+	ReportTargetPost    = "post"
+	ReportTargetComment = "comment"
+	ReportTargetUser    = "user"
+)
+
+// The reasons a report can be made for
+const (
+	ReportReasonSpam           = "spam"
+	ReportReasonHarassment     = "harassment"
+	ReportReasonHateSpeech     = "hate_speech"
+	ReportReasonViolence       = "violence"
+	ReportReasonNudity         = "nudity"
+	ReportReasonSelfHarm       = "self_harm"
+	ReportReasonMisinformation = "misinformation"
+	ReportReasonImpersonation  = "impersonation"
+	ReportReasonOther          = "other"
+)
+
+const (
+	ReportOpen     = "open"
+	ReportResolved = "resolved"
+)
+
+// The actions a moderator can take on a reported target
+const (
+	ModerationDismiss       = "dismiss"
+	ModerationRemoveContent = "remove_content"
+	ModerationSuspendUser   = "suspend_user"
+)
+
+// The actions a moderator can take directly on the content of another user
+const (
+	ModerationEditContent   = "edit_content"
+	ModerationDeleteContent = "delete_content"
+)
+
+type CreateReportParams struct {
+	ReporterID primitive.ObjectID `json:"reporter_id" bson:"reporter_id"`
+	TargetType string             `json:"target_type" bson:"target_type"`
+	TargetID   primitive.ObjectID `json:"target_id" bson:"target_id"`
+	Reason     string             `json:"reason" bson:"reason"`
+	Details    string             `json:"details" bson:"details"`
+}
+
+// CreateReport opens a report on the post, comment or user. A user can only have one open report on each target
+func (q *Queries) CreateReport(ctx context.Context, arg CreateReportParams) (Report, error) {
+	report := Report{
+		ID:         primitive.NewObjectID(),
+		ReporterID: arg.ReporterID,
+		TargetType: arg.TargetType,
+		TargetID:   arg.TargetID,
+		Reason:     arg.Reason,
+		Details:    arg.Details,
+		Status:     ReportOpen,
+		CreatedAt:  time.Now(),
+	}
+
+	coll := q.db.Collection("reports")
+	_, err := coll.InsertOne(ctx, report)
+	if err != nil {
+		if duplicatedIndex(err) == reportIndex {
+			return Report{}, ErrAlreadyReported
+		}
+		return Report{}, err
+	}
+
+	return report, nil
+}
+
+type ListReportsParams struct {
+	TargetID primitive.ObjectID `json:"target_id" bson:"target_id"`
+	Offset   int64              `json:"offset" bson:"offset"`
+	Limit    int64              `json:"limit" bson:"limit"`
+}
+
+// ListReports lists the reports made on the target, the latest first
+func (q *Queries) ListReports(ctx context.Context, arg ListReportsParams) ([]Report, error) {
+	filter := bson.D{primitive.E{Key: "target_id", Value: arg.TargetID}}
+	opts := options.Find().
+		SetSort(bson.D{primitive.E{Key: "_id", Value: -1}}).
+		SetSkip(arg.Offset).
+		SetLimit(arg.Limit)
+
+	var reports []Report
+	coll := q.db.Collection("reports")
+	cursor, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	for cursor.Next(ctx) {
+		var report Report
+		err = cursor.Decode(&report)
+		if err != nil {
+			return nil, err
+		}
+
+		reports = append(reports, report)
+	}
+
+	return reports, nil
+}
+
+// ReportGroup gathers the open reports of a target, which are handled together
+type ReportGroup struct {
+	TargetType      string             `json:"target_type" bson:"target_type"`
+	TargetID        primitive.ObjectID `json:"target_id" bson:"target_id"`
+	ReportCount     int64              `json:"report_count" bson:"report_count"`
+	Reasons         []string           `json:"reasons" bson:"reasons"`
+	FirstReportedAt time.Time          `json:"first_reported_at" bson:"first_reported_at"`
+	LastReportedAt  time.Time          `json:"last_reported_at" bson:"last_reported_at"`
+}
+
+type ListReportGroupsParams struct {
+	TargetType string `json:"target_type" bson:"target_type"`
+	Offset     int64  `json:"offset" bson:"offset"`
+	Limit      int64  `json:"limit" bson:"limit"`
+}
+
+// ListReportGroups is the moderation queue: the targets with open reports, the most reported first
+func (q *Queries) ListReportGroups(ctx context.Context, arg ListReportGroupsParams) ([]ReportGroup, error) {
+	match := bson.M{"status": ReportOpen}
+	if arg.TargetType != "" {
+		match["target_type"] = arg.TargetType
+	}
+
+	pipeline := mongo.Pipeline{
+		bson.D{primitive.E{Key: "$match", Value: match}},
+		bson.D{primitive.E{Key: "$group", Value: bson.M{
+			"_id":               bson.M{"target_type": "$target_type", "target_id": "$target_id"},
+			"report_count":      bson.M{"$sum": 1},
+			"reasons":           bson.M{"$addToSet": "$reason"},
+			"first_reported_at": bson.M{"$min": "$created_at"},
+			"last_reported_at":  bson.M{"$max": "$created_at"},
+		}}},
+		bson.D{primitive.E{Key: "$sort", Value: bson.D{
+			primitive.E{Key: "report_count", Value: -1},
+			primitive.E{Key: "first_reported_at", Value: 1},
+		}}},
+		bson.D{primitive.E{Key: "$skip", Value: arg.Offset}},
+		bson.D{primitive.E{Key: "$limit", Value: arg.Limit}},
+		bson.D{primitive.E{Key: "$project", Value: bson.M{
+			"_id":               0,
+			"target_type":       "$_id.target_type",
+			"target_id":         "$_id.target_id",
+			"report_count":      1,
+			"reasons":           1,
+			"first_reported_at": 1,
+			"last_reported_at":  1,
+		}}},
+	}
+
+	var groups []ReportGroup
+	coll := q.db.Collection("reports")
+	cursor, err := coll.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	for cursor.Next(ctx) {
+		var group ReportGroup
+		err = cursor.Decode(&group)
+		if err != nil {
+			return nil, err
+		}
+
+		groups = append(groups, group)
+	}
+
+	return groups, nil
+}
+
+type ResolveReportsParams struct {
+	ModeratorID primitive.ObjectID `json:"moderator_id" bson:"moderator_id"`
+	TargetID    primitive.ObjectID `json:"target_id" bson:"target_id"`
+	Action      string             `json:"action" bson:"action"`
+	Note        string             `json:"note" bson:"note"`
+}
+
+// ResolveReports takes the action on the target and closes its open reports, recording it in the
+// moderation history. The removed content is only hidden, so it can be restored after an appeal.
+// It returns mongo.ErrNoDocuments if the target has no open reports
+func (q *Queries) ResolveReports(ctx context.Context, arg ResolveReportsParams) (ModerationAction, error) {
+	action := ModerationAction{
+		ID:          primitive.NewObjectID(),
+		ModeratorID: arg.ModeratorID,
+		TargetID:    arg.TargetID,
+		Action:      arg.Action,
+		Note:        arg.Note,
+		CreatedAt:   time.Now(),
+	}
+
+	err := q.execTx(ctx, func(ctx context.Context) error {
+		filter := bson.M{"target_id": arg.TargetID, "status": ReportOpen}
+
+		var report Report
+		err := q.db.Collection("reports").FindOne(ctx, filter).Decode(&report)
+		if err != nil {
+			return err
+		}
+		action.TargetType = report.TargetType
+
+		action.UserID, err = q.targetAuthor(ctx, report.TargetType, arg.TargetID)
+		// the reports of a target deleted meanwhile can still be dismissed
+		if err == mongo.ErrNoDocuments && arg.Action == ModerationDismiss {
+			err = nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch arg.Action {
+		case ModerationDismiss:
+			// the reports are closed without touching the target
+		case ModerationRemoveContent:
+			err = q.removeContent(ctx, report.TargetType, arg.TargetID)
+		case ModerationSuspendUser:
+			err = q.suspendUser(ctx, action.UserID)
+		default:
+			err = ErrInvalidModerationAction
+		}
+		if err != nil {
+			return err
+		}
+
+		update := bson.M{"$set": bson.M{"status": ReportResolved, "resolution_id": action.ID}}
+
+		result, err := q.db.Collection("reports").UpdateMany(ctx, filter, update)
+		if err != nil {
+			return err
+		}
+		action.ReportCount = result.ModifiedCount
+
+		_, err = q.db.Collection("moderation_actions").InsertOne(ctx, action)
+		return err
+	})
+	if err != nil {
+		return ModerationAction{}, err
+	}
+
+	return action, nil
+}
+
+// targetAuthor returns the user responsible for the reported target, the user itself for a user report
+func (q *Queries) targetAuthor(ctx context.Context, targetType string, targetID primitive.ObjectID) (primitive.ObjectID, error) {
+	var collection string
+	switch targetType {
+	case ReportTargetPost:
+		collection = "posts"
+	case ReportTargetComment:
+		collection = "comments"
+	case ReportTargetUser:
+		return targetID, nil
+	default:
+		return primitive.NilObjectID, ErrInvalidModerationAction
+	}
+
+	var target struct {
+		UserID primitive.ObjectID `bson:"user_id"`
+	}
+
+	opts := options.FindOne().SetProjection(bson.M{"user_id": 1})
+	err := q.db.Collection(collection).FindOne(ctx, bson.M{"_id": targetID}, opts).Decode(&target)
+
+	return target.UserID, err
+}
+
+// removeContent hides the post or comment from everyone, keeping it in the database
+func (q *Queries) removeContent(ctx context.Context, targetType string, targetID primitive.ObjectID) error {
+	var collection string
+	switch targetType {
+	case ReportTargetPost:
+		collection = "posts"
+	case ReportTargetComment:
+		collection = "comments"
+	default:
+		return ErrInvalidModerationAction
+	}
+
+	update := bson.M{"$set": bson.M{"removed": true}}
+	_, err := q.db.Collection(collection).UpdateByID(ctx, targetID, update)
+
+	return err
+}
+
+// SuspendUser keeps the user from logging in again and blocks its current sessions,
+// like resolving a report with ModerationSuspendUser but without a report
+func (q *Queries) SuspendUser(ctx context.Context, userID primitive.ObjectID) error {
+	return q.execTx(ctx, func(ctx context.Context) error {
+		return q.suspendUser(ctx, userID)
+	})
+}
+
+// suspendUser keeps the user from logging in again and blocks its current sessions
+func (q *Queries) suspendUser(ctx context.Context, userID primitive.ObjectID) error {
+	if userID.IsZero() {
+		return ErrNoAuthor
+	}
+
+	update := bson.M{"$set": bson.M{"suspended_at": time.Now()}}
+	_, err := q.db.Collection("users").UpdateByID(ctx, userID, update)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.BlockUserSessions(ctx, BlockUserSessionsParams{UserID: userID})
+	return err
+}
+
+type CreateModerationActionParams struct {
+	ModeratorID primitive.ObjectID `json:"moderator_id" bson:"moderator_id"`
+	TargetType  string             `json:"target_type" bson:"target_type"`
+	TargetID    primitive.ObjectID `json:"target_id" bson:"target_id"`
+	UserID      primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Action      string             `json:"action" bson:"action"`
+	Note        string             `json:"note" bson:"note"`
+}
+
+// CreateModerationAction records in the moderation history an action taken without a report,
+// like a moderator editing or deleting the content of another user
+func (q *Queries) CreateModerationAction(ctx context.Context, arg CreateModerationActionParams) (ModerationAction, error) {
+	action := ModerationAction{
+		ID:          primitive.NewObjectID(),
+		ModeratorID: arg.ModeratorID,
+		TargetType:  arg.TargetType,
+		TargetID:    arg.TargetID,
+		UserID:      arg.UserID,
+		Action:      arg.Action,
+		Note:        arg.Note,
+		CreatedAt:   time.Now(),
+	}
+
+	_, err := q.db.Collection("moderation_actions").InsertOne(ctx, action)
+	if err != nil {
+		return ModerationAction{}, err
+	}
+
+	return action, nil
+}
+
+type ListModerationActionsParams struct {
+	TargetID primitive.ObjectID `json:"target_id" bson:"target_id"`
+	Offset   int64              `json:"offset" bson:"offset"`
+	Limit    int64              `json:"limit" bson:"limit"`
+}
+
+// ListModerationActions lists the moderation history, of the target if one is given, the latest first
+func (q *Queries) ListModerationActions(ctx context.Context, arg ListModerationActionsParams) ([]ModerationAction, error) {
+	filter := bson.M{}
+	if !arg.TargetID.IsZero() {
+		filter["target_id"] = arg.TargetID
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{primitive.E{Key: "_id", Value: -1}}).
+		SetSkip(arg.Offset).
+		SetLimit(arg.Limit)
+
+	var actions []ModerationAction
+	coll := q.db.Collection("moderation_actions")
+	cursor, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	for cursor.Next(ctx) {
+		var action ModerationAction
+		err = cursor.Decode(&action)
+		if err != nil {
+			return nil, err
+		}
+
+		actions = append(actions, action)
+	}
+
+	return actions, nil
+}
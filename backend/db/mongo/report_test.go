@@ -0,0 +1,209 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DMV-Nicolas/robotgram/backend/util"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func randomReport(t *testing.T, targetType string, targetID primitive.ObjectID) Report {
+	arg := CreateReportParams{
+		ReporterID: randomUser(t).ID,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Reason:     ReportReasonSpam,
+		Details:    util.RandomString(20),
+	}
+
+	report, err := testQueries.CreateReport(testCtx, arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.ReporterID, report.ReporterID)
+	require.Equal(t, arg.TargetID, report.TargetID)
+	require.Equal(t, ReportOpen, report.Status)
+	require.WithinDuration(t, time.Now(), report.CreatedAt, time.Second)
+
+	return report
+}
+
+func TestCreateReport(t *testing.T) {
+	post := randomPost(t)
+	report := randomReport(t, ReportTargetPost, post.ID)
+
+	arg := CreateReportParams{
+		ReporterID: report.ReporterID,
+		TargetType: ReportTargetPost,
+		TargetID:   post.ID,
+		Reason:     ReportReasonHarassment,
+	}
+
+	_, err := testQueries.CreateReport(testCtx, arg)
+	require.ErrorIs(t, err, ErrAlreadyReported)
+
+	reports, err := testQueries.ListReports(testCtx, ListReportsParams{TargetID: post.ID, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, reports, 1)
+	require.Equal(t, report.ID, reports[0].ID)
+}
+
+func TestListReportGroups(t *testing.T) {
+	post := randomPost(t)
+	for i := 0; i < 3; i++ {
+		randomReport(t, ReportTargetPost, post.ID)
+	}
+
+	groups, err := testQueries.ListReportGroups(testCtx, ListReportGroupsParams{TargetType: ReportTargetPost, Limit: 1000})
+	require.NoError(t, err)
+
+	var found bool
+	for _, group := range groups {
+		require.Equal(t, ReportTargetPost, group.TargetType)
+
+		if group.TargetID == post.ID {
+			found = true
+			require.Equal(t, int64(3), group.ReportCount)
+			require.Equal(t, []string{ReportReasonSpam}, group.Reasons)
+		}
+	}
+	require.True(t, found)
+}
+
+func TestResolveReportsRemoveContent(t *testing.T) {
+	moderator := randomUser(t)
+	post := randomPost(t)
+	comment := randomComment(t, randomUser(t).ID, post.ID)
+	randomReport(t, ReportTargetPost, post.ID)
+	randomReport(t, ReportTargetComment, comment.ID)
+
+	for _, targetID := range []primitive.ObjectID{post.ID, comment.ID} {
+		arg := ResolveReportsParams{
+			ModeratorID: moderator.ID,
+			TargetID:    targetID,
+			Action:      ModerationRemoveContent,
+			Note:        util.RandomString(10),
+		}
+
+		action, err := testQueries.ResolveReports(testCtx, arg)
+		require.NoError(t, err)
+		require.Equal(t, moderator.ID, action.ModeratorID)
+		require.Equal(t, int64(1), action.ReportCount)
+
+		_, err = testQueries.ResolveReports(testCtx, arg)
+		require.ErrorIs(t, err, mongo.ErrNoDocuments)
+	}
+
+	// the removed content is kept but hidden from the listings
+	gotPost, err := testQueries.GetPost(testCtx, "_id", post.ID)
+	require.NoError(t, err)
+	require.True(t, gotPost.Removed)
+
+	posts, err := testQueries.ListPosts(testCtx, ListPostsParams{UserID: post.UserID, ViewerID: post.UserID, Limit: 10})
+	require.NoError(t, err)
+	require.Empty(t, posts)
+
+	comments, err := testQueries.ListComments(testCtx, ListCommentsParams{PostID: post.ID, Limit: 10})
+	require.NoError(t, err)
+	require.Empty(t, comments)
+
+	actions, err := testQueries.ListModerationActions(testCtx, ListModerationActionsParams{TargetID: post.ID, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, actions, 1)
+	require.Equal(t, ModerationRemoveContent, actions[0].Action)
+	require.Equal(t, post.UserID, actions[0].UserID)
+}
+
+func TestResolveReportsSuspendUser(t *testing.T) {
+	user := randomUser(t)
+	session := randomUserSession(t, user)
+	report := randomReport(t, ReportTargetUser, user.ID)
+
+	arg := ResolveReportsParams{
+		ModeratorID: randomUser(t).ID,
+		TargetID:    user.ID,
+		Action:      ModerationRemoveContent,
+	}
+
+	// a user isn't content that can be removed
+	_, err := testQueries.ResolveReports(testCtx, arg)
+	require.ErrorIs(t, err, ErrInvalidModerationAction)
+
+	arg.Action = ModerationSuspendUser
+	action, err := testQueries.ResolveReports(testCtx, arg)
+	require.NoError(t, err)
+	require.Equal(t, user.ID, action.UserID)
+
+	gotUser, err := testQueries.GetUser(testCtx, "_id", user.ID)
+	require.NoError(t, err)
+	require.WithinDuration(t, time.Now(), gotUser.SuspendedAt, time.Second)
+
+	gotSession, err := testQueries.GetSession(testCtx, session.ID)
+	require.NoError(t, err)
+	require.True(t, gotSession.IsBlocked)
+
+	reports, err := testQueries.ListReports(testCtx, ListReportsParams{TargetID: user.ID, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, reports, 1)
+	require.Equal(t, ReportResolved, reports[0].Status)
+	require.Equal(t, action.ID, reports[0].ResolutionID)
+
+	// the target can be reported again once its reports are resolved
+	_, err = testQueries.CreateReport(testCtx, CreateReportParams{
+		ReporterID: report.ReporterID,
+		TargetType: ReportTargetUser,
+		TargetID:   user.ID,
+		Reason:     ReportReasonImpersonation,
+	})
+	require.NoError(t, err)
+}
+
+func TestCreateModerationAction(t *testing.T) {
+	user := randomUser(t)
+	post := randomPostByUser(t, user.ID)
+
+	arg := CreateModerationActionParams{
+		ModeratorID: randomUser(t).ID,
+		TargetType:  ReportTargetPost,
+		TargetID:    post.ID,
+		UserID:      user.ID,
+		Action:      ModerationEditContent,
+		Note:        util.RandomDescription(5),
+	}
+
+	action, err := testQueries.CreateModerationAction(testCtx, arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.ModeratorID, action.ModeratorID)
+	require.Equal(t, arg.TargetType, action.TargetType)
+	require.Equal(t, arg.TargetID, action.TargetID)
+	require.Equal(t, arg.UserID, action.UserID)
+	require.Equal(t, arg.Action, action.Action)
+	require.Equal(t, arg.Note, action.Note)
+	require.Zero(t, action.ReportCount)
+	require.WithinDuration(t, time.Now(), action.CreatedAt, time.Second)
+
+	actions, err := testQueries.ListModerationActions(testCtx, ListModerationActionsParams{TargetID: post.ID, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, actions, 1)
+	require.Equal(t, action.ID, actions[0].ID)
+}
+
+func TestSuspendUser(t *testing.T) {
+	user := randomUser(t)
+	session := randomUserSession(t, user)
+
+	err := testQueries.SuspendUser(testCtx, user.ID)
+	require.NoError(t, err)
+
+	gotUser, err := testQueries.GetUser(testCtx, "_id", user.ID)
+	require.NoError(t, err)
+	require.WithinDuration(t, time.Now(), gotUser.SuspendedAt, time.Second)
+
+	gotSession, err := testQueries.GetSession(testCtx, session.ID)
+	require.NoError(t, err)
+	require.True(t, gotSession.IsBlocked)
+
+	err = testQueries.SuspendUser(testCtx, primitive.NilObjectID)
+	require.ErrorIs(t, err, ErrNoAuthor)
+}
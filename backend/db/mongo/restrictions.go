@@ -8,12 +8,26 @@ import (
 )
 
 var (
-	ErrUsernameTaken  = errors.New("the username must be unique")
-	ErrEmailTaken     = errors.New("the email must be unique")
-	ErrDuplicatedLike = errors.New("the like has already been given")
+	ErrUsernameTaken           = errors.New("the username must be unique")
+	ErrEmailTaken              = errors.New("the email must be unique")
+	ErrDuplicatedLike          = errors.New("the like has already been given")
+	ErrAlreadyFollows          = errors.New("the user is already followed")
+	ErrSelfFollow              = errors.New("a user cannot follow itself")
+	ErrFollowRequested         = errors.New("the follow has already been requested")
+	ErrAlreadyBlocked          = errors.New("the user is already blocked")
+	ErrSelfBlock               = errors.New("a user cannot block itself")
+	ErrAlreadyMuted            = errors.New("the user is already muted")
+	ErrSelfMute                = errors.New("a user cannot mute itself")
+	ErrAlreadyReported         = errors.New("the target has already been reported")
+	ErrInvalidModerationAction = errors.New("the moderation action can't be taken on the target")
+	ErrNoAuthor                = errors.New("the content has no author")
+	ErrNothingToUpdate         = errors.New("there are no fields to update")
+	ErrPurgeScheduled          = errors.New("the account is already scheduled for deletion")
+	ErrPurgeNotCancelable      = errors.New("the account has no deletion that can be canceled")
+	ErrSessionRotated          = errors.New("the session has already been rotated")
 )
 
-// UsernameTaken verifies in the database if the provided username is taken or not
+// UsernameTaken verifies in the database if the provided username is taken or not, ignoring the case
 func (q *Queries) UsernameTaken(ctx context.Context, username string) error {
 	_, err := q.GetUser(ctx, "username", username)
 	if err == mongo.ErrNoDocuments {
@@ -27,7 +41,7 @@ func (q *Queries) UsernameTaken(ctx context.Context, username string) error {
 	return ErrUsernameTaken
 }
 
-// EmailTaken verifies in the database if the provided email is taken or not
+// EmailTaken verifies in the database if the provided email is taken or not, ignoring the case
 func (q *Queries) EmailTaken(ctx context.Context, email string) error {
 	_, err := q.GetUser(ctx, "email", email)
 	if err == mongo.ErrNoDocuments {
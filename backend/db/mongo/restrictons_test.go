@@ -1,6 +1,8 @@
 package db
 
 import (
+	"bytes"
+	"strings"
 	"testing"
 
 	"github.com/DMV-Nicolas/robotgram/backend/util"
@@ -42,3 +44,85 @@ func TestEmailTaken(t *testing.T) {
 	require.EqualError(t, ErrEmailTaken, err.Error())
 	require.Empty(t, result)
 }
+
+func TestUsernameTakenIgnoresCase(t *testing.T) {
+	user1 := randomUser(t)
+
+	arg := CreateUserParams{
+		Username:       strings.ToUpper(user1.Username),
+		HashedPassword: util.RandomPassword(16),
+		FullName:       util.RandomUsername(),
+		Email:          util.RandomEmail(),
+		Avatar:         "avatar.png",
+		Gender:         "male",
+	}
+
+	result, err := testQueries.CreateUser(testCtx, arg)
+	require.ErrorIs(t, err, ErrUsernameTaken)
+	require.Empty(t, result)
+
+	user2, err := testQueries.GetUser(testCtx, "username", arg.Username)
+	require.NoError(t, err)
+	require.Equal(t, user1.ID, user2.ID)
+}
+
+func TestEmailTakenIgnoresCase(t *testing.T) {
+	user1 := randomUser(t)
+
+	arg := CreateUserParams{
+		Username:       util.RandomUsername(),
+		HashedPassword: util.RandomPassword(16),
+		FullName:       util.RandomUsername(),
+		Email:          strings.ToUpper(user1.Email),
+		Avatar:         "avatar.png",
+		Gender:         "male",
+	}
+
+	result, err := testQueries.CreateUser(testCtx, arg)
+	require.ErrorIs(t, err, ErrEmailTaken)
+	require.Empty(t, result)
+
+	user2, err := testQueries.GetUser(testCtx, "email", arg.Email)
+	require.NoError(t, err)
+	require.Equal(t, user1.ID, user2.ID)
+}
+
+func TestCreateUserConcurrent(t *testing.T) {
+	n := 10
+	username := util.RandomUsername()
+
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		// every signup uses a different case of the same username
+		name := []byte(username)
+		if i%2 == 1 {
+			name = bytes.ToUpper(name)
+		}
+
+		arg := CreateUserParams{
+			Username:       string(name),
+			HashedPassword: util.RandomPassword(16),
+			FullName:       util.RandomUsername(),
+			Email:          util.RandomEmail(),
+			Avatar:         "avatar.png",
+			Gender:         "male",
+		}
+
+		go func() {
+			_, err := testQueries.CreateUser(testCtx, arg)
+			errs <- err
+		}()
+	}
+
+	created := 0
+	for i := 0; i < n; i++ {
+		err := <-errs
+		if err == nil {
+			created++
+			continue
+		}
+		require.ErrorIs(t, err, ErrUsernameTaken)
+	}
+
+	require.Equal(t, 1, created)
+}
@@ -0,0 +1,222 @@
+package db
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/DMV-Nicolas/robotgram/backend/util"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type SearchParams struct {
+	Query    string             `json:"query" bson:"query"`
+	Offset   int64              `json:"offset" bson:"offset"`
+	Limit    int64              `json:"limit" bson:"limit"`
+	ViewerID primitive.ObjectID `json:"viewer_id" bson:"viewer_id"`
+}
+
+// SearchUsers finds the users by their username and full name, ignoring the case and the accents.
+// First come the users with a word starting with each word of the query, the exact username before the
+// username prefixes and the most followed first. Then come the users the text index finds with only some
+// of the words, or with them in their description, the best matches first.
+// The users blocked by the viewer, or who blocked it, are left out
+func (q *Queries) SearchUsers(ctx context.Context, arg SearchParams) ([]User, error) {
+	terms := util.SearchTerms(arg.Query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	var blocked bson.A
+	if !arg.ViewerID.IsZero() {
+		var err error
+		blocked, err = q.blockedUserIDs(ctx, arg.ViewerID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	prefixes := make(bson.A, len(terms))
+	for i, term := range terms {
+		// an anchored regex without options is resolved with the search_keys index
+		prefixes[i] = primitive.Regex{Pattern: "^" + regexp.QuoteMeta(term)}
+	}
+
+	prefixFilter := bson.M{
+		"search_keys": bson.M{"$all": prefixes},
+		"purge_at":    bson.M{"$exists": false},
+	}
+	if len(blocked) > 0 {
+		prefixFilter["_id"] = bson.M{"$nin": blocked}
+	}
+
+	coll := q.db.Collection("users")
+	nPrefix, err := coll.CountDocuments(ctx, prefixFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	var users []User
+	if arg.Offset < nPrefix {
+		username := strings.Join(terms, "")
+		pipeline := mongo.Pipeline{
+			bson.D{primitive.E{Key: "$match", Value: prefixFilter}},
+			bson.D{primitive.E{Key: "$addFields", Value: bson.M{"rank": usernameRank(username)}}},
+			bson.D{primitive.E{Key: "$sort", Value: bson.D{
+				primitive.E{Key: "rank", Value: -1},
+				primitive.E{Key: "followers_count", Value: -1},
+				primitive.E{Key: "_id", Value: 1},
+			}}},
+			bson.D{primitive.E{Key: "$skip", Value: arg.Offset}},
+			bson.D{primitive.E{Key: "$limit", Value: arg.Limit}},
+			bson.D{primitive.E{Key: "$project", Value: listedUserProjection}},
+		}
+
+		users, err = q.aggregateUsers(ctx, pipeline)
+		if err != nil || int64(len(users)) == arg.Limit {
+			return users, err
+		}
+	}
+
+	// the text index matches whole words, so it only adds the users the prefixes missed
+	textFilter := bson.M{
+		"$text":    bson.M{"$search": strings.Join(terms, " ")},
+		"purge_at": bson.M{"$exists": false},
+		"$nor":     bson.A{bson.M{"search_keys": bson.M{"$all": prefixes}}},
+	}
+	if len(blocked) > 0 {
+		textFilter["_id"] = bson.M{"$nin": blocked}
+	}
+	opts := options.Find().
+		SetProjection(append(bson.D{primitive.E{Key: "score", Value: bson.M{"$meta": "textScore"}}}, listedUserProjection...)).
+		SetSort(bson.D{
+			primitive.E{Key: "score", Value: bson.M{"$meta": "textScore"}},
+			primitive.E{Key: "_id", Value: 1},
+		}).
+		SetSkip(max(arg.Offset-nPrefix, 0)).
+		SetLimit(arg.Limit - int64(len(users)))
+
+	cursor, err := coll.Find(ctx, textFilter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	for cursor.Next(ctx) {
+		var user User
+		err = cursor.Decode(&user)
+		if err != nil {
+			return nil, err
+		}
+
+		users = append(users, user)
+	}
+
+	return users, nil
+}
+
+// usernameRank ranks 2 the user whose username is the query, 1 the ones whose username starts with it
+// and 0 the rest, which only matched by their full name
+func usernameRank(username string) bson.M {
+	lower := bson.M{"$toLower": "$username"}
+	return bson.M{"$switch": bson.M{
+		"branches": bson.A{
+			bson.M{"case": bson.M{"$eq": bson.A{lower, username}}, "then": 2},
+			bson.M{"case": bson.M{"$eq": bson.A{bson.M{"$indexOfCP": bson.A{lower, username}}, 0}}, "then": 1},
+		},
+		"default": 0,
+	}}
+}
+
+func (q *Queries) aggregateUsers(ctx context.Context, pipeline mongo.Pipeline) ([]User, error) {
+	var users []User
+	coll := q.db.Collection("users")
+	cursor, err := coll.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	for cursor.Next(ctx) {
+		var user User
+		err = cursor.Decode(&user)
+		if err != nil {
+			return nil, err
+		}
+
+		users = append(users, user)
+	}
+
+	return users, nil
+}
+
+// SearchPosts finds the posts whose description has the words of the query, the best matches first.
+// The words are compared with their Spanish stem and ignoring the case and the accents, so "robots"
+// finds "Robot" and "canción" finds "cancion"
+func (q *Queries) SearchPosts(ctx context.Context, arg SearchParams) ([]Post, error) {
+	filter, err := q.visiblePostsFilter(ctx, arg.ViewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	filter["$text"] = bson.M{"$search": arg.Query}
+	opts := options.Find().
+		SetProjection(bson.M{"score": bson.M{"$meta": "textScore"}}).
+		SetSort(bson.D{
+			primitive.E{Key: "score", Value: bson.M{"$meta": "textScore"}},
+			primitive.E{Key: "_id", Value: -1},
+		}).
+		SetSkip(arg.Offset).
+		SetLimit(arg.Limit)
+
+	var posts []Post
+	coll := q.db.Collection("posts")
+	cursor, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	for cursor.Next(ctx) {
+		var post Post
+		err = cursor.Decode(&post)
+		if err != nil {
+			return nil, err
+		}
+
+		posts = append(posts, post)
+	}
+
+	return posts, nil
+}
+
+// RepairSearchKeys computes the search keys of the users created before they existed, and returns
+// how many users were updated
+func (q *Queries) RepairSearchKeys(ctx context.Context) (int64, error) {
+	coll := q.db.Collection("users")
+	filter := bson.M{"search_keys": bson.M{"$exists": false}}
+	opts := options.Find().SetProjection(bson.M{"username": 1, "full_name": 1})
+
+	cursor, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		return 0, err
+	}
+
+	var repaired int64
+	for cursor.Next(ctx) {
+		var user User
+		err = cursor.Decode(&user)
+		if err != nil {
+			return repaired, err
+		}
+
+		update := bson.M{"$set": bson.M{"search_keys": util.UserSearchKeys(user.Username, user.FullName)}}
+		_, err = coll.UpdateByID(ctx, user.ID, update)
+		if err != nil {
+			return repaired, err
+		}
+		repaired++
+	}
+
+	return repaired, nil
+}
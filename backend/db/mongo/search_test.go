@@ -0,0 +1,110 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/DMV-Nicolas/robotgram/backend/util"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func randomUserWithName(t *testing.T, username, fullName string) User {
+	result, err := testQueries.CreateUser(testCtx, CreateUserParams{
+		Username:       username,
+		HashedPassword: util.RandomPassword(16),
+		FullName:       fullName,
+		Email:          util.RandomEmail(),
+		Avatar:         util.RandomImage(),
+		Gender:         "male",
+	})
+	require.NoError(t, err)
+
+	user, err := testQueries.GetUser(testCtx, "_id", result.InsertedID)
+	require.NoError(t, err)
+	require.Equal(t, util.UserSearchKeys(username, fullName), user.SearchKeys)
+
+	return user
+}
+
+func searchUserIDs(t *testing.T, query string, offset, limit int64) []primitive.ObjectID {
+	users, err := testQueries.SearchUsers(testCtx, SearchParams{Query: query, Offset: offset, Limit: limit})
+	require.NoError(t, err)
+
+	ids := make([]primitive.ObjectID, len(users))
+	for i, user := range users {
+		require.Empty(t, user.HashedPassword)
+		ids[i] = user.ID
+	}
+
+	return ids
+}
+
+func TestSearchUsers(t *testing.T) {
+	// a random word keeps the users of other tests out of the results
+	word := util.RandomString(10)
+
+	exact := randomUserWithName(t, word, "Robot")
+	prefix := randomUserWithName(t, word+"bot", "Robot")
+	name := randomUserWithName(t, util.RandomUsername(), "José "+word+"ñez")
+	partial := randomUserWithName(t, util.RandomUsername(), "Ana "+word)
+
+	require.Equal(t, []primitive.ObjectID{exact.ID, prefix.ID, name.ID, partial.ID}, searchUserIDs(t, word, 0, 10))
+
+	// the accents and the case are ignored
+	require.Equal(t, name.ID, searchUserIDs(t, "JOSE "+word+"nez", 0, 10)[0])
+
+	// the text index finds the users with only some of the words
+	require.ElementsMatch(t, []primitive.ObjectID{partial.ID, exact.ID, prefix.ID}, searchUserIDs(t, "ana "+word+" robot", 0, 10)[:3])
+
+	// the pages go on from the prefix matches to the text matches
+	require.Equal(t, []primitive.ObjectID{prefix.ID, name.ID}, searchUserIDs(t, word, 1, 2))
+	require.Empty(t, searchUserIDs(t, "?!", 0, 10))
+
+	// the full name can be changed
+	fullName := "Nicolás " + word + "z"
+	_, err := testQueries.UpdateUser(testCtx, UpdateUserParams{ID: partial.ID, FullName: &fullName})
+	require.NoError(t, err)
+	require.Equal(t, partial.ID, searchUserIDs(t, "nicolas "+word+"z", 0, 10)[0])
+}
+
+func TestSearchUsersBlocked(t *testing.T) {
+	word := util.RandomString(10)
+	viewer := randomUser(t)
+	blocked := randomUserWithName(t, word, "Robot")
+	blocker := randomUserWithName(t, word+"bot", "Robot")
+	other := randomUserWithName(t, util.RandomUsername(), "Ana "+word)
+
+	_, err := testQueries.BlockUser(testCtx, BlockParams{BlockerID: viewer.ID, BlockedID: blocked.ID})
+	require.NoError(t, err)
+	_, err = testQueries.BlockUser(testCtx, BlockParams{BlockerID: blocker.ID, BlockedID: viewer.ID})
+	require.NoError(t, err)
+
+	// the blocks in both directions hide the users from the prefix and the text matches
+	for _, query := range []string{word, "ana " + word + " robot"} {
+		users, err := testQueries.SearchUsers(testCtx, SearchParams{Query: query, Limit: 10, ViewerID: viewer.ID})
+		require.NoError(t, err)
+		require.Len(t, users, 1)
+		require.Equal(t, other.ID, users[0].ID)
+	}
+
+	// the users not involved still find them
+	require.Len(t, searchUserIDs(t, word, 0, 10), 3)
+}
+
+func TestSearchPosts(t *testing.T) {
+	word := util.RandomString(10)
+	user := randomUser(t)
+	post1 := randomPostByUserWithDescription(t, user.ID, "mis "+word+" bailan una canción")
+	post2 := randomPostByUserWithDescription(t, user.ID, "un "+word)
+	randomPostByUserWithDescription(t, user.ID, "nada que ver")
+
+	posts, err := testQueries.SearchPosts(testCtx, SearchParams{Query: word, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, posts, 2)
+	require.ElementsMatch(t, []primitive.ObjectID{post1.ID, post2.ID}, []primitive.ObjectID{posts[0].ID, posts[1].ID})
+
+	// the best match comes first, the accents are ignored
+	posts, err = testQueries.SearchPosts(testCtx, SearchParams{Query: word + " cancion", Limit: 10})
+	require.NoError(t, err)
+	require.Equal(t, post1.ID, posts[0].ID)
+}
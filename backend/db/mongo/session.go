@@ -21,7 +21,20 @@ type CreateSessionParams struct {
 }
 
 func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (*mongo.InsertOneResult, error) {
-	session := Session(arg)
+	// a login starts a new family
+	now := time.Now()
+	session := Session{
+		ID:           arg.ID,
+		UserID:       arg.UserID,
+		FamilyID:     arg.ID,
+		RefreshToken: arg.RefreshToken,
+		UserAgent:    arg.UserAgent,
+		ClientIP:     arg.ClientIP,
+		IsBlocked:    arg.IsBlocked,
+		ExpiresAt:    arg.ExpiresAt,
+		LastUsedAt:   now,
+		CreatedAt:    now,
+	}
 
 	coll := q.db.Collection("sessions")
 	result, err := coll.InsertOne(ctx, session)
@@ -40,6 +53,39 @@ func (q *Queries) GetSession(ctx context.Context, id primitive.ObjectID) (Sessio
 	return session, err
 }
 
+// ListSessions lists the sessions of the user that aren't blocked, rotated nor expired, the newest first
+func (q *Queries) ListSessions(ctx context.Context, userID primitive.ObjectID) ([]Session, error) {
+	filter := bson.M{
+		"user_id":    userID,
+		"is_blocked": false,
+		"rotated_at": bson.M{"$exists": false},
+		"expires_at": bson.M{"$gt": time.Now()},
+	}
+	opts := options.Find().SetSort(bson.D{
+		primitive.E{Key: "created_at", Value: -1},
+		primitive.E{Key: "_id", Value: -1},
+	})
+
+	var sessions []Session
+	coll := q.db.Collection("sessions")
+	cursor, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	for cursor.Next(ctx) {
+		var session Session
+		err = cursor.Decode(&session)
+		if err != nil {
+			return nil, err
+		}
+
+		sessions = append(sessions, session)
+	}
+
+	return sessions, nil
+}
+
 func (q *Queries) DeleteSession(ctx context.Context, id primitive.ObjectID) (*mongo.DeleteResult, error) {
 	filter := bson.M{"_id": id}
 
@@ -61,3 +107,117 @@ func (q *Queries) BlockSession(ctx context.Context, id primitive.ObjectID) (*mon
 
 	return result, err
 }
+
+type BlockUserSessionsParams struct {
+	UserID   primitive.ObjectID `json:"user_id" bson:"user_id"`
+	ExceptID primitive.ObjectID `json:"except_id" bson:"except_id"`
+}
+
+// BlockUserSessions blocks every session of the user except the family of the given one
+func (q *Queries) BlockUserSessions(ctx context.Context, arg BlockUserSessionsParams) (*mongo.UpdateResult, error) {
+	filter := bson.M{"user_id": arg.UserID}
+
+	if !arg.ExceptID.IsZero() {
+		except, err := q.GetSession(ctx, arg.ExceptID)
+		if err != nil && err != mongo.ErrNoDocuments {
+			return nil, err
+		}
+
+		filter["_id"] = bson.M{"$ne": arg.ExceptID}
+		if !except.FamilyID.IsZero() {
+			filter["family_id"] = bson.M{"$ne": except.FamilyID}
+		}
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"is_blocked": true,
+		},
+	}
+
+	coll := q.db.Collection("sessions")
+	result, err := coll.UpdateMany(ctx, filter, update)
+
+	return result, err
+}
+
+// BlockSessionFamily blocks the given session and all the sessions of its family
+func (q *Queries) BlockSessionFamily(ctx context.Context, id primitive.ObjectID) (*mongo.UpdateResult, error) {
+	session, err := q.GetSession(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bson.M{"_id": session.ID}
+	if !session.FamilyID.IsZero() {
+		filter = bson.M{"family_id": session.FamilyID}
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"is_blocked": true,
+		},
+	}
+
+	coll := q.db.Collection("sessions")
+	result, err := coll.UpdateMany(ctx, filter, update)
+
+	return result, err
+}
+
+type RotateSessionParams struct {
+	ID           primitive.ObjectID `json:"id" bson:"_id"`
+	NewID        primitive.ObjectID `json:"new_id" bson:"new_id"`
+	RefreshToken string             `json:"refresh_token" bson:"refresh_token"`
+	UserAgent    string             `json:"user_agent" bson:"user_agent"`
+	ClientIP     string             `json:"client_ip" bson:"client_ip"`
+	ExpiresAt    time.Time          `json:"expires_at" bson:"expires_at"`
+}
+
+// RotateSession marks the session as rotated and creates its successor in the same family.
+// It returns ErrSessionRotated if the session was already rotated, which means its refresh token is being reused
+func (q *Queries) RotateSession(ctx context.Context, arg RotateSessionParams) (Session, error) {
+	var session Session
+	err := q.execTx(ctx, func(ctx context.Context) error {
+		now := time.Now()
+		filter := bson.M{
+			"_id":        arg.ID,
+			"is_blocked": false,
+			"rotated_at": bson.M{"$exists": false},
+		}
+		update := bson.M{"$set": bson.M{"rotated_at": now}}
+
+		var parent Session
+		coll := q.db.Collection("sessions")
+		err := coll.FindOneAndUpdate(ctx, filter, update).Decode(&parent)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				return ErrSessionRotated
+			}
+			return err
+		}
+
+		familyID := parent.FamilyID
+		if familyID.IsZero() {
+			familyID = parent.ID
+		}
+
+		session = Session{
+			ID:           arg.NewID,
+			UserID:       parent.UserID,
+			FamilyID:     familyID,
+			ParentID:     parent.ID,
+			RefreshToken: arg.RefreshToken,
+			UserAgent:    arg.UserAgent,
+			ClientIP:     arg.ClientIP,
+			IsBlocked:    false,
+			ExpiresAt:    arg.ExpiresAt,
+			LastUsedAt:   now,
+			// the family keeps the time of its login
+			CreatedAt: parent.CreatedAt,
+		}
+
+		_, err = coll.InsertOne(ctx, session)
+		return err
+	})
+
+	return session, err
+}
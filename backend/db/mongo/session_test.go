@@ -11,7 +11,10 @@ import (
 )
 
 func randomSession(t *testing.T) Session {
-	user := randomUser(t)
+	return randomUserSession(t, randomUser(t))
+}
+
+func randomUserSession(t *testing.T, user User) Session {
 	arg := CreateSessionParams{
 		ID:           primitive.NewObjectID(),
 		UserID:       user.ID,
@@ -41,6 +44,9 @@ func randomSession(t *testing.T) Session {
 	require.Equal(t, arg.ClientIP, session.ClientIP)
 	require.Equal(t, arg.IsBlocked, session.IsBlocked)
 	require.WithinDuration(t, arg.ExpiresAt, session.ExpiresAt, time.Second)
+	require.Equal(t, insertedID, session.FamilyID)
+	require.True(t, session.RotatedAt.IsZero())
+	require.WithinDuration(t, time.Now(), session.CreatedAt, time.Second)
 
 	return session
 }
@@ -70,6 +76,24 @@ func TestGetSession(t *testing.T) {
 	require.Empty(t, session3)
 }
 
+func TestListSessions(t *testing.T) {
+	user := randomUser(t)
+	session1 := randomUserSession(t, user)
+	session2 := randomUserSession(t, user)
+	blocked := randomUserSession(t, user)
+
+	_, err := testQueries.BlockSession(testCtx, blocked.ID)
+	require.NoError(t, err)
+
+	sessions, err := testQueries.ListSessions(testCtx, user.ID)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+
+	// the newest first
+	require.Equal(t, session2.ID, sessions[0].ID)
+	require.Equal(t, session1.ID, sessions[1].ID)
+}
+
 func TestDeleteSession(t *testing.T) {
 	session1 := randomSession(t)
 
@@ -99,3 +123,139 @@ func TestBlockSession(t *testing.T) {
 
 	require.True(t, session2.IsBlocked)
 }
+
+func TestBlockUserSessions(t *testing.T) {
+	user := randomUser(t)
+	current := randomUserSession(t, user)
+	other1 := randomUserSession(t, user)
+	other2 := randomUserSession(t, user)
+	stranger := randomSession(t)
+
+	arg := BlockUserSessionsParams{
+		UserID:   user.ID,
+		ExceptID: current.ID,
+	}
+
+	result, err := testQueries.BlockUserSessions(testCtx, arg)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, result.MatchedCount)
+	require.EqualValues(t, 2, result.ModifiedCount)
+
+	for _, session := range []Session{other1, other2} {
+		session, err = testQueries.GetSession(testCtx, session.ID)
+		require.NoError(t, err)
+		require.True(t, session.IsBlocked)
+	}
+
+	for _, session := range []Session{current, stranger} {
+		session, err = testQueries.GetSession(testCtx, session.ID)
+		require.NoError(t, err)
+		require.False(t, session.IsBlocked)
+	}
+}
+
+func rotateSession(t *testing.T, parent Session) Session {
+	arg := RotateSessionParams{
+		ID:           parent.ID,
+		NewID:        primitive.NewObjectID(),
+		RefreshToken: util.RandomString(30),
+		UserAgent:    util.RandomString(10),
+		ClientIP:     util.RandomString(10),
+		ExpiresAt:    time.Now().Add(time.Minute),
+	}
+
+	session, err := testQueries.RotateSession(testCtx, arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.NewID, session.ID)
+	require.Equal(t, parent.UserID, session.UserID)
+	require.Equal(t, parent.FamilyID, session.FamilyID)
+	require.Equal(t, parent.ID, session.ParentID)
+	require.Equal(t, arg.RefreshToken, session.RefreshToken)
+	require.False(t, session.IsBlocked)
+	require.WithinDuration(t, parent.CreatedAt, session.CreatedAt, time.Millisecond)
+	require.WithinDuration(t, time.Now(), session.LastUsedAt, time.Second)
+
+	return session
+}
+
+func TestRotateSession(t *testing.T) {
+	session1 := randomSession(t)
+	session2 := rotateSession(t, session1)
+
+	session1, err := testQueries.GetSession(testCtx, session1.ID)
+	require.NoError(t, err)
+	require.WithinDuration(t, time.Now(), session1.RotatedAt, time.Second)
+
+	session3, err := testQueries.GetSession(testCtx, session2.ID)
+	require.NoError(t, err)
+	require.Equal(t, session2.FamilyID, session3.FamilyID)
+	require.True(t, session3.RotatedAt.IsZero())
+	require.WithinDuration(t, session1.CreatedAt, session3.CreatedAt, time.Millisecond)
+	require.WithinDuration(t, time.Now(), session3.LastUsedAt, time.Second)
+
+	// a rotated session can't be rotated again
+	arg := RotateSessionParams{
+		ID:           session1.ID,
+		NewID:        primitive.NewObjectID(),
+		RefreshToken: util.RandomString(30),
+		ExpiresAt:    time.Now().Add(time.Minute),
+	}
+
+	_, err = testQueries.RotateSession(testCtx, arg)
+	require.ErrorIs(t, err, ErrSessionRotated)
+
+	_, err = testQueries.GetSession(testCtx, arg.NewID)
+	require.ErrorIs(t, err, mongo.ErrNoDocuments)
+
+	// only the last session of the family is listed
+	sessions, err := testQueries.ListSessions(testCtx, session1.UserID)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	require.Equal(t, session2.ID, sessions[0].ID)
+}
+
+func TestBlockSessionFamily(t *testing.T) {
+	user := randomUser(t)
+	session1 := randomUserSession(t, user)
+	session2 := rotateSession(t, session1)
+	session3 := rotateSession(t, session2)
+	other := randomUserSession(t, user)
+
+	result, err := testQueries.BlockSessionFamily(testCtx, session1.ID)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, result.MatchedCount)
+
+	for _, session := range []Session{session1, session2, session3} {
+		session, err = testQueries.GetSession(testCtx, session.ID)
+		require.NoError(t, err)
+		require.True(t, session.IsBlocked)
+	}
+
+	other, err = testQueries.GetSession(testCtx, other.ID)
+	require.NoError(t, err)
+	require.False(t, other.IsBlocked)
+}
+
+func TestBlockUserSessionsKeepsFamily(t *testing.T) {
+	user := randomUser(t)
+	session1 := randomUserSession(t, user)
+	session2 := rotateSession(t, session1)
+	other := randomUserSession(t, user)
+
+	arg := BlockUserSessionsParams{
+		UserID:   user.ID,
+		ExceptID: session1.ID,
+	}
+
+	result, err := testQueries.BlockUserSessions(testCtx, arg)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, result.ModifiedCount)
+
+	session2, err = testQueries.GetSession(testCtx, session2.ID)
+	require.NoError(t, err)
+	require.False(t, session2.IsBlocked)
+
+	other, err = testQueries.GetSession(testCtx, other.ID)
+	require.NoError(t, err)
+	require.True(t, other.IsBlocked)
+}
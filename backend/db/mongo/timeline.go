@@ -0,0 +1,444 @@
+package db
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	TimelineJobFanout   = "fanout"
+	TimelineJobBackfill = "backfill"
+	TimelineJobCleanup  = "cleanup"
+)
+
+const (
+	TimelineJobPending    = "pending"
+	TimelineJobProcessing = "processing"
+	TimelineJobDone       = "done"
+	TimelineJobFailed     = "failed"
+)
+
+// timelineJobLease is the time after which a processing job is considered abandoned and can be claimed again
+const timelineJobLease = 5 * time.Minute
+
+type EnqueueTimelineJobParams struct {
+	Kind     string             `json:"kind" bson:"kind"`
+	PostID   primitive.ObjectID `json:"post_id" bson:"post_id"`
+	AuthorID primitive.ObjectID `json:"author_id" bson:"author_id"`
+	OwnerID  primitive.ObjectID `json:"owner_id" bson:"owner_id"`
+}
+
+func (q *Queries) EnqueueTimelineJob(ctx context.Context, arg EnqueueTimelineJobParams) (*mongo.InsertOneResult, error) {
+	now := time.Now()
+	job := TimelineJob{
+		ID:        primitive.NewObjectID(),
+		Kind:      arg.Kind,
+		PostID:    arg.PostID,
+		AuthorID:  arg.AuthorID,
+		OwnerID:   arg.OwnerID,
+		Status:    TimelineJobPending,
+		Processed: 0,
+		Error:     "",
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	coll := q.db.Collection("timeline_jobs")
+	result, err := coll.InsertOne(ctx, job)
+
+	return result, err
+}
+
+// ClaimTimelineJob marks the oldest pending job, or an abandoned one, as processing and returns it.
+// It returns mongo.ErrNoDocuments when the queue is empty
+func (q *Queries) ClaimTimelineJob(ctx context.Context) (TimelineJob, error) {
+	now := time.Now()
+	filter := bson.M{
+		"$or": bson.A{
+			bson.M{"status": TimelineJobPending},
+			bson.M{"status": TimelineJobProcessing, "updated_at": bson.M{"$lt": now.Add(-timelineJobLease)}},
+		},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"status":     TimelineJobProcessing,
+			"updated_at": now,
+		},
+	}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{primitive.E{Key: "created_at", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var job TimelineJob
+	coll := q.db.Collection("timeline_jobs")
+	err := coll.FindOneAndUpdate(ctx, filter, update, opts).Decode(&job)
+
+	return job, err
+}
+
+type UpdateTimelineJobParams struct {
+	ID           primitive.ObjectID `json:"id" bson:"_id"`
+	Status       string             `json:"status" bson:"status"`
+	Processed    int64              `json:"processed" bson:"processed"`
+	LastFollowID primitive.ObjectID `json:"last_follow_id" bson:"last_follow_id"`
+	Error        string             `json:"error" bson:"error"`
+}
+
+// UpdateTimelineJob saves the status and the progress of the job, an empty LastFollowID keeps the saved one
+func (q *Queries) UpdateTimelineJob(ctx context.Context, arg UpdateTimelineJobParams) (*mongo.UpdateResult, error) {
+	set := bson.M{
+		"status":     arg.Status,
+		"processed":  arg.Processed,
+		"error":      arg.Error,
+		"updated_at": time.Now(),
+	}
+	if !arg.LastFollowID.IsZero() {
+		set["last_follow_id"] = arg.LastFollowID
+	}
+	update := bson.M{"$set": set}
+
+	coll := q.db.Collection("timeline_jobs")
+	result, err := coll.UpdateByID(ctx, arg.ID, update)
+
+	return result, err
+}
+
+type ListTimelineJobsParams struct {
+	Status string `json:"status" bson:"status"`
+	Offset int64  `json:"offset" bson:"offset"`
+	Limit  int64  `json:"limit" bson:"limit"`
+}
+
+func (q *Queries) ListTimelineJobs(ctx context.Context, arg ListTimelineJobsParams) ([]TimelineJob, error) {
+	filter := bson.M{}
+	if arg.Status != "" {
+		filter["status"] = arg.Status
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{primitive.E{Key: "created_at", Value: -1}}).
+		SetSkip(arg.Offset).
+		SetLimit(arg.Limit)
+
+	var jobs []TimelineJob
+	coll := q.db.Collection("timeline_jobs")
+	cursor, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	for cursor.Next(ctx) {
+		var job TimelineJob
+		err = cursor.Decode(&job)
+		if err != nil {
+			return nil, err
+		}
+
+		jobs = append(jobs, job)
+	}
+
+	return jobs, nil
+}
+
+type TimelineStats struct {
+	Pending         int64     `json:"pending"`
+	Processing      int64     `json:"processing"`
+	Done            int64     `json:"done"`
+	Failed          int64     `json:"failed"`
+	OldestPendingAt time.Time `json:"oldest_pending_at"`
+}
+
+// GetTimelineStats counts the timeline jobs by status
+func (q *Queries) GetTimelineStats(ctx context.Context) (TimelineStats, error) {
+	pipeline := mongo.Pipeline{
+		bson.D{primitive.E{Key: "$group", Value: bson.M{
+			"_id":    "$status",
+			"count":  bson.M{"$sum": 1},
+			"oldest": bson.M{"$min": "$created_at"},
+		}}},
+	}
+
+	coll := q.db.Collection("timeline_jobs")
+	cursor, err := coll.Aggregate(ctx, pipeline)
+	if err != nil {
+		return TimelineStats{}, err
+	}
+
+	var stats TimelineStats
+	for cursor.Next(ctx) {
+		var group struct {
+			Status string    `bson:"_id"`
+			Count  int64     `bson:"count"`
+			Oldest time.Time `bson:"oldest"`
+		}
+		err = cursor.Decode(&group)
+		if err != nil {
+			return TimelineStats{}, err
+		}
+
+		switch group.Status {
+		case TimelineJobPending:
+			stats.Pending = group.Count
+			stats.OldestPendingAt = group.Oldest
+		case TimelineJobProcessing:
+			stats.Processing = group.Count
+		case TimelineJobDone:
+			stats.Done = group.Count
+		case TimelineJobFailed:
+			stats.Failed = group.Count
+		}
+	}
+
+	return stats, nil
+}
+
+// AddTimelineEntries inserts the given entries, ignoring the posts already present in the owner timeline
+func (q *Queries) AddTimelineEntries(ctx context.Context, entries []TimelineEntry) (*mongo.BulkWriteResult, error) {
+	if len(entries) == 0 {
+		return &mongo.BulkWriteResult{}, nil
+	}
+
+	models := make([]mongo.WriteModel, len(entries))
+	for i, entry := range entries {
+		if entry.ID.IsZero() {
+			entry.ID = primitive.NewObjectID()
+		}
+
+		models[i] = mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"owner_id": entry.OwnerID, "post_id": entry.PostID}).
+			SetUpdate(bson.M{"$setOnInsert": entry}).
+			SetUpsert(true)
+	}
+
+	coll := q.db.Collection("timelines")
+	result, err := coll.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+
+	return result, err
+}
+
+type BackfillTimelineParams struct {
+	OwnerID  primitive.ObjectID `json:"owner_id" bson:"owner_id"`
+	AuthorID primitive.ObjectID `json:"author_id" bson:"author_id"`
+	Limit    int64              `json:"limit" bson:"limit"`
+}
+
+// BackfillTimeline copies the latest posts of the author into the owner timeline
+func (q *Queries) BackfillTimeline(ctx context.Context, arg BackfillTimelineParams) (*mongo.BulkWriteResult, error) {
+	filter := bson.M{"user_id": arg.AuthorID}
+	posts, err := q.findFeedPosts(ctx, filter, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]TimelineEntry, len(posts))
+	for i, post := range posts {
+		entries[i] = TimelineEntry{
+			OwnerID:   arg.OwnerID,
+			PostID:    post.ID,
+			AuthorID:  post.UserID,
+			CreatedAt: post.CreatedAt,
+		}
+	}
+
+	return q.AddTimelineEntries(ctx, entries)
+}
+
+type RemoveTimelineAuthorParams struct {
+	OwnerID  primitive.ObjectID `json:"owner_id" bson:"owner_id"`
+	AuthorID primitive.ObjectID `json:"author_id" bson:"author_id"`
+}
+
+// RemoveTimelineAuthor removes the posts of the author from the owner timeline
+func (q *Queries) RemoveTimelineAuthor(ctx context.Context, arg RemoveTimelineAuthorParams) (*mongo.DeleteResult, error) {
+	filter := bson.M{"owner_id": arg.OwnerID, "author_id": arg.AuthorID}
+
+	coll := q.db.Collection("timelines")
+	result, err := coll.DeleteMany(ctx, filter)
+
+	return result, err
+}
+
+type ListFeedParams struct {
+	UserID          primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Limit           int64              `json:"limit" bson:"limit"`
+	BeforeCreatedAt time.Time          `json:"before_created_at" bson:"before_created_at"`
+	BeforeID        primitive.ObjectID `json:"before_id" bson:"before_id"`
+	FanoutLimit     int64              `json:"fanout_limit" bson:"fanout_limit"`
+}
+
+// ListFeed lists, newest first, the posts of the given user and of the accounts it follows.
+// The posts of accounts with less followers than FanoutLimit are read from the materialized timeline,
+// the rest are pulled from the posts collection. A FanoutLimit of zero disables the timeline.
+// When BeforeID is set only the posts older than the (BeforeCreatedAt, BeforeID) cursor are listed.
+// The posts of the muted accounts and of the accounts being deleted are left out
+func (q *Queries) ListFeed(ctx context.Context, arg ListFeedParams) ([]Post, error) {
+	authors, err := q.pulledAuthors(ctx, arg.UserID, arg.FanoutLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	hidden, err := q.mutedUserIDs(ctx, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	pending, err := q.pendingPurgeUserIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	hidden = append(hidden, pending...)
+
+	filter := bson.M{"user_id": bson.M{"$in": authors, "$nin": hidden}}
+	if !arg.BeforeID.IsZero() {
+		filter["$or"] = beforeCursor(arg.BeforeCreatedAt, "_id", arg.BeforeID)
+	}
+
+	posts, err := q.findFeedPosts(ctx, filter, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	if arg.FanoutLimit <= 0 {
+		return posts, nil
+	}
+
+	timelinePosts, err := q.timelinePosts(ctx, arg, hidden)
+	if err != nil {
+		return nil, err
+	}
+
+	return mergeFeedPosts(posts, timelinePosts, arg.Limit), nil
+}
+
+// pulledAuthors returns the user and the followed accounts whose posts are not fanned out
+func (q *Queries) pulledAuthors(ctx context.Context, userID primitive.ObjectID, fanoutLimit int64) (bson.A, error) {
+	followees, err := q.db.Collection("follows").Distinct(ctx, "following_id", bson.M{"follower_id": userID})
+	if err != nil {
+		return nil, err
+	}
+
+	if fanoutLimit <= 0 {
+		return append(followees, userID), nil
+	}
+
+	filter := bson.M{
+		"_id":             bson.M{"$in": followees},
+		"followers_count": bson.M{"$gte": fanoutLimit},
+	}
+	celebrities, err := q.db.Collection("users").Distinct(ctx, "_id", filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(celebrities, userID), nil
+}
+
+func (q *Queries) timelinePosts(ctx context.Context, arg ListFeedParams, hidden bson.A) ([]Post, error) {
+	filter := bson.M{"owner_id": arg.UserID, "author_id": bson.M{"$nin": hidden}}
+	if !arg.BeforeID.IsZero() {
+		filter["$or"] = beforeCursor(arg.BeforeCreatedAt, "post_id", arg.BeforeID)
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{
+			primitive.E{Key: "created_at", Value: -1},
+			primitive.E{Key: "post_id", Value: -1},
+		}).
+		SetLimit(arg.Limit)
+
+	coll := q.db.Collection("timelines")
+	cursor, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var postIDs bson.A
+	for cursor.Next(ctx) {
+		var entry TimelineEntry
+		err = cursor.Decode(&entry)
+		if err != nil {
+			return nil, err
+		}
+
+		postIDs = append(postIDs, entry.PostID)
+	}
+
+	if len(postIDs) == 0 {
+		return nil, nil
+	}
+
+	// posts deleted after being fanned out are skipped
+	return q.findFeedPosts(ctx, bson.M{"_id": bson.M{"$in": postIDs}}, arg.Limit)
+}
+
+func (q *Queries) findFeedPosts(ctx context.Context, filter bson.M, limit int64) ([]Post, error) {
+	filter["removed"] = bson.M{"$ne": true}
+	opts := options.Find().
+		SetSort(bson.D{
+			primitive.E{Key: "created_at", Value: -1},
+			primitive.E{Key: "_id", Value: -1},
+		}).
+		SetLimit(limit)
+
+	var posts []Post
+	coll := q.db.Collection("posts")
+	cursor, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	for cursor.Next(ctx) {
+		var post Post
+		err = cursor.Decode(&post)
+		if err != nil {
+			return nil, err
+		}
+
+		posts = append(posts, post)
+	}
+
+	return posts, nil
+}
+
+// beforeCursor builds the conditions that match the documents older than the cursor
+func beforeCursor(createdAt time.Time, idKey string, id primitive.ObjectID) bson.A {
+	return bson.A{
+		bson.M{"created_at": bson.M{"$lt": createdAt}},
+		bson.M{"created_at": createdAt, idKey: bson.M{"$lt": id}},
+	}
+}
+
+// mergeFeedPosts merges two feeds sorted newest first, dropping the duplicated posts
+func mergeFeedPosts(a, b []Post, limit int64) []Post {
+	seen := make(map[primitive.ObjectID]bool, len(a)+len(b))
+	posts := make([]Post, 0, len(a)+len(b))
+	for _, feed := range [][]Post{a, b} {
+		for _, post := range feed {
+			if seen[post.ID] {
+				continue
+			}
+
+			seen[post.ID] = true
+			posts = append(posts, post)
+		}
+	}
+
+	sort.Slice(posts, func(i, j int) bool {
+		if posts[i].CreatedAt.Equal(posts[j].CreatedAt) {
+			return posts[i].ID.Hex() > posts[j].ID.Hex()
+		}
+		return posts[i].CreatedAt.After(posts[j].CreatedAt)
+	})
+
+	if int64(len(posts)) > limit {
+		posts = posts[:limit]
+	}
+
+	return posts
+}
@@ -0,0 +1,263 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func randomTimelineJob(t *testing.T, kind string) TimelineJob {
+	arg := EnqueueTimelineJobParams{
+		Kind:     kind,
+		PostID:   primitive.NewObjectID(),
+		AuthorID: primitive.NewObjectID(),
+		OwnerID:  primitive.NewObjectID(),
+	}
+
+	result, err := testQueries.EnqueueTimelineJob(testCtx, arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, result)
+
+	insertedID, ok := result.InsertedID.(primitive.ObjectID)
+	require.True(t, ok)
+	require.NotEqual(t, primitive.NilObjectID, insertedID)
+
+	return TimelineJob{
+		ID:       insertedID,
+		Kind:     arg.Kind,
+		PostID:   arg.PostID,
+		AuthorID: arg.AuthorID,
+		OwnerID:  arg.OwnerID,
+		Status:   TimelineJobPending,
+	}
+}
+
+func TestEnqueueTimelineJob(t *testing.T) {
+	job := randomTimelineJob(t, TimelineJobFanout)
+
+	jobs, err := testQueries.ListTimelineJobs(testCtx, ListTimelineJobsParams{Status: TimelineJobPending, Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	require.Equal(t, job.ID, jobs[0].ID)
+	require.Equal(t, job.Kind, jobs[0].Kind)
+	require.Equal(t, job.PostID, jobs[0].PostID)
+	require.Equal(t, TimelineJobPending, jobs[0].Status)
+	require.Zero(t, jobs[0].Processed)
+	require.WithinDuration(t, time.Now(), jobs[0].CreatedAt, time.Second)
+}
+
+func TestClaimTimelineJob(t *testing.T) {
+	randomTimelineJob(t, TimelineJobCleanup)
+
+	job, err := testQueries.ClaimTimelineJob(testCtx)
+	require.NoError(t, err)
+	require.NotEmpty(t, job)
+	require.Equal(t, TimelineJobProcessing, job.Status)
+	require.WithinDuration(t, time.Now(), job.UpdatedAt, time.Second)
+}
+
+func TestUpdateTimelineJob(t *testing.T) {
+	job := randomTimelineJob(t, TimelineJobFanout)
+
+	arg := UpdateTimelineJobParams{
+		ID:        job.ID,
+		Status:    TimelineJobFailed,
+		Processed: 10,
+		Error:     "something went wrong",
+	}
+
+	result, err := testQueries.UpdateTimelineJob(testCtx, arg)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, result.MatchedCount)
+
+	jobs, err := testQueries.ListTimelineJobs(testCtx, ListTimelineJobsParams{Status: TimelineJobFailed, Limit: 100})
+	require.NoError(t, err)
+
+	var found bool
+	for _, j := range jobs {
+		if j.ID == job.ID {
+			found = true
+			require.Equal(t, arg.Processed, j.Processed)
+			require.Equal(t, arg.Error, j.Error)
+		}
+	}
+	require.True(t, found)
+}
+
+func TestGetTimelineStats(t *testing.T) {
+	randomTimelineJob(t, TimelineJobBackfill)
+
+	stats, err := testQueries.GetTimelineStats(testCtx)
+	require.NoError(t, err)
+	require.NotZero(t, stats.Pending)
+	require.False(t, stats.OldestPendingAt.IsZero())
+}
+
+func TestAddTimelineEntries(t *testing.T) {
+	ownerID := primitive.NewObjectID()
+	post := randomPost(t)
+	entries := []TimelineEntry{
+		{OwnerID: ownerID, PostID: post.ID, AuthorID: post.UserID, CreatedAt: post.CreatedAt},
+	}
+
+	result, err := testQueries.AddTimelineEntries(testCtx, entries)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, result.UpsertedCount)
+
+	// adding the same post again does nothing
+	result, err = testQueries.AddTimelineEntries(testCtx, entries)
+	require.NoError(t, err)
+	require.Zero(t, result.UpsertedCount)
+}
+
+func TestAddTimelineEntriesConcurrent(t *testing.T) {
+	n := 10
+	post := randomPost(t)
+	entries := []TimelineEntry{
+		{OwnerID: primitive.NewObjectID(), PostID: post.ID, AuthorID: post.UserID, CreatedAt: post.CreatedAt},
+	}
+
+	upserted := make(chan int64, n)
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		go func() {
+			result, err := testQueries.AddTimelineEntries(testCtx, entries)
+			errs <- err
+			if err == nil {
+				upserted <- result.UpsertedCount
+			}
+		}()
+	}
+
+	var total int64
+	for i := 0; i < n; i++ {
+		require.NoError(t, <-errs)
+		total += <-upserted
+	}
+
+	require.EqualValues(t, 1, total)
+}
+
+func TestBackfillTimeline(t *testing.T) {
+	ownerID := primitive.NewObjectID()
+	author := randomUser(t)
+	n := 5
+	for i := 0; i < n; i++ {
+		randomPostByUser(t, author.ID)
+	}
+
+	arg := BackfillTimelineParams{
+		OwnerID:  ownerID,
+		AuthorID: author.ID,
+		Limit:    int64(n - 2),
+	}
+
+	result, err := testQueries.BackfillTimeline(testCtx, arg)
+	require.NoError(t, err)
+	require.EqualValues(t, n-2, result.UpsertedCount)
+}
+
+func TestRemoveTimelineAuthor(t *testing.T) {
+	ownerID := primitive.NewObjectID()
+	author := randomUser(t)
+	n := 3
+	for i := 0; i < n; i++ {
+		randomPostByUser(t, author.ID)
+	}
+
+	_, err := testQueries.BackfillTimeline(testCtx, BackfillTimelineParams{OwnerID: ownerID, AuthorID: author.ID, Limit: int64(n)})
+	require.NoError(t, err)
+
+	arg := RemoveTimelineAuthorParams{
+		OwnerID:  ownerID,
+		AuthorID: author.ID,
+	}
+
+	result, err := testQueries.RemoveTimelineAuthor(testCtx, arg)
+	require.NoError(t, err)
+	require.EqualValues(t, n, result.DeletedCount)
+}
+
+func TestListFeed(t *testing.T) {
+	user := randomUser(t)
+	own := randomPostByUser(t, user.ID)
+
+	n := 4
+	for i := 0; i < n; i++ {
+		followee := randomUser(t)
+		randomFollow(t, user.ID, followee.ID)
+		randomPostByUser(t, followee.ID)
+	}
+
+	// posts from accounts that are not followed are not in the feed
+	randomPost(t)
+
+	arg := ListFeedParams{
+		UserID: user.ID,
+		Limit:  int64(n + 10),
+	}
+
+	feed, err := testQueries.ListFeed(testCtx, arg)
+	require.NoError(t, err)
+	require.Len(t, feed, n+1)
+	require.Equal(t, own.ID, feed[len(feed)-1].ID)
+
+	for i := 1; i < len(feed); i++ {
+		require.False(t, feed[i].CreatedAt.After(feed[i-1].CreatedAt))
+	}
+
+	// paginate with the cursor of the second post
+	arg = ListFeedParams{
+		UserID:          user.ID,
+		Limit:           2,
+		BeforeCreatedAt: feed[1].CreatedAt,
+		BeforeID:        feed[1].ID,
+	}
+
+	// a new post must not shift the next page
+	randomPostByUser(t, user.ID)
+
+	page, err := testQueries.ListFeed(testCtx, arg)
+	require.NoError(t, err)
+	require.Len(t, page, 2)
+	require.Equal(t, feed[2].ID, page[0].ID)
+	require.Equal(t, feed[3].ID, page[1].ID)
+}
+
+func TestListFeedWithTimeline(t *testing.T) {
+	user := randomUser(t)
+	own := randomPostByUser(t, user.ID)
+
+	// the celebrity has two followers and its posts are pulled at read time
+	celebrity := randomUser(t)
+	randomFollow(t, user.ID, celebrity.ID)
+	randomFollow(t, randomUser(t).ID, celebrity.ID)
+	celebrityPost := randomPostByUser(t, celebrity.ID)
+
+	// the posts of the normal account are read from the timeline
+	followee := randomUser(t)
+	randomFollow(t, user.ID, followee.ID)
+	followeePost := randomPostByUser(t, followee.ID)
+	randomPostByUser(t, followee.ID)
+
+	entries := []TimelineEntry{
+		{OwnerID: user.ID, PostID: followeePost.ID, AuthorID: followee.ID, CreatedAt: followeePost.CreatedAt},
+	}
+	_, err := testQueries.AddTimelineEntries(testCtx, entries)
+	require.NoError(t, err)
+
+	arg := ListFeedParams{
+		UserID:      user.ID,
+		Limit:       10,
+		FanoutLimit: 2,
+	}
+
+	feed, err := testQueries.ListFeed(testCtx, arg)
+	require.NoError(t, err)
+	require.Len(t, feed, 3)
+	require.Equal(t, followeePost.ID, feed[0].ID)
+	require.Equal(t, celebrityPost.ID, feed[1].ID)
+	require.Equal(t, own.ID, feed[2].ID)
+}
@@ -0,0 +1,57 @@
+package db
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// execTx runs fn inside a transaction when the deployment supports them. Standalone servers,
+// used in development, don't, so there fn runs directly and a failure can leave its writes half applied
+func (q *Queries) execTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if !q.supportsTransactions(ctx) {
+		return fn(ctx)
+	}
+
+	session, err := q.db.Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (any, error) {
+		return nil, fn(sessCtx)
+	})
+
+	return err
+}
+
+// supportsTransactions reports if the server is a replica set member or a mongos router,
+// the only deployments where multi-document transactions are available.
+// The answer is cached once the server replied
+func (q *Queries) supportsTransactions(ctx context.Context) bool {
+	q.txMutex.Lock()
+	defer q.txMutex.Unlock()
+
+	if q.txChecked {
+		return q.txSupported
+	}
+
+	var hello struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+
+	cmd := bson.D{primitive.E{Key: "hello", Value: 1}}
+	err := q.db.RunCommand(ctx, cmd).Decode(&hello)
+	if err != nil {
+		return false
+	}
+
+	q.txChecked = true
+	q.txSupported = hello.SetName != "" || hello.Msg == "isdbgrid"
+
+	return q.txSupported
+}
@@ -4,12 +4,20 @@ import (
 	"context"
 	"time"
 
+	"github.com/DMV-Nicolas/robotgram/backend/util"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// listedUserProjection leaves out of the listed users what only matters in their profile or to themselves
+var listedUserProjection = bson.D{
+	primitive.E{Key: "hashed_password", Value: 0},
+	primitive.E{Key: "email", Value: 0},
+	primitive.E{Key: "description", Value: 0},
+}
+
 type CreateUserParams struct {
 	Username       string `json:"username" bson:"username"`
 	HashedPassword string `json:"hashed_password" bson:"hashed_password"`
@@ -19,15 +27,9 @@ type CreateUserParams struct {
 	Gender         string `json:"gender" bson:"gender"`
 }
 
+// CreateUser inserts the user relying on the unique indexes to reject a taken username or email,
+// so two concurrent signups with the same username can't both succeed
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (*mongo.InsertOneResult, error) {
-	if err := q.UsernameTaken(ctx, arg.Username); err != nil {
-		return nil, err
-	}
-
-	if err := q.EmailTaken(ctx, arg.Email); err != nil {
-		return nil, err
-	}
-
 	user := User{
 		ID:             primitive.NewObjectID(),
 		Username:       arg.Username,
@@ -37,18 +39,33 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (*mongo.
 		Avatar:         arg.Avatar,
 		Description:    "",
 		Gender:         arg.Gender,
+		Role:           util.RoleUser,
+		SearchKeys:     util.UserSearchKeys(arg.Username, arg.FullName),
 		CreatedAt:      time.Now(),
 	}
 
 	coll := q.db.Collection("users")
 	result, err := coll.InsertOne(ctx, user)
+	if err != nil {
+		switch duplicatedIndex(err) {
+		case usernameIndex:
+			return nil, ErrUsernameTaken
+		case emailIndex:
+			return nil, ErrEmailTaken
+		}
+		return nil, err
+	}
 
-	return result, err
+	return result, nil
 }
 
+// GetUser finds the user whose key is equal to value, the username and the email are compared ignoring the case
 func (q *Queries) GetUser(ctx context.Context, key string, value any) (User, error) {
 	filter := bson.D{primitive.E{Key: key, Value: value}}
 	opts := options.FindOne()
+	if key == "username" || key == "email" {
+		opts.SetCollation(caseInsensitive)
+	}
 
 	var user User
 	coll := q.db.Collection("users")
@@ -64,18 +81,13 @@ type ListUsersParams struct {
 
 func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error) {
 	filter := bson.D{}
-	projection := bson.D{
-		primitive.E{Key: "hashed_password", Value: 0},
-		primitive.E{Key: "email", Value: 0},
-		primitive.E{Key: "description", Value: 0},
-	}
 
 	var users []User
 	coll := q.db.Collection("users")
 	cursor, err := coll.Find(ctx, filter, options.Find().
 		SetSkip(arg.Offset).
 		SetLimit(arg.Limit).
-		SetProjection(projection))
+		SetProjection(listedUserProjection))
 
 	if err != nil {
 		return nil, err
@@ -94,38 +106,119 @@ func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, e
 	return users, nil
 }
 
+// UpdateUserParams contains the fields to change of the user, the nil fields are left untouched
 type UpdateUserParams struct {
-	ID             primitive.ObjectID `json:"id" bson:"_id"`
-	HashedPassword string             `json:"hashed_password" bson:"hashed_password"`
-	FullName       string             `json:"full_name" bson:"full_name"`
-	Description    string             `json:"description" bson:"description"`
-	Gender         string             `json:"gender" bson:"gender"`
-	Avatar         string             `json:"avatar" bson:"avatar"`
+	ID             primitive.ObjectID  `json:"id" bson:"_id"`
+	HashedPassword *string             `json:"hashed_password" bson:"hashed_password"`
+	FullName       *string             `json:"full_name" bson:"full_name"`
+	Description    *string             `json:"description" bson:"description"`
+	Gender         *string             `json:"gender" bson:"gender"`
+	AvatarID       *primitive.ObjectID `json:"avatar_id" bson:"avatar_id"`
+	Avatar         *string             `json:"avatar" bson:"avatar"`
+	IsPrivate      *bool               `json:"is_private" bson:"is_private"`
+	Role           *string             `json:"role" bson:"role"`
 }
 
+// UpdateUser sets the given fields. Changing the privacy copies it to the posts of the user
 func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (*mongo.UpdateResult, error) {
+	set := bson.M{}
+	if arg.HashedPassword != nil {
+		set["hashed_password"] = *arg.HashedPassword
+	}
+	if arg.FullName != nil {
+		user, err := q.GetUser(ctx, "_id", arg.ID)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				return &mongo.UpdateResult{}, nil
+			}
+			return nil, err
+		}
+
+		set["full_name"] = *arg.FullName
+		set["search_keys"] = util.UserSearchKeys(user.Username, *arg.FullName)
+	}
+	if arg.Description != nil {
+		set["description"] = *arg.Description
+	}
+	if arg.Gender != nil {
+		set["gender"] = *arg.Gender
+	}
+	if arg.AvatarID != nil {
+		set["avatar_id"] = *arg.AvatarID
+	}
+	if arg.Avatar != nil {
+		set["avatar"] = *arg.Avatar
+	}
+	if arg.IsPrivate != nil {
+		set["is_private"] = *arg.IsPrivate
+	}
+	if arg.Role != nil {
+		set["role"] = *arg.Role
+	}
+
+	if len(set) == 0 {
+		return nil, ErrNothingToUpdate
+	}
+
 	filter := bson.M{"_id": arg.ID}
-	update := bson.M{
-		"$set": bson.M{
-			"hashed_password": arg.HashedPassword,
-			"full_name":       arg.FullName,
-			"description":     arg.Description,
-			"gender":          arg.Gender,
-			"avatar":          arg.Avatar,
-		},
+	update := bson.M{"$set": set}
+
+	if arg.IsPrivate == nil {
+		coll := q.db.Collection("users")
+		return coll.UpdateOne(ctx, filter, update)
 	}
 
-	coll := q.db.Collection("users")
-	result, err := coll.UpdateOne(ctx, filter, update)
+	var result *mongo.UpdateResult
+	err := q.execTx(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = q.db.Collection("users").UpdateOne(ctx, filter, update)
+		if err != nil || result.MatchedCount == 0 {
+			return err
+		}
 
-	return result, err
+		filter := bson.M{"user_id": arg.ID}
+		update := bson.M{"$set": bson.M{"private": *arg.IsPrivate}}
+		_, err = q.db.Collection("posts").UpdateMany(ctx, filter, update)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
 }
 
+// DeleteUser removes the user and everything that belongs to it at once, which suits small accounts.
+// Large ones are purged in batches by SchedulePurge. The media files are left in the storage
 func (q *Queries) DeleteUser(ctx context.Context, id primitive.ObjectID) (*mongo.DeleteResult, error) {
-	filter := bson.M{"_id": id}
+	var result *mongo.DeleteResult
+	err := q.execTx(ctx, func(ctx context.Context) error {
+		steps := []string{
+			PurgeStepPosts,
+			PurgeStepComments,
+			PurgeStepLikes,
+			PurgeStepFollows,
+			PurgeStepTimeline,
+			PurgeStepSessions,
+			PurgeStepNotifications,
+			PurgeStepMessages,
+		}
 
-	coll := q.db.Collection("users")
-	result, err := coll.DeleteOne(ctx, filter)
+		for _, step := range steps {
+			_, err := q.purgeUserStep(ctx, PurgeUserStepParams{UserID: id, Step: step})
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err := q.db.Collection("media").DeleteMany(ctx, bson.M{"user_id": id})
+		if err != nil {
+			return err
+		}
+
+		result, err = q.db.Collection("users").DeleteOne(ctx, bson.M{"_id": id})
+		return err
+	})
 
 	return result, err
 }
@@ -40,6 +40,7 @@ func randomUser(t *testing.T) User {
 	require.Equal(t, arg.Avatar, user.Avatar)
 	require.Equal(t, arg.Gender, user.Gender)
 	require.Empty(t, user.Description)
+	require.Equal(t, util.RoleUser, user.Role)
 	require.WithinDuration(t, time.Now(), user.CreatedAt, time.Second)
 
 	return user
@@ -101,14 +102,22 @@ func TestListUsers(t *testing.T) {
 
 func TestUpdateUser(t *testing.T) {
 	user1 := randomUser(t)
+	media := randomMedia(t, user1.ID)
+
+	hashedPassword := util.RandomPassword(20)
+	fullName := util.RandomUsername()
+	description := util.RandomPassword(100)
+	gender := "female"
+	avatar := media.Renditions[0].URL
 
 	arg := UpdateUserParams{
 		ID:             user1.ID,
-		HashedPassword: util.RandomPassword(20),
-		FullName:       util.RandomUsername(),
-		Description:    util.RandomPassword(100),
-		Gender:         "female",
-		Avatar:         "other-avatar.png",
+		HashedPassword: &hashedPassword,
+		FullName:       &fullName,
+		Description:    &description,
+		Gender:         &gender,
+		AvatarID:       &media.ID,
+		Avatar:         &avatar,
 	}
 
 	result, err := testQueries.UpdateUser(testCtx, arg)
@@ -124,14 +133,56 @@ func TestUpdateUser(t *testing.T) {
 	require.Equal(t, user1.ID, user2.ID)
 	require.Equal(t, user1.Username, user2.Username)
 	require.Equal(t, user1.Email, user2.Email)
-	require.NotEqual(t, user1.HashedPassword, user2.HashedPassword)
-	require.NotEqual(t, user1.FullName, user2.FullName)
-	require.NotEqual(t, user1.Avatar, user2.Avatar)
-	require.NotEqual(t, user1.Gender, user2.Gender)
+	require.Equal(t, hashedPassword, user2.HashedPassword)
+	require.Equal(t, fullName, user2.FullName)
+	require.Equal(t, description, user2.Description)
+	require.Equal(t, gender, user2.Gender)
+	require.Equal(t, media.ID, user2.AvatarID)
+	require.Equal(t, avatar, user2.Avatar)
 
 	require.WithinDuration(t, user1.CreatedAt, user2.CreatedAt, time.Second)
 }
 
+func TestUpdateUserPartial(t *testing.T) {
+	user1 := randomUser(t)
+
+	description := util.RandomDescription(10)
+	arg := UpdateUserParams{
+		ID:          user1.ID,
+		Description: &description,
+	}
+
+	result, err := testQueries.UpdateUser(testCtx, arg)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, result.ModifiedCount)
+
+	user2, err := testQueries.GetUser(testCtx, "_id", user1.ID)
+	require.NoError(t, err)
+	require.Equal(t, description, user2.Description)
+	require.Equal(t, user1.HashedPassword, user2.HashedPassword)
+	require.Equal(t, user1.FullName, user2.FullName)
+	require.Equal(t, user1.Gender, user2.Gender)
+	require.Equal(t, user1.Avatar, user2.Avatar)
+
+	result, err = testQueries.UpdateUser(testCtx, UpdateUserParams{ID: user1.ID})
+	require.ErrorIs(t, err, ErrNothingToUpdate)
+	require.Nil(t, result)
+}
+
+func TestUpdateUserRole(t *testing.T) {
+	user1 := randomUser(t)
+
+	role := util.RoleModerator
+	result, err := testQueries.UpdateUser(testCtx, UpdateUserParams{ID: user1.ID, Role: &role})
+	require.NoError(t, err)
+	require.EqualValues(t, 1, result.ModifiedCount)
+
+	user2, err := testQueries.GetUser(testCtx, "_id", user1.ID)
+	require.NoError(t, err)
+	require.Equal(t, util.RoleModerator, user2.Role)
+	require.Equal(t, user1.Username, user2.Username)
+}
+
 func TestDeleteUser(t *testing.T) {
 	user1 := randomUser(t)
 
@@ -145,3 +196,57 @@ func TestDeleteUser(t *testing.T) {
 	require.EqualError(t, mongo.ErrNoDocuments, err.Error())
 	require.Empty(t, user2)
 }
+
+func TestDeleteUserCascade(t *testing.T) {
+	user := randomUser(t)
+	other := randomUser(t)
+
+	post := randomPostByUser(t, user.ID)
+	otherPost := randomPostByUser(t, other.ID)
+	comment := randomComment(t, user.ID, otherPost.ID)
+	receivedComment := randomComment(t, other.ID, post.ID)
+	like := randomLike(t, user.ID, otherPost.ID)
+	receivedLike := randomLike(t, other.ID, post.ID)
+	session := randomUserSession(t, user)
+	media := randomMedia(t, user.ID)
+	randomFollow(t, user.ID, other.ID)
+	randomFollow(t, other.ID, user.ID)
+
+	other, err := testQueries.GetUser(testCtx, "_id", other.ID)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, other.FollowersCount)
+
+	result, err := testQueries.DeleteUser(testCtx, user.ID)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, result.DeletedCount)
+
+	_, err = testQueries.GetPost(testCtx, "_id", post.ID)
+	require.ErrorIs(t, err, mongo.ErrNoDocuments)
+
+	for _, c := range []Comment{comment, receivedComment} {
+		_, err = testQueries.GetComment(testCtx, c.ID)
+		require.ErrorIs(t, err, mongo.ErrNoDocuments)
+	}
+
+	for _, l := range []Like{like, receivedLike} {
+		_, err = testQueries.GetLike(testCtx, l.ID)
+		require.ErrorIs(t, err, mongo.ErrNoDocuments)
+	}
+
+	_, err = testQueries.GetSession(testCtx, session.ID)
+	require.ErrorIs(t, err, mongo.ErrNoDocuments)
+
+	_, err = testQueries.GetMedia(testCtx, media.ID)
+	require.ErrorIs(t, err, mongo.ErrNoDocuments)
+
+	nFollowing, err := testQueries.CountFollowing(testCtx, other.ID)
+	require.NoError(t, err)
+	require.Zero(t, nFollowing)
+
+	other, err = testQueries.GetUser(testCtx, "_id", other.ID)
+	require.NoError(t, err)
+	require.Zero(t, other.FollowersCount)
+
+	_, err = testQueries.GetPost(testCtx, "_id", otherPost.ID)
+	require.NoError(t, err)
+}
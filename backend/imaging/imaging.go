@@ -0,0 +1,123 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+
+	// registers the gif decoder
+	_ "image/gif"
+)
+
+// maxPixels limits the size of the decoded images to avoid decompression bombs
+const maxPixels = 40_000_000
+
+// jpegQuality is the quality used to re-encode the opaque images
+const jpegQuality = 85
+
+var (
+	ErrUnsupportedFormat = errors.New("image format is not supported")
+	ErrTooManyPixels     = errors.New("image dimensions are too large")
+)
+
+// Spec describes a rendition to generate.
+// The image is scaled down to fit MaxSide, a MaxSide of 0 keeps the original size
+type Spec struct {
+	Name    string
+	MaxSide int
+}
+
+// DefaultSpecs are the renditions generated for every uploaded image
+var DefaultSpecs = []Spec{
+	{Name: "thumbnail", MaxSide: 320},
+	{Name: "feed", MaxSide: 1080},
+	{Name: "original", MaxSide: 0},
+}
+
+// Rendition is an encoded version of the processed image
+type Rendition struct {
+	Name        string
+	ContentType string
+	Width       int
+	Height      int
+	Data        []byte
+}
+
+// Result contains the information extracted from an image and its renditions
+type Result struct {
+	Width      int
+	Height     int
+	Blurhash   string
+	Renditions []Rendition
+}
+
+// Process decodes the image, applies its EXIF orientation and re-encodes it in every rendition of specs.
+// The renditions never contain the metadata of the original file, since only the pixels are encoded again
+func Process(data []byte, specs []Spec) (Result, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return Result{}, ErrUnsupportedFormat
+	}
+
+	if config.Width*config.Height > maxPixels {
+		return Result{}, ErrTooManyPixels
+	}
+
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return Result{}, ErrUnsupportedFormat
+	}
+
+	img := toRGBA(decoded)
+	if format == "jpeg" {
+		img = orient(img, jpegOrientation(data))
+	}
+
+	bounds := img.Bounds()
+	result := Result{
+		Width:      bounds.Dx(),
+		Height:     bounds.Dy(),
+		Blurhash:   Blurhash(fit(img, 32), 4, 3),
+		Renditions: make([]Rendition, len(specs)),
+	}
+
+	// the images with transparency are kept as png, the rest is compressed as jpeg
+	opaque := img.Opaque()
+	for i, spec := range specs {
+		resized := fit(img, spec.MaxSide)
+
+		buf := new(bytes.Buffer)
+		contentType := "image/jpeg"
+		if opaque {
+			err = jpeg.Encode(buf, resized, &jpeg.Options{Quality: jpegQuality})
+		} else {
+			contentType = "image/png"
+			err = png.Encode(buf, resized)
+		}
+
+		if err != nil {
+			return Result{}, err
+		}
+
+		result.Renditions[i] = Rendition{
+			Name:        spec.Name,
+			ContentType: contentType,
+			Width:       resized.Bounds().Dx(),
+			Height:      resized.Bounds().Dy(),
+			Data:        buf.Bytes(),
+		}
+	}
+
+	return result, nil
+}
+
+// toRGBA copies the image into an RGBA image whose bounds start at the origin
+func toRGBA(img image.Image) *image.RGBA {
+	bounds := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, bounds.Min, draw.Src)
+	return rgba
+}
@@ -0,0 +1,168 @@
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcess(t *testing.T) {
+	img := solidImage(2000, 1000, color.RGBA{R: 200, G: 100, B: 50, A: 255})
+
+	buf := new(bytes.Buffer)
+	err := jpeg.Encode(buf, img, nil)
+	require.NoError(t, err)
+
+	result, err := Process(buf.Bytes(), DefaultSpecs)
+	require.NoError(t, err)
+
+	require.Equal(t, 2000, result.Width)
+	require.Equal(t, 1000, result.Height)
+	require.Len(t, result.Blurhash, 6+2*11)
+	require.Len(t, result.Renditions, len(DefaultSpecs))
+
+	sizes := map[string][2]int{
+		"thumbnail": {320, 160},
+		"feed":      {1080, 540},
+		"original":  {2000, 1000},
+	}
+
+	for _, rendition := range result.Renditions {
+		require.Equal(t, "image/jpeg", rendition.ContentType)
+		require.Equal(t, sizes[rendition.Name], [2]int{rendition.Width, rendition.Height})
+
+		decoded, err := jpeg.Decode(bytes.NewReader(rendition.Data))
+		require.NoError(t, err)
+		require.Equal(t, rendition.Width, decoded.Bounds().Dx())
+		require.Equal(t, rendition.Height, decoded.Bounds().Dy())
+	}
+}
+
+func TestProcessTransparent(t *testing.T) {
+	img := solidImage(10, 30, color.RGBA{R: 0, G: 0, B: 0, A: 0})
+
+	buf := new(bytes.Buffer)
+	err := png.Encode(buf, img)
+	require.NoError(t, err)
+
+	result, err := Process(buf.Bytes(), []Spec{{Name: "small", MaxSide: 15}})
+	require.NoError(t, err)
+	require.Len(t, result.Renditions, 1)
+
+	rendition := result.Renditions[0]
+	require.Equal(t, "image/png", rendition.ContentType)
+	require.Equal(t, 5, rendition.Width)
+	require.Equal(t, 15, rendition.Height)
+}
+
+func TestProcessStripsEXIF(t *testing.T) {
+	// 4x2 image whose EXIF says it must be rotated 90 degrees clockwise
+	img := solidImage(4, 2, color.RGBA{R: 255, A: 255})
+
+	buf := new(bytes.Buffer)
+	err := jpeg.Encode(buf, img, nil)
+	require.NoError(t, err)
+
+	data := withEXIFOrientation(buf.Bytes(), 6)
+	require.Equal(t, 6, jpegOrientation(data))
+
+	result, err := Process(data, []Spec{{Name: "original"}})
+	require.NoError(t, err)
+
+	require.Equal(t, 2, result.Width)
+	require.Equal(t, 4, result.Height)
+
+	rendition := result.Renditions[0]
+	require.False(t, bytes.Contains(rendition.Data, []byte("Exif")))
+	require.Equal(t, 1, jpegOrientation(rendition.Data))
+}
+
+func TestProcessInvalidImage(t *testing.T) {
+	_, err := Process([]byte("this is not an image"), DefaultSpecs)
+	require.ErrorIs(t, err, ErrUnsupportedFormat)
+}
+
+func TestOrient(t *testing.T) {
+	// 2x1 image with a red pixel followed by a blue one
+	src := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	red := color.RGBA{R: 255, A: 255}
+	blue := color.RGBA{B: 255, A: 255}
+	src.SetRGBA(0, 0, red)
+	src.SetRGBA(1, 0, blue)
+
+	testCases := []struct {
+		orientation int
+		width       int
+		height      int
+		redAt       image.Point
+	}{
+		{orientation: 1, width: 2, height: 1, redAt: image.Pt(0, 0)},
+		{orientation: 2, width: 2, height: 1, redAt: image.Pt(1, 0)},
+		{orientation: 3, width: 2, height: 1, redAt: image.Pt(1, 0)},
+		{orientation: 4, width: 2, height: 1, redAt: image.Pt(0, 0)},
+		{orientation: 5, width: 1, height: 2, redAt: image.Pt(0, 0)},
+		{orientation: 6, width: 1, height: 2, redAt: image.Pt(0, 0)},
+		{orientation: 7, width: 1, height: 2, redAt: image.Pt(0, 1)},
+		{orientation: 8, width: 1, height: 2, redAt: image.Pt(0, 1)},
+	}
+
+	for _, tc := range testCases {
+		dst := orient(src, tc.orientation)
+		require.Equal(t, tc.width, dst.Bounds().Dx(), "orientation %d", tc.orientation)
+		require.Equal(t, tc.height, dst.Bounds().Dy(), "orientation %d", tc.orientation)
+		require.Equal(t, red, dst.RGBAAt(tc.redAt.X, tc.redAt.Y), "orientation %d", tc.orientation)
+	}
+}
+
+func TestBlurhash(t *testing.T) {
+	img := solidImage(8, 8, color.RGBA{R: 255, A: 255})
+
+	// with a single component the hash is the size flag, the maximum value and the average color
+	hash := Blurhash(img, 1, 1)
+	require.Equal(t, "00TI:j", hash)
+
+	// "L" encodes 4x3 components, followed by the maximum value, the average color and 11 AC components
+	hash = Blurhash(img, 4, 3)
+	require.Len(t, hash, 6+2*11)
+	require.Equal(t, "L", hash[:1])
+	require.Equal(t, "TI:j", hash[2:6])
+
+	// the hash only depends on the content
+	require.Equal(t, hash, Blurhash(solidImage(8, 8, color.RGBA{R: 255, A: 255}), 4, 3))
+	require.NotEqual(t, hash, Blurhash(solidImage(8, 8, color.RGBA{B: 255, A: 255}), 4, 3))
+}
+
+func solidImage(width, height int, c color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+// withEXIFOrientation inserts an EXIF segment with the given orientation after the start of image marker
+func withEXIFOrientation(data []byte, orientation byte) []byte {
+	tiff := []byte{
+		'M', 'M', 0x00, 0x2A, 0x00, 0x00, 0x00, 0x08, // header
+		0x00, 0x01, // one entry
+		0x01, 0x12, 0x00, 0x03, 0x00, 0x00, 0x00, 0x01, 0x00, orientation, 0x00, 0x00, // orientation
+		0x00, 0x00, 0x00, 0x00, // no next IFD
+	}
+
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	length := len(segment) + 2
+
+	exif := []byte{0xFF, 0xE1, byte(length >> 8), byte(length)}
+	exif = append(exif, segment...)
+
+	result := append([]byte{}, data[:2]...)
+	result = append(result, exif...)
+	return append(result, data[2:]...)
+}
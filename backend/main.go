@@ -7,6 +7,10 @@ import (
 
 	"github.com/DMV-Nicolas/robotgram/backend/api"
 	db "github.com/DMV-Nicolas/robotgram/backend/db/mongo"
+	"github.com/DMV-Nicolas/robotgram/backend/purge"
+	"github.com/DMV-Nicolas/robotgram/backend/realtime"
+	"github.com/DMV-Nicolas/robotgram/backend/storage"
+	"github.com/DMV-Nicolas/robotgram/backend/timeline"
 	"github.com/DMV-Nicolas/robotgram/backend/util"
 	_ "github.com/golang/mock/mockgen/model"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -27,11 +31,37 @@ func main() {
 		log.Fatal("cannot connect to database:", err)
 	}
 
+	// create the unique indexes the queries rely on
+	database := client.Database(config.DBName)
+	err = db.CreateIndexes(context.Background(), database)
+	if err != nil {
+		log.Fatal("cannot create database indexes:", err)
+	}
+
+	// deliver the events to the connected clients
+	broker, err := realtime.NewBroker(config)
+	if err != nil {
+		log.Fatal("cannot create realtime broker:", err)
+	}
+
 	// create an object queries for the database functions
-	queries := db.NewQuerier(client.Database(config.DBName))
+	queries := db.NewQuerier(database, realtime.NewNotifier(broker))
+
+	// materialize the timelines in background
+	worker := timeline.NewWorker(queries, config)
+	go worker.Start(context.Background())
+
+	// delete the accounts whose grace period is over in background
+	mediaStorage, err := storage.NewBackend(config)
+	if err != nil {
+		log.Fatal("cannot create media storage:", err)
+	}
+
+	purger := purge.NewWorker(queries, mediaStorage, config)
+	go purger.Start(context.Background())
 
 	// create server
-	server, err := api.NewServer(config, queries)
+	server, err := api.NewServer(config, queries, broker)
 	if err != nil {
 		log.Fatal("cannot create server:", err)
 	}
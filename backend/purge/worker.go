@@ -0,0 +1,195 @@
+package purge
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	db "github.com/DMV-Nicolas/robotgram/backend/db/mongo"
+	"github.com/DMV-Nicolas/robotgram/backend/storage"
+	"github.com/DMV-Nicolas/robotgram/backend/util"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// steps are the steps of a purge in the order they are run
+var steps = []string{
+	db.PurgeStepPosts,
+	db.PurgeStepComments,
+	db.PurgeStepLikes,
+	db.PurgeStepFollows,
+	db.PurgeStepTimeline,
+	db.PurgeStepSessions,
+	db.PurgeStepNotifications,
+	db.PurgeStepMessages,
+	db.PurgeStepMedia,
+	db.PurgeStepUser,
+}
+
+// Worker deletes the accounts whose grace period is over, in batches so the big accounts
+// don't hold the database for long
+type Worker struct {
+	queries      db.Querier
+	storage      storage.Backend
+	batchSize    int64
+	pollInterval time.Duration
+}
+
+// NewWorker creates a new purge Worker
+func NewWorker(queries db.Querier, storage storage.Backend, config util.Config) *Worker {
+	return &Worker{
+		queries:      queries,
+		storage:      storage,
+		batchSize:    config.PurgeBatchSize,
+		pollInterval: config.PurgePollInterval,
+	}
+}
+
+// Start processes the purges until the context is canceled
+func (worker *Worker) Start(ctx context.Context) {
+	for {
+		processed, err := worker.ProcessNext(ctx)
+		if err != nil {
+			log.Println("purge worker:", err)
+		}
+
+		if processed && err == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(worker.pollInterval):
+		}
+	}
+}
+
+// ProcessNext claims and processes the next purge.
+// It returns false when there was nothing to purge
+func (worker *Worker) ProcessNext(ctx context.Context) (bool, error) {
+	job, err := worker.queries.ClaimPurgeJob(ctx)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return false, nil
+		}
+		return false, err
+	}
+
+	step, processed, err := worker.process(ctx, job)
+
+	arg := db.UpdatePurgeJobParams{
+		ID:        job.ID,
+		Status:    db.PurgeJobDone,
+		Step:      step,
+		Processed: processed,
+	}
+
+	if err != nil {
+		arg.Status = db.PurgeJobFailed
+		arg.Error = err.Error()
+	}
+
+	_, updateErr := worker.queries.UpdatePurgeJob(ctx, arg)
+	if err != nil {
+		return true, fmt.Errorf("purge %s failed: %w", job.ID.Hex(), err)
+	}
+
+	return true, updateErr
+}
+
+// process runs the steps of the purge from the one where it stopped, saving the progress after
+// each batch. It returns the step reached and the number of documents removed so far
+func (worker *Worker) process(ctx context.Context, job db.PurgeJob) (string, int64, error) {
+	start := 0
+	for i, step := range steps {
+		if step == job.Step {
+			start = i
+		}
+	}
+
+	processed := job.Processed
+	for _, step := range steps[start:] {
+		for {
+			n, err := worker.purge(ctx, job, step)
+			processed += n
+			if err != nil {
+				return step, processed, err
+			}
+
+			if n == 0 {
+				break
+			}
+
+			_, err = worker.queries.UpdatePurgeJob(ctx, db.UpdatePurgeJobParams{
+				ID:        job.ID,
+				Status:    db.PurgeJobProcessing,
+				Step:      step,
+				Processed: processed,
+			})
+			if err != nil {
+				return step, processed, err
+			}
+
+			if n < worker.batchSize {
+				break
+			}
+		}
+	}
+
+	return db.PurgeStepUser, processed, nil
+}
+
+// purge removes the next batch of documents of the step
+func (worker *Worker) purge(ctx context.Context, job db.PurgeJob, step string) (int64, error) {
+	switch step {
+	case db.PurgeStepMedia:
+		return worker.purgeMedia(ctx, job)
+	case db.PurgeStepUser:
+		// by now only the user document is left
+		result, err := worker.queries.DeleteUser(ctx, job.UserID)
+		if err != nil {
+			return 0, err
+		}
+		return result.DeletedCount, nil
+	default:
+		arg := db.PurgeUserStepParams{
+			UserID: job.UserID,
+			Step:   step,
+			Limit:  worker.batchSize,
+		}
+		return worker.queries.PurgeUserStep(ctx, arg)
+	}
+}
+
+// purgeMedia removes the files of the uploaded media before their documents, so a failure
+// never leaves files that nothing points to
+func (worker *Worker) purgeMedia(ctx context.Context, job db.PurgeJob) (int64, error) {
+	arg := db.ListUserMediaParams{
+		UserID: job.UserID,
+		Limit:  worker.batchSize,
+	}
+
+	medias, err := worker.queries.ListUserMedia(ctx, arg)
+	if err != nil {
+		return 0, err
+	}
+
+	var n int64
+	for _, media := range medias {
+		for _, rendition := range media.Renditions {
+			err = worker.storage.Delete(ctx, rendition.Key)
+			if err != nil {
+				return n, err
+			}
+		}
+
+		_, err = worker.queries.DeleteMedia(ctx, media.ID)
+		if err != nil {
+			return n, err
+		}
+		n++
+	}
+
+	return n, nil
+}
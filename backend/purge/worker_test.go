@@ -0,0 +1,170 @@
+package purge
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	mockdb "github.com/DMV-Nicolas/robotgram/backend/db/mock"
+	db "github.com/DMV-Nicolas/robotgram/backend/db/mongo"
+	"github.com/DMV-Nicolas/robotgram/backend/storage"
+	"github.com/DMV-Nicolas/robotgram/backend/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestProcessNext(t *testing.T) {
+	userID := util.RandomID()
+	media := db.Media{
+		ID:     util.RandomID(),
+		UserID: userID,
+		Renditions: []db.Rendition{
+			{Name: "feed", Key: userID.Hex() + "/feed.jpg"},
+			{Name: "thumbnail", Key: userID.Hex() + "/thumbnail.jpg"},
+		},
+	}
+
+	expectStep := func(querier *mockdb.MockQuerier, step string, n int64) {
+		arg := db.PurgeUserStepParams{UserID: userID, Step: step, Limit: 2}
+		querier.EXPECT().PurgeUserStep(gomock.Any(), gomock.Eq(arg)).Times(1).Return(n, nil)
+	}
+
+	expectProgress := func(querier *mockdb.MockQuerier, job db.PurgeJob, status, step string, processed int64) {
+		arg := db.UpdatePurgeJobParams{ID: job.ID, Status: status, Step: step, Processed: processed}
+		querier.EXPECT().UpdatePurgeJob(gomock.Any(), gomock.Eq(arg)).Times(1).Return(&mongo.UpdateResult{}, nil)
+	}
+
+	testCases := []struct {
+		name       string
+		job        db.PurgeJob
+		buildStubs func(querier *mockdb.MockQuerier, job db.PurgeJob)
+		check      func(t *testing.T, processed bool, err error, store storage.Backend)
+	}{
+		{
+			name: "OK",
+			job: db.PurgeJob{
+				ID:     util.RandomID(),
+				UserID: userID,
+				Step:   db.PurgeStepPosts,
+			},
+			buildStubs: func(querier *mockdb.MockQuerier, job db.PurgeJob) {
+				expectStep(querier, db.PurgeStepPosts, 2)
+				expectProgress(querier, job, db.PurgeJobProcessing, db.PurgeStepPosts, 2)
+				expectStep(querier, db.PurgeStepPosts, 1)
+				expectProgress(querier, job, db.PurgeJobProcessing, db.PurgeStepPosts, 3)
+				expectStep(querier, db.PurgeStepComments, 0)
+				expectStep(querier, db.PurgeStepLikes, 0)
+				expectStep(querier, db.PurgeStepFollows, 0)
+				expectStep(querier, db.PurgeStepTimeline, 0)
+				expectStep(querier, db.PurgeStepSessions, 1)
+				expectProgress(querier, job, db.PurgeJobProcessing, db.PurgeStepSessions, 4)
+				expectStep(querier, db.PurgeStepNotifications, 0)
+				expectStep(querier, db.PurgeStepMessages, 0)
+
+				arg := db.ListUserMediaParams{UserID: userID, Limit: 2}
+				querier.EXPECT().ListUserMedia(gomock.Any(), gomock.Eq(arg)).Times(1).Return([]db.Media{media}, nil)
+				querier.EXPECT().DeleteMedia(gomock.Any(), gomock.Eq(media.ID)).Times(1).Return(&mongo.DeleteResult{DeletedCount: 1}, nil)
+				expectProgress(querier, job, db.PurgeJobProcessing, db.PurgeStepMedia, 5)
+
+				querier.EXPECT().DeleteUser(gomock.Any(), gomock.Eq(userID)).Times(1).Return(&mongo.DeleteResult{DeletedCount: 1}, nil)
+				expectProgress(querier, job, db.PurgeJobProcessing, db.PurgeStepUser, 6)
+				expectProgress(querier, job, db.PurgeJobDone, db.PurgeStepUser, 6)
+			},
+			check: func(t *testing.T, processed bool, err error, store storage.Backend) {
+				require.NoError(t, err)
+				require.True(t, processed)
+
+				for _, rendition := range media.Renditions {
+					_, err := store.Get(context.TODO(), rendition.Key)
+					require.ErrorIs(t, err, storage.ErrNotFound)
+				}
+			},
+		},
+		{
+			name: "Resumes",
+			job: db.PurgeJob{
+				ID:        util.RandomID(),
+				UserID:    userID,
+				Step:      db.PurgeStepSessions,
+				Processed: 10,
+			},
+			buildStubs: func(querier *mockdb.MockQuerier, job db.PurgeJob) {
+				querier.EXPECT().PurgeUserStep(gomock.Any(), gomock.Any()).Times(3).Return(int64(0), nil)
+				querier.EXPECT().ListUserMedia(gomock.Any(), gomock.Any()).Times(1).Return(nil, nil)
+				querier.EXPECT().DeleteUser(gomock.Any(), gomock.Eq(userID)).Times(1).Return(&mongo.DeleteResult{DeletedCount: 1}, nil)
+				expectProgress(querier, job, db.PurgeJobProcessing, db.PurgeStepUser, 11)
+				expectProgress(querier, job, db.PurgeJobDone, db.PurgeStepUser, 11)
+			},
+			check: func(t *testing.T, processed bool, err error, store storage.Backend) {
+				require.NoError(t, err)
+				require.True(t, processed)
+			},
+		},
+		{
+			name: "Failed",
+			job: db.PurgeJob{
+				ID:        util.RandomID(),
+				UserID:    userID,
+				Step:      db.PurgeStepLikes,
+				Processed: 7,
+			},
+			buildStubs: func(querier *mockdb.MockQuerier, job db.PurgeJob) {
+				querier.EXPECT().PurgeUserStep(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), mongo.ErrClientDisconnected)
+				querier.EXPECT().DeleteUser(gomock.Any(), gomock.Any()).Times(0)
+
+				failed := db.UpdatePurgeJobParams{
+					ID:        job.ID,
+					Status:    db.PurgeJobFailed,
+					Step:      db.PurgeStepLikes,
+					Processed: 7,
+					Error:     mongo.ErrClientDisconnected.Error(),
+				}
+				querier.EXPECT().UpdatePurgeJob(gomock.Any(), gomock.Eq(failed)).Times(1).Return(&mongo.UpdateResult{}, nil)
+			},
+			check: func(t *testing.T, processed bool, err error, store storage.Backend) {
+				require.ErrorIs(t, err, mongo.ErrClientDisconnected)
+				require.True(t, processed)
+			},
+		},
+		{
+			name: "EmptyQueue",
+			job:  db.PurgeJob{},
+			buildStubs: func(querier *mockdb.MockQuerier, job db.PurgeJob) {
+				querier.EXPECT().UpdatePurgeJob(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, processed bool, err error, store storage.Backend) {
+				require.NoError(t, err)
+				require.False(t, processed)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			queries := mockdb.NewMockQuerier(ctrl)
+			if tc.job.ID == primitive.NilObjectID {
+				queries.EXPECT().ClaimPurgeJob(gomock.Any()).Times(1).Return(db.PurgeJob{}, mongo.ErrNoDocuments)
+			} else {
+				queries.EXPECT().ClaimPurgeJob(gomock.Any()).Times(1).Return(tc.job, nil)
+			}
+			tc.buildStubs(queries, tc.job)
+
+			store := storage.NewLocalBackend(t.TempDir())
+			for _, rendition := range media.Renditions {
+				err := store.Put(context.TODO(), rendition.Key, strings.NewReader("image"), 5, "image/jpeg")
+				require.NoError(t, err)
+			}
+
+			config := util.Config{PurgeBatchSize: 2}
+
+			worker := NewWorker(queries, store, config)
+			processed, err := worker.ProcessNext(context.TODO())
+			tc.check(t, processed, err, store)
+		})
+	}
+}
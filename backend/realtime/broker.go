@@ -0,0 +1,87 @@
+package realtime
+
+import (
+	"fmt"
+
+	db "github.com/DMV-Nicolas/robotgram/backend/db/mongo"
+	"github.com/DMV-Nicolas/robotgram/backend/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// The types of the events
+const (
+	EventNotification = "notification"
+	EventLikeCount    = "like_count"
+	EventComment      = "comment"
+	EventMessage      = "message"
+)
+
+// Event is a message pushed to the clients subscribed to its topic
+type Event struct {
+	Topic string `json:"topic"`
+	Type  string `json:"type"`
+	Data  any    `json:"data"`
+}
+
+// UserTopic is the topic of the events only meant for the user, like its notifications
+func UserTopic(userID primitive.ObjectID) string {
+	return "user:" + userID.Hex()
+}
+
+// TargetTopic is the topic of the events about a post or a comment, like its new likes and replies
+func TargetTopic(targetID primitive.ObjectID) string {
+	return "target:" + targetID.Hex()
+}
+
+// Broker delivers the published events to the subscriptions of their topic
+type Broker interface {
+	// Publish sends the event to the subscriptions of its topic without waiting for them
+	Publish(event Event)
+
+	// Subscribe opens a new subscription without topics
+	Subscribe() Subscription
+}
+
+// Subscription receives the events of the topics it is subscribed to
+type Subscription interface {
+	// Events returns the channel of the received events, it is closed when the subscription ends
+	Events() <-chan Event
+
+	// Add subscribes to the topics
+	Add(topics ...string)
+
+	// Remove unsubscribes from the topics
+	Remove(topics ...string)
+
+	// Close ends the subscription, closing it again does nothing
+	Close()
+}
+
+// NewBroker creates the Broker selected in the config
+func NewBroker(config util.Config) (Broker, error) {
+	switch config.RealtimeBroker {
+	case "", "memory":
+		return NewHub(config.RealtimeBufferSize), nil
+	default:
+		return nil, fmt.Errorf("unknown realtime broker: %s", config.RealtimeBroker)
+	}
+}
+
+// Notifier publishes the notifications to the topic of their user as they are created or updated
+type Notifier struct {
+	broker Broker
+}
+
+// NewNotifier creates a new Notifier that publishes through the broker
+func NewNotifier(broker Broker) *Notifier {
+	return &Notifier{broker: broker}
+}
+
+// Notify publishes the notification
+func (notifier *Notifier) Notify(notification db.Notification) {
+	notifier.broker.Publish(Event{
+		Topic: UserTopic(notification.UserID),
+		Type:  EventNotification,
+		Data:  notification,
+	})
+}
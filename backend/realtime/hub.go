@@ -0,0 +1,140 @@
+package realtime
+
+import (
+	"sync"
+)
+
+// defaultBufferSize is the number of events a subscription can fall behind when the size isn't configured
+const defaultBufferSize = 64
+
+// Hub is a Broker that delivers the events inside the process, so it only reaches the clients connected
+// to this instance. Each subscription buffers up to bufferSize events, and the subscriptions that fall
+// further behind are closed instead of slowing down the publishers, their clients must reconnect and
+// fetch what they missed
+type Hub struct {
+	mu         sync.RWMutex
+	topics     map[string]map[*hubSubscription]struct{}
+	bufferSize int
+}
+
+// NewHub creates a new Hub
+func NewHub(bufferSize int) *Hub {
+	if bufferSize <= 0 {
+		bufferSize = defaultBufferSize
+	}
+
+	return &Hub{
+		topics:     make(map[string]map[*hubSubscription]struct{}),
+		bufferSize: bufferSize,
+	}
+}
+
+// Publish sends the event to the subscriptions of its topic
+func (hub *Hub) Publish(event Event) {
+	var lagging []*hubSubscription
+
+	hub.mu.RLock()
+	for sub := range hub.topics[event.Topic] {
+		select {
+		case sub.events <- event:
+		default:
+			lagging = append(lagging, sub)
+		}
+	}
+	hub.mu.RUnlock()
+
+	for _, sub := range lagging {
+		sub.Close()
+	}
+}
+
+// Subscribe opens a new subscription without topics
+func (hub *Hub) Subscribe() Subscription {
+	return &hubSubscription{
+		hub:    hub,
+		events: make(chan Event, hub.bufferSize),
+		topics: make(map[string]struct{}),
+	}
+}
+
+// Topics returns how many subscriptions each topic has
+func (hub *Hub) Topics() map[string]int {
+	hub.mu.RLock()
+	defer hub.mu.RUnlock()
+
+	topics := make(map[string]int, len(hub.topics))
+	for topic, subs := range hub.topics {
+		topics[topic] = len(subs)
+	}
+
+	return topics
+}
+
+// hubSubscription is guarded by the mutex of its hub, which also keeps the events from being sent
+// once the channel is closed
+type hubSubscription struct {
+	hub    *Hub
+	events chan Event
+	topics map[string]struct{}
+	closed bool
+}
+
+func (sub *hubSubscription) Events() <-chan Event {
+	return sub.events
+}
+
+func (sub *hubSubscription) Add(topics ...string) {
+	sub.hub.mu.Lock()
+	defer sub.hub.mu.Unlock()
+
+	if sub.closed {
+		return
+	}
+
+	for _, topic := range topics {
+		subs, ok := sub.hub.topics[topic]
+		if !ok {
+			subs = make(map[*hubSubscription]struct{})
+			sub.hub.topics[topic] = subs
+		}
+
+		subs[sub] = struct{}{}
+		sub.topics[topic] = struct{}{}
+	}
+}
+
+func (sub *hubSubscription) Remove(topics ...string) {
+	sub.hub.mu.Lock()
+	defer sub.hub.mu.Unlock()
+
+	for _, topic := range topics {
+		sub.remove(topic)
+	}
+}
+
+func (sub *hubSubscription) Close() {
+	sub.hub.mu.Lock()
+	defer sub.hub.mu.Unlock()
+
+	if sub.closed {
+		return
+	}
+
+	for topic := range sub.topics {
+		sub.remove(topic)
+	}
+
+	sub.closed = true
+	close(sub.events)
+}
+
+// remove must be called holding the lock of the hub
+func (sub *hubSubscription) remove(topic string) {
+	subs := sub.hub.topics[topic]
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(sub.hub.topics, topic)
+	}
+
+	delete(sub.topics, topic)
+}
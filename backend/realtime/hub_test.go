@@ -0,0 +1,156 @@
+package realtime
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	db "github.com/DMV-Nicolas/robotgram/backend/db/mongo"
+	"github.com/DMV-Nicolas/robotgram/backend/util"
+	"github.com/stretchr/testify/require"
+)
+
+func requireEvent(t *testing.T, sub Subscription, event Event) {
+	select {
+	case got, ok := <-sub.Events():
+		require.True(t, ok)
+		require.Equal(t, event, got)
+	case <-time.After(time.Second):
+		t.Fatal("the event wasn't received")
+	}
+}
+
+func requireNoEvent(t *testing.T, sub Subscription) {
+	select {
+	case got := <-sub.Events():
+		t.Fatalf("unexpected event: %v", got)
+	default:
+	}
+}
+
+func TestHubPublish(t *testing.T) {
+	hub := NewHub(10)
+	topic := TargetTopic(util.RandomID())
+	event := Event{Topic: topic, Type: EventLikeCount, Data: 5}
+
+	sub1 := hub.Subscribe()
+	sub1.Add(topic)
+	sub2 := hub.Subscribe()
+	sub2.Add(topic, TargetTopic(util.RandomID()))
+	other := hub.Subscribe()
+	other.Add(TargetTopic(util.RandomID()))
+
+	hub.Publish(event)
+
+	requireEvent(t, sub1, event)
+	requireEvent(t, sub2, event)
+	requireNoEvent(t, other)
+
+	sub2.Remove(topic)
+	hub.Publish(event)
+
+	requireEvent(t, sub1, event)
+	requireNoEvent(t, sub2)
+}
+
+func TestHubClose(t *testing.T) {
+	hub := NewHub(10)
+	topic := TargetTopic(util.RandomID())
+
+	sub := hub.Subscribe()
+	sub.Add(topic)
+	require.Equal(t, map[string]int{topic: 1}, hub.Topics())
+
+	sub.Close()
+	sub.Close()
+
+	_, ok := <-sub.Events()
+	require.False(t, ok)
+	require.Empty(t, hub.Topics())
+
+	// a closed subscription doesn't come back
+	sub.Add(topic)
+	hub.Publish(Event{Topic: topic})
+	require.Empty(t, hub.Topics())
+}
+
+func TestHubDropsLaggingSubscriptions(t *testing.T) {
+	hub := NewHub(2)
+	topic := TargetTopic(util.RandomID())
+
+	slow := hub.Subscribe()
+	slow.Add(topic)
+	fast := hub.Subscribe()
+	fast.Add(topic)
+
+	for i := 0; i < 3; i++ {
+		event := Event{Topic: topic, Type: EventComment, Data: i}
+		hub.Publish(event)
+		requireEvent(t, fast, event)
+	}
+
+	// the buffered events are still delivered before the channel is closed
+	for i := 0; i < 2; i++ {
+		requireEvent(t, slow, Event{Topic: topic, Type: EventComment, Data: i})
+	}
+
+	_, ok := <-slow.Events()
+	require.False(t, ok)
+	require.Equal(t, map[string]int{topic: 1}, hub.Topics())
+}
+
+func TestHubConcurrent(t *testing.T) {
+	hub := NewHub(1000)
+	topic := TargetTopic(util.RandomID())
+
+	sub := hub.Subscribe()
+	sub.Add(topic)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 50; j++ {
+				hub.Publish(Event{Topic: topic})
+
+				other := hub.Subscribe()
+				other.Add(topic)
+				other.Close()
+			}
+		}()
+	}
+	wg.Wait()
+
+	require.Len(t, sub.Events(), 500)
+}
+
+func TestNotifier(t *testing.T) {
+	hub := NewHub(10)
+	notification := db.Notification{
+		ID:     util.RandomID(),
+		UserID: util.RandomID(),
+		Type:   db.NotificationFollow,
+	}
+
+	sub := hub.Subscribe()
+	sub.Add(UserTopic(notification.UserID))
+
+	NewNotifier(hub).Notify(notification)
+
+	requireEvent(t, sub, Event{
+		Topic: UserTopic(notification.UserID),
+		Type:  EventNotification,
+		Data:  notification,
+	})
+}
+
+func TestNewBroker(t *testing.T) {
+	broker, err := NewBroker(util.Config{})
+	require.NoError(t, err)
+	require.IsType(t, &Hub{}, broker)
+
+	_, err = NewBroker(util.Config{RealtimeBroker: "carrier-pigeon"})
+	require.Error(t, err)
+}
@@ -0,0 +1,472 @@
+// Package seed generates a plausible dataset to fill a development database: users following each other
+// with a power-law distribution, and their posts, comments, threaded replies and likes.
+// The dataset only depends on its options, the ids and timestamps included, so the same seed gives
+// the same dataset and it can be shared to reproduce a bug or to run a load test
+package seed
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	db "github.com/DMV-Nicolas/robotgram/backend/db/mongo"
+	"github.com/DMV-Nicolas/robotgram/backend/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// popularityExponent shapes how the follows concentrate on a few accounts, the k-th most popular user
+// is picked as often as the most popular one divided by k to this power
+const popularityExponent = 1.2
+
+// The mean delays of the reactions to a post or a comment, most come soon after it and a few much later
+const (
+	commentDelay = 6 * time.Hour
+	replyDelay   = 2 * time.Hour
+	likeDelay    = 12 * time.Hour
+)
+
+var (
+	ErrNoUsers        = errors.New("the dataset needs at least one user")
+	ErrInvalidOptions = errors.New("the counts and the span can't be negative and the reply rate must be lower than 1")
+)
+
+// Options configures the size and the shape of the dataset. The counts per user, post and comment are means,
+// the generated ones vary around them and grow with the popularity of the author
+type Options struct {
+	Seed            int64
+	Users           int
+	FollowsPerUser  int
+	PostsPerUser    int
+	CommentsPerPost int
+	// ReplyRate is the probability of a comment getting one more reply
+	ReplyRate    float64
+	LikesPerPost int
+	// the dataset spans from Until minus Span to Until
+	Until time.Time
+	Span  time.Duration
+	// HashedPassword is the password of every user, so any of them can log in
+	HashedPassword string
+}
+
+// Dataset holds the generated documents with their denormalized counters already computed
+type Dataset struct {
+	Users    []db.User
+	Follows  []db.Follow
+	Posts    []db.Post
+	Comments []db.Comment
+	Likes    []db.Like
+	Hashtags []db.Hashtag
+}
+
+type generator struct {
+	opts    Options
+	rand    *rand.Rand
+	start   time.Time
+	dataset Dataset
+
+	// the users are referred to by their index in the dataset
+	usernames   map[string]int
+	userIndexes map[primitive.ObjectID]int
+	popularity  []float64
+	followers   [][]int
+	following   [][]int
+	postAuthors []int
+}
+
+// Generate generates the dataset described by the options
+func Generate(opts Options) (Dataset, error) {
+	if opts.Users < 1 {
+		return Dataset{}, ErrNoUsers
+	}
+	if opts.FollowsPerUser < 0 || opts.PostsPerUser < 0 || opts.CommentsPerPost < 0 || opts.LikesPerPost < 0 ||
+		opts.ReplyRate < 0 || opts.ReplyRate >= 1 || opts.Span < 0 {
+		return Dataset{}, ErrInvalidOptions
+	}
+
+	g := &generator{
+		opts:        opts,
+		rand:        rand.New(rand.NewSource(opts.Seed)),
+		start:       opts.Until.Add(-opts.Span),
+		usernames:   make(map[string]int),
+		userIndexes: make(map[primitive.ObjectID]int),
+	}
+
+	g.generateUsers()
+	g.generateFollows()
+	g.generatePosts()
+	g.generateComments()
+	g.generateLikes()
+	g.countHashtags()
+
+	return g.dataset, nil
+}
+
+func (g *generator) generateUsers() {
+	// the users join during the first part of the span so the last ones still have time to post
+	joinedAt := make([]time.Time, g.opts.Users)
+	joinEnd := g.start.Add(g.opts.Span * 4 / 5)
+	for i := range joinedAt {
+		joinedAt[i] = g.between(g.start, joinEnd)
+	}
+	sort.Slice(joinedAt, func(i, j int) bool { return joinedAt[i].Before(joinedAt[j]) })
+
+	g.dataset.Users = make([]db.User, g.opts.Users)
+	for i := range g.dataset.Users {
+		first := g.rand.Intn(len(firstNames))
+		last := lastNames[g.rand.Intn(len(lastNames))]
+		fullName := firstNames[first] + " " + last
+		username := g.username(firstNames[first], last, i)
+
+		// the first half of the first names are female
+		gender := "male"
+		if first < len(firstNames)/2 {
+			gender = "female"
+		}
+
+		g.dataset.Users[i] = db.User{
+			ID:             g.objectID(joinedAt[i]),
+			Username:       username,
+			HashedPassword: g.opts.HashedPassword,
+			FullName:       fullName,
+			Email:          username + "@example.com",
+			Gender:         gender,
+			Role:           util.RoleUser,
+			SearchKeys:     util.UserSearchKeys(username, fullName),
+			CreatedAt:      joinedAt[i],
+		}
+		g.userIndexes[g.dataset.Users[i].ID] = i
+	}
+
+	// a shuffled zipf distribution, so the popular accounts aren't just the oldest ones
+	g.popularity = make([]float64, g.opts.Users)
+	var total float64
+	for i, rank := range g.rand.Perm(g.opts.Users) {
+		total += 1 / math.Pow(float64(rank+1), popularityExponent)
+		g.popularity[i] = total
+	}
+}
+
+// username makes a unique username out of the name of the user, numbering the repeated ones
+func (g *generator) username(first, last string, user int) string {
+	var base string
+	switch g.rand.Intn(4) {
+	case 0:
+		base = first + last
+	case 1:
+		base = first + last[:1]
+	case 2:
+		base = first + usernameSuffixes[g.rand.Intn(len(usernameSuffixes))]
+	default:
+		base = fmt.Sprintf("%s%s%d", first, last, g.rand.Intn(100))
+	}
+	base = strings.ToLower(base)
+
+	username := base
+	for n := 2; ; n++ {
+		if _, taken := g.usernames[username]; !taken {
+			break
+		}
+		username = fmt.Sprintf("%s%d", base, n)
+	}
+
+	g.usernames[username] = user
+	return username
+}
+
+func (g *generator) generateFollows() {
+	n := len(g.dataset.Users)
+	g.followers = make([][]int, n)
+	g.following = make([][]int, n)
+
+	for follower := range g.dataset.Users {
+		want := min(g.count(float64(g.opts.FollowsPerUser)), n-1)
+
+		followed := make(map[int]bool)
+		for attempts := 0; len(followed) < want && attempts < want*10; attempts++ {
+			following := g.pickUser()
+			if following == follower || followed[following] {
+				continue
+			}
+			followed[following] = true
+
+			since := g.dataset.Users[follower].CreatedAt
+			if joined := g.dataset.Users[following].CreatedAt; joined.After(since) {
+				since = joined
+			}
+			createdAt := g.between(since, g.opts.Until)
+
+			g.dataset.Follows = append(g.dataset.Follows, db.Follow{
+				ID:          g.objectID(createdAt),
+				FollowerID:  g.dataset.Users[follower].ID,
+				FollowingID: g.dataset.Users[following].ID,
+				CreatedAt:   createdAt,
+			})
+			g.dataset.Users[following].FollowersCount++
+			g.followers[following] = append(g.followers[following], follower)
+			g.following[follower] = append(g.following[follower], following)
+		}
+	}
+}
+
+func (g *generator) generatePosts() {
+	for author, user := range g.dataset.Users {
+		for i := g.count(float64(g.opts.PostsPerUser)); i > 0; i-- {
+			createdAt := g.between(user.CreatedAt, g.opts.Until)
+			id := g.objectID(createdAt)
+			description := g.postDescription(author)
+
+			g.dataset.Posts = append(g.dataset.Posts, db.Post{
+				ID:          id,
+				UserID:      user.ID,
+				Images:      g.images(id),
+				Description: description,
+				Hashtags:    util.ExtractHashtags(description),
+				Mentions:    g.mentions(description),
+				CreatedAt:   createdAt,
+			})
+			g.postAuthors = append(g.postAuthors, author)
+		}
+	}
+}
+
+func (g *generator) postDescription(author int) string {
+	parts := []string{postOpenings[g.rand.Intn(len(postOpenings))]}
+
+	if ending := postEndings[g.rand.Intn(len(postEndings))]; ending != "" {
+		parts = append(parts, ending)
+	}
+
+	if following := g.following[author]; len(following) > 0 && g.rand.Float64() < 0.2 {
+		friend := following[g.rand.Intn(len(following))]
+		parts = append(parts, "With @"+g.dataset.Users[friend].Username)
+	}
+
+	for _, i := range g.rand.Perm(len(hashtags))[:g.rand.Intn(4)] {
+		parts = append(parts, "#"+hashtags[i])
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// images links the post to placeholder images, seeded with its id so they are stable too
+func (g *generator) images(postID primitive.ObjectID) []db.Image {
+	images := make([]db.Image, 1+g.rand.Intn(3))
+	for i := range images {
+		url := fmt.Sprintf("https://picsum.photos/seed/%s-%d", postID.Hex(), i)
+		images[i] = db.Image{
+			URL:          url + "/1080/1080",
+			ThumbnailURL: url + "/320/320",
+			OriginalURL:  url + "/1080/1080",
+			Width:        1080,
+			Height:       1080,
+		}
+	}
+
+	return images
+}
+
+// mentions resolves the mentions of the text among the generated users, like the queries do on creation
+func (g *generator) mentions(text string) []db.Mention {
+	var mentions []db.Mention
+	for _, token := range util.ExtractMentions(text) {
+		user, found := g.usernames[token.Username]
+		if !found {
+			continue
+		}
+
+		mentions = append(mentions, db.Mention{
+			UserID: g.dataset.Users[user].ID,
+			Start:  token.Start,
+			End:    token.End,
+		})
+	}
+
+	return mentions
+}
+
+func (g *generator) generateComments() {
+	for p := range g.dataset.Posts {
+		author := g.postAuthors[p]
+		for i := g.count(float64(g.opts.CommentsPerPost) * g.boost(author)); i > 0; i-- {
+			post := &g.dataset.Posts[p]
+			createdAt := g.after(post.CreatedAt, commentDelay)
+			commenter := g.audience(author)
+
+			parent := g.addComment(db.Comment{
+				UserID:     g.dataset.Users[commenter].ID,
+				TargetID:   post.ID,
+				RootPostID: post.ID,
+				Content:    comments[g.rand.Intn(len(comments))],
+				CreatedAt:  createdAt,
+			})
+			post.CommentCount++
+
+			// the thread goes on with replies to the comment or to the latest reply
+			for g.rand.Float64() < g.opts.ReplyRate {
+				parentComment := g.dataset.Comments[parent]
+
+				replier := author
+				if g.rand.Float64() < 0.5 {
+					replier = g.audience(author)
+				}
+
+				content := replies[g.rand.Intn(len(replies))]
+				if replierID := g.dataset.Users[replier].ID; replierID != parentComment.UserID {
+					parentAuthor := g.userIndexes[parentComment.UserID]
+					content = "@" + g.dataset.Users[parentAuthor].Username + " " + content
+				}
+
+				reply := g.addComment(db.Comment{
+					UserID:     g.dataset.Users[replier].ID,
+					TargetID:   parentComment.ID,
+					ParentID:   parentComment.ID,
+					RootPostID: post.ID,
+					Content:    content,
+					CreatedAt:  g.after(parentComment.CreatedAt, replyDelay),
+				})
+				g.dataset.Comments[parent].CommentCount++
+
+				if g.rand.Float64() < 0.5 {
+					parent = reply
+				}
+			}
+		}
+	}
+}
+
+// addComment completes the comment and appends it, returning its index
+func (g *generator) addComment(comment db.Comment) int {
+	comment.ID = g.objectID(comment.CreatedAt)
+	comment.Mentions = g.mentions(comment.Content)
+
+	g.dataset.Comments = append(g.dataset.Comments, comment)
+	return len(g.dataset.Comments) - 1
+}
+
+func (g *generator) generateLikes() {
+	for p := range g.dataset.Posts {
+		author := g.postAuthors[p]
+		post := &g.dataset.Posts[p]
+		post.LikeCount = g.like(post.ID, post.CreatedAt, author, float64(g.opts.LikesPerPost)*g.boost(author))
+	}
+
+	for c := range g.dataset.Comments {
+		comment := &g.dataset.Comments[c]
+		author := g.userIndexes[comment.UserID]
+		comment.LikeCount = g.like(comment.ID, comment.CreatedAt, author, float64(g.opts.LikesPerPost)/10)
+	}
+}
+
+// like adds likes to the target from different users of the audience of its author, returning how many
+func (g *generator) like(targetID primitive.ObjectID, createdAt time.Time, author int, mean float64) int64 {
+	want := min(g.count(mean), len(g.dataset.Users))
+
+	liked := make(map[int]bool)
+	for attempts := 0; len(liked) < want && attempts < want*10; attempts++ {
+		liker := g.audience(author)
+		if liked[liker] {
+			continue
+		}
+		liked[liker] = true
+
+		likedAt := g.after(createdAt, likeDelay)
+		g.dataset.Likes = append(g.dataset.Likes, db.Like{
+			ID:        g.objectID(likedAt),
+			UserID:    g.dataset.Users[liker].ID,
+			TargetID:  targetID,
+			CreatedAt: likedAt,
+		})
+	}
+
+	return int64(len(liked))
+}
+
+func (g *generator) countHashtags() {
+	counts := make(map[string]*db.Hashtag)
+	for _, post := range g.dataset.Posts {
+		for _, name := range post.Hashtags {
+			hashtag, found := counts[name]
+			if !found {
+				hashtag = &db.Hashtag{Name: name}
+				counts[name] = hashtag
+			}
+
+			hashtag.PostCount++
+			if post.CreatedAt.After(hashtag.UpdatedAt) {
+				hashtag.UpdatedAt = post.CreatedAt
+			}
+		}
+	}
+
+	for _, hashtag := range counts {
+		g.dataset.Hashtags = append(g.dataset.Hashtags, *hashtag)
+	}
+	sort.Slice(g.dataset.Hashtags, func(i, j int) bool {
+		return g.dataset.Hashtags[i].Name < g.dataset.Hashtags[j].Name
+	})
+}
+
+// pickUser picks a user with a probability proportional to its popularity
+func (g *generator) pickUser() int {
+	total := g.popularity[len(g.popularity)-1]
+	i := sort.SearchFloat64s(g.popularity, g.rand.Float64()*total)
+
+	return min(i, len(g.popularity)-1)
+}
+
+// audience picks who reacts to the content of the author, most of the times one of its followers
+func (g *generator) audience(author int) int {
+	if followers := g.followers[author]; len(followers) > 0 && g.rand.Float64() < 0.7 {
+		return followers[g.rand.Intn(len(followers))]
+	}
+
+	return g.pickUser()
+}
+
+// boost is how many times more reactions than the mean the content of the author gets, for its followers
+func (g *generator) boost(author int) float64 {
+	followers := float64(len(g.followers[author]))
+	return math.Sqrt((followers + 1) / float64(g.opts.FollowsPerUser+1))
+}
+
+// count returns a random count around the mean, an exponential distribution so most are small and a few large
+func (g *generator) count(mean float64) int {
+	if mean <= 0 {
+		return 0
+	}
+
+	return int(g.rand.ExpFloat64()*mean + 0.5)
+}
+
+// between returns a random time from a to b, rounded to the milliseconds stored by mongo
+func (g *generator) between(a, b time.Time) time.Time {
+	if !b.After(a) {
+		return a
+	}
+
+	return a.Add(time.Duration(g.rand.Int63n(int64(b.Sub(a))))).Truncate(time.Millisecond)
+}
+
+// after returns a random time after t, soon after it most of the times, and never after the end of the dataset
+func (g *generator) after(t time.Time, mean time.Duration) time.Time {
+	delayed := t.Add(time.Duration(g.rand.ExpFloat64() * float64(mean))).Truncate(time.Millisecond)
+	if delayed.After(g.opts.Until) {
+		return g.between(t, g.opts.Until)
+	}
+
+	return delayed
+}
+
+// objectID generates an id with the creation time of the document, as mongo does, and the rest from the seed
+func (g *generator) objectID(t time.Time) primitive.ObjectID {
+	var id primitive.ObjectID
+	binary.BigEndian.PutUint32(id[0:4], uint32(t.Unix()))
+	binary.BigEndian.PutUint64(id[4:12], g.rand.Uint64())
+
+	return id
+}
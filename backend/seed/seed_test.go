@@ -0,0 +1,169 @@
+package seed
+
+import (
+	"testing"
+	"time"
+
+	db "github.com/DMV-Nicolas/robotgram/backend/db/mongo"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func testOptions(seed int64) Options {
+	return Options{
+		Seed:            seed,
+		Users:           200,
+		FollowsPerUser:  15,
+		PostsPerUser:    3,
+		CommentsPerPost: 2,
+		ReplyRate:       0.4,
+		LikesPerPost:    8,
+		Until:           time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+		Span:            365 * 24 * time.Hour,
+		HashedPassword:  "hashed",
+	}
+}
+
+func TestGenerateDeterministic(t *testing.T) {
+	dataset1, err := Generate(testOptions(42))
+	require.NoError(t, err)
+
+	dataset2, err := Generate(testOptions(42))
+	require.NoError(t, err)
+	require.Equal(t, dataset1, dataset2)
+
+	dataset3, err := Generate(testOptions(43))
+	require.NoError(t, err)
+	require.NotEqual(t, dataset1.Users[0].ID, dataset3.Users[0].ID)
+}
+
+func TestGenerateConsistent(t *testing.T) {
+	opts := testOptions(7)
+	dataset, err := Generate(opts)
+	require.NoError(t, err)
+	require.Len(t, dataset.Users, opts.Users)
+	require.NotEmpty(t, dataset.Follows)
+	require.NotEmpty(t, dataset.Posts)
+	require.NotEmpty(t, dataset.Comments)
+	require.NotEmpty(t, dataset.Likes)
+	require.NotEmpty(t, dataset.Hashtags)
+
+	start := opts.Until.Add(-opts.Span)
+	inSpan := func(createdAt time.Time, id primitive.ObjectID) {
+		require.False(t, createdAt.Before(start))
+		require.False(t, createdAt.After(opts.Until))
+		require.Equal(t, createdAt.Unix(), id.Timestamp().Unix())
+	}
+
+	users := make(map[primitive.ObjectID]db.User)
+	usernames := make(map[string]bool)
+	for _, user := range dataset.Users {
+		require.False(t, usernames[user.Username])
+		require.Regexp(t, "^[a-z0-9]+$", user.Username)
+		inSpan(user.CreatedAt, user.ID)
+
+		users[user.ID] = user
+		usernames[user.Username] = true
+	}
+
+	followers := make(map[primitive.ObjectID]int64)
+	follows := make(map[[2]primitive.ObjectID]bool)
+	for _, follow := range dataset.Follows {
+		require.NotEqual(t, follow.FollowerID, follow.FollowingID)
+		require.False(t, follows[[2]primitive.ObjectID{follow.FollowerID, follow.FollowingID}])
+		require.False(t, follow.CreatedAt.Before(users[follow.FollowerID].CreatedAt))
+		require.False(t, follow.CreatedAt.Before(users[follow.FollowingID].CreatedAt))
+		inSpan(follow.CreatedAt, follow.ID)
+
+		follows[[2]primitive.ObjectID{follow.FollowerID, follow.FollowingID}] = true
+		followers[follow.FollowingID]++
+	}
+
+	var maxFollowers int64
+	for _, user := range dataset.Users {
+		require.Equal(t, followers[user.ID], user.FollowersCount)
+		maxFollowers = max(maxFollowers, user.FollowersCount)
+	}
+	// the follows concentrate on a few popular accounts
+	require.Greater(t, maxFollowers, int64(5*opts.FollowsPerUser))
+
+	createdAt := make(map[primitive.ObjectID]time.Time)
+	comments := make(map[primitive.ObjectID]int64)
+	likes := make(map[primitive.ObjectID]int64)
+	for _, post := range dataset.Posts {
+		require.False(t, post.CreatedAt.Before(users[post.UserID].CreatedAt))
+		inSpan(post.CreatedAt, post.ID)
+		createdAt[post.ID] = post.CreatedAt
+	}
+
+	var replies int
+	for _, comment := range dataset.Comments {
+		require.False(t, createdAt[comment.TargetID].IsZero())
+		require.False(t, comment.CreatedAt.Before(createdAt[comment.TargetID]))
+		require.False(t, users[comment.UserID].ID.IsZero())
+		inSpan(comment.CreatedAt, comment.ID)
+
+		if comment.ParentID.IsZero() {
+			require.Equal(t, comment.RootPostID, comment.TargetID)
+		} else {
+			require.Equal(t, comment.ParentID, comment.TargetID)
+			replies++
+		}
+
+		createdAt[comment.ID] = comment.CreatedAt
+		comments[comment.TargetID]++
+	}
+	require.NotZero(t, replies)
+
+	liked := make(map[[2]primitive.ObjectID]bool)
+	for _, like := range dataset.Likes {
+		require.False(t, createdAt[like.TargetID].IsZero())
+		require.False(t, like.CreatedAt.Before(createdAt[like.TargetID]))
+		require.False(t, liked[[2]primitive.ObjectID{like.UserID, like.TargetID}])
+		inSpan(like.CreatedAt, like.ID)
+
+		liked[[2]primitive.ObjectID{like.UserID, like.TargetID}] = true
+		likes[like.TargetID]++
+	}
+
+	for _, post := range dataset.Posts {
+		require.Equal(t, comments[post.ID], post.CommentCount)
+		require.Equal(t, likes[post.ID], post.LikeCount)
+	}
+	for _, comment := range dataset.Comments {
+		require.Equal(t, comments[comment.ID], comment.CommentCount)
+		require.Equal(t, likes[comment.ID], comment.LikeCount)
+		for _, mention := range comment.Mentions {
+			require.False(t, users[mention.UserID].ID.IsZero())
+		}
+	}
+
+	var tagged int64
+	for _, post := range dataset.Posts {
+		tagged += int64(len(post.Hashtags))
+	}
+	var counted int64
+	for _, hashtag := range dataset.Hashtags {
+		counted += hashtag.PostCount
+	}
+	require.Equal(t, tagged, counted)
+}
+
+func TestGenerateInvalidOptions(t *testing.T) {
+	opts := testOptions(1)
+	opts.Users = 0
+	_, err := Generate(opts)
+	require.ErrorIs(t, err, ErrNoUsers)
+
+	opts = testOptions(1)
+	opts.ReplyRate = 1
+	_, err = Generate(opts)
+	require.ErrorIs(t, err, ErrInvalidOptions)
+
+	opts = testOptions(1)
+	opts.Users = 1
+	dataset, err := Generate(opts)
+	require.NoError(t, err)
+	require.Len(t, dataset.Users, 1)
+	require.Empty(t, dataset.Follows)
+}
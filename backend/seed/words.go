@@ -0,0 +1,84 @@
+package seed
+
+var firstNames = []string{
+	"Ana", "Lucia", "Sofia", "Valentina", "Camila", "Isabella", "Mariana", "Daniela", "Laura", "Paula",
+	"Sara", "Elena", "Emma", "Olivia", "Mia", "Nora", "Julia", "Clara", "Alba", "Irene",
+	"Mateo", "Santiago", "Sebastian", "Nicolas", "Diego", "Samuel", "Daniel", "David", "Gabriel", "Lucas",
+	"Martin", "Tomas", "Andres", "Felipe", "Pablo", "Hugo", "Leo", "Adrian", "Javier", "Manuel",
+}
+
+var lastNames = []string{
+	"Garcia", "Rodriguez", "Martinez", "Lopez", "Gonzalez", "Perez", "Sanchez", "Ramirez", "Torres", "Flores",
+	"Rivera", "Gomez", "Diaz", "Reyes", "Morales", "Castro", "Ortiz", "Silva", "Rojas", "Vargas",
+	"Smith", "Johnson", "Brown", "Taylor", "Wilson", "Moore", "Clark", "Hall", "Young", "King",
+}
+
+// usernameSuffixes are added to some usernames so they don't all look like a full name
+var usernameSuffixes = []string{
+	"photos", "travels", "cooks", "draws", "runs", "reads", "plays", "music", "studio", "daily",
+}
+
+var postOpenings = []string{
+	"Sunday morning at the park",
+	"Finally finished this one",
+	"Trying a new recipe tonight",
+	"View from the top",
+	"Weekend plans sorted",
+	"Coffee first, everything else later",
+	"Golden hour never disappoints",
+	"Back to the mountains",
+	"Found this street art on the way home",
+	"Homemade bread, attempt number three",
+	"A quiet afternoon with a good book",
+	"The garden is finally blooming",
+	"Rainy day in the city",
+	"First time trying this trail",
+	"Built a tiny robot this week",
+	"Family dinner done right",
+	"Morning run by the river",
+	"New plants for the balcony",
+	"Sketching at the museum",
+	"Road trip snacks ready",
+}
+
+var postEndings = []string{
+	"",
+	"Loving it.",
+	"Who is in for next time?",
+	"Can't wait to go back.",
+	"Highly recommended.",
+	"Not bad for a first try.",
+	"More photos soon.",
+	"Tell me your favorite spot.",
+}
+
+var hashtags = []string{
+	"travel", "food", "coffee", "sunset", "nature", "photography", "weekend", "art", "music", "fitness",
+	"books", "dogs", "cats", "cooking", "mountains", "beach", "city", "friends", "gardening", "robots",
+}
+
+var comments = []string{
+	"Love this!",
+	"Beautiful shot",
+	"Where is this?",
+	"So good",
+	"I need to try this",
+	"Great colors",
+	"This made my day",
+	"Congrats!",
+	"Looks amazing",
+	"Take me with you next time",
+	"What camera do you use?",
+	"Saving this for later",
+}
+
+var replies = []string{
+	"thanks!",
+	"right?",
+	"totally agree",
+	"next time for sure",
+	"it was even better in person",
+	"I'll send you the details",
+	"glad you like it",
+	"haha yes",
+}
@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/DMV-Nicolas/robotgram/backend/util"
+)
+
+var (
+	ErrNotFound   = errors.New("file not found")
+	ErrInvalidKey = errors.New("invalid file key")
+)
+
+// Backend is an interface for storing the uploaded files
+type Backend interface {
+	// Put stores the content of body under the given key
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+
+	// Get opens the file stored under the given key, it returns ErrNotFound if it doesn't exist
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+
+	// Delete removes the file stored under the given key
+	Delete(ctx context.Context, key string) error
+}
+
+// NewBackend creates the Backend selected in the config
+func NewBackend(config util.Config) (Backend, error) {
+	switch config.MediaBackend {
+	case "", "local":
+		return NewLocalBackend(config.MediaLocalDir), nil
+	case "s3":
+		return NewS3Backend(S3Config{
+			Endpoint:  config.S3Endpoint,
+			Region:    config.S3Region,
+			Bucket:    config.S3Bucket,
+			AccessKey: config.S3AccessKey,
+			SecretKey: config.S3SecretKey,
+		})
+	default:
+		return nil, fmt.Errorf("unknown media backend: %s", config.MediaBackend)
+	}
+}
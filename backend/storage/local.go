@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocalBackend stores the files in a directory of the local filesystem
+type LocalBackend struct {
+	dir string
+}
+
+// NewLocalBackend creates a new LocalBackend that stores the files inside dir
+func NewLocalBackend(dir string) Backend {
+	return &LocalBackend{dir: dir}
+}
+
+// Put stores the content of body under the given key
+func (backend *LocalBackend) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	path, err := backend.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		return err
+	}
+
+	// write to a temporary file first so a failed upload never leaves a partial file
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = io.Copy(tmp, body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// Get opens the file stored under the given key
+func (backend *LocalBackend) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := backend.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// Delete removes the file stored under the given key
+func (backend *LocalBackend) Delete(ctx context.Context, key string) error {
+	path, err := backend.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+// path converts the key into a path inside the backend directory
+func (backend *LocalBackend) path(key string) (string, error) {
+	if key == "" || strings.Contains(key, "..") || strings.HasPrefix(key, "/") {
+		return "", ErrInvalidKey
+	}
+
+	return filepath.Join(backend.dir, filepath.FromSlash(key)), nil
+}
@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/DMV-Nicolas/robotgram/backend/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalBackend(t *testing.T) {
+	backend := NewLocalBackend(t.TempDir())
+	testBackend(t, backend)
+}
+
+func TestLocalBackendInvalidKey(t *testing.T) {
+	backend := NewLocalBackend(t.TempDir())
+
+	for _, key := range []string{"", "../secret", "/etc/passwd"} {
+		err := backend.Put(context.TODO(), key, bytes.NewReader(nil), 0, "image/png")
+		require.ErrorIs(t, err, ErrInvalidKey)
+
+		_, err = backend.Get(context.TODO(), key)
+		require.ErrorIs(t, err, ErrInvalidKey)
+	}
+}
+
+// testBackend checks the behavior that every Backend must share
+func testBackend(t *testing.T, backend Backend) {
+	key := util.RandomID().Hex() + "/" + util.RandomID().Hex() + ".png"
+	content := []byte(util.RandomString(64))
+
+	err := backend.Put(context.TODO(), key, bytes.NewReader(content), int64(len(content)), "image/png")
+	require.NoError(t, err)
+
+	file, err := backend.Get(context.TODO(), key)
+	require.NoError(t, err)
+
+	gotContent, err := io.ReadAll(file)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+	require.Equal(t, content, gotContent)
+
+	err = backend.Delete(context.TODO(), key)
+	require.NoError(t, err)
+
+	_, err = backend.Get(context.TODO(), key)
+	require.ErrorIs(t, err, ErrNotFound)
+
+	// deleting a missing file is not an error
+	err = backend.Delete(context.TODO(), key)
+	require.NoError(t, err)
+}
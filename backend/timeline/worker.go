@@ -0,0 +1,220 @@
+package timeline
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	db "github.com/DMV-Nicolas/robotgram/backend/db/mongo"
+	"github.com/DMV-Nicolas/robotgram/backend/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Worker materializes the users timelines by processing the queued timeline jobs
+type Worker struct {
+	queries      db.Querier
+	fanoutLimit  int64
+	batchSize    int64
+	backfillSize int64
+	pollInterval time.Duration
+}
+
+// NewWorker creates a new timeline Worker
+func NewWorker(queries db.Querier, config util.Config) *Worker {
+	return &Worker{
+		queries:      queries,
+		fanoutLimit:  config.TimelineFanoutLimit,
+		batchSize:    config.TimelineBatchSize,
+		backfillSize: config.TimelineBackfillSize,
+		pollInterval: config.TimelinePollInterval,
+	}
+}
+
+// Start processes the queued jobs until the context is canceled
+func (worker *Worker) Start(ctx context.Context) {
+	for {
+		processed, err := worker.ProcessNext(ctx)
+		if err != nil {
+			log.Println("timeline worker:", err)
+		}
+
+		if processed && err == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(worker.pollInterval):
+		}
+	}
+}
+
+// ProcessNext claims and processes the next job of the queue.
+// It returns false when there was no job to process
+func (worker *Worker) ProcessNext(ctx context.Context) (bool, error) {
+	job, err := worker.queries.ClaimTimelineJob(ctx)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return false, nil
+		}
+		return false, err
+	}
+
+	processed, err := worker.process(ctx, job)
+
+	arg := db.UpdateTimelineJobParams{
+		ID:        job.ID,
+		Status:    db.TimelineJobDone,
+		Processed: processed,
+	}
+
+	if err != nil {
+		arg.Status = db.TimelineJobFailed
+		arg.Error = err.Error()
+	}
+
+	_, updateErr := worker.queries.UpdateTimelineJob(ctx, arg)
+	if err != nil {
+		return true, fmt.Errorf("job %s failed: %w", job.ID.Hex(), err)
+	}
+
+	return true, updateErr
+}
+
+func (worker *Worker) process(ctx context.Context, job db.TimelineJob) (int64, error) {
+	switch job.Kind {
+	case db.TimelineJobFanout:
+		return worker.fanout(ctx, job)
+	case db.TimelineJobBackfill:
+		return worker.backfill(ctx, job)
+	case db.TimelineJobCleanup:
+		return worker.cleanup(ctx, job)
+	default:
+		return 0, fmt.Errorf("unknown job kind: %s", job.Kind)
+	}
+}
+
+// fanout copies the post into the timelines of the author followers, saving the last follow of each batch
+// so an interrupted job resumes after it, even if follows were added or removed in between
+func (worker *Worker) fanout(ctx context.Context, job db.TimelineJob) (int64, error) {
+	post, err := worker.queries.GetPost(ctx, "_id", job.PostID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			// the post was deleted before being fanned out
+			return job.Processed, nil
+		}
+		return job.Processed, err
+	}
+
+	pulled, err := worker.pulled(ctx, post.UserID)
+	if err != nil || pulled {
+		return job.Processed, err
+	}
+
+	processed := job.Processed
+	lastFollowID := job.LastFollowID
+	for {
+		arg := db.ListFollowersAfterParams{
+			UserID:  post.UserID,
+			AfterID: lastFollowID,
+			Limit:   worker.batchSize,
+		}
+
+		follows, err := worker.queries.ListFollowersAfter(ctx, arg)
+		if err != nil {
+			return processed, err
+		}
+
+		if len(follows) == 0 {
+			return processed, nil
+		}
+
+		entries := make([]db.TimelineEntry, len(follows))
+		for i, follow := range follows {
+			entries[i] = db.TimelineEntry{
+				OwnerID:   follow.FollowerID,
+				PostID:    post.ID,
+				AuthorID:  post.UserID,
+				CreatedAt: post.CreatedAt,
+			}
+		}
+
+		_, err = worker.queries.AddTimelineEntries(ctx, entries)
+		if err != nil {
+			return processed, err
+		}
+
+		processed += int64(len(follows))
+		lastFollowID = follows[len(follows)-1].ID
+		_, err = worker.queries.UpdateTimelineJob(ctx, db.UpdateTimelineJobParams{
+			ID:           job.ID,
+			Status:       db.TimelineJobProcessing,
+			Processed:    processed,
+			LastFollowID: lastFollowID,
+		})
+		if err != nil {
+			return processed, err
+		}
+
+		if int64(len(follows)) < worker.batchSize {
+			return processed, nil
+		}
+	}
+}
+
+// backfill copies the latest posts of a newly followed account into the follower timeline
+func (worker *Worker) backfill(ctx context.Context, job db.TimelineJob) (int64, error) {
+	pulled, err := worker.pulled(ctx, job.AuthorID)
+	if err != nil || pulled {
+		return 0, err
+	}
+
+	arg := db.BackfillTimelineParams{
+		OwnerID:  job.OwnerID,
+		AuthorID: job.AuthorID,
+		Limit:    worker.backfillSize,
+	}
+
+	result, err := worker.queries.BackfillTimeline(ctx, arg)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.UpsertedCount, nil
+}
+
+// cleanup removes the posts of an unfollowed account from the follower timeline
+func (worker *Worker) cleanup(ctx context.Context, job db.TimelineJob) (int64, error) {
+	arg := db.RemoveTimelineAuthorParams{
+		OwnerID:  job.OwnerID,
+		AuthorID: job.AuthorID,
+	}
+
+	result, err := worker.queries.RemoveTimelineAuthor(ctx, arg)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.DeletedCount, nil
+}
+
+// pulled reports if the posts of the author are read at request time instead of being fanned out,
+// which is the case for every author when the fan-out is disabled and for the very followed accounts
+func (worker *Worker) pulled(ctx context.Context, authorID primitive.ObjectID) (bool, error) {
+	if worker.fanoutLimit <= 0 {
+		return true, nil
+	}
+
+	author, err := worker.queries.GetUser(ctx, "_id", authorID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return true, nil
+		}
+		return false, err
+	}
+
+	return author.FollowersCount >= worker.fanoutLimit, nil
+}
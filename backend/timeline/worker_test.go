@@ -0,0 +1,238 @@
+package timeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mockdb "github.com/DMV-Nicolas/robotgram/backend/db/mock"
+	db "github.com/DMV-Nicolas/robotgram/backend/db/mongo"
+	"github.com/DMV-Nicolas/robotgram/backend/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestProcessNext(t *testing.T) {
+	author := db.User{ID: util.RandomID(), FollowersCount: 3}
+	celebrity := db.User{ID: util.RandomID(), FollowersCount: 100}
+	post := db.Post{ID: util.RandomID(), UserID: author.ID, CreatedAt: time.Now()}
+	follows := []db.Follow{
+		{ID: util.RandomID(), FollowerID: util.RandomID(), FollowingID: author.ID},
+		{ID: util.RandomID(), FollowerID: util.RandomID(), FollowingID: author.ID},
+	}
+
+	testCases := []struct {
+		name       string
+		job        db.TimelineJob
+		buildStubs func(querier *mockdb.MockQuerier, job db.TimelineJob)
+		check      func(t *testing.T, processed bool, err error)
+	}{
+		{
+			name: "FanoutOK",
+			job: db.TimelineJob{
+				ID:       util.RandomID(),
+				Kind:     db.TimelineJobFanout,
+				PostID:   post.ID,
+				AuthorID: author.ID,
+			},
+			buildStubs: func(querier *mockdb.MockQuerier, job db.TimelineJob) {
+				querier.EXPECT().GetPost(gomock.Any(), gomock.Eq("_id"), gomock.Eq(post.ID)).Times(1).Return(post, nil)
+				querier.EXPECT().GetUser(gomock.Any(), gomock.Eq("_id"), gomock.Eq(author.ID)).Times(1).Return(author, nil)
+
+				firstPage := db.ListFollowersAfterParams{UserID: author.ID, Limit: 2}
+				secondPage := db.ListFollowersAfterParams{UserID: author.ID, AfterID: follows[1].ID, Limit: 2}
+				querier.EXPECT().ListFollowersAfter(gomock.Any(), gomock.Eq(firstPage)).Times(1).Return(follows, nil)
+				querier.EXPECT().ListFollowersAfter(gomock.Any(), gomock.Eq(secondPage)).Times(1).Return(nil, nil)
+
+				entries := []db.TimelineEntry{
+					{OwnerID: follows[0].FollowerID, PostID: post.ID, AuthorID: author.ID, CreatedAt: post.CreatedAt},
+					{OwnerID: follows[1].FollowerID, PostID: post.ID, AuthorID: author.ID, CreatedAt: post.CreatedAt},
+				}
+				querier.EXPECT().AddTimelineEntries(gomock.Any(), gomock.Eq(entries)).Times(1).Return(&mongo.BulkWriteResult{UpsertedCount: 2}, nil)
+
+				progress := db.UpdateTimelineJobParams{ID: job.ID, Status: db.TimelineJobProcessing, Processed: 2, LastFollowID: follows[1].ID}
+				done := db.UpdateTimelineJobParams{ID: job.ID, Status: db.TimelineJobDone, Processed: 2}
+				querier.EXPECT().UpdateTimelineJob(gomock.Any(), gomock.Eq(progress)).Times(1).Return(&mongo.UpdateResult{}, nil)
+				querier.EXPECT().UpdateTimelineJob(gomock.Any(), gomock.Eq(done)).Times(1).Return(&mongo.UpdateResult{}, nil)
+			},
+			check: func(t *testing.T, processed bool, err error) {
+				require.NoError(t, err)
+				require.True(t, processed)
+			},
+		},
+		{
+			name: "FanoutResumes",
+			job: db.TimelineJob{
+				ID:           util.RandomID(),
+				Kind:         db.TimelineJobFanout,
+				PostID:       post.ID,
+				AuthorID:     author.ID,
+				Processed:    2,
+				LastFollowID: follows[1].ID,
+			},
+			buildStubs: func(querier *mockdb.MockQuerier, job db.TimelineJob) {
+				querier.EXPECT().GetPost(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(post, nil)
+				querier.EXPECT().GetUser(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(author, nil)
+
+				arg := db.ListFollowersAfterParams{UserID: author.ID, AfterID: follows[1].ID, Limit: 2}
+				querier.EXPECT().ListFollowersAfter(gomock.Any(), gomock.Eq(arg)).Times(1).Return(nil, nil)
+				querier.EXPECT().AddTimelineEntries(gomock.Any(), gomock.Any()).Times(0)
+
+				done := db.UpdateTimelineJobParams{ID: job.ID, Status: db.TimelineJobDone, Processed: 2}
+				querier.EXPECT().UpdateTimelineJob(gomock.Any(), gomock.Eq(done)).Times(1).Return(&mongo.UpdateResult{}, nil)
+			},
+			check: func(t *testing.T, processed bool, err error) {
+				require.NoError(t, err)
+				require.True(t, processed)
+			},
+		},
+		{
+			name: "FanoutSkipsCelebrity",
+			job: db.TimelineJob{
+				ID:       util.RandomID(),
+				Kind:     db.TimelineJobFanout,
+				PostID:   post.ID,
+				AuthorID: celebrity.ID,
+			},
+			buildStubs: func(querier *mockdb.MockQuerier, job db.TimelineJob) {
+				celebrityPost := post
+				celebrityPost.UserID = celebrity.ID
+				querier.EXPECT().GetPost(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(celebrityPost, nil)
+				querier.EXPECT().GetUser(gomock.Any(), gomock.Eq("_id"), gomock.Eq(celebrity.ID)).Times(1).Return(celebrity, nil)
+				querier.EXPECT().ListFollowersAfter(gomock.Any(), gomock.Any()).Times(0)
+
+				done := db.UpdateTimelineJobParams{ID: job.ID, Status: db.TimelineJobDone, Processed: 0}
+				querier.EXPECT().UpdateTimelineJob(gomock.Any(), gomock.Eq(done)).Times(1).Return(&mongo.UpdateResult{}, nil)
+			},
+			check: func(t *testing.T, processed bool, err error) {
+				require.NoError(t, err)
+				require.True(t, processed)
+			},
+		},
+		{
+			name: "FanoutDeletedPost",
+			job: db.TimelineJob{
+				ID:       util.RandomID(),
+				Kind:     db.TimelineJobFanout,
+				PostID:   post.ID,
+				AuthorID: author.ID,
+			},
+			buildStubs: func(querier *mockdb.MockQuerier, job db.TimelineJob) {
+				querier.EXPECT().GetPost(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(db.Post{}, mongo.ErrNoDocuments)
+				querier.EXPECT().ListFollowersAfter(gomock.Any(), gomock.Any()).Times(0)
+
+				done := db.UpdateTimelineJobParams{ID: job.ID, Status: db.TimelineJobDone, Processed: 0}
+				querier.EXPECT().UpdateTimelineJob(gomock.Any(), gomock.Eq(done)).Times(1).Return(&mongo.UpdateResult{}, nil)
+			},
+			check: func(t *testing.T, processed bool, err error) {
+				require.NoError(t, err)
+				require.True(t, processed)
+			},
+		},
+		{
+			name: "FanoutFailed",
+			job: db.TimelineJob{
+				ID:       util.RandomID(),
+				Kind:     db.TimelineJobFanout,
+				PostID:   post.ID,
+				AuthorID: author.ID,
+			},
+			buildStubs: func(querier *mockdb.MockQuerier, job db.TimelineJob) {
+				querier.EXPECT().GetPost(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(post, nil)
+				querier.EXPECT().GetUser(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(author, nil)
+				querier.EXPECT().ListFollowersAfter(gomock.Any(), gomock.Any()).Times(1).Return(nil, mongo.ErrClientDisconnected)
+
+				failed := db.UpdateTimelineJobParams{
+					ID:     job.ID,
+					Status: db.TimelineJobFailed,
+					Error:  mongo.ErrClientDisconnected.Error(),
+				}
+				querier.EXPECT().UpdateTimelineJob(gomock.Any(), gomock.Eq(failed)).Times(1).Return(&mongo.UpdateResult{}, nil)
+			},
+			check: func(t *testing.T, processed bool, err error) {
+				require.ErrorIs(t, err, mongo.ErrClientDisconnected)
+				require.True(t, processed)
+			},
+		},
+		{
+			name: "BackfillOK",
+			job: db.TimelineJob{
+				ID:       util.RandomID(),
+				Kind:     db.TimelineJobBackfill,
+				AuthorID: author.ID,
+				OwnerID:  follows[0].FollowerID,
+			},
+			buildStubs: func(querier *mockdb.MockQuerier, job db.TimelineJob) {
+				querier.EXPECT().GetUser(gomock.Any(), gomock.Eq("_id"), gomock.Eq(author.ID)).Times(1).Return(author, nil)
+
+				arg := db.BackfillTimelineParams{OwnerID: job.OwnerID, AuthorID: author.ID, Limit: 10}
+				querier.EXPECT().BackfillTimeline(gomock.Any(), gomock.Eq(arg)).Times(1).Return(&mongo.BulkWriteResult{UpsertedCount: 4}, nil)
+
+				done := db.UpdateTimelineJobParams{ID: job.ID, Status: db.TimelineJobDone, Processed: 4}
+				querier.EXPECT().UpdateTimelineJob(gomock.Any(), gomock.Eq(done)).Times(1).Return(&mongo.UpdateResult{}, nil)
+			},
+			check: func(t *testing.T, processed bool, err error) {
+				require.NoError(t, err)
+				require.True(t, processed)
+			},
+		},
+		{
+			name: "CleanupOK",
+			job: db.TimelineJob{
+				ID:       util.RandomID(),
+				Kind:     db.TimelineJobCleanup,
+				AuthorID: author.ID,
+				OwnerID:  follows[0].FollowerID,
+			},
+			buildStubs: func(querier *mockdb.MockQuerier, job db.TimelineJob) {
+				arg := db.RemoveTimelineAuthorParams{OwnerID: job.OwnerID, AuthorID: author.ID}
+				querier.EXPECT().RemoveTimelineAuthor(gomock.Any(), gomock.Eq(arg)).Times(1).Return(&mongo.DeleteResult{DeletedCount: 7}, nil)
+
+				done := db.UpdateTimelineJobParams{ID: job.ID, Status: db.TimelineJobDone, Processed: 7}
+				querier.EXPECT().UpdateTimelineJob(gomock.Any(), gomock.Eq(done)).Times(1).Return(&mongo.UpdateResult{}, nil)
+			},
+			check: func(t *testing.T, processed bool, err error) {
+				require.NoError(t, err)
+				require.True(t, processed)
+			},
+		},
+		{
+			name: "EmptyQueue",
+			job:  db.TimelineJob{},
+			buildStubs: func(querier *mockdb.MockQuerier, job db.TimelineJob) {
+				querier.EXPECT().UpdateTimelineJob(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, processed bool, err error) {
+				require.NoError(t, err)
+				require.False(t, processed)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			queries := mockdb.NewMockQuerier(ctrl)
+			if tc.job.ID == primitive.NilObjectID {
+				queries.EXPECT().ClaimTimelineJob(gomock.Any()).Times(1).Return(db.TimelineJob{}, mongo.ErrNoDocuments)
+			} else {
+				queries.EXPECT().ClaimTimelineJob(gomock.Any()).Times(1).Return(tc.job, nil)
+			}
+			tc.buildStubs(queries, tc.job)
+
+			config := util.Config{
+				TimelineFanoutLimit:  10,
+				TimelineBatchSize:    2,
+				TimelineBackfillSize: 10,
+			}
+
+			worker := NewWorker(queries, config)
+			processed, err := worker.ProcessNext(context.TODO())
+			tc.check(t, processed, err)
+		})
+	}
+}
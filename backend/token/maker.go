@@ -8,8 +8,8 @@ import (
 
 // Maker is an interface for managing tokens.
 type Maker interface {
-	// CreateToken creates a new token for the specific username and duration
-	CreateToken(userID primitive.ObjectID, duration time.Duration) (string, *Payload, error)
+	// CreateToken creates a new token of the kind for the specific user, session, role and duration
+	CreateToken(kind string, userID, sessionID primitive.ObjectID, role string, duration time.Duration) (string, *Payload, error)
 
 	// VerifyToken checks if the token is valid or not
 	VerifyToken(token string) (*Payload, error)
@@ -28,9 +28,9 @@ func NewPasetoMaker(symmetricKey string) (Maker, error) {
 	}, nil
 }
 
-// CreateToken creates a new token for the specific username and duration
-func (maker PasetoMaker) CreateToken(userID primitive.ObjectID, duration time.Duration) (string, *Payload, error) {
-	payload := NewPayload(userID, duration)
+// CreateToken creates a new token of the kind for the specific user, session, role and duration
+func (maker PasetoMaker) CreateToken(kind string, userID, sessionID primitive.ObjectID, role string, duration time.Duration) (string, *Payload, error) {
+	payload := NewPayload(kind, userID, sessionID, role, duration)
 	token, err := maker.paseto.Encrypt(maker.symmetricKey, payload, nil)
 	return token, payload, err
 }
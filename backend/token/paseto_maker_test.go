@@ -10,6 +10,7 @@ import (
 
 func TestPasetoToken(t *testing.T) {
 	userID := util.RandomID()
+	sessionID := util.RandomID()
 	duration := time.Minute
 	issuedAt := time.Now()
 	expiresAt := time.Now().Add(duration)
@@ -18,7 +19,7 @@ func TestPasetoToken(t *testing.T) {
 	require.NoError(t, err)
 	require.NotEmpty(t, maker)
 
-	token, payload, err := maker.CreateToken(userID, duration)
+	token, payload, err := maker.CreateToken(KindAccess, userID, sessionID, util.RoleModerator, duration)
 	require.NoError(t, err)
 	require.NotEmpty(t, token)
 	require.NotEmpty(t, payload)
@@ -27,7 +28,10 @@ func TestPasetoToken(t *testing.T) {
 	require.NoError(t, err)
 	require.NotEmpty(t, payload)
 
+	require.Equal(t, KindAccess, payload.Kind)
 	require.Equal(t, userID, payload.UserID)
+	require.Equal(t, sessionID, payload.SessionID)
+	require.Equal(t, util.RoleModerator, payload.Role)
 	require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
 	require.WithinDuration(t, expiresAt, payload.ExpiresAt, time.Second)
 }
@@ -40,7 +44,7 @@ func TestExpiredPasetoToken(t *testing.T) {
 	require.NoError(t, err)
 	require.NotEmpty(t, maker)
 
-	token, payload, err := maker.CreateToken(userID, duration)
+	token, payload, err := maker.CreateToken(KindAccess, userID, util.RandomID(), util.RoleUser, duration)
 	require.NoError(t, err)
 	require.NotEmpty(t, token)
 	require.NotEmpty(t, payload)
@@ -12,19 +12,31 @@ var (
 	ErrInvalidToken = errors.New("token is invalid")
 )
 
+// The access tokens authenticate the requests, the refresh tokens are only accepted to get new tokens
+const (
+	KindAccess  = "access"
+	KindRefresh = "refresh"
+)
+
 // Payload contains the payload data of the token
 type Payload struct {
 	ID        primitive.ObjectID `json:"id"`
+	Kind      string             `json:"kind"`
 	UserID    primitive.ObjectID `json:"user_id"`
+	SessionID primitive.ObjectID `json:"session_id"`
+	Role      string             `json:"role"`
 	IssuedAt  time.Time          `json:"issued_at"`
 	ExpiresAt time.Time          `json:"expires_at"`
 }
 
-// NewPayload creates a new token payload with a specific username and duration
-func NewPayload(userID primitive.ObjectID, duration time.Duration) *Payload {
+// NewPayload creates a new token payload with a specific kind, user, session, role and duration
+func NewPayload(kind string, userID, sessionID primitive.ObjectID, role string, duration time.Duration) *Payload {
 	return &Payload{
 		ID:        primitive.NewObjectID(),
+		Kind:      kind,
 		UserID:    userID,
+		SessionID: sessionID,
+		Role:      role,
 		IssuedAt:  time.Now(),
 		ExpiresAt: time.Now().Add(duration),
 	}
@@ -18,6 +18,25 @@ type Config struct {
 	TokenSymmetricKey    string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
 	AccessTokenDuration  time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
 	RefreshTokenDuration time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
+	TimelineFanoutLimit  int64         `mapstructure:"TIMELINE_FANOUT_LIMIT"`
+	TimelineBatchSize    int64         `mapstructure:"TIMELINE_BATCH_SIZE"`
+	TimelineBackfillSize int64         `mapstructure:"TIMELINE_BACKFILL_SIZE"`
+	TimelinePollInterval time.Duration `mapstructure:"TIMELINE_POLL_INTERVAL"`
+	PurgeGracePeriod     time.Duration `mapstructure:"PURGE_GRACE_PERIOD"`
+	PurgeBatchSize       int64         `mapstructure:"PURGE_BATCH_SIZE"`
+	PurgePollInterval    time.Duration `mapstructure:"PURGE_POLL_INTERVAL"`
+	MediaBackend         string        `mapstructure:"MEDIA_BACKEND"`
+	MediaLocalDir        string        `mapstructure:"MEDIA_LOCAL_DIR"`
+	MediaMaxSize         int64         `mapstructure:"MEDIA_MAX_SIZE"`
+	MediaBaseURL         string        `mapstructure:"MEDIA_BASE_URL"`
+	S3Endpoint           string        `mapstructure:"S3_ENDPOINT"`
+	S3Region             string        `mapstructure:"S3_REGION"`
+	S3Bucket             string        `mapstructure:"S3_BUCKET"`
+	S3AccessKey          string        `mapstructure:"S3_ACCESS_KEY"`
+	S3SecretKey          string        `mapstructure:"S3_SECRET_KEY"`
+	RealtimeBroker       string        `mapstructure:"REALTIME_BROKER"`
+	RealtimeBufferSize   int           `mapstructure:"REALTIME_BUFFER_SIZE"`
+	RealtimePingInterval time.Duration `mapstructure:"REALTIME_PING_INTERVAL"`
 }
 
 // LoadConfig reads configuration from config file or environment variables.
@@ -0,0 +1,86 @@
+package util
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	// MaxHashtagLength is the max number of characters of a hashtag, the longer ones are ignored
+	MaxHashtagLength = 100
+	// MaxPostHashtags is the max number of hashtags kept from a text
+	MaxPostHashtags = 30
+)
+
+// ExtractHashtags returns the normalized hashtags of the text, without repetitions and in order of appearance.
+// A hashtag starts with # and goes on while there are letters, marks, digits or underscores, so "#café",
+// "#東京" and "#go_lang" are hashtags. A # preceded by one of those characters, like in "a#b" or "&#39;",
+// doesn't start a hashtag, and the hashtags made only of digits or underscores are ignored
+func ExtractHashtags(text string) []string {
+	var hashtags []string
+	seen := make(map[string]bool)
+
+	runes := []rune(norm.NFC.String(text))
+	for i := 0; i < len(runes); i++ {
+		if !isHashSign(runes[i]) || (i > 0 && isHashtagRune(runes[i-1])) {
+			continue
+		}
+
+		end := i + 1
+		for end < len(runes) && isHashtagRune(runes[end]) {
+			end++
+		}
+
+		tag := NormalizeHashtag(string(runes[i+1 : end]))
+		i = end - 1
+
+		if tag == "" || seen[tag] {
+			continue
+		}
+
+		seen[tag] = true
+		hashtags = append(hashtags, tag)
+		if len(hashtags) == MaxPostHashtags {
+			break
+		}
+	}
+
+	return hashtags
+}
+
+// NormalizeHashtag returns the canonical form of the hashtag, which is compared ignoring the case and the
+// Unicode representation, so "#Café" and "café" are the same hashtag. It returns an empty string if tag
+// isn't a valid hashtag
+func NormalizeHashtag(tag string) string {
+	tag = strings.TrimLeftFunc(tag, isHashSign)
+	tag = norm.NFKC.String(strings.ToLower(tag))
+
+	hasLetter := false
+	length := 0
+	for _, r := range tag {
+		if !isHashtagRune(r) {
+			return ""
+		}
+
+		if unicode.IsLetter(r) || unicode.IsMark(r) {
+			hasLetter = true
+		}
+		length++
+	}
+
+	if !hasLetter || length > MaxHashtagLength {
+		return ""
+	}
+
+	return tag
+}
+
+func isHashSign(r rune) bool {
+	return r == '#' || r == '＃'
+}
+
+func isHashtagRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsMark(r) || unicode.IsDigit(r) || r == '_'
+}
@@ -0,0 +1,91 @@
+package util
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractHashtags(t *testing.T) {
+	testCases := []struct {
+		name     string
+		text     string
+		hashtags []string
+	}{
+		{
+			name:     "Simple",
+			text:     "my new #robot at the #Beach",
+			hashtags: []string{"robot", "beach"},
+		},
+		{
+			name:     "Unicode",
+			text:     "#Café con leche en #東京 y #niño",
+			hashtags: []string{"café", "東京", "niño"},
+		},
+		{
+			name:     "Decomposed",
+			text:     "#cafe\u0301 #caf\u00e9",
+			hashtags: []string{"café"},
+		},
+		{
+			name:     "Punctuation",
+			text:     "(#first), #second! #third.#fourth",
+			hashtags: []string{"first", "second", "third", "fourth"},
+		},
+		{
+			name:     "Repeated",
+			text:     "#Go #go #GO",
+			hashtags: []string{"go"},
+		},
+		{
+			name:     "NotAfterWord",
+			text:     "a#b &#39; email#tag",
+			hashtags: nil,
+		},
+		{
+			name:     "OnlyDigits",
+			text:     "#1 #2024 #__ #top10",
+			hashtags: []string{"top10"},
+		},
+		{
+			name:     "Fullwidth",
+			text:     "＃ロボット",
+			hashtags: []string{"ロボット"},
+		},
+		{
+			name:     "Empty",
+			text:     "# ## no tags",
+			hashtags: nil,
+		},
+		{
+			name:     "TooLong",
+			text:     "#" + strings.Repeat("a", MaxHashtagLength+1) + " #short",
+			hashtags: []string{"short"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.hashtags, ExtractHashtags(tc.text))
+		})
+	}
+}
+
+func TestExtractHashtagsLimit(t *testing.T) {
+	var text strings.Builder
+	for i := 0; i < MaxPostHashtags+10; i++ {
+		text.WriteString(" #tag" + RandomString(8))
+	}
+
+	require.Len(t, ExtractHashtags(text.String()), MaxPostHashtags)
+}
+
+func TestNormalizeHashtag(t *testing.T) {
+	require.Equal(t, "robot", NormalizeHashtag("#Robot"))
+	require.Equal(t, "robot", NormalizeHashtag("robot"))
+	require.Equal(t, "café", NormalizeHashtag("CAFÉ"))
+	require.Empty(t, NormalizeHashtag("not a tag"))
+	require.Empty(t, NormalizeHashtag("2024"))
+	require.Empty(t, NormalizeHashtag(""))
+}
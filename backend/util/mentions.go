@@ -0,0 +1,67 @@
+package util
+
+// MaxMentions is the max number of mentions kept from a text
+const MaxMentions = 50
+
+// MentionToken is an @username found in a text. Start and End delimit the whole mention, @ included,
+// and are counted in UTF-16 code units like the string indexes of JavaScript, so the clients can slice
+// the text with them directly
+type MentionToken struct {
+	Username string
+	Start    int
+	End      int
+}
+
+// ExtractMentions returns the @username mentions of the text in order of appearance. A username is made of
+// ASCII letters and digits, like the ones accepted on sign up, and an @ preceded by one of those characters,
+// like in an email address, doesn't start a mention
+func ExtractMentions(text string) []MentionToken {
+	var mentions []MentionToken
+
+	runes := []rune(text)
+	offset := 0
+	for i := 0; i < len(runes); i++ {
+		start := offset
+		offset += utf16Len(runes[i])
+		if runes[i] != '@' || (i > 0 && isUsernameRune(runes[i-1])) {
+			continue
+		}
+
+		end := i + 1
+		for end < len(runes) && isUsernameRune(runes[end]) {
+			end++
+		}
+
+		if end == i+1 {
+			continue
+		}
+
+		// the username runes are ASCII, so each one takes a single code unit
+		offset += end - i - 1
+		mentions = append(mentions, MentionToken{
+			Username: string(runes[i+1 : end]),
+			Start:    start,
+			End:      offset,
+		})
+		i = end - 1
+
+		if len(mentions) == MaxMentions {
+			break
+		}
+	}
+
+	return mentions
+}
+
+func isUsernameRune(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+}
+
+// utf16Len returns how many UTF-16 code units encode r, the runes outside the basic plane take a surrogate pair
+func utf16Len(r rune) int {
+	if r >= 0x10000 {
+		return 2
+	}
+
+	return 1
+}
@@ -0,0 +1,69 @@
+package util
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractMentions(t *testing.T) {
+	testCases := []struct {
+		name     string
+		text     string
+		mentions []MentionToken
+	}{
+		{
+			name: "Simple",
+			text: "hi @robot and @Nico42!",
+			mentions: []MentionToken{
+				{Username: "robot", Start: 3, End: 9},
+				{Username: "Nico42", Start: 14, End: 21},
+			},
+		},
+		{
+			name: "Repeated",
+			text: "@a @a",
+			mentions: []MentionToken{
+				{Username: "a", Start: 0, End: 2},
+				{Username: "a", Start: 3, End: 5},
+			},
+		},
+		{
+			name: "UTF16Offsets",
+			text: "🤖 é @robot",
+			mentions: []MentionToken{
+				{Username: "robot", Start: 5, End: 11},
+			},
+		},
+		{
+			name: "Punctuation",
+			text: "(@first),@second.",
+			mentions: []MentionToken{
+				{Username: "first", Start: 1, End: 7},
+				{Username: "second", Start: 9, End: 16},
+			},
+		},
+		{
+			name:     "Email",
+			text:     "write to robot@mail.com",
+			mentions: nil,
+		},
+		{
+			name:     "Empty",
+			text:     "@ @@ @_ no mentions",
+			mentions: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.mentions, ExtractMentions(tc.text))
+		})
+	}
+}
+
+func TestExtractMentionsLimit(t *testing.T) {
+	text := strings.Repeat("@robot ", MaxMentions+10)
+	require.Len(t, ExtractMentions(text), MaxMentions)
+}
@@ -22,24 +22,24 @@ func RandomID() primitive.ObjectID {
 
 // RandomUsername generates a random username.
 func RandomUsername() string {
-	monsters := []string{
-		"Caballo", "Omnitorrinco", "Avion", "HijoDePuta", "Avi",
+	names := []string{
+		"Caballo", "Ornitorrinco", "Avion", "Robot", "Avi",
 		"Luis", "Rodrigo", "Andres", "Santiago", "Diego", "Gustavo",
-		"Juan", "Nicolas", "Cristiansito", "Juliansito", "Valentinita",
+		"Juan", "Nicolas", "Cristian", "Julian", "Valentina",
 		"Sebastian", "David",
 	}
-	actions := []string{
-		"VioladorDe", "AbusadorDe", "TerapeutaDe", "SimpDe", "DesarmadorDe",
-		"DominadoPor", "EsclavizadoPor", "SexualmenteAbusadoPor", "AtraidoPor",
-		"AmanteDelSexoCon", "AsesinadoPor", "TraumadoPor", "LiderDe", "CreadorDe",
+	roles := []string{
+		"AmigoDe", "FanDe", "VecinoDe", "CocineroDe", "FotografoDe",
+		"ProfesorDe", "GuardianDe", "ExploradorDe", "PintorDe",
+		"CuidadorDe", "DibujanteDe", "TerapeutaDe", "LiderDe", "CreadorDe",
 	}
-	victims := []string{
-		"Abuelas", "Feministas", "Comunistas", "Capitalistas", "Langostas",
-		"Hombres", "Jirafas", "Penes", "Duendes",
+	things := []string{
+		"Gatos", "Estrellas", "Volcanes", "Nubes", "Langostas",
+		"Robots", "Jirafas", "Cometas", "Duendes",
 	}
-	str := monsters[rand.Intn(len(monsters))]
-	str += actions[rand.Intn(len(actions))]
-	str += victims[rand.Intn(len(victims))]
+	str := names[rand.Intn(len(names))]
+	str += roles[rand.Intn(len(roles))]
+	str += things[rand.Intn(len(things))]
 	str += fmt.Sprint(rand.Intn(1000))
 	return str
 }
@@ -48,13 +48,13 @@ func RandomUsername() string {
 func RandomEmail() string {
 	names := []string{
 		"abuela", "cristian", "santiago",
-		"feminista", "nicolas", "juan",
-		"comunista", "julian", "diego",
-		"capitalista", "pepito", "valentina",
+		"gato", "nicolas", "juan",
+		"cometa", "julian", "diego",
+		"robot", "pepito", "valentina",
 		"langosta", "avi", "maria",
-		"hombre", "rodolfo", "fernando",
+		"estrella", "rodolfo", "fernando",
 		"jirafa", "gustavo", "proplayer",
-		"pene", "rodrigo", "noob",
+		"volcan", "rodrigo", "noob",
 		"duende", "luis", "hacker",
 	}
 	business := []string{
@@ -80,12 +80,9 @@ func RandomPassword(size int) string {
 	return RandomString(size)
 }
 
-// RandomImage generates a random image.
+// RandomImage generates the URL of a random uploaded image.
 func RandomImage() string {
-	n1 := rand.Intn(100) + 400
-	n2 := rand.Intn(100) + 400
-	image := fmt.Sprintf("https://random.imagecdn.app/%d/%d", n1, n2)
-	return image
+	return fmt.Sprintf("/v1/media/%s", RandomID().Hex())
 }
 
 // RandomImages generates random images.
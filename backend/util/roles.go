@@ -0,0 +1,43 @@
+package util
+
+// The roles a user can have, a user without role is a regular user
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+// The permissions that the roles grant, the regular users have none
+const (
+	// PermissionModerateContent lets edit and delete the content of the other users and handle the reports
+	PermissionModerateContent = "moderate_content"
+	// PermissionManageUsers lets change the role of the users
+	PermissionManageUsers = "manage_users"
+	// PermissionViewJobs lets see the background jobs
+	PermissionViewJobs = "view_jobs"
+)
+
+var rolePermissions = map[string][]string{
+	RoleModerator: {PermissionModerateContent},
+	RoleAdmin:     {PermissionModerateContent, PermissionManageUsers, PermissionViewJobs},
+}
+
+// HasPermission tells if the role grants the permission
+func HasPermission(role, permission string) bool {
+	for _, p := range rolePermissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsSupportedRole tells if the role exists
+func IsSupportedRole(role string) bool {
+	switch role {
+	case RoleUser, RoleModerator, RoleAdmin:
+		return true
+	}
+	return false
+}
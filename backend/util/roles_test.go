@@ -0,0 +1,26 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHasPermission(t *testing.T) {
+	require.False(t, HasPermission(RoleUser, PermissionModerateContent))
+	require.False(t, HasPermission("", PermissionModerateContent))
+
+	require.True(t, HasPermission(RoleModerator, PermissionModerateContent))
+	require.False(t, HasPermission(RoleModerator, PermissionManageUsers))
+
+	for _, permission := range []string{PermissionModerateContent, PermissionManageUsers, PermissionViewJobs} {
+		require.True(t, HasPermission(RoleAdmin, permission))
+	}
+}
+
+func TestIsSupportedRole(t *testing.T) {
+	for _, role := range []string{RoleUser, RoleModerator, RoleAdmin} {
+		require.True(t, IsSupportedRole(role))
+	}
+	require.False(t, IsSupportedRole("root"))
+}
@@ -0,0 +1,58 @@
+package util
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// MaxSearchTerms is the max number of words of a search that are looked up, the rest are ignored
+const MaxSearchTerms = 5
+
+// FoldSearch returns the text lowercased and without accents, so "José" and "jose" are searched alike
+func FoldSearch(text string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(t, text)
+	if err != nil {
+		folded = text
+	}
+
+	return strings.ToLower(folded)
+}
+
+// SearchTerms splits the search in folded words, without repetitions and in order of appearance.
+// Anything that isn't a letter or a digit separates the words
+func SearchTerms(search string) []string {
+	return searchWords(search, MaxSearchTerms)
+}
+
+// UserSearchKeys returns the words a user can be found by: its username and each word of its full name,
+// folded like SearchTerms so a search matches them by prefix
+func UserSearchKeys(username, fullName string) []string {
+	return searchWords(username+" "+fullName, 0)
+}
+
+func searchWords(text string, limit int) []string {
+	fields := strings.FieldsFunc(FoldSearch(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var words []string
+	seen := make(map[string]bool)
+	for _, field := range fields {
+		if seen[field] {
+			continue
+		}
+
+		seen[field] = true
+		words = append(words, field)
+		if len(words) == limit {
+			break
+		}
+	}
+
+	return words
+}
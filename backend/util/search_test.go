@@ -0,0 +1,59 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFoldSearch(t *testing.T) {
+	require.Equal(t, "jose nunez", FoldSearch("José Núñez"))
+	require.Equal(t, "jose", FoldSearch("josé"))
+	require.Equal(t, "東京", FoldSearch("東京"))
+}
+
+func TestSearchTerms(t *testing.T) {
+	testCases := []struct {
+		name   string
+		search string
+		terms  []string
+	}{
+		{
+			name:   "Simple",
+			search: "María José",
+			terms:  []string{"maria", "jose"},
+		},
+		{
+			name:   "Punctuation",
+			search: "  @nico,  garcía-lópez!",
+			terms:  []string{"nico", "garcia", "lopez"},
+		},
+		{
+			name:   "Repeated",
+			search: "Ana ana ANA",
+			terms:  []string{"ana"},
+		},
+		{
+			name:   "TooMany",
+			search: "a b c d e f g",
+			terms:  []string{"a", "b", "c", "d", "e"},
+		},
+		{
+			name:   "Empty",
+			search: " ... ",
+			terms:  nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.terms, SearchTerms(tc.search))
+		})
+	}
+}
+
+func TestUserSearchKeys(t *testing.T) {
+	require.Equal(t, []string{"nicolas", "perez"}, UserSearchKeys("Nicolas", "Nicolás Pérez"))
+	require.Len(t, UserSearchKeys("a", "b c d e f g"), 7)
+	require.Equal(t, []string{"robot42"}, UserSearchKeys("robot42", ""))
+}
@@ -18,8 +18,8 @@ interface Props {
 
 export function Comment({ comment, isDescriptionStyle, isReplyStyle, updateTransform, focusInput, updateInputValue }: Props) {
   const { user } = useUserByID({ userID: comment.userID })
-  const { likes, liked, toggleLike } = useLikes({ targetID: comment.id })
-  const { comments, createComment } = useComments({ targetID: comment.id })
+  const { likes, liked, toggleLike } = useLikes({ targetID: comment.id, likeCount: comment.likeCount })
+  const { comments, createComment } = useComments({ targetID: isDescriptionStyle ? '' : comment.id, replies: true })
   const elapsedTime = getTimeElapsed(comment.createdAt)
 
   const handleToggleLike = () => {
@@ -37,11 +37,14 @@ export function Comment({ comment, isDescriptionStyle, isReplyStyle, updateTrans
       <div className='comment__main'>
         <img className="comment__avatar" src={user.avatar} alt={`Avatar image of ${user.username}`} />
         <div className='comment__text'>
-          <p className='comment__content'>
-            <strong className="comment__username">{user.username} </strong>
-            {comment.content}
-          </p>
-          {!isDescriptionStyle &&
+          {comment.deleted
+            ? <p className='comment__content'><em>This comment was deleted</em></p>
+            : <p className='comment__content'>
+              <strong className="comment__username">{user.username} </strong>
+              {comment.content}
+            </p>
+          }
+          {!isDescriptionStyle && !comment.deleted &&
             <footer className='comment__footer'>
               <small className='comment__small'>{elapsedTime}</small>
               {likes !== 0 &&
@@ -55,7 +58,7 @@ export function Comment({ comment, isDescriptionStyle, isReplyStyle, updateTrans
             </footer>
           }
         </div>
-        {!isDescriptionStyle &&
+        {!isDescriptionStyle && !comment.deleted &&
           <button className='comment__likeButton' onClick={handleToggleLike}>
             {liked
               ? <Heart size={12} />
@@ -4,7 +4,6 @@ import { useCreatePost } from '../hooks/useCreatePost'
 import { useId, useState } from 'react'
 import './CreatePost.css'
 import { toast } from 'sonner'
-import { validateUrl } from '../services/validate'
 
 export function CreatePost() {
   const { createPost } = useCreatePost()
@@ -30,12 +29,12 @@ export function CreatePost() {
 
     let error = false
     const images = inputAmount.map((v) => {
-      const imageUrl = formData.get(`imageUrl${v}`) as string
-      if (!validateUrl(imageUrl)) {
-        toast.error('Invalid Url!')
+      const image = formData.get(`image${v}`) as File
+      if (image.size === 0 || !image.type.startsWith('image/')) {
+        toast.error('Invalid image!')
         error = true
       }
-      return imageUrl
+      return image
     })
 
     if (error) return
@@ -56,10 +55,10 @@ export function CreatePost() {
                 </label>
                 {inputAmount.length !== v + 1
                   ? <>
-                    <input className='createPost__input' type="text" id={inputImageUrlID + v} name={`imageUrl${v}`} placeholder={`Image url ${v + 1}`} />
+                    <input className='createPost__input' type="file" accept="image/jpeg,image/png,image/gif" id={inputImageUrlID + v} name={`image${v}`} />
                   </>
                   : <>
-                    <input className='createPost__input createPost__input--shorter' type="text" id={inputImageUrlID + v} name={`imageUrl${v}`} placeholder={`Image url ${v + 1}`} />
+                    <input className='createPost__input createPost__input--shorter' type="file" accept="image/jpeg,image/png,image/gif" id={inputImageUrlID + v} name={`image${v}`} />
                     <input className='createPost__input createPost__input--increment' type="button" name='increment' value="+" onClick={handleClick} />
                   </>
                 }
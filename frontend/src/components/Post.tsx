@@ -3,7 +3,7 @@ import { useUserByID } from '../hooks/useUserByID'
 import { useLikes } from '../hooks/useLikes'
 import { getTimeElapsed } from '../services/time'
 import { Comment, EmptyHeart, Heart, Options, Save, Share } from './Icons'
-import { type PostType } from '../types'
+import { type ImageType, type PostType } from '../types'
 import { Slider } from './Slider'
 import './Post.css'
 
@@ -30,7 +30,7 @@ export function PostHeader({ username, userAvatar, postCreatedAt }: PostHeaderPr
 }
 
 interface PostBodyProps {
-  postImages: string[]
+  postImages: ImageType[]
   postID: string
   username: string
 }
@@ -94,7 +94,7 @@ interface PostProps {
 
 export function Post({ post }: PostProps) {
   const { user } = useUserByID({ userID: post.userID })
-  const { toggleLike, liked, likes } = useLikes({ targetID: post.id })
+  const { toggleLike, liked, likes } = useLikes({ targetID: post.id, likeCount: post.likeCount })
   const navigate = useNavigate()
 
   const handleToggleLike = () => {
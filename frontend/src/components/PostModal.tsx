@@ -6,14 +6,14 @@ import { Close } from './Icons'
 import { Slider } from './Slider'
 import { PostFooter, PostHeader } from './Post'
 import { Comment } from './Comment'
-import { type CommentType, type PostType, type UserType } from '../types'
+import { type CommentType, type ImageType, type PostType, type UserType } from '../types'
 import { useTransform } from '../hooks/useTransform'
 import './PostModal.css'
 
 interface PostModalLeftProps {
   postID: string
   username: string
-  postImages: string[]
+  postImages: ImageType[]
 }
 
 function PostModalLeft({ postID, username, postImages }: PostModalLeftProps) {
@@ -87,6 +87,9 @@ function PostModalRight({ userID, username, userAvatar, postID, postDescription,
                   targetID: postID,
                   userID,
                   content: postDescription,
+                  likeCount: postLikes,
+                  commentCount: 0,
+                  deleted: false,
                   createdAt: postCreatedAt
                 }}
                 isDescriptionStyle={true}
@@ -141,7 +144,7 @@ interface PostModalProps {
 }
 
 export function PostModal({ user, post }: PostModalProps) {
-  const { likes, liked, toggleLike } = useLikes({ targetID: post.id })
+  const { likes, liked, toggleLike } = useLikes({ targetID: post.id, likeCount: post.likeCount })
   const { comments, createComment } = useComments({ targetID: post.id })
   const navigate = useNavigate()
 
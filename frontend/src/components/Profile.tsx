@@ -49,7 +49,7 @@ function ProfileBody({ posts, username }: ProfileBodyProps) {
         {posts.map((post) => (
           <li className='profileBody__li' key={post.id}>
             <Link to={`/post/${post.id}`}>
-              <img className='profileBody__postImage' src={post.images[0]} alt={`Post image of ${username}`} />
+              <img className='profileBody__postImage' src={post.images[0].thumbnailURL} alt={`Post image of ${username}`} />
             </Link>
           </li>
         ))}
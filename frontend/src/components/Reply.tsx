@@ -15,7 +15,7 @@ interface Props {
 
 export function Reply({ comment, createComment, updateTransform, focusInput, updateInputValue }: Props) {
   const { user } = useUserByID({ userID: comment.userID })
-  const { toggleLike, liked, likes } = useLikes({ targetID: comment.id })
+  const { toggleLike, liked, likes } = useLikes({ targetID: comment.id, likeCount: comment.likeCount })
   const elapsedTime = getTimeElapsed(comment.createdAt)
 
   const handleToggleLike = () => {
@@ -1,10 +1,12 @@
-import { useEffect, useRef, useState } from 'react'
+import { useEffect, useMemo, useRef, useState } from 'react'
+import { type ImageType } from '../types'
+import { blurhashToDataURL } from '../services/blurhash'
 import './Slider.css'
 
 interface Props {
   id: string
   username: string
-  images: string[]
+  images: ImageType[]
   forceLimitHeight: boolean
 }
 
@@ -12,6 +14,7 @@ export function Slider({ id, username, images, forceLimitHeight }: Props) {
   const [slide, setSlide] = useState(0)
   const [sliderHeight, setSliderHeight] = useState(0)
   const sliderRef = useRef<HTMLDivElement>(null)
+  const placeholders = useMemo(() => images.map((image) => blurhashToDataURL(image.blurhash)), [images])
 
   const prevSlide = (): void => {
     if (slide > 0) setSlide(slide - 1)
@@ -30,22 +33,16 @@ export function Slider({ id, username, images, forceLimitHeight }: Props) {
       return
     }
 
+    // the dimensions come with the post, so the space is reserved before the image loads
     const resizeObserver = new ResizeObserver(() => {
       if (!(sliderRef.current instanceof HTMLDivElement)) {
         return
       }
       const sliderWidth = sliderRef.current.offsetWidth
-      const image = new Image()
-      image.src = images[0]
+      const { width, height } = images[0]
+      if (width === 0 || height === 0) return
 
-      image.onload = () => {
-        const height = image.height / (image.width / sliderWidth)
-        if (height >= limitHeight) {
-          setSliderHeight(limitHeight)
-        } else {
-          setSliderHeight(height)
-        }
-      }
+      setSliderHeight(Math.min(height / (width / sliderWidth), limitHeight))
     })
 
     resizeObserver.observe(sliderRef.current)
@@ -53,7 +50,7 @@ export function Slider({ id, username, images, forceLimitHeight }: Props) {
     return () => {
       resizeObserver.disconnect()
     }
-  }, [sliderRef.current])
+  }, [sliderRef.current, images])
 
   return (
     <div className="slider" ref={sliderRef}>
@@ -61,9 +58,14 @@ export function Slider({ id, username, images, forceLimitHeight }: Props) {
         <span className="slider__leftArrow instagramIcons" onClick={prevSlide}></span>
       }
       <img
-        style={{ height: `${sliderHeight}px` }}
+        style={{
+          height: `${sliderHeight}px`,
+          backgroundImage: placeholders[slide] !== '' ? `url(${placeholders[slide]})` : undefined
+        }}
         className="slider__image"
-        src={images[slide]}
+        src={images[slide].url}
+        width={images[slide].width}
+        height={images[slide].height}
         alt={`Post image of ${username}`}
       />
       {slide < images.length - 1 &&
@@ -8,13 +8,25 @@ export const DEFAULT_USER: UserType = {
   fullName: 'Default User',
   gender: 'male',
   id: '',
+  isPrivate: false,
   username: 'defaultuser'
 }
 
 export const DEFAULT_POST: PostType = {
   id: '',
   userID: '',
-  images: ['https://cdn2.iconfinder.com/data/icons/admin-tools-2/25/image2-512.png'],
+  images: [{
+    mediaID: '',
+    url: 'https://cdn2.iconfinder.com/data/icons/admin-tools-2/25/image2-512.png',
+    thumbnailURL: 'https://cdn2.iconfinder.com/data/icons/admin-tools-2/25/image2-512.png',
+    originalURL: 'https://cdn2.iconfinder.com/data/icons/admin-tools-2/25/image2-512.png',
+    width: 512,
+    height: 512,
+    blurhash: ''
+  }],
   description: '',
+  hashtags: [],
+  likeCount: 0,
+  commentCount: 0,
   createdAt: ''
 }
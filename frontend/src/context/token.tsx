@@ -1,4 +1,4 @@
-import { createContext, useState } from 'react'
+import { createContext, useRef, useState } from 'react'
 import { read, store } from '../services/storage'
 import { type RefreshTokenResponse, type TokenContextType } from '../types'
 import { toast } from 'sonner'
@@ -28,10 +28,13 @@ export function TokenProvider({ children }: { children?: React.ReactNode }) {
     store('refresh_token', newToken)
   }
 
-  const refreshAccessToken = async () => {
+  // the refresh token can be used only once, so the concurrent refreshes share the same request
+  const refreshing = useRef<Promise<Error | undefined> | null>(null)
+
+  const requestRefresh = async () => {
     const res = await fetch('http://localhost:5000/v1/token/refresh', {
       method: 'POST',
-      body: JSON.stringify({ refresh_token: refreshToken }),
+      body: JSON.stringify({ refresh_token: read('refresh_token') ?? refreshToken }),
       headers: {
         'Content-Type': 'application/json',
         Accept: 'application/json'
@@ -45,6 +48,17 @@ export function TokenProvider({ children }: { children?: React.ReactNode }) {
 
     const data: RefreshTokenResponse = await res.json()
     updateAccessToken(data.access_token)
+    updateRefreshToken(data.refresh_token)
+  }
+
+  const refreshAccessToken = async () => {
+    if (refreshing.current === null) {
+      refreshing.current = requestRefresh().finally(() => {
+        refreshing.current = null
+      })
+    }
+
+    return await refreshing.current
   }
 
   return (
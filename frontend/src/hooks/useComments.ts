@@ -3,7 +3,8 @@ import { useToken } from './useToken'
 import { toast } from 'sonner'
 import { type ListCommentsResponse, type CommentType } from '../types'
 
-export function useComments({ targetID }: { targetID: string }) {
+// useComments lists the top-level comments of a post, or the replies to a comment when replies is set
+export function useComments({ targetID, replies = false }: { targetID: string, replies?: boolean }) {
   const [comments, setComments] = useState<CommentType[]>([])
   const [reListComments, setReListComments] = useState(false)
   const { accessToken, refreshAccessToken, updateAccessToken, updateRefreshToken } = useToken()
@@ -34,7 +35,10 @@ export function useComments({ targetID }: { targetID: string }) {
 
   useEffect(() => {
     const fetchComments = async () => {
-      const res = await fetch(`http://localhost:5000/v1/comments/${targetID}?offset=0&limit=100`)
+      const url = replies
+        ? `http://localhost:5000/v1/comments/${targetID}/replies?offset=0&limit=100`
+        : `http://localhost:5000/v1/comments/${targetID}?offset=0&limit=100`
+      const res = await fetch(url)
 
       if (!res.ok) {
         toast.error('cannot list comments')
@@ -50,6 +54,9 @@ export function useComments({ targetID }: { targetID: string }) {
           userID: dataComment.user_id,
           targetID: dataComment.target_id,
           content: dataComment.content,
+          likeCount: dataComment.like_count,
+          commentCount: dataComment.comment_count,
+          deleted: dataComment.deleted,
           createdAt: dataComment.created_at
         }
         return comment
@@ -57,8 +64,12 @@ export function useComments({ targetID }: { targetID: string }) {
       setComments(comments.reverse())
     }
 
+    if (targetID.length !== 24) {
+      return
+    }
+
     fetchComments()
-  }, [targetID, reListComments])
+  }, [targetID, replies, reListComments])
 
   return { comments, createComment }
 }
@@ -1,13 +1,55 @@
 import { toast } from 'sonner'
 import { useToken } from './useToken'
+import { type UploadMediaResponse } from '../types'
 
 export function useCreatePost() {
   const { accessToken, refreshAccessToken, updateAccessToken, updateRefreshToken } = useToken()
 
-  const createPost = async ({ description, images }: { description: string, images: string[] }) => {
+  const handleUnauthorized = async () => {
+    const err = await refreshAccessToken()
+    if (err instanceof Error) {
+      updateAccessToken('')
+      updateRefreshToken('')
+    }
+  }
+
+  const uploadMedia = async (file: File) => {
+    const body = new FormData()
+    body.append('file', file)
+
+    const res = await fetch('http://localhost:5000/v1/media', {
+      method: 'POST',
+      body,
+      headers: {
+        Accept: 'application/json',
+        Authorization: `Bearer ${accessToken}`
+      },
+      credentials: 'include'
+    })
+
+    if (!res.ok) {
+      return undefined
+    }
+
+    const data: UploadMediaResponse = await res.json()
+    return data.id
+  }
+
+  const createPost = async ({ description, images }: { description: string, images: File[] }) => {
+    const media: string[] = []
+    for (const image of images) {
+      const id = await uploadMedia(image)
+      if (id === undefined) {
+        toast.error(`cannot upload ${image.name}`)
+        await handleUnauthorized()
+        return
+      }
+      media.push(id)
+    }
+
     const res = await fetch('http://localhost:5000/v1/posts', {
       method: 'POST',
-      body: JSON.stringify({ description, images }),
+      body: JSON.stringify({ description, media }),
       headers: {
         'Content-Type': 'application/json',
         Accept: 'application/json',
@@ -18,11 +60,7 @@ export function useCreatePost() {
 
     if (!res.ok) {
       toast.error('cannot create post')
-      const err = await refreshAccessToken()
-      if (err instanceof Error) {
-        updateAccessToken('')
-        updateRefreshToken('')
-      }
+      await handleUnauthorized()
       return
     }
 
@@ -1,17 +1,18 @@
 import { toast } from 'sonner'
 import { useToken } from './useToken'
 import { useEffect, useState } from 'react'
-import { type LikesCountResponse, type IsLikedResponse } from '../types'
+import { type IsLikedResponse, type LikeResponse } from '../types'
 
-export function useLikes({ targetID }: { targetID: string }) {
+// useLikes starts from the like count stored in the post or comment, so the cards don't have to count them
+export function useLikes({ targetID, likeCount }: { targetID: string, likeCount: number }) {
   const { accessToken, refreshAccessToken, updateAccessToken, updateRefreshToken } = useToken()
-  const [likes, setLikes] = useState(0)
+  const [likes, setLikes] = useState(likeCount)
   const [liked, setLiked] = useState(false)
 
   const toggleLike = async () => {
-    const res = await fetch('http://localhost:5000/v1/likes', {
-      method: 'POST',
-      body: JSON.stringify({ target_id: targetID }),
+    // PUT and DELETE are idempotent, so a double click can't like the target twice
+    const res = await fetch(`http://localhost:5000/v1/likes/${targetID}`, {
+      method: liked ? 'DELETE' : 'PUT',
       headers: {
         'Content-Type': 'application/json',
         Accept: 'application/json',
@@ -29,29 +30,15 @@ export function useLikes({ targetID }: { targetID: string }) {
       }
       return
     }
-    setLikes(liked ? likes - 1 : likes + 1)
-    setLiked(!liked)
+
+    const data: LikeResponse = await res.json()
+    setLikes(data.like_count)
+    setLiked(data.liked)
   }
 
   useEffect(() => {
-    const fetchCountLikes = async () => {
-      const res = await fetch(`http://localhost:5000/v1/likes/${targetID}/count`)
-
-      if (!res.ok) {
-        toast.error('cannot count likes')
-        return
-      }
-
-      const data: LikesCountResponse = await res.json()
-      setLikes(data)
-    }
-
-    if (targetID.length !== 24) {
-      return
-    }
-
-    fetchCountLikes()
-  }, [targetID])
+    setLikes(likeCount)
+  }, [likeCount])
 
   useEffect(() => {
     const fetchIsLiked = async () => {
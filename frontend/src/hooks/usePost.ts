@@ -2,6 +2,7 @@ import { useEffect, useState } from 'react'
 import { DEFAULT_POST } from '../constants'
 import { type PostType, type PostResponse } from '../types'
 import { toast } from 'sonner'
+import { toImage } from '../services/image'
 
 export function usePost({ postID }: { postID: string }) {
   const [post, setPost] = useState(DEFAULT_POST)
@@ -19,8 +20,11 @@ export function usePost({ postID }: { postID: string }) {
       const post: PostType = {
         id: data.id,
         userID: data.user_id,
-        images: data.images,
+        images: data.images.map(toImage),
         description: data.description,
+        hashtags: data.hashtags ?? [],
+        likeCount: data.like_count,
+        commentCount: data.comment_count,
         createdAt: data.created_at
       }
       setPost(post)
@@ -1,6 +1,7 @@
 import { useEffect, useState } from 'react'
 import { type PostType, type ListPostsResponse } from '../types'
 import { toast } from 'sonner'
+import { toImage } from '../services/image'
 
 export function usePosts({ userID }: { userID?: string }) {
   const [posts, setPosts] = useState<PostType[]>([])
@@ -25,8 +26,11 @@ export function usePosts({ userID }: { userID?: string }) {
         const post: PostType = {
           id: dataPost.id,
           userID: dataPost.user_id,
-          images: dataPost.images,
+          images: dataPost.images.map(toImage),
           description: dataPost.description,
+          hashtags: dataPost.hashtags ?? [],
+          likeCount: dataPost.like_count,
+          commentCount: dataPost.comment_count,
           createdAt: dataPost.created_at
         }
         return post
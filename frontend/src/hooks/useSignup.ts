@@ -12,8 +12,7 @@ export function useSignup() {
         email,
         password,
         gender,
-        full_name: username,
-        avatar: 'https://cdn-icons-png.flaticon.com/512/1068/1068549.png'
+        full_name: username
       }),
       headers: {
         'Content-Type': 'application/json'
@@ -21,9 +21,10 @@ export const useUserByID = ({ userID }: { userID: string }) => {
         username: data.username,
         fullName: data.full_name,
         email: data.email,
-        avatar: data.avatar,
+        avatar: data.avatar !== '' ? data.avatar : DEFAULT_USER.avatar,
         description: data.description,
         gender: data.gender,
+        isPrivate: data.is_private,
         createdAt: data.created_at
       }
       setUser(user)
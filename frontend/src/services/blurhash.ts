@@ -0,0 +1,93 @@
+// decoder of the BlurHash placeholders generated by the backend (https://github.com/woltapp/blurhash)
+
+const BASE83_CHARS = '0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz#$%*+,-.:;=?@[]^_{|}~'
+
+const decode83 = (str: string) => {
+  let value = 0
+  for (const char of str) {
+    value = value * 83 + BASE83_CHARS.indexOf(char)
+  }
+  return value
+}
+
+const sRGBToLinear = (value: number) => {
+  const v = value / 255
+  return v <= 0.04045 ? v / 12.92 : Math.pow((v + 0.055) / 1.055, 2.4)
+}
+
+const linearTosRGB = (value: number) => {
+  const v = Math.max(0, Math.min(1, value))
+  return v <= 0.0031308
+    ? Math.round(v * 12.92 * 255 + 0.5)
+    : Math.round((1.055 * Math.pow(v, 1 / 2.4) - 0.055) * 255 + 0.5)
+}
+
+const signPow = (value: number, exp: number) => Math.sign(value) * Math.pow(Math.abs(value), exp)
+
+export function decodeBlurhash(hash: string, width: number, height: number) {
+  const sizeFlag = decode83(hash[0])
+  const numX = (sizeFlag % 9) + 1
+  const numY = Math.floor(sizeFlag / 9) + 1
+  const maximumValue = (decode83(hash[1]) + 1) / 166
+
+  const colors: number[][] = []
+  for (let i = 0; i < numX * numY; i++) {
+    if (i === 0) {
+      const value = decode83(hash.substring(2, 6))
+      colors.push([sRGBToLinear(value >> 16), sRGBToLinear((value >> 8) & 255), sRGBToLinear(value & 255)])
+      continue
+    }
+
+    const value = decode83(hash.substring(4 + i * 2, 6 + i * 2))
+    colors.push([
+      signPow((Math.floor(value / (19 * 19)) - 9) / 9, 2) * maximumValue,
+      signPow(((Math.floor(value / 19) % 19) - 9) / 9, 2) * maximumValue,
+      signPow(((value % 19) - 9) / 9, 2) * maximumValue
+    ])
+  }
+
+  const pixels = new Uint8ClampedArray(width * height * 4)
+  for (let y = 0; y < height; y++) {
+    for (let x = 0; x < width; x++) {
+      let r = 0
+      let g = 0
+      let b = 0
+      for (let j = 0; j < numY; j++) {
+        for (let i = 0; i < numX; i++) {
+          const basis = Math.cos((Math.PI * x * i) / width) * Math.cos((Math.PI * y * j) / height)
+          const color = colors[i + j * numX]
+          r += color[0] * basis
+          g += color[1] * basis
+          b += color[2] * basis
+        }
+      }
+
+      const offset = 4 * (x + y * width)
+      pixels[offset] = linearTosRGB(r)
+      pixels[offset + 1] = linearTosRGB(g)
+      pixels[offset + 2] = linearTosRGB(b)
+      pixels[offset + 3] = 255
+    }
+  }
+
+  return pixels
+}
+
+// blurhashToDataURL draws the placeholder in a small canvas, the browser scales it up smoothly
+export function blurhashToDataURL(hash: string) {
+  if (hash.length < 6) return ''
+
+  const size = 32
+  const canvas = document.createElement('canvas')
+  canvas.width = size
+  canvas.height = size
+
+  const context = canvas.getContext('2d')
+  if (context === null) return ''
+
+  const imageData = context.createImageData(size, size)
+  imageData.data.set(decodeBlurhash(hash, size, size))
+  context.putImageData(imageData, 0, 0)
+
+  return canvas.toDataURL()
+}
@@ -0,0 +1,13 @@
+import { type ImageResponse, type ImageType } from '../types'
+
+export function toImage(data: ImageResponse): ImageType {
+  return {
+    mediaID: data.media_id,
+    url: data.url,
+    thumbnailURL: data.thumbnail_url,
+    originalURL: data.original_url,
+    width: data.width,
+    height: data.height,
+    blurhash: data.blurhash
+  }
+}
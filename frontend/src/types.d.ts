@@ -6,6 +6,8 @@ export interface UserResponse {
   avatar: string
   description: string
   gender: string
+  is_private: boolean
+  role: 'user' | 'moderator' | 'admin'
   created_at: string
 }
 
@@ -17,6 +19,7 @@ export interface UserType {
   avatar: string
   description: string
   gender: string
+  isPrivate: boolean
   createdAt: string
 }
 
@@ -28,19 +31,112 @@ export interface UsersLoginResponse {
   refresh_token_expires_at: string
 }
 
+export interface ImageResponse {
+  media_id: string
+  url: string
+  thumbnail_url: string
+  original_url: string
+  width: number
+  height: number
+  blurhash: string
+}
+
+export interface ImageType {
+  mediaID: string
+  url: string
+  thumbnailURL: string
+  originalURL: string
+  width: number
+  height: number
+  blurhash: string
+}
+
+export interface FollowRequestResponse {
+  id: string
+  follower_id: string
+  following_id: string
+  created_at: string
+}
+
+export interface BlockResponse {
+  id: string
+  blocker_id: string
+  blocked_id: string
+  created_at: string
+}
+
+export interface MuteResponse {
+  id: string
+  muter_id: string
+  muted_id: string
+  created_at: string
+}
+
+export interface ReportRequest {
+  target_type: 'post' | 'comment' | 'user'
+  target_id: string
+  reason: 'spam' | 'harassment' | 'hate_speech' | 'violence' | 'nudity' | 'self_harm' | 'misinformation' | 'impersonation' | 'other'
+  details?: string
+}
+
+export interface NotificationResponse {
+  id: string
+  user_id: string
+  type: 'like' | 'comment' | 'reply' | 'follow' | 'mention' | 'follow_request'
+  target_id: string
+  actor_ids: string[]
+  actor_count: number
+  read: boolean
+  created_at: string
+  updated_at: string
+}
+
+export interface ConversationResponse {
+  id: string
+  members: Array<{ user_id: string, last_read_id: string }>
+  last_message_at: string
+  created_at: string
+  unread_count?: number
+}
+
+export interface MessageResponse {
+  id: string
+  conversation_id: string
+  user_id: string
+  content: string
+  post_id: string
+  deleted: boolean
+  edited_at: string
+  created_at: string
+}
+
+export interface MentionResponse {
+  user_id: string
+  start: number
+  end: number
+}
+
 export interface PostResponse {
   id: string
   user_id: string
-  images: string[]
+  images: ImageResponse[]
   description: string
+  hashtags: string[] | null
+  mentions: MentionResponse[] | null
+  like_count: number
+  comment_count: number
+  removed: boolean
   created_at: string
 }
 
 export interface PostType {
   id: string
   userID: string
-  images: string[]
+  images: ImageType[]
   description: string
+  hashtags: string[]
+  likeCount: number
+  commentCount: number
   createdAt: string
 }
 
@@ -50,7 +146,14 @@ export interface CommentResponse {
   id: string
   user_id: string
   target_id: string
+  parent_id: string
+  root_post_id: string
   content: string
+  mentions: MentionResponse[] | null
+  like_count: number
+  comment_count: number
+  deleted: boolean
+  removed: boolean
   created_at: string
 }
 
@@ -59,19 +162,27 @@ export interface CommentType {
   userID: string
   targetID: string
   content: string
+  likeCount: number
+  commentCount: number
+  deleted: boolean
   createdAt: string
 }
 
 export type ListCommentsResponse = CommentResponse[] | null
 
 export interface RefreshTokenResponse {
+  session_id: string
   access_token: string
   access_token_expires_at: string
+  refresh_token: string
+  refresh_token_expires_at: string
 }
 
 export interface GetTokenDataResponse {
   id: string
   user_id: string
+  session_id: string
+  role: 'user' | 'moderator' | 'admin'
   issued_at: string
   expires_at: string
 }
@@ -84,10 +195,30 @@ export interface TokenContextType {
   refreshAccessToken: () => Promise<Error | undefined>
 }
 
-export type LikesCountResponse = number
-
 export type IsLikedResponse = boolean
 
+export interface LikeResponse {
+  liked: boolean
+  like_count: number
+}
+
+export interface RenditionResponse {
+  name: string
+  url: string
+  content_type: string
+  width: number
+  height: number
+  size: number
+}
+
+export interface UploadMediaResponse {
+  id: string
+  width: number
+  height: number
+  blurhash: string
+  renditions: RenditionResponse[]
+}
+
 export interface CreatedResponse {
   InsertedID: string
 }